@@ -0,0 +1,467 @@
+// Command langlex tokenizes a file with a registered language or a
+// TextMate grammar and prints the tokens, for debugging grammars and
+// for use in shell pipelines.
+//
+// Usage:
+//
+//	langlex [flags] [file]
+//
+// The input is read from file, or from the standard input if no file
+// is given. The language is chosen with -lang, or else from the
+// extension of file. The flags are:
+//
+//	-lang name
+//		lex with the registered language name, such as "json"
+//	-textmate file
+//		lex with the TextMate grammar in file, in JSON form
+//	-format table|json|annotate
+//		print a table of tokens (the default), one JSON object per
+//		token, or the source with every token annotated with its kind
+//	-list
+//		list the registered languages and exit
+//
+// The exit status is 1 if the input holds lexical errors and 2 if the
+// input cannot be read or the flags are invalid.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andrieee44/langengine/csvlex"
+	"github.com/andrieee44/langengine/jsonlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/andrieee44/langengine/shlex"
+)
+
+// errLexical reports that the input holds lexical errors, which were
+// printed along with the other tokens.
+var errLexical = errors.New("input holds lexical errors")
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+type jsonToken struct {
+	Kind   string       `json:"kind"`
+	Text   string       `json:"text"`
+	Start  jsonPosition `json:"start"`
+	End    jsonPosition `json:"end"`
+	Trivia bool         `json:"trivia,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func init() {
+	var (
+		lang lexer.Language
+		err  error
+	)
+
+	for _, lang = range builtins() {
+		err = lexer.Register(lang)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// run runs the command with args and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var (
+		flags    *flag.FlagSet
+		name     string
+		textmate string
+		format   string
+		list     bool
+		lang     lexer.Language
+		src      io.Reader
+		file     *os.File
+		err      error
+	)
+
+	flags = flag.NewFlagSet("langlex", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&name, "lang", "", "lex with the registered language `name`")
+	flags.StringVar(&textmate, "textmate", "", "lex with the TextMate grammar in `file`")
+	flags.StringVar(&format, "format", "table", "print tokens as table, json, or annotate")
+	flags.BoolVar(&list, "list", false, "list the registered languages and exit")
+
+	err = flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if list {
+		err = listLanguages(stdout)
+
+		return status(stderr, err)
+	}
+
+	if flags.NArg() > 1 {
+		return status(stderr, errors.New("at most one file may be given"))
+	}
+
+	lang, err = chooseLanguage(name, textmate, flags.Arg(0))
+	if err != nil {
+		return status(stderr, err)
+	}
+
+	src = stdin
+
+	if flags.NArg() == 1 {
+		file, err = os.Open(flags.Arg(0))
+		if err != nil {
+			return status(stderr, err)
+		}
+
+		defer file.Close()
+
+		src = file
+	}
+
+	switch format {
+	case "table":
+		err = printTable(stdout, lang, src)
+	case "json":
+		err = printJSON(stdout, lang, src)
+	case "annotate":
+		err = printAnnotated(stdout, lang, src)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+
+	return status(stderr, err)
+}
+
+// status reports err, if any, and returns the matching exit status.
+func status(stderr io.Writer, err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errLexical):
+		fmt.Fprintf(stderr, "langlex: %v\n", err)
+
+		return 1
+	default:
+		fmt.Fprintf(stderr, "langlex: %v\n", err)
+
+		return 2
+	}
+}
+
+func chooseLanguage(name, textmate, file string) (lexer.Language, error) {
+	var (
+		lang lexer.Language
+		ok   bool
+	)
+
+	switch {
+	case textmate != "":
+		return loadTextMate(textmate)
+	case name != "":
+		lang, ok = lexer.ForLanguage(name)
+		if !ok {
+			return lexer.Language{}, fmt.Errorf("unknown language %q", name)
+		}
+	case file != "":
+		lang, ok = lexer.ForFile(file)
+		if !ok {
+			return lexer.Language{}, fmt.Errorf("no language for %q, use -lang", file)
+		}
+	default:
+		return lexer.Language{}, errors.New("no language given, use -lang")
+	}
+
+	return lang, nil
+}
+
+// loadTextMate returns a language lexing with the TextMate grammar in
+// file, naming every kind after its scope.
+func loadTextMate(file string) (lexer.Language, error) {
+	var (
+		data    []byte
+		names   map[lexer.Kind]string
+		scopes  map[string]lexer.Kind
+		imp     lexgen.Import
+		grammar *lexgen.Grammar
+		err     error
+	)
+
+	data, err = os.ReadFile(file)
+	if err != nil {
+		return lexer.Language{}, err
+	}
+
+	names = make(map[lexer.Kind]string)
+	scopes = make(map[string]lexer.Kind)
+
+	imp, err = lexgen.ImportTextMate(data, func(scope string) lexer.Kind {
+		var (
+			kind lexer.Kind
+			ok   bool
+		)
+
+		kind, ok = scopes[scope]
+		if !ok {
+			kind = lexer.KindUser + lexer.Kind(len(scopes))
+			scopes[scope] = kind
+			names[kind] = scope
+		}
+
+		return kind
+	})
+	if err != nil {
+		return lexer.Language{}, err
+	}
+
+	grammar, err = lexgen.Compile(imp.Rules)
+	if err != nil {
+		return lexer.Language{}, err
+	}
+
+	return lexer.Language{
+		Name:      file,
+		NewLexer:  grammar.NewLexer,
+		KindNames: names,
+	}, nil
+}
+
+func listLanguages(w io.Writer) error {
+	var (
+		lang lexer.Language
+		err  error
+	)
+
+	for _, lang = range lexer.Languages() {
+		_, err = fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\n",
+			lang.Name,
+			strings.Join(lang.Aliases, ","),
+			strings.Join(lang.Extensions, ","),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lex calls fn for every token of src lexed with lang, stopping at the
+// first error fn returns. Returns errLexical if the input holds lexical
+// errors, or the error of the input if it cannot be read.
+func lex(lang lexer.Language, src io.Reader, fn func(lexer.Token) error) error {
+	var (
+		lx      *lexer.Lexer
+		tok     lexer.Token
+		invalid bool
+		err     error
+	)
+
+	lx = lang.NewLexer(src)
+
+	for tok = range lx.Tokens() {
+		invalid = invalid || tok.Kind == lexer.KindError
+
+		err = fn(tok)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case lx.Err() != nil && lx.Err() != io.EOF:
+		return lx.Err()
+	case invalid:
+		return errLexical
+	default:
+		return nil
+	}
+}
+
+func printTable(w io.Writer, lang lexer.Language, src io.Reader) error {
+	var (
+		tw  *tabwriter.Writer
+		err error
+	)
+
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	err = lex(lang, src, func(tok lexer.Token) error {
+		var (
+			kind string
+			err  error
+		)
+
+		kind = lang.KindName(tok.Kind)
+		if tok.Trivia {
+			kind += " (trivia)"
+		}
+
+		_, err = fmt.Fprintf(
+			tw,
+			"%d:%d\t%s\t%q\n",
+			tok.Span.Start.Line,
+			tok.Span.Start.Column,
+			kind,
+			tok.Text,
+		)
+
+		return err
+	})
+
+	return errors.Join(tw.Flush(), err)
+}
+
+func printJSON(w io.Writer, lang lexer.Language, src io.Reader) error {
+	var enc *json.Encoder
+
+	enc = json.NewEncoder(w)
+
+	return lex(lang, src, func(tok lexer.Token) error {
+		return enc.Encode(jsonToken{
+			Kind:   lang.KindName(tok.Kind),
+			Text:   tok.Text,
+			Start:  jsonPosition(tok.Span.Start),
+			End:    jsonPosition(tok.Span.End),
+			Trivia: tok.Trivia,
+		})
+	})
+}
+
+// printAnnotated prints the source with every token that is not trivia
+// bracketed and labeled with its kind, such as [String "a"], and every
+// error inserted where it occurred.
+func printAnnotated(w io.Writer, lang lexer.Language, src io.Reader) error {
+	var (
+		data      []byte
+		offset    int
+		err, werr error
+	)
+
+	data, err = io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	err = lex(lang, strings.NewReader(string(data)), func(tok lexer.Token) error {
+		var (
+			gap  []byte
+			text string
+			err  error
+		)
+
+		if tok.Span.Start.Offset > offset {
+			gap = data[offset:tok.Span.Start.Offset]
+		}
+
+		switch {
+		case tok.Kind == lexer.KindError:
+			text = fmt.Sprintf(
+				"%s[Error: %s]%s",
+				gap,
+				tok.Text,
+				tok.Span.Raw(data),
+			)
+		case tok.Trivia:
+			text = fmt.Sprintf("%s%s", gap, tok.Span.Raw(data))
+		default:
+			text = fmt.Sprintf(
+				"%s[%s %s]",
+				gap,
+				lang.KindName(tok.Kind),
+				tok.Span.Raw(data),
+			)
+		}
+
+		offset = max(offset, tok.Span.End.Offset)
+		_, err = io.WriteString(w, text)
+
+		return err
+	})
+
+	if err != nil && !errors.Is(err, errLexical) {
+		return err
+	}
+
+	_, werr = w.Write(data[min(offset, len(data)):])
+
+	return errors.Join(werr, err)
+}
+
+// builtins returns the languages of the format packages of the module.
+func builtins() []lexer.Language {
+	var (
+		csv, tsv lexer.Language
+		names    map[lexer.Kind]string
+	)
+
+	names = map[lexer.Kind]string{
+		csvlex.KindField:  "Field",
+		csvlex.KindRecord: "Record",
+	}
+
+	csv = lexer.Language{
+		Name:       "csv",
+		Extensions: []string{".csv"},
+		NewLexer: func(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+			return csvlex.NewLexer(rd, csvlex.CSV, opts...)
+		},
+		KindNames: names,
+	}
+
+	tsv = lexer.Language{
+		Name:       "tsv",
+		Extensions: []string{".tsv", ".tab"},
+		NewLexer: func(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+			return csvlex.NewLexer(rd, csvlex.TSV, opts...)
+		},
+		KindNames: names,
+	}
+
+	return []lexer.Language{
+		{
+			Name:       "json",
+			Extensions: []string{".json"},
+			NewLexer:   jsonlex.NewLexer,
+			KindNames: map[lexer.Kind]string{
+				jsonlex.KindBeginObject:    "BeginObject",
+				jsonlex.KindEndObject:      "EndObject",
+				jsonlex.KindBeginArray:     "BeginArray",
+				jsonlex.KindEndArray:       "EndArray",
+				jsonlex.KindNameSeparator:  "NameSeparator",
+				jsonlex.KindValueSeparator: "ValueSeparator",
+				jsonlex.KindString:         "String",
+				jsonlex.KindNumber:         "Number",
+				jsonlex.KindTrue:           "True",
+				jsonlex.KindFalse:          "False",
+				jsonlex.KindNull:           "Null",
+			},
+		},
+		csv,
+		tsv,
+		{
+			Name:       "sh",
+			Aliases:    []string{"shell", "bash"},
+			Extensions: []string{".sh", ".bash"},
+			NewLexer:   shlex.NewLexer,
+			KindNames: map[lexer.Kind]string{
+				shlex.KindWord:     "Word",
+				shlex.KindOperator: "Operator",
+			},
+		},
+	}
+}
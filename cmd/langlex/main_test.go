@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	type testData struct {
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		status int
+	}
+
+	var (
+		dir     string
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	dir = t.TempDir()
+
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "words.json"),
+		[]byte(`{"scopeName": "source.words", "patterns": [
+			{"match": "[a-z]+", "name": "word"},
+			{"match": " +"}
+		]}`),
+		0o600,
+	))
+
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "input.sh"),
+		[]byte("ls | wc"),
+		0o600,
+	))
+
+	testTbl = map[string]testData{
+		"Table": {
+			args:   []string{"-lang", "json"},
+			stdin:  "[1]",
+			stdout: "1:1  BeginArray  \"[\"\n1:2  Number      \"1\"\n1:3  EndArray    \"]\"\n",
+		},
+		"JSON": {
+			args:  []string{"-format", "json", "-lang", "CSV"},
+			stdin: "a",
+			stdout: `{"kind":"Field","text":"a","start":{"line":1,"column":1,"offset":0},"end":{"line":1,"column":2,"offset":1}}` + "\n" +
+				`{"kind":"Record","text":"","start":{"line":1,"column":2,"offset":1},"end":{"line":1,"column":2,"offset":1}}` + "\n",
+		},
+		"Annotate": {
+			args:   []string{"-format", "annotate", filepath.Join(dir, "input.sh")},
+			stdout: "[Word ls] [Operator |] [Word wc]",
+		},
+		"TextMate": {
+			args:   []string{"-format", "annotate", "-textmate", filepath.Join(dir, "words.json")},
+			stdin:  "ab cd!",
+			stdout: "[word ab][source.words  ][word cd][source.words !]",
+		},
+		"LexicalError": {
+			args:   []string{"-format", "annotate", "-lang", "json"},
+			stdin:  "[nul]",
+			stdout: "[BeginArray []" + `[Error: invalid literal "nul"]nul]`,
+			stderr: "langlex: input holds lexical errors\n",
+			status: 1,
+		},
+		"UnknownLanguage": {
+			args:   []string{"-lang", "cobol"},
+			stderr: "langlex: unknown language \"cobol\"\n",
+			status: 2,
+		},
+		"NoLanguage": {
+			args:   []string{filepath.Join(dir, "input.txt")},
+			stderr: "langlex: no language for \"" + filepath.Join(dir, "input.txt") + "\", use -lang\n",
+			status: 2,
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+
+			t.Parallel()
+
+			assert.Equal(t, test.status, run(
+				test.args,
+				strings.NewReader(test.stdin),
+				&stdout,
+				&stderr,
+			))
+			assert.Equal(t, test.stdout, stdout.String())
+			assert.Equal(t, test.stderr, stderr.String())
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package csvlex implements a lexer for CSV, TSV, and similar delimited
+// record formats on top of the lexer package. It follows the quoting
+// rules of RFC 4180 while allowing the delimiter, quote, and escape
+// characters to be configured through a Dialect.
+//
+// Unlike encoding/csv, every field and record boundary is reported as a
+// lexer.Token carrying its exact span, so malformed input can be
+// diagnosed down to the offending rune.
+package csvlex // import "github.com/andrieee44/langengine/csvlex"
+
+import (
+	"io"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindField is the kind of a single field. Its text is the field
+	// exactly as written, including any enclosing quotes; use
+	// Dialect.Unquote to obtain the field value.
+	KindField lexer.Kind = lexer.KindUser + iota
+
+	// KindRecord is the kind of the token that terminates a record. Its
+	// text is the line terminator ("\n" or "\r\n"), or empty for a final
+	// record that is not followed by a line terminator.
+	KindRecord
+)
+
+// Dialect describes the punctuation of a delimited record format.
+type Dialect struct {
+	// Delimiter separates the fields of a record.
+	Delimiter rune
+
+	// Quote encloses fields that contain delimiters, quotes, or line
+	// terminators. A zero Quote disables quoting entirely.
+	Quote rune
+
+	// Escape introduces an escaped character inside a quoted field. A
+	// zero Escape selects the RFC 4180 convention, where a quote inside
+	// a quoted field is written as two quotes.
+	Escape rune
+}
+
+var (
+	// CSV is the dialect described by RFC 4180.
+	CSV = Dialect{
+		Delimiter: ',',
+		Quote:     '"',
+	}
+
+	// TSV is the tab-separated dialect described by the IANA
+	// text/tab-separated-values registration, which has no quoting.
+	TSV = Dialect{
+		Delimiter: '\t',
+	}
+)
+
+// NewLexer returns a lexer.Lexer that splits rd into KindField and
+// KindRecord tokens according to dialect. Malformed input, such as an
+// unterminated quoted field or a quote inside an unquoted field, stops
+// the Lexer with a lexer.KindError token spanning the offending text.
+// The options configure the underlying lexer.Reader.
+func NewLexer(
+	rd io.Reader,
+	dialect Dialect,
+	opts ...lexer.Option,
+) *lexer.Lexer {
+	return lexer.NewLexer(rd, dialect.lexRecord, opts...)
+}
+
+// Unquote returns the value of a KindField token text by removing the
+// enclosing quotes and resolving doubled quotes or escape sequences.
+// Text that is not quoted is returned unchanged.
+func (dialect Dialect) Unquote(text string) string {
+	var (
+		quote string
+		sb    strings.Builder
+		runes []rune
+		idx   int
+	)
+
+	quote = string(dialect.Quote)
+
+	if dialect.Quote == 0 ||
+		len(text) < 2*len(quote) ||
+		!strings.HasPrefix(text, quote) ||
+		!strings.HasSuffix(text, quote) {
+		return text
+	}
+
+	text = text[len(quote) : len(text)-len(quote)]
+	if dialect.Escape == 0 {
+		return strings.ReplaceAll(text, quote+quote, quote)
+	}
+
+	runes = []rune(text)
+
+	for idx = 0; idx < len(runes); idx++ {
+		if runes[idx] == dialect.Escape && idx+1 < len(runes) {
+			idx++
+		}
+
+		sb.WriteRune(runes[idx])
+	}
+
+	return sb.String()
+}
+
+func (dialect Dialect) lexRecord(lx *lexer.Lexer) lexer.StateFn {
+	if lx.AtEOF() {
+		return nil
+	}
+
+	return dialect.lexField
+}
+
+func (dialect Dialect) lexField(lx *lexer.Lexer) lexer.StateFn {
+	if dialect.Quote != 0 && lx.Accept(string(dialect.Quote)) {
+		return dialect.lexQuoted
+	}
+
+	until(lx, func(char rune) bool {
+		return char == dialect.Delimiter ||
+			char == '\r' ||
+			char == '\n' ||
+			dialect.Quote != 0 && char == dialect.Quote
+	})
+
+	if dialect.Quote != 0 && lx.Accept(string(dialect.Quote)) {
+		return lx.Errorf("bare %q in non-quoted field", dialect.Quote)
+	}
+
+	lx.Emit(KindField)
+
+	return dialect.lexSeparator
+}
+
+func (dialect Dialect) lexQuoted(lx *lexer.Lexer) lexer.StateFn {
+	var char rune
+
+	for {
+		until(lx, func(char rune) bool {
+			return char == dialect.Quote ||
+				dialect.Escape != 0 && char == dialect.Escape
+		})
+
+		if lx.AtEOF() {
+			return lx.Errorf("unterminated quoted field")
+		}
+
+		char = lx.Next()
+
+		switch {
+		case char == dialect.Escape && char != dialect.Quote:
+			if lx.AtEOF() {
+				return lx.Errorf("unterminated quoted field")
+			}
+
+			lx.Next()
+		case dialect.Escape != 0 && dialect.Escape != dialect.Quote:
+			lx.Emit(KindField)
+
+			return dialect.lexSeparator
+		case !lx.Accept(string(dialect.Quote)):
+			lx.Emit(KindField)
+
+			return dialect.lexSeparator
+		}
+	}
+}
+
+func (dialect Dialect) lexSeparator(lx *lexer.Lexer) lexer.StateFn {
+	var (
+		end  bool
+		char rune
+	)
+
+	end = lx.AtEOF()
+	char = lx.Next()
+
+	switch {
+	case end, char == '\n':
+		lx.Emit(KindRecord)
+
+		return dialect.lexRecord
+	case char == dialect.Delimiter:
+		lx.Ignore()
+
+		return dialect.lexField
+	case char == '\r' && lx.Accept("\n"):
+		lx.Emit(KindRecord)
+
+		return dialect.lexRecord
+	default:
+		return lx.Errorf("unexpected %q after field", char)
+	}
+}
+
+// until consumes runes up to the end of input or up to the first rune
+// for which stop returns true. Unlike UntilFunc, it does not stop at a
+// NUL byte, which a field holds like any other rune.
+func until(lx *lexer.Lexer, stop func(rune) bool) {
+	for !lx.AtEOF() && !stop(lx.Peek()) {
+		lx.Next()
+	}
+}
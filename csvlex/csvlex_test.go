@@ -0,0 +1,164 @@
+package csvlex_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/csvlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkToken(
+	kind lexer.Kind,
+	text string,
+	start, end lexer.Position,
+) lexer.Token {
+	return lexer.Token{
+		Kind: kind,
+		Text: text,
+		Span: lexer.Span{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+func pos(line, column, offset int) lexer.Position {
+	return lexer.Position{
+		Line:   line,
+		Column: column,
+		Offset: offset,
+	}
+}
+
+func TestNewLexer(t *testing.T) {
+	type testData struct {
+		content string
+		dialect csvlex.Dialect
+		tokens  []lexer.Token
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Base": {
+			content: "a,b\r\nc,d\n",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "a", pos(1, 1, 0), pos(1, 2, 1)),
+				mkToken(csvlex.KindField, "b", pos(1, 3, 2), pos(1, 4, 3)),
+				mkToken(csvlex.KindRecord, "\r\n", pos(1, 4, 3), pos(2, 1, 5)),
+				mkToken(csvlex.KindField, "c", pos(2, 1, 5), pos(2, 2, 6)),
+				mkToken(csvlex.KindField, "d", pos(2, 3, 7), pos(2, 4, 8)),
+				mkToken(csvlex.KindRecord, "\n", pos(2, 4, 8), pos(3, 1, 9)),
+			},
+		},
+		"EmptyFields": {
+			content: ",",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "", pos(1, 1, 0), pos(1, 1, 0)),
+				mkToken(csvlex.KindField, "", pos(1, 2, 1), pos(1, 2, 1)),
+				mkToken(csvlex.KindRecord, "", pos(1, 2, 1), pos(1, 2, 1)),
+			},
+		},
+		"Quoted": {
+			content: "\"a,\"\"b\"\"\n\",c",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "\"a,\"\"b\"\"\n\"", pos(1, 1, 0), pos(2, 2, 10)),
+				mkToken(csvlex.KindField, "c", pos(2, 3, 11), pos(2, 4, 12)),
+				mkToken(csvlex.KindRecord, "", pos(2, 4, 12), pos(2, 4, 12)),
+			},
+		},
+		"NUL": {
+			content: "a\x00b,\"\x00\"\nc",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "a\x00b", pos(1, 1, 0), pos(1, 4, 3)),
+				mkToken(csvlex.KindField, "\"\x00\"", pos(1, 5, 4), pos(1, 8, 7)),
+				mkToken(csvlex.KindRecord, "\n", pos(1, 8, 7), pos(2, 1, 8)),
+				mkToken(csvlex.KindField, "c", pos(2, 1, 8), pos(2, 2, 9)),
+				mkToken(csvlex.KindRecord, "", pos(2, 2, 9), pos(2, 2, 9)),
+			},
+		},
+		"Escaped": {
+			content: `'a\'b';c`,
+			dialect: csvlex.Dialect{
+				Delimiter: ';',
+				Quote:     '\'',
+				Escape:    '\\',
+			},
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, `'a\'b'`, pos(1, 1, 0), pos(1, 7, 6)),
+				mkToken(csvlex.KindField, "c", pos(1, 8, 7), pos(1, 9, 8)),
+				mkToken(csvlex.KindRecord, "", pos(1, 9, 8), pos(1, 9, 8)),
+			},
+		},
+		"TSV": {
+			content: "a\t\"b\n",
+			dialect: csvlex.TSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "a", pos(1, 1, 0), pos(1, 2, 1)),
+				mkToken(csvlex.KindField, "\"b", pos(1, 3, 2), pos(1, 5, 4)),
+				mkToken(csvlex.KindRecord, "\n", pos(1, 5, 4), pos(2, 1, 5)),
+			},
+		},
+		"Unterminated": {
+			content: "a\n\"b",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "a", pos(1, 1, 0), pos(1, 2, 1)),
+				mkToken(csvlex.KindRecord, "\n", pos(1, 2, 1), pos(2, 1, 2)),
+				mkToken(lexer.KindError, "unterminated quoted field", pos(2, 1, 2), pos(2, 3, 4)),
+			},
+		},
+		"BareQuote": {
+			content: "ab\"c",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(lexer.KindError, `bare '"' in non-quoted field`, pos(1, 1, 0), pos(1, 4, 3)),
+			},
+		},
+		"TrailingGarbage": {
+			content: "\"a\"b",
+			dialect: csvlex.CSV,
+			tokens: []lexer.Token{
+				mkToken(csvlex.KindField, "\"a\"", pos(1, 1, 0), pos(1, 4, 3)),
+				mkToken(lexer.KindError, "unexpected 'b' after field", pos(1, 4, 3), pos(1, 5, 4)),
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var lx *lexer.Lexer
+
+			lx = csvlex.NewLexer(strings.NewReader(test.content), test.dialect)
+
+			assert.Equal(t, test.tokens, slices.Collect(lx.Tokens()))
+		})
+	}
+}
+
+func TestDialectUnquote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `a"b`, csvlex.CSV.Unquote(`"a""b"`))
+	assert.Equal(t, "ab", csvlex.CSV.Unquote("ab"))
+	assert.Equal(t, `"`, csvlex.CSV.Unquote(`"`))
+	assert.Equal(t, `"a"`, csvlex.TSV.Unquote(`"a"`))
+	assert.Equal(t, `a'b\`, csvlex.Dialect{
+		Delimiter: ',',
+		Quote:     '\'',
+		Escape:    '\\',
+	}.Unquote(`'a\'b\\'`))
+}
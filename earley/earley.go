@@ -0,0 +1,408 @@
+// Package earley parses token streams with context-free grammars of
+// any shape, ambiguous and left-recursive ones included, using Earley's
+// algorithm. It is the fallback for grammars that do not fit the
+// deterministic engines of the parser and peg packages, such as query
+// languages and search syntaxes close to natural language, where an
+// input may have several readings and the application picks one.
+//
+// Parse returns a parse forest: every way the input derives from the
+// start rule, sharing the subtrees common to several derivations, so
+// that exponentially many trees take polynomial space. Trees expands a
+// forest into parse trees.
+package earley // import "github.com/andrieee44/langengine/earley"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+var (
+	// ErrNoRules is returned by Compile for a start rule with no
+	// productions.
+	ErrNoRules = errors.New("earley: no productions for start rule")
+
+	// ErrUndefinedRule is returned by Compile for a production referring
+	// to a rule with no productions.
+	ErrUndefinedRule = errors.New("earley: undefined rule")
+)
+
+// Symbol is a symbol of the right-hand side of a production: either a
+// nonterminal, naming a rule, or a terminal, matching a token.
+type Symbol struct {
+	// Rule is the name of the rule of a nonterminal. It is empty for a
+	// terminal.
+	Rule string
+
+	// Kind is the kind of the token matched by a terminal.
+	Kind lexer.Kind
+
+	// Text is the text of the token matched by a terminal, if not
+	// empty, as for keywords lexed with the kind of identifiers.
+	Text string
+}
+
+// Production is a rule of the grammar: its name derives the sequence of
+// symbols. A rule has as many productions as it has alternatives, and
+// derives the empty sequence if it has a production with no symbols.
+type Production struct {
+	// Name is the name of the rule.
+	Name string
+
+	// Symbols is the right-hand side of the production.
+	Symbols []Symbol
+}
+
+// Grammar is a compiled context-free grammar. A new Grammar is
+// constructed with Compile and is safe for concurrent use once
+// KindName is set.
+type Grammar struct {
+	// KindName names kinds in syntax errors, such as the KindName method
+	// of a lexer.Language. Nil selects lexer.Kind.String.
+	KindName func(lexer.Kind) string
+
+	start    string
+	prods    []Production
+	byName   map[string][]int
+	nullable map[string]bool
+}
+
+// Node is a node of a parse forest: a rule, or a token, matched over a
+// range of the input, with every way the rule derives the range.
+type Node struct {
+	// Rule is the name of the rule, or empty for a token.
+	Rule string
+
+	// Token is the token of a node with an empty Rule.
+	Token lexer.Token
+
+	// Span is the region of the input the node matched. A node matching
+	// no token has an empty span where the next token starts.
+	Span lexer.Span
+
+	// Alternatives holds every derivation of the rule, each with a node
+	// for every symbol of its production. The input is ambiguous at the
+	// node if there is more than one. Nodes are shared between
+	// derivations, and a grammar with cyclic rules, such as A <- A,
+	// yields a cyclic forest.
+	Alternatives [][]*Node
+}
+
+// NT returns the nonterminal symbol of the rule name.
+func NT(name string) Symbol {
+	return Symbol{Rule: name}
+}
+
+// T returns the terminal symbol matching a token of kind.
+func T(kind lexer.Kind) Symbol {
+	return Symbol{Kind: kind}
+}
+
+// Lit returns the terminal symbol matching a token of kind with text.
+func Lit(kind lexer.Kind, text string) Symbol {
+	return Symbol{Kind: kind, Text: text}
+}
+
+// Compile compiles the productions of a grammar whose start rule is
+// start.
+//
+// Returns ErrNoRules if start has no productions, and ErrUndefinedRule
+// for a production referring to a rule with none.
+func Compile(start string, prods []Production) (*Grammar, error) {
+	var (
+		grammar *Grammar
+		prod    Production
+		sym     Symbol
+		i       int
+	)
+
+	grammar = &Grammar{
+		start:  start,
+		prods:  slices.Clone(prods),
+		byName: make(map[string][]int),
+	}
+
+	for i, prod = range prods {
+		grammar.byName[prod.Name] = append(grammar.byName[prod.Name], i)
+	}
+
+	if len(grammar.byName[start]) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNoRules, start)
+	}
+
+	for _, prod = range prods {
+		for _, sym = range prod.Symbols {
+			if sym.Rule != "" && len(grammar.byName[sym.Rule]) == 0 {
+				return nil, fmt.Errorf(
+					"%w: %q in rule %q",
+					ErrUndefinedRule,
+					sym.Rule,
+					prod.Name,
+				)
+			}
+		}
+	}
+
+	grammar.nullable = nullable(prods)
+
+	return grammar, nil
+}
+
+// MustCompile is like Compile but panics if the grammar cannot be
+// compiled. It simplifies the initialization of global grammars.
+func MustCompile(start string, prods []Production) *Grammar {
+	var (
+		grammar *Grammar
+		err     error
+	)
+
+	grammar, err = Compile(start, prods)
+	if err != nil {
+		panic(err)
+	}
+
+	return grammar
+}
+
+// nullable returns the set of the rules deriving the empty sequence.
+func nullable(prods []Production) map[string]bool {
+	var (
+		null    map[string]bool
+		prod    Production
+		changed bool
+	)
+
+	null = make(map[string]bool)
+
+	for changed = true; changed; {
+		changed = false
+
+		for _, prod = range prods {
+			if !null[prod.Name] && !slices.ContainsFunc(prod.Symbols, func(sym Symbol) bool {
+				return sym.Rule == "" || !null[sym.Rule]
+			}) {
+				null[prod.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	return null
+}
+
+type item struct {
+	prod   int
+	dot    int
+	origin int
+}
+
+type itemSet struct {
+	items []item
+	seen  map[item]bool
+}
+
+func (set *itemSet) add(it item) {
+	if set.seen == nil {
+		set.seen = make(map[item]bool)
+	}
+
+	if !set.seen[it] {
+		set.seen[it] = true
+		set.items = append(set.items, it)
+	}
+}
+
+// Parse parses the tokens of stream, leaving out trivia, and returns
+// the forest of the start rule over all of them.
+//
+// Returns a *parser.SyntaxError at the first token that no derivation
+// can continue with, or at the end of the input if it ends early,
+// listing the tokens expected there.
+func (grammar *Grammar) Parse(stream lexer.TokenStream) (*Node, error) {
+	var (
+		tokens []lexer.Token
+		tok    lexer.Token
+		sets   []itemSet
+		it     item
+		k      int
+	)
+
+	for tok = range lexer.Tokens(stream) {
+		if !tok.Trivia {
+			tokens = append(tokens, tok)
+		}
+	}
+
+	sets = make([]itemSet, len(tokens)+1)
+
+	for _, k = range grammar.byName[grammar.start] {
+		sets[0].add(item{prod: k})
+	}
+
+	for k = range sets {
+		grammar.close(sets, k, tokens)
+
+		if k < len(tokens) && len(sets[k+1].items) == 0 {
+			return nil, grammar.syntaxError(sets[k], tokens, k)
+		}
+	}
+
+	for _, it = range sets[len(tokens)].items {
+		if it.origin == 0 && grammar.complete(it) &&
+			grammar.prods[it.prod].Name == grammar.start {
+			return (&forest{
+				grammar: grammar,
+				sets:    sets,
+				tokens:  tokens,
+				nodes:   make(map[nodeKey]*Node),
+			}).node(grammar.start, 0, len(tokens)), nil
+		}
+	}
+
+	return nil, grammar.syntaxError(sets[len(tokens)], tokens, len(tokens))
+}
+
+// close predicts and completes the items of sets[k], and scans the
+// token at k into sets[k+1].
+func (grammar *Grammar) close(sets []itemSet, k int, tokens []lexer.Token) {
+	var (
+		it, parent item
+		sym        Symbol
+		prod       int
+		i, j       int
+		count      int
+	)
+
+	for i = 0; i < len(sets[k].items); i++ {
+		it = sets[k].items[i]
+
+		if grammar.complete(it) {
+			// Items added to sets[k] later, by the completion of a
+			// nullable rule, are advanced when they are predicted.
+			count = len(sets[it.origin].items)
+
+			for j = 0; j < count; j++ {
+				parent = sets[it.origin].items[j]
+				if grammar.next(parent).Rule == grammar.prods[it.prod].Name &&
+					!grammar.complete(parent) {
+					sets[k].add(item{parent.prod, parent.dot + 1, parent.origin})
+				}
+			}
+
+			continue
+		}
+
+		sym = grammar.next(it)
+
+		if sym.Rule == "" {
+			if k < len(tokens) && matches(sym, tokens[k]) {
+				sets[k+1].add(item{it.prod, it.dot + 1, it.origin})
+			}
+
+			continue
+		}
+
+		for _, prod = range grammar.byName[sym.Rule] {
+			sets[k].add(item{prod: prod, origin: k})
+		}
+
+		if grammar.nullable[sym.Rule] {
+			sets[k].add(item{it.prod, it.dot + 1, it.origin})
+		}
+	}
+}
+
+// complete reports whether the dot of it is at the end of its
+// production.
+func (grammar *Grammar) complete(it item) bool {
+	return it.dot == len(grammar.prods[it.prod].Symbols)
+}
+
+// next returns the symbol after the dot of it, or the zero Symbol if
+// it is complete.
+func (grammar *Grammar) next(it item) Symbol {
+	if grammar.complete(it) {
+		return Symbol{}
+	}
+
+	return grammar.prods[it.prod].Symbols[it.dot]
+}
+
+// syntaxError returns the error at token k, given the set of items
+// before it.
+func (grammar *Grammar) syntaxError(
+	set itemSet,
+	tokens []lexer.Token,
+	k int,
+) *parser.SyntaxError {
+	var (
+		expected []string
+		it       item
+		sym      Symbol
+		desc     string
+		found    string
+		span     lexer.Span
+	)
+
+	for _, it = range set.items {
+		sym = grammar.next(it)
+		if grammar.complete(it) || sym.Rule != "" {
+			continue
+		}
+
+		desc = grammar.describe(sym)
+		if !slices.Contains(expected, desc) {
+			expected = append(expected, desc)
+		}
+	}
+
+	if k < len(tokens) {
+		span = tokens[k].Span
+		found = fmt.Sprintf("%q", tokens[k].Text)
+	} else {
+		span = emptySpan(tokens, k)
+		found = "end of input"
+	}
+
+	return &parser.SyntaxError{Diagnostic: lexer.Diagnostic{
+		Span: span,
+		Message: fmt.Sprintf(
+			"expected %s, found %s",
+			lexer.OrList(expected),
+			found,
+		),
+	}}
+}
+
+// describe returns the description of a terminal in errors.
+func (grammar *Grammar) describe(sym Symbol) string {
+	if sym.Text != "" {
+		return fmt.Sprintf("%q", sym.Text)
+	}
+
+	if grammar.KindName == nil {
+		return sym.Kind.String()
+	}
+
+	return grammar.KindName(sym.Kind)
+}
+
+func matches(sym Symbol, tok lexer.Token) bool {
+	return tok.Kind == sym.Kind && (sym.Text == "" || tok.Text == sym.Text)
+}
+
+// emptySpan returns the empty span where token k starts, or where the
+// input ends.
+func emptySpan(tokens []lexer.Token, k int) lexer.Span {
+	switch {
+	case k < len(tokens):
+		return lexer.Span{Start: tokens[k].Span.Start, End: tokens[k].Span.Start}
+	case k > 0:
+		return lexer.Span{Start: tokens[k-1].Span.End, End: tokens[k-1].Span.End}
+	default:
+		return lexer.Span{}
+	}
+}
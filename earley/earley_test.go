@@ -0,0 +1,211 @@
+package earley_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/earley"
+	"github.com/andrieee44/langengine/examples/expr"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// ambiguous is a grammar of differences and sums without precedence or
+// associativity, with optional signs.
+var ambiguous = earley.MustCompile("Expr", []earley.Production{
+	{Name: "Expr", Symbols: []earley.Symbol{
+		earley.NT("Expr"),
+		earley.T(expr.KindOperator),
+		earley.NT("Expr"),
+	}},
+	{Name: "Expr", Symbols: []earley.Symbol{
+		earley.NT("Sign"),
+		earley.T(expr.KindNumber),
+	}},
+	{Name: "Sign", Symbols: []earley.Symbol{earley.Lit(expr.KindOperator, "-")}},
+	{Name: "Sign"},
+})
+
+// shape returns the rules and texts of node and its descendants, as in
+// Expr(Sign(), 1), leaving out the rule of tokens.
+func shape(node *parser.Node) string {
+	var (
+		parts []string
+		child *parser.Node
+	)
+
+	if node.Rule == "" {
+		return node.Text
+	}
+
+	for _, child = range node.Children {
+		parts = append(parts, shape(child))
+	}
+
+	return node.Rule + "(" + strings.Join(parts, " ") + ")"
+}
+
+func parse(grammar *earley.Grammar, src string) (*earley.Node, error) {
+	return grammar.Parse(expr.NewLexer(strings.NewReader(src)))
+}
+
+func TestGrammarParse(t *testing.T) {
+	type testData struct {
+		src       string
+		want      []string
+		ambiguous bool
+		err       string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		node    *earley.Node
+		tree    *parser.Node
+		shapes  []string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"number": {src: "1", want: []string{"Expr(Sign() 1)"}},
+		"sign":   {src: "-1", want: []string{"Expr(Sign(-) 1)"}},
+		"unambiguous": {
+			src:  "1 - -2",
+			want: []string{"Expr(Expr(Sign() 1) - Expr(Sign(-) 2))"},
+		},
+		"ambiguous": {
+			src: "1 - 2 + 3",
+			want: []string{
+				"Expr(Expr(Sign() 1) - Expr(Expr(Sign() 2) + Expr(Sign() 3)))",
+				"Expr(Expr(Expr(Sign() 1) - Expr(Sign() 2)) + Expr(Sign() 3))",
+			},
+			ambiguous: true,
+		},
+		"unexpected": {
+			src: "1 2",
+			err: `1:3: expected Kind(5), found "2"`,
+		},
+		"end": {
+			src: "1 +",
+			err: `1:4: expected "-" or Kind(3), found end of input`,
+		},
+		"empty": {
+			err: `0:0: expected "-" or Kind(3), found end of input`,
+		},
+	}
+
+	for name, test = range testTbl {
+		node, err = parse(ambiguous, test.src)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+			assert.IsType(t, &parser.SyntaxError{}, err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.ambiguous, node.Ambiguous(), name)
+
+		shapes = nil
+		for _, tree = range node.Trees(0) {
+			shapes = append(shapes, shape(tree))
+		}
+
+		assert.ElementsMatch(t, test.want, shapes, name)
+	}
+}
+
+func TestGrammarParseKindName(t *testing.T) {
+	var (
+		grammar *earley.Grammar
+		err     error
+	)
+
+	t.Parallel()
+
+	grammar = earley.MustCompile("Call", []earley.Production{
+		{Name: "Call", Symbols: []earley.Symbol{
+			earley.T(expr.KindIdent),
+			earley.T(expr.KindLeftParen),
+			earley.T(expr.KindRightParen),
+		}},
+	})
+	grammar.KindName = func(kind lexer.Kind) string {
+		return map[lexer.Kind]string{
+			expr.KindIdent:      "identifier",
+			expr.KindLeftParen:  "'('",
+			expr.KindRightParen: "')'",
+		}[kind]
+	}
+
+	_, err = parse(grammar, "f(1)")
+
+	assert.EqualError(t, err, `1:3: expected ')', found "1"`)
+}
+
+func TestNodeTrees(t *testing.T) {
+	var (
+		node  *earley.Node
+		trees []*parser.Node
+		err   error
+	)
+
+	t.Parallel()
+
+	// The 4 operators can be grouped in 14 ways, the Catalan number C4.
+	node, err = parse(ambiguous, "1 - 2 - 3 - 4 - 5")
+
+	assert.NoError(t, err)
+	assert.Len(t, node.Trees(0), 14)
+
+	trees = node.Trees(3)
+	assert.Len(t, trees, 3)
+	assert.Equal(t, "1 - 2 - 3 - 4 - 5", trees[0].Text)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{Line: 1, Column: 1, Offset: 0},
+		End:   lexer.Position{Line: 1, Column: 18, Offset: 17},
+	}, trees[0].Span)
+}
+
+func TestNodeTreesCycle(t *testing.T) {
+	var (
+		node *earley.Node
+		err  error
+	)
+
+	t.Parallel()
+
+	node, err = parse(earley.MustCompile("A", []earley.Production{
+		{Name: "A", Symbols: []earley.Symbol{earley.NT("A")}},
+		{Name: "A", Symbols: []earley.Symbol{earley.T(expr.KindNumber)}},
+	}), "1")
+
+	assert.NoError(t, err)
+	assert.True(t, node.Ambiguous())
+	assert.Equal(t, "A(1)", shape(node.Trees(0)[0]))
+	assert.Len(t, node.Trees(0), 1)
+}
+
+func TestCompile(t *testing.T) {
+	var err error
+
+	t.Parallel()
+
+	_, err = earley.Compile("S", nil)
+	assert.ErrorIs(t, err, earley.ErrNoRules)
+	assert.EqualError(t, err, `earley: no productions for start rule: "S"`)
+
+	_, err = earley.Compile("S", []earley.Production{
+		{Name: "S", Symbols: []earley.Symbol{earley.NT("T")}},
+	})
+	assert.ErrorIs(t, err, earley.ErrUndefinedRule)
+	assert.EqualError(t, err, `earley: undefined rule: "T" in rule "S"`)
+
+	assert.Panics(t, func() {
+		earley.MustCompile("S", nil)
+	})
+}
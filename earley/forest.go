@@ -0,0 +1,278 @@
+package earley
+
+import (
+	"slices"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+type nodeKey struct {
+	rule       string
+	start, end int
+}
+
+// forest builds the nodes of a parse forest from the item sets of a
+// successful parse.
+type forest struct {
+	grammar *Grammar
+	sets    []itemSet
+	tokens  []lexer.Token
+	nodes   map[nodeKey]*Node
+	leaves  map[int]*Node
+}
+
+// node returns the node of rule over the tokens from start to end,
+// shared by every derivation including it.
+func (f *forest) node(rule string, start, end int) *Node {
+	var (
+		node *Node
+		key  nodeKey
+		prod int
+		ok   bool
+	)
+
+	key = nodeKey{rule, start, end}
+
+	node, ok = f.nodes[key]
+	if ok {
+		return node
+	}
+
+	node = &Node{Rule: rule, Span: f.span(start, end)}
+	f.nodes[key] = node
+
+	for _, prod = range f.grammar.byName[rule] {
+		if f.sets[end].seen[item{prod, len(f.grammar.prods[prod].Symbols), start}] {
+			f.derive(node, prod, len(f.grammar.prods[prod].Symbols), start, end, nil)
+		}
+	}
+
+	return node
+}
+
+// derive adds to node every derivation of the first dot symbols of
+// prod over the tokens from start to end, followed by the nodes of
+// suffix.
+func (f *forest) derive(
+	node *Node,
+	prod, dot, start, end int,
+	suffix []*Node,
+) {
+	var (
+		sym Symbol
+		it  item
+		mid int
+	)
+
+	if dot == 0 {
+		if start == end {
+			node.Alternatives = append(node.Alternatives, suffix)
+		}
+
+		return
+	}
+
+	sym = f.grammar.prods[prod].Symbols[dot-1]
+	it = item{prod, dot - 1, start}
+
+	if sym.Rule == "" {
+		mid = end - 1
+		if mid >= start && matches(sym, f.tokens[mid]) && f.sets[mid].seen[it] {
+			f.derive(node, prod, dot-1, start, mid, prepend(f.leaf(mid), suffix))
+		}
+
+		return
+	}
+
+	for mid = start; mid <= end; mid++ {
+		if f.sets[mid].seen[it] && f.completes(sym.Rule, mid, end) {
+			f.derive(
+				node,
+				prod,
+				dot-1,
+				start,
+				mid,
+				prepend(f.node(sym.Rule, mid, end), suffix),
+			)
+		}
+	}
+}
+
+// completes reports whether rule derives the tokens from start to end.
+func (f *forest) completes(rule string, start, end int) bool {
+	var prod int
+
+	for _, prod = range f.grammar.byName[rule] {
+		if f.sets[end].seen[item{prod, len(f.grammar.prods[prod].Symbols), start}] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// leaf returns the node of the token at i.
+func (f *forest) leaf(i int) *Node {
+	var (
+		node *Node
+		ok   bool
+	)
+
+	if f.leaves == nil {
+		f.leaves = make(map[int]*Node)
+	}
+
+	node, ok = f.leaves[i]
+	if !ok {
+		node = &Node{Token: f.tokens[i], Span: f.tokens[i].Span}
+		f.leaves[i] = node
+	}
+
+	return node
+}
+
+// span returns the span of the tokens from start to end.
+func (f *forest) span(start, end int) lexer.Span {
+	if start == end {
+		return emptySpan(f.tokens, start)
+	}
+
+	return lexer.Span{
+		Start: f.tokens[start].Span.Start,
+		End:   f.tokens[end-1].Span.End,
+	}
+}
+
+func prepend(node *Node, nodes []*Node) []*Node {
+	return append([]*Node{node}, nodes...)
+}
+
+// Ambiguous reports whether the input has more than one derivation
+// within the forest rooted at node.
+func (node *Node) Ambiguous() bool {
+	return node.ambiguous(make(map[*Node]bool))
+}
+
+func (node *Node) ambiguous(seen map[*Node]bool) bool {
+	var (
+		alt   []*Node
+		child *Node
+	)
+
+	if seen[node] {
+		return false
+	}
+
+	seen[node] = true
+
+	if len(node.Alternatives) > 1 {
+		return true
+	}
+
+	for _, alt = range node.Alternatives {
+		for _, child = range alt {
+			if child.ambiguous(seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Trees expands the forest rooted at node into at most limit parse
+// trees, in the order of the productions of the grammar, or into every
+// tree if limit is not positive. Tokens become nodes with an empty Rule
+// and the text of the token, and rule nodes with their rule name and
+// the text of their tokens joined by spaces, as the tokens do not hold
+// the text between them. Derivations through a cycle of the forest are
+// left out, as they would never end.
+func (node *Node) Trees(limit int) []*parser.Node {
+	return node.trees(limit, make(map[*Node]bool))
+}
+
+func (node *Node) trees(limit int, active map[*Node]bool) []*parser.Node {
+	var (
+		trees    []*parser.Node
+		combos   [][]*parser.Node
+		next     [][]*parser.Node
+		subtrees []*parser.Node
+		combo    []*parser.Node
+		subtree  *parser.Node
+		alt      []*Node
+		child    *Node
+	)
+
+	if node.Rule == "" {
+		return []*parser.Node{{Text: node.Token.Text, Span: node.Span}}
+	}
+
+	if active[node] {
+		return nil
+	}
+
+	active[node] = true
+	defer delete(active, node)
+
+	for _, alt = range node.Alternatives {
+		combos = [][]*parser.Node{nil}
+
+		for _, child = range alt {
+			subtrees = child.trees(limit, active)
+			next = nil
+
+			for _, combo = range combos {
+				for _, subtree = range subtrees {
+					next = append(next, append(slices.Clip(combo), subtree))
+				}
+			}
+
+			combos = truncate(next, limit)
+		}
+
+		for _, combo = range combos {
+			trees = append(trees, &parser.Node{
+				Rule:     node.Rule,
+				Text:     joinText(combo),
+				Span:     node.Span,
+				Children: combo,
+			})
+		}
+
+		if limit > 0 && len(trees) >= limit {
+			return trees[:limit]
+		}
+	}
+
+	return trees
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+
+	return items
+}
+
+// joinText returns the texts of nodes joined by spaces, leaving out
+// empty ones.
+func joinText(nodes []*parser.Node) string {
+	var (
+		text string
+		node *parser.Node
+	)
+
+	for _, node = range nodes {
+		switch {
+		case node.Text == "":
+		case text == "":
+			text = node.Text
+		default:
+			text += " " + node.Text
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,63 @@
+package eval
+
+// Env is an environment binding names to values, nested in the
+// environment enclosing it. Names are resolved from the innermost
+// environment outwards. An Env is not safe for concurrent use.
+type Env struct {
+	parent *Env
+	vars   map[string]Value
+}
+
+// NewEnv returns a new environment nested in parent, or a root
+// environment if parent is nil.
+func NewEnv(parent *Env) *Env {
+	return &Env{parent: parent, vars: make(map[string]Value)}
+}
+
+// Parent returns the environment enclosing env, or nil for a root
+// environment.
+func (env *Env) Parent() *Env {
+	return env.parent
+}
+
+// Define binds name to v in env itself, shadowing any binding of name
+// in enclosing environments and replacing one in env.
+func (env *Env) Define(name string, v Value) {
+	env.vars[name] = v
+}
+
+// Lookup returns the value name is bound to in the innermost
+// environment, from env outwards, binding it.
+func (env *Env) Lookup(name string) (Value, bool) {
+	var (
+		v  Value
+		ok bool
+	)
+
+	for ; env != nil; env = env.parent {
+		v, ok = env.vars[name]
+		if ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// Assign rebinds name to v in the innermost environment, from env
+// outwards, binding it. It reports false, binding nothing, if name is
+// unbound.
+func (env *Env) Assign(name string, v Value) bool {
+	var ok bool
+
+	for ; env != nil; env = env.parent {
+		_, ok = env.vars[name]
+		if ok {
+			env.vars[name] = v
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,245 @@
+// Package eval is the skeleton of a tree-walking interpreter, the last
+// step from lexing and parsing to running a small language. It
+// evaluates parse trees, as built by the peg and earley packages, by
+// dispatching every parser.Node to the Rule registered for its grammar
+// rule, and provides what most such interpreters need around that:
+// runtime values, environments nested by scope, and a call stack
+// recording where each active call was made, so that runtime errors
+// carry a span and a trace like syntax errors do.
+//
+// A minimal evaluator of calls to builtins looks like:
+//
+//	ev = eval.New(map[string]eval.Rule{
+//		"Number": func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+//			...
+//		},
+//		"Call": func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+//			... evaluate the callee and arguments, then
+//			return ev.Call(name, callee, args, node.Span)
+//		},
+//	})
+//	ev.Globals.Define("print", &eval.Builtin{Name: "print", Fn: print})
+//	v, err = ev.Eval(tree, ev.Globals)
+package eval // import "github.com/andrieee44/langengine/eval"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+// DefaultMaxDepth is the maximum depth of the call stack of an
+// Evaluator whose MaxDepth is 0.
+const DefaultMaxDepth = 1000
+
+var (
+	// ErrNoRule is returned by Evaluator.Eval for a node whose rule has
+	// no Rule registered and that does not have exactly one child.
+	ErrNoRule = errors.New("eval: no rule")
+
+	// ErrNotCallable is returned by Evaluator.Call for a value that is
+	// not Callable.
+	ErrNotCallable = errors.New("eval: value is not callable")
+
+	// ErrArity is returned by a call with the wrong number of arguments.
+	ErrArity = errors.New("eval: wrong number of arguments")
+
+	// ErrStackOverflow is returned by Evaluator.Call when the call stack
+	// would exceed the maximum depth, typically on unbounded recursion.
+	ErrStackOverflow = errors.New("eval: stack overflow")
+)
+
+// Rule evaluates a node in env. Rules evaluate the children of node
+// with ev.Eval and report errors with ev.Errorf.
+type Rule func(ev *Evaluator, node *parser.Node, env *Env) (Value, error)
+
+// Frame is an active call on the call stack.
+type Frame struct {
+	// Name is the name the function was called by.
+	Name string
+
+	// Call is the span of the call expression.
+	Call lexer.Span
+}
+
+// RuntimeError is the error returned for a failure during evaluation.
+type RuntimeError struct {
+	// Diagnostic describes the error, spanning the offending node.
+	Diagnostic lexer.Diagnostic
+
+	// Stack holds the active calls when the error occurred, innermost
+	// first.
+	Stack []Frame
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Evaluator evaluates parse trees. A new Evaluator is constructed with
+// New and is not safe for concurrent use.
+type Evaluator struct {
+	// Rules maps the names of grammar rules to their evaluation.
+	Rules map[string]Rule
+
+	// Globals is the root environment, for builtins and top-level
+	// definitions.
+	Globals *Env
+
+	// MaxDepth is the maximum depth of the call stack, or 0 to select
+	// DefaultMaxDepth.
+	MaxDepth int
+
+	stack []Frame
+}
+
+// Error returns the diagnostic of the error, formatted as
+// "line:column: message", followed by a line for each frame of the
+// stack, as in "\tin f called at 3:5".
+func (err *RuntimeError) Error() string {
+	var (
+		sb    strings.Builder
+		frame Frame
+	)
+
+	sb.WriteString(err.Diagnostic.String())
+
+	for _, frame = range err.Stack {
+		fmt.Fprintf(
+			&sb,
+			"\n\tin %s called at %d:%d",
+			frame.Name,
+			frame.Call.Start.Line,
+			frame.Call.Start.Column,
+		)
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (err *RuntimeError) Unwrap() error {
+	return err.Err
+}
+
+// New returns an Evaluator with rules and an empty Globals.
+func New(rules map[string]Rule) *Evaluator {
+	return &Evaluator{Rules: rules, Globals: NewEnv(nil)}
+}
+
+// Eval evaluates node in env with the Rule registered for its rule. A
+// node whose rule has none, but that has exactly one child, evaluates
+// to its child, so that rules which only group alternatives, such as
+// Expr <- Call / Number, need no Rule.
+//
+// Returns the error of the Rule, or a *RuntimeError wrapping ErrNoRule.
+func (ev *Evaluator) Eval(node *parser.Node, env *Env) (Value, error) {
+	var (
+		rule Rule
+		ok   bool
+	)
+
+	rule, ok = ev.Rules[node.Rule]
+	if ok {
+		return rule(ev, node, env)
+	}
+
+	if len(node.Children) == 1 {
+		return ev.Eval(node.Children[0], env)
+	}
+
+	return nil, ev.Errorf(node.Span, "%w for %q", ErrNoRule, node.Rule)
+}
+
+// Call calls callee with args, recording the call by name at span on
+// the call stack while it runs.
+//
+// Returns a *RuntimeError wrapping ErrNotCallable if callee is not
+// Callable, or ErrStackOverflow if the stack is full, at span. An error
+// of the call that is not a *RuntimeError becomes one at span, with the
+// stack including the call.
+func (ev *Evaluator) Call(
+	name string,
+	callee Value,
+	args []Value,
+	span lexer.Span,
+) (Value, error) {
+	var (
+		fn    Callable
+		v     Value
+		depth int
+		ok    bool
+		err   error
+	)
+
+	fn, ok = callee.(Callable)
+	if !ok {
+		return nil, ev.Errorf(
+			span,
+			"%w: %s of type %s",
+			ErrNotCallable,
+			name,
+			callee.Type(),
+		)
+	}
+
+	depth = ev.MaxDepth
+	if depth == 0 {
+		depth = DefaultMaxDepth
+	}
+
+	if len(ev.stack) >= depth {
+		return nil, ev.Errorf(span, "%w calling %s", ErrStackOverflow, name)
+	}
+
+	ev.stack = append(ev.stack, Frame{Name: name, Call: span})
+	defer func() {
+		ev.stack = ev.stack[:len(ev.stack)-1]
+	}()
+
+	v, err = fn.Call(ev, args)
+	if err != nil {
+		return nil, ev.Error(span, err)
+	}
+
+	return v, nil
+}
+
+// Stack returns the active calls, innermost first.
+func (ev *Evaluator) Stack() []Frame {
+	var (
+		frames []Frame
+		i      int
+	)
+
+	for i = len(ev.stack) - 1; i >= 0; i-- {
+		frames = append(frames, ev.stack[i])
+	}
+
+	return frames
+}
+
+// Error returns err as a *RuntimeError at span with the current stack,
+// or err itself if it already is one, so that the innermost span and
+// stack of an error are kept as it propagates.
+func (ev *Evaluator) Error(span lexer.Span, err error) error {
+	var rterr *RuntimeError
+
+	if errors.As(err, &rterr) {
+		return err
+	}
+
+	return &RuntimeError{
+		Diagnostic: lexer.Diagnostic{Span: span, Message: err.Error()},
+		Stack:      ev.Stack(),
+		Err:        err,
+	}
+}
+
+// Errorf returns a *RuntimeError at span with the current stack and the
+// formatted message, wrapping the error of a %w verb.
+func (ev *Evaluator) Errorf(span lexer.Span, format string, args ...any) error {
+	return ev.Error(span, fmt.Errorf(format, args...))
+}
@@ -0,0 +1,301 @@
+package eval_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/andrieee44/langengine/peg"
+	"github.com/stretchr/testify/assert"
+)
+
+var calls = peg.MustCompile(`
+Expr   <- Call / Number / Name
+Call   <- Name "(" (Expr ("," Expr)*)? ")"
+Name   <- [a-z]+
+Number <- [0-9]+
+`)
+
+var errBoom = errors.New("boom")
+
+// newEvaluator returns an Evaluator of calls, with the builtins sum and
+// fail, and the function twice(f) calling f(1) twice.
+func newEvaluator(t *testing.T) *eval.Evaluator {
+	var ev *eval.Evaluator
+
+	ev = eval.New(map[string]eval.Rule{
+		"Number": func(ev *eval.Evaluator, node *parser.Node, _ *eval.Env) (eval.Value, error) {
+			var (
+				n   int64
+				err error
+			)
+
+			n, err = strconv.ParseInt(node.Text, 10, 64)
+			if err != nil {
+				return nil, ev.Error(node.Span, err)
+			}
+
+			return eval.Int(n), nil
+		},
+		"Name": func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+			var (
+				v  eval.Value
+				ok bool
+			)
+
+			v, ok = env.Lookup(node.Text)
+			if !ok {
+				return nil, ev.Errorf(node.Span, "undefined: %s", node.Text)
+			}
+
+			return v, nil
+		},
+		"Call": func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+			var (
+				callee eval.Value
+				args   []eval.Value
+				arg    eval.Value
+				child  *parser.Node
+				err    error
+			)
+
+			callee, err = ev.Eval(node.Children[0], env)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, child = range node.Children[1:] {
+				arg, err = ev.Eval(child, env)
+				if err != nil {
+					return nil, err
+				}
+
+				args = append(args, arg)
+			}
+
+			return ev.Call(node.Children[0].Text, callee, args, node.Span)
+		},
+	})
+
+	ev.Globals.Define("sum", &eval.Builtin{
+		Name: "sum",
+		Fn: func(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+			var (
+				total eval.Int
+				arg   eval.Value
+			)
+
+			for _, arg = range args {
+				total += arg.(eval.Int)
+			}
+
+			return total, nil
+		},
+	})
+	ev.Globals.Define("fail", &eval.Builtin{
+		Name: "fail",
+		Fn: func(_ *eval.Evaluator, _ []eval.Value) (eval.Value, error) {
+			return nil, errBoom
+		},
+	})
+	ev.Globals.Define("twice", &eval.Func{
+		Name:   "twice",
+		Params: []string{"f"},
+		Body:   mustParse(t, "sum(f(1),f(1))"),
+		Env:    ev.Globals,
+	})
+
+	return ev
+}
+
+func mustParse(t *testing.T, src string) *parser.Node {
+	var (
+		node *parser.Node
+		err  error
+	)
+
+	node, err = calls.Parse(strings.NewReader(src))
+	assert.NoError(t, err, src)
+
+	return node
+}
+
+func TestEvaluatorEval(t *testing.T) {
+	type testData struct {
+		src  string
+		want eval.Value
+		err  string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		ev      *eval.Evaluator
+		v       eval.Value
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"number":  {src: "42", want: eval.Int(42)},
+		"builtin": {src: "sum(1,2,sum(3,4))", want: eval.Int(10)},
+		"func":    {src: "twice(sum)", want: eval.Int(2)},
+		"undefined": {
+			src: "sum(1,x)",
+			err: "1:7: undefined: x",
+		},
+		"not callable": {
+			src: "twice(sum(1))",
+			err: "1:5: eval: value is not callable: f of type int" +
+				"\n\tin twice called at 1:1",
+		},
+		"trace": {
+			src: "sum(2,twice(fail))",
+			err: "1:5: boom\n\tin f called at 1:5\n\tin twice called at 1:7",
+		},
+		"arity": {
+			src: "twice(1,2)",
+			err: "1:1: eval: wrong number of arguments: <func twice> takes 1" +
+				" arguments, got 2\n\tin twice called at 1:1",
+		},
+	}
+
+	for name, test = range testTbl {
+		ev = newEvaluator(t)
+
+		v, err = ev.Eval(mustParse(t, test.src), ev.Globals)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+			assert.IsType(t, &eval.RuntimeError{}, err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, v, name)
+		assert.Empty(t, ev.Stack(), name)
+	}
+}
+
+func TestEvaluatorCallStackOverflow(t *testing.T) {
+	var (
+		ev    *eval.Evaluator
+		rterr *eval.RuntimeError
+		err   error
+	)
+
+	t.Parallel()
+
+	ev = newEvaluator(t)
+	ev.MaxDepth = 5
+	ev.Globals.Define("loop", &eval.Func{
+		Name:   "loop",
+		Params: []string{"n"},
+		Body:   mustParse(t, "loop(n)"),
+		Env:    ev.Globals,
+	})
+
+	_, err = ev.Eval(mustParse(t, "loop(1)"), ev.Globals)
+
+	assert.ErrorIs(t, err, eval.ErrStackOverflow)
+	assert.ErrorAs(t, err, &rterr)
+	assert.Len(t, rterr.Stack, 5)
+	assert.Equal(t, "loop", rterr.Stack[0].Name)
+	assert.Empty(t, ev.Stack())
+}
+
+func TestEvaluatorEvalNoRule(t *testing.T) {
+	var err error
+
+	t.Parallel()
+
+	_, err = eval.New(nil).Eval(&parser.Node{Rule: "Block"}, nil)
+
+	assert.ErrorIs(t, err, eval.ErrNoRule)
+	assert.EqualError(t, err, `0:0: eval: no rule for "Block"`)
+}
+
+func TestEnv(t *testing.T) {
+	var (
+		global, local *eval.Env
+		v             eval.Value
+		ok            bool
+	)
+
+	t.Parallel()
+
+	global = eval.NewEnv(nil)
+	global.Define("x", eval.Int(1))
+
+	local = eval.NewEnv(global)
+	assert.Same(t, global, local.Parent())
+
+	local.Define("y", eval.String("a"))
+	assert.True(t, local.Assign("x", eval.Int(2)))
+	assert.False(t, local.Assign("z", eval.Nil{}))
+
+	v, ok = global.Lookup("x")
+	assert.True(t, ok)
+	assert.Equal(t, eval.Int(2), v)
+
+	local.Define("x", eval.Bool(true))
+
+	v, _ = local.Lookup("x")
+	assert.Equal(t, eval.Bool(true), v)
+
+	v, _ = global.Lookup("x")
+	assert.Equal(t, eval.Int(2), v)
+
+	_, ok = global.Lookup("y")
+	assert.False(t, ok)
+}
+
+func TestValues(t *testing.T) {
+	type testData struct {
+		v      eval.Value
+		typ    string
+		str    string
+		truthy bool
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"nil":   {v: eval.Nil{}, typ: "nil", str: "nil"},
+		"true":  {v: eval.Bool(true), typ: "bool", str: "true", truthy: true},
+		"zero":  {v: eval.Int(0), typ: "int", str: "0"},
+		"float": {v: eval.Float(1.5), typ: "float", str: "1.5", truthy: true},
+		"empty": {v: eval.String(""), typ: "string"},
+		"list": {
+			v:      eval.List{eval.Int(1), eval.String("a")},
+			typ:    "list",
+			str:    `[1, "a"]`,
+			truthy: true,
+		},
+		"empty list": {v: eval.List{}, typ: "list", str: "[]", truthy: true},
+		"builtin": {
+			v:      &eval.Builtin{Name: "print"},
+			typ:    "function",
+			str:    "<builtin print>",
+			truthy: true,
+		},
+		"func": {v: &eval.Func{}, typ: "function", str: "<func>", truthy: true},
+	}
+
+	for name, test = range testTbl {
+		assert.Equal(t, test.typ, test.v.Type(), name)
+		assert.Equal(t, test.str, test.v.String(), name)
+		assert.Equal(t, test.truthy, eval.Truthy(test.v), name)
+	}
+}
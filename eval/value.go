@@ -0,0 +1,220 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andrieee44/langengine/parser"
+)
+
+// Value is a runtime value. The types of the package cover the values
+// of most small languages; an application adds its own by implementing
+// Value, and Callable for values that can be called.
+type Value interface {
+	// Type returns the name of the type of the value, as in errors.
+	Type() string
+
+	// String returns the value as the language would print it.
+	String() string
+}
+
+// Callable is a Value that can be called with Evaluator.Call.
+type Callable interface {
+	Value
+
+	// Call calls the value with args.
+	Call(ev *Evaluator, args []Value) (Value, error)
+}
+
+// Nil is the absent value.
+type Nil struct{}
+
+// Bool is a boolean value.
+type Bool bool
+
+// Int is an integer value.
+type Int int64
+
+// Float is a floating-point value.
+type Float float64
+
+// String is a string value.
+type String string
+
+// List is an ordered sequence of values.
+type List []Value
+
+// Builtin is a function implemented in Go.
+type Builtin struct {
+	// Name is the name of the function, as in String.
+	Name string
+
+	// Fn implements the function.
+	Fn func(ev *Evaluator, args []Value) (Value, error)
+}
+
+// Func is a function defined in the evaluated language, whose body is a
+// parse tree evaluated in a new environment binding its parameters,
+// nested in the environment it was defined in.
+type Func struct {
+	// Name is the name of the function, or empty if it is anonymous.
+	Name string
+
+	// Params holds the names of the parameters.
+	Params []string
+
+	// Body is the tree evaluated by a call.
+	Body *parser.Node
+
+	// Env is the environment the function closes over.
+	Env *Env
+}
+
+// Truthy reports whether v counts as true in a condition: every value
+// is, except Nil, Bool(false), and the zero Int, Float, and String.
+func Truthy(v Value) bool {
+	switch v {
+	case nil, Nil{}, Bool(false), Int(0), Float(0), String(""):
+		return false
+	default:
+		return true
+	}
+}
+
+// Type returns "nil".
+func (Nil) Type() string {
+	return "nil"
+}
+
+// String returns "nil".
+func (Nil) String() string {
+	return "nil"
+}
+
+// Type returns "bool".
+func (Bool) Type() string {
+	return "bool"
+}
+
+// String returns "true" or "false".
+func (b Bool) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+// Type returns "int".
+func (Int) Type() string {
+	return "int"
+}
+
+// String returns the decimal representation of i.
+func (i Int) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Type returns "float".
+func (Float) Type() string {
+	return "float"
+}
+
+// String returns the shortest representation of f.
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+// Type returns "string".
+func (String) Type() string {
+	return "string"
+}
+
+// String returns s unquoted.
+func (s String) String() string {
+	return string(s)
+}
+
+// Type returns "list".
+func (List) Type() string {
+	return "list"
+}
+
+// String returns the elements of l in brackets, with strings quoted, as
+// in [1, "a"].
+func (l List) String() string {
+	var (
+		parts []string
+		v     Value
+		s     String
+		ok    bool
+	)
+
+	for _, v = range l {
+		s, ok = v.(String)
+		if ok {
+			parts = append(parts, strconv.Quote(string(s)))
+
+			continue
+		}
+
+		parts = append(parts, v.String())
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Type returns "function".
+func (*Builtin) Type() string {
+	return "function"
+}
+
+// String returns the name of the function, as in <builtin print>.
+func (fn *Builtin) String() string {
+	return "<builtin " + fn.Name + ">"
+}
+
+// Call calls Fn.
+func (fn *Builtin) Call(ev *Evaluator, args []Value) (Value, error) {
+	return fn.Fn(ev, args)
+}
+
+// Type returns "function".
+func (*Func) Type() string {
+	return "function"
+}
+
+// String returns the name of the function, as in <func f>.
+func (fn *Func) String() string {
+	if fn.Name == "" {
+		return "<func>"
+	}
+
+	return "<func " + fn.Name + ">"
+}
+
+// Call evaluates Body with the parameters bound to args.
+//
+// Returns an error wrapping ErrArity if the number of args differs from
+// the number of parameters.
+func (fn *Func) Call(ev *Evaluator, args []Value) (Value, error) {
+	var (
+		env *Env
+		i   int
+	)
+
+	if len(args) != len(fn.Params) {
+		return nil, fmt.Errorf(
+			"%w: %s takes %d arguments, got %d",
+			ErrArity,
+			fn,
+			len(fn.Params),
+			len(args),
+		)
+	}
+
+	env = NewEnv(fn.Env)
+
+	for i = range fn.Params {
+		env.Define(fn.Params[i], args[i])
+	}
+
+	return ev.Eval(fn.Body, env)
+}
@@ -0,0 +1,16 @@
+// Package examples holds complete example languages, each a subpackage
+// that can be imported as is or copied as the starting point of a new
+// grammar:
+//
+//   - jsonrules lexes JSON with a declarative lexgen rule set.
+//   - ini lexes INI files with the helpers of the inilex package,
+//     recovering from errors line by line.
+//   - expr lexes a small expression language with keyword sets and
+//     operator tables.
+//   - indent lexes a language whose blocks are delimited by
+//     indentation, emitting indent and dedent tokens.
+//   - template parses and renders Mustache-like templates, combining a
+//     modal lexer, a hand-written parser, and the eval package.
+//
+// Their tests use the lextest package, and show how to test a lexer.
+package examples // import "github.com/andrieee44/langengine/examples"
@@ -0,0 +1,185 @@
+// Package expr is an example of a complete lexer for a small
+// expression language, assembled from the building blocks of the lexer
+// package rather than from a declarative rule set:
+//
+//	# Greatest of two values.
+//	let max = fn(a, b) if a >= b then a else b
+//	max(2.5e3, -x) ?? "none"
+//
+// Identifiers are resolved into keywords with a lexer.KeywordSet,
+// operators are matched longest first with a lexer.OperatorTable, and
+// a malformed literal is reported with a lexer.KindError token without
+// stopping the Lexer, so that every error of the input is found in one
+// pass.
+package expr // import "github.com/andrieee44/langengine/examples/expr"
+
+import (
+	"io"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindIdent is the kind of an identifier that is not a keyword.
+	KindIdent lexer.Kind = lexer.KindUser + iota
+
+	// KindNumber is the kind of a decimal number literal, with an
+	// optional fraction and exponent.
+	KindNumber
+
+	// KindString is the kind of a double-quoted string literal,
+	// including its quotes, in which a backslash escapes the next rune.
+	KindString
+
+	// KindOperator is the kind of an operator such as "+" or ">=".
+	KindOperator
+
+	// KindLeftParen is the kind of the '(' token.
+	KindLeftParen
+
+	// KindRightParen is the kind of the ')' token.
+	KindRightParen
+
+	// KindComma is the kind of the ',' token.
+	KindComma
+
+	// KindComment is the kind of a comment running from '#' to the end
+	// of the line. It is emitted as trivia.
+	KindComment
+
+	// KindLet is the kind of the let keyword.
+	KindLet
+
+	// KindFn is the kind of the fn keyword.
+	KindFn
+
+	// KindIf is the kind of the if keyword.
+	KindIf
+
+	// KindThen is the kind of the then keyword.
+	KindThen
+
+	// KindElse is the kind of the else keyword.
+	KindElse
+)
+
+var (
+	keywords = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"let":  KindLet,
+		"fn":   KindFn,
+		"if":   KindIf,
+		"then": KindThen,
+		"else": KindElse,
+	})
+
+	operators = lexer.NewOperatorTable([]string{
+		"+", "-", "*", "/", "%", "=", "==", "!=", "<", "<=", ">", ">=",
+		"!", "&&", "||", "??",
+	})
+)
+
+// NewLexer returns a lexer.Lexer that splits rd into expression tokens.
+// The options configure the underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexToken, opts...)
+}
+
+// lexToken lexes the token starting at the current position.
+func lexToken(lx *lexer.Lexer) lexer.StateFn {
+	var ok bool
+
+	lx.AcceptRunFunc(unicode.IsSpace)
+	lx.Ignore()
+
+	switch {
+	case lx.Peek() == lexer.EOF:
+		return nil
+	case lx.Accept("#"):
+		lx.Until("\n")
+		lx.EmitTrivia(KindComment)
+	case lx.Accept("("):
+		lx.Emit(KindLeftParen)
+	case lx.Accept(")"):
+		lx.Emit(KindRightParen)
+	case lx.Accept(","):
+		lx.Emit(KindComma)
+	case lx.Peek() == '"':
+		return lexString
+	case lx.AcceptFunc(isDigit):
+		return lexNumber
+	case lx.AcceptIdentifier(isIdentStart, isIdentPart):
+		lx.EmitIdentifier(KindIdent, keywords)
+	default:
+		_, ok = lx.AcceptOperator(operators)
+		if ok {
+			lx.Emit(KindOperator)
+
+			return lexToken
+		}
+
+		lx.Next()
+		lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexToken
+}
+
+// lexNumber lexes the rest of a number literal after its first digit.
+func lexNumber(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(isDigit)
+
+	if lx.Accept(".") && lx.AcceptRunFunc(isDigit) == 0 {
+		lx.Errorf("missing digits after decimal point")
+
+		return lexToken
+	}
+
+	if lx.Accept("eE") {
+		lx.Accept("+-")
+
+		if lx.AcceptRunFunc(isDigit) == 0 {
+			lx.Errorf("missing digits in exponent")
+
+			return lexToken
+		}
+	}
+
+	lx.Emit(KindNumber)
+
+	return lexToken
+}
+
+// lexString lexes a string literal. An unterminated literal is reported
+// up to the end of its line.
+func lexString(lx *lexer.Lexer) lexer.StateFn {
+	lx.Accept(`"`)
+
+	for {
+		lx.Until("\"\\\n")
+
+		switch {
+		case lx.Accept(`"`):
+			lx.Emit(KindString)
+
+			return lexToken
+		case lx.Accept(`\`) && lx.Next() != lexer.EOF:
+		default:
+			lx.Errorf("unterminated string literal")
+
+			return lexToken
+		}
+	}
+}
+
+func isDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+func isIdentStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isIdentPart(char rune) bool {
+	return isIdentStart(char) || unicode.IsDigit(char)
+}
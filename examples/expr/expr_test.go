@@ -0,0 +1,88 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/expr"
+	"github.com/andrieee44/langengine/lexer/lextest"
+)
+
+var (
+	ident   = lextest.Kind(expr.KindIdent, "Ident")
+	number  = lextest.Kind(expr.KindNumber, "Number")
+	str     = lextest.Kind(expr.KindString, "String")
+	op      = lextest.Kind(expr.KindOperator, "Operator")
+	lparen  = lextest.Kind(expr.KindLeftParen, "LeftParen")
+	rparen  = lextest.Kind(expr.KindRightParen, "RightParen")
+	comma   = lextest.Kind(expr.KindComma, "Comma")
+	comment = lextest.Kind(expr.KindComment, "Comment")
+	let     = lextest.Kind(expr.KindLet, "Let")
+	fn      = lextest.Kind(expr.KindFn, "Fn")
+	ifKw    = lextest.Kind(expr.KindIf, "If")
+	then    = lextest.Kind(expr.KindThen, "Then")
+	elseKw  = lextest.Kind(expr.KindElse, "Else")
+)
+
+func TestLexer(t *testing.T) {
+	var (
+		testTbl map[string][]lextest.Want
+		src     string
+		wants   []lextest.Want
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]lextest.Want{
+		"# Greatest of two values.\nlet max = fn(a, b) if a >= b then a else b\n": {
+			comment("# Greatest of two values."),
+			let("let").At(2, 1),
+			ident("max"),
+			op("="),
+			fn("fn"),
+			lparen("("),
+			ident("a"),
+			comma(","),
+			ident("b"),
+			rparen(")"),
+			ifKw("if"),
+			ident("a"),
+			op(">="),
+			ident("b"),
+			then("then"),
+			ident("a"),
+			elseKw("else"),
+			ident("b"),
+			lextest.EOF,
+		},
+		`max(2.5e3, -x_1) ?? "no\"ne" || !élan`: {
+			ident("max"),
+			lparen("("),
+			number("2.5e3"),
+			comma(","),
+			op("-"),
+			ident("x_1"),
+			rparen(")"),
+			op("??"),
+			str(`"no\"ne"`),
+			op("||"),
+			op("!"),
+			ident("élan"),
+			lextest.EOF,
+		},
+		"1. + 2e + @ \"open\nlets": {
+			lextest.Error("missing digits after decimal point").At(1, 1),
+			op("+").At(1, 4),
+			lextest.Error("missing digits in exponent").At(1, 6),
+			op("+"),
+			lextest.Error(`unexpected "@"`).At(1, 11),
+			lextest.Error("unterminated string literal").At(1, 13),
+			ident("lets").At(2, 1),
+			lextest.EOF,
+		},
+	}
+
+	for src, wants = range testTbl {
+		lextest.Expect(t, expr.NewLexer(strings.NewReader(src)), wants...)
+	}
+}
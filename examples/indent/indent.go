@@ -0,0 +1,249 @@
+// Package indent is an example of a lexer for a language where
+// indentation delimits blocks, in the style of Python:
+//
+//	def area(w, h):
+//	    if w > 0:
+//	        return w * h
+//	    return 0
+//
+// The lexer turns the layout into tokens, so that a parser can treat
+// blocks like braces: a KindNewline token ends every logical line, a
+// KindIndent token opens a block where a line is indented deeper than
+// the one before it, and a KindDedent token closes one for every level
+// a line returns to. Blank lines, comment lines, and lines continued
+// inside brackets or after a backslash do not affect the layout. The
+// state the layout needs, a stack of indentation widths and the depth
+// of bracket nesting, lives in a value whose methods are the states of
+// the Lexer.
+package indent // import "github.com/andrieee44/langengine/examples/indent"
+
+import (
+	"io"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindNewline is the kind of the line feed ending a logical line.
+	// The token closing a last line without a line feed is empty.
+	KindNewline lexer.Kind = lexer.KindUser + iota
+
+	// KindIndent is the kind of the indentation opening a block.
+	KindIndent
+
+	// KindDedent is the kind of the empty token closing a block.
+	KindDedent
+
+	// KindIdent is the kind of an identifier or keyword.
+	KindIdent
+
+	// KindNumber is the kind of an integer literal.
+	KindNumber
+
+	// KindString is the kind of a single-line string literal in single
+	// or double quotes, including its quotes.
+	KindString
+
+	// KindPunct is the kind of an operator or delimiter, such as ':',
+	// '+', or '('.
+	KindPunct
+
+	// KindComment is the kind of a comment running from '#' to the end
+	// of the line. It is emitted as trivia.
+	KindComment
+)
+
+// tabWidth is the multiple of columns a tab advances indentation to.
+const tabWidth = 8
+
+type layout struct {
+	widths []int
+	depth  int
+	open   bool
+}
+
+// NewLexer returns a lexer.Lexer that splits rd into tokens, layout
+// included. Indentation that returns to a width no enclosing block
+// has is reported with a lexer.KindError token, and lexing continues
+// as if a block of that width were open. The options configure the
+// underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	var lay *layout
+
+	lay = &layout{widths: []int{0}}
+
+	return lexer.NewLexer(rd, lay.lexLineStart, opts...)
+}
+
+// lexLineStart lexes the indentation of a line.
+func (lay *layout) lexLineStart(lx *lexer.Lexer) lexer.StateFn {
+	var width int
+
+	lx.AcceptRun(" \t")
+	width = indentWidth(lx.PeekToken())
+
+	switch {
+	case lx.AtEOF():
+		lx.Ignore()
+
+		return lay.lexEOF
+	case lx.Accept("\n") || lx.AcceptSeq("\r\n"):
+		lx.Ignore()
+
+		return lay.lexLineStart
+	case lx.Peek() == '#':
+		lx.Ignore()
+
+		return lay.lexToken
+	case width > lay.widths[len(lay.widths)-1]:
+		lay.widths = append(lay.widths, width)
+		lx.Emit(KindIndent)
+	default:
+		lx.Ignore()
+
+		for width < lay.widths[len(lay.widths)-1] {
+			lay.widths = lay.widths[:len(lay.widths)-1]
+			lx.Emit(KindDedent)
+		}
+
+		if width != lay.widths[len(lay.widths)-1] {
+			lay.widths = append(lay.widths, width)
+			lx.Errorf("unindent does not match any outer indentation level")
+		}
+	}
+
+	lay.open = true
+
+	return lay.lexToken
+}
+
+// lexToken lexes the token starting at the current position of a line.
+func (lay *layout) lexToken(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRun(" \t")
+	lx.Ignore()
+
+	switch {
+	case lx.AtEOF():
+		return lay.lexEOF
+	case lx.Accept("#"):
+		lx.UntilFunc(isLineEnd)
+		lx.EmitTrivia(KindComment)
+	case lx.HasPrefix("\n") || lx.HasPrefix("\r\n"):
+		return lay.lexLineEnd
+	case lx.AcceptSeq("\\\n") || lx.AcceptSeq("\\\r\n"):
+		lx.Ignore()
+	case lx.Accept("([{"):
+		lay.depth++
+		lx.Emit(KindPunct)
+	case lx.Accept(")]}"):
+		lay.depth = max(lay.depth-1, 0)
+		lx.Emit(KindPunct)
+	case lx.Accept(":,.;+-*/%=<>!"):
+		lx.Accept("=")
+		lx.Emit(KindPunct)
+	case lx.Peek() == '\'' || lx.Peek() == '"':
+		return lay.lexString
+	case lx.AcceptRun("0123456789") != 0:
+		lx.Emit(KindNumber)
+	case lx.AcceptIdentifier(isIdentStart, isIdentPart):
+		lx.Emit(KindIdent)
+	default:
+		lx.Next()
+		lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lay.lexToken
+}
+
+// lexString lexes a string literal, which ends at the first quote
+// matching the opening one.
+func (lay *layout) lexString(lx *lexer.Lexer) lexer.StateFn {
+	var quote rune
+
+	quote = lx.Next()
+
+	lx.UntilFunc(func(char rune) bool {
+		return char == quote || isLineEnd(char)
+	})
+
+	if !lx.Accept(string(quote)) {
+		lx.Errorf("unterminated string literal")
+
+		return lay.lexToken
+	}
+
+	lx.Emit(KindString)
+
+	return lay.lexToken
+}
+
+// lexLineEnd lexes the line terminator at the current position, which
+// ends the logical line unless brackets are open.
+func (lay *layout) lexLineEnd(lx *lexer.Lexer) lexer.StateFn {
+	lx.Accept("\r")
+	lx.Accept("\n")
+
+	if lay.depth > 0 {
+		lx.Ignore()
+
+		return lay.lexToken
+	}
+
+	if !lay.open {
+		lx.Ignore()
+
+		return lay.lexLineStart
+	}
+
+	lx.Emit(KindNewline)
+	lay.open = false
+
+	return lay.lexLineStart
+}
+
+// lexEOF ends the last logical line, if it lacks a line feed, and
+// closes the blocks still open.
+func (lay *layout) lexEOF(lx *lexer.Lexer) lexer.StateFn {
+	if lay.open {
+		lx.Emit(KindNewline)
+		lay.open = false
+	}
+
+	for len(lay.widths) > 1 {
+		lay.widths = lay.widths[:len(lay.widths)-1]
+		lx.Emit(KindDedent)
+	}
+
+	return nil
+}
+
+// indentWidth returns the width of the indentation text, in columns.
+func indentWidth(text string) int {
+	var (
+		width int
+		char  rune
+	)
+
+	for _, char = range text {
+		if char == '\t' {
+			width += tabWidth - width%tabWidth
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
+
+func isLineEnd(char rune) bool {
+	return char == '\n' || char == '\r'
+}
+
+func isIdentStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isIdentPart(char rune) bool {
+	return isIdentStart(char) || unicode.IsDigit(char)
+}
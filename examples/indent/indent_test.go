@@ -0,0 +1,105 @@
+package indent_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/indent"
+	"github.com/andrieee44/langengine/lexer/lextest"
+)
+
+var (
+	newline = lextest.Kind(indent.KindNewline, "Newline")
+	indt    = lextest.Kind(indent.KindIndent, "Indent")
+	dedent  = lextest.Kind(indent.KindDedent, "Dedent")
+	ident   = lextest.Kind(indent.KindIdent, "Ident")
+	number  = lextest.Kind(indent.KindNumber, "Number")
+	str     = lextest.Kind(indent.KindString, "String")
+	punct   = lextest.Kind(indent.KindPunct, "Punct")
+	comment = lextest.Kind(indent.KindComment, "Comment")
+)
+
+func TestLexer(t *testing.T) {
+	var (
+		testTbl map[string][]lextest.Want
+		src     string
+		wants   []lextest.Want
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]lextest.Want{
+		"def area(w, h):\n    if w > 0:\n\n        # positive\n\treturn w * h\n    return 0\n": {
+			ident("def"),
+			ident("area"),
+			punct("("),
+			ident("w"),
+			punct(","),
+			ident("h"),
+			punct(")"),
+			punct(":"),
+			newline("\n"),
+			indt("    ").At(2, 1),
+			ident("if"),
+			ident("w"),
+			punct(">"),
+			number("0"),
+			punct(":"),
+			newline("\n"),
+			comment("# positive").At(4, 9),
+			indt("\t").At(5, 1),
+			ident("return"),
+			ident("w"),
+			punct("*"),
+			ident("h"),
+			newline("\n"),
+			dedent("").At(6, 5),
+			ident("return"),
+			number("0"),
+			newline("\n"),
+			dedent("").At(7, 1),
+			lextest.EOF,
+		},
+		"x = [1,\n  2] + \\\n 'a'\r\nif x:\n  y": {
+			ident("x"),
+			punct("="),
+			punct("["),
+			number("1"),
+			punct(","),
+			number("2").At(2, 3),
+			punct("]"),
+			punct("+"),
+			str("'a'").At(3, 2),
+			newline("\r\n"),
+			ident("if").At(4, 1),
+			ident("x"),
+			punct(":"),
+			newline("\n"),
+			indt("  "),
+			ident("y"),
+			newline(""),
+			dedent(""),
+			lextest.EOF,
+		},
+		"a:\n    b\n  c\n\"d": {
+			ident("a"),
+			punct(":"),
+			newline("\n"),
+			indt("    "),
+			ident("b"),
+			newline("\n"),
+			dedent("").At(3, 3),
+			lextest.Error("unindent does not match any outer indentation level"),
+			ident("c"),
+			newline("\n"),
+			dedent("").At(4, 1),
+			lextest.Error("unterminated string literal").At(4, 1),
+			newline(""),
+			lextest.EOF,
+		},
+	}
+
+	for src, wants = range testTbl {
+		lextest.Expect(t, indent.NewLexer(strings.NewReader(src)), wants...)
+	}
+}
@@ -0,0 +1,140 @@
+// Package ini is an example of a complete line-oriented lexer, for INI
+// and TOML-style configuration files, assembled from the helpers of
+// the inilex package. It shows how a grammar built from lexer.StateFn
+// states recovers from errors: a malformed line is reported with a
+// lexer.KindError token and lexing resumes on the next line.
+//
+// Blank lines and whitespace around keys, separators, and values are
+// skipped. Values run to the end of their line, including a trailing
+// backslash continuation; use inilex.JoinContinuations to obtain the
+// logical value of a KindValue token.
+package ini // import "github.com/andrieee44/langengine/examples/ini"
+
+import (
+	"io"
+
+	"github.com/andrieee44/langengine/inilex"
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindSection is the kind of a section header, including its
+	// brackets, such as "[server]".
+	KindSection lexer.Kind = lexer.KindUser + iota
+
+	// KindKey is the kind of a bare or quoted key.
+	KindKey
+
+	// KindSeparator is the kind of the '=' or ':' separating a key from
+	// its value.
+	KindSeparator
+
+	// KindValue is the kind of a value.
+	KindValue
+
+	// KindComment is the kind of a comment introduced by ';' or '#'. It
+	// is emitted as trivia.
+	KindComment
+)
+
+// NewLexer returns a lexer.Lexer that splits rd into INI tokens. The
+// options configure the underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexLine, opts...)
+}
+
+// lexLine lexes the start of a line, after any indentation.
+func lexLine(lx *lexer.Lexer) lexer.StateFn {
+	var (
+		ok  bool
+		err error
+	)
+
+	lx.AcceptRun(" \t\r\n")
+	lx.Ignore()
+
+	if lx.Peek() == lexer.EOF {
+		return nil
+	}
+
+	if inilex.AcceptComment(lx.Reader, ";#") {
+		lx.EmitTrivia(KindComment)
+
+		return lexLine
+	}
+
+	ok, err = inilex.AcceptSection(lx.Reader)
+	if err != nil {
+		return recoverLine(lx, "unterminated section header")
+	}
+
+	if ok {
+		lx.Emit(KindSection)
+
+		return lexLineEnd
+	}
+
+	ok, err = inilex.AcceptQuotedKey(lx.Reader)
+	if err != nil {
+		return recoverLine(lx, "unterminated quoted key")
+	}
+
+	if !ok && !inilex.AcceptBareKey(lx.Reader) {
+		lx.Next()
+
+		return recoverLine(lx, "unexpected %q at start of line", lx.PeekToken())
+	}
+
+	lx.Emit(KindKey)
+
+	return lexSeparator
+}
+
+// lexSeparator lexes the separator following a key and the value after
+// it.
+func lexSeparator(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRun(" \t")
+	lx.Ignore()
+
+	if !lx.Accept("=:") {
+		return recoverLine(lx, "missing '=' after key")
+	}
+
+	lx.Emit(KindSeparator)
+	lx.AcceptRun(" \t")
+	lx.Ignore()
+
+	if inilex.AcceptValue(lx.Reader) != 0 {
+		lx.Emit(KindValue)
+	}
+
+	return lexLine
+}
+
+// lexLineEnd lexes the rest of a line after a section header, which
+// may only hold a comment.
+func lexLineEnd(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRun(" \t")
+	lx.Ignore()
+
+	if inilex.AcceptComment(lx.Reader, ";#") {
+		lx.EmitTrivia(KindComment)
+	}
+
+	if !lx.HasPrefix("\n") && !lx.HasPrefix("\r\n") && !lx.AtEOF() {
+		return recoverLine(lx, "unexpected text after section header")
+	}
+
+	return lexLine
+}
+
+// recoverLine reports an error spanning the rest of the line and
+// resumes lexing on the next line.
+func recoverLine(lx *lexer.Lexer, format string, args ...any) lexer.StateFn {
+	lx.UntilFunc(func(char rune) bool {
+		return char == '\r' || char == '\n'
+	})
+	lx.Errorf(format, args...)
+
+	return lexLine
+}
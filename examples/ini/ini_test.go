@@ -0,0 +1,66 @@
+package ini_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/ini"
+	"github.com/andrieee44/langengine/lexer/lextest"
+)
+
+var (
+	section   = lextest.Kind(ini.KindSection, "Section")
+	key       = lextest.Kind(ini.KindKey, "Key")
+	separator = lextest.Kind(ini.KindSeparator, "Separator")
+	value     = lextest.Kind(ini.KindValue, "Value")
+	comment   = lextest.Kind(ini.KindComment, "Comment")
+)
+
+func TestLexer(t *testing.T) {
+	var (
+		testTbl map[string][]lextest.Want
+		src     string
+		wants   []lextest.Want
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]lextest.Want{
+		"; settings\n[server] # main\nhost = example.com\r\n\"max conns\": 10\n": {
+			comment("; settings"),
+			section("[server]").At(2, 1),
+			comment("# main"),
+			key("host").At(3, 1),
+			separator("="),
+			value("example.com"),
+			key(`"max conns"`).At(4, 1),
+			separator(":"),
+			value("10"),
+			lextest.EOF,
+		},
+		"path = a \\\n  b\nempty =\n": {
+			key("path"),
+			separator("="),
+			value("a \\\n  b"),
+			key("empty").At(3, 1),
+			separator("="),
+			lextest.EOF,
+		},
+		"[open\nkey\n!x = 1\n[a] b\nok=1": {
+			lextest.Error("unterminated section header").At(1, 1),
+			key("key").At(2, 1),
+			lextest.Error("missing '=' after key").At(2, 4),
+			lextest.Error(`unexpected "!" at start of line`).At(3, 1),
+			section("[a]").At(4, 1),
+			lextest.Error("unexpected text after section header").At(4, 5),
+			key("ok").At(5, 1),
+			separator("="),
+			value("1"),
+			lextest.EOF,
+		},
+	}
+
+	for src, wants = range testTbl {
+		lextest.Expect(t, ini.NewLexer(strings.NewReader(src)), wants...)
+	}
+}
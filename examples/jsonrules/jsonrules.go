@@ -0,0 +1,48 @@
+// Package jsonrules is an example of a complete lexer written as a
+// declarative rule set, lexing JSON text with the lexgen package rather
+// than with the hand-written states of the jsonlex package. It produces
+// the same kinds as jsonlex, so that the two can be compared, and shows
+// the trade-off between them: the rules are shorter and can be checked
+// for conflicts, while jsonlex reports malformed input more precisely.
+package jsonrules // import "github.com/andrieee44/langengine/examples/jsonrules"
+
+import (
+	"io"
+
+	"github.com/andrieee44/langengine/jsonlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+)
+
+// Rules are the rules of the JSON grammar, in order of priority.
+var Rules = []lexgen.Rule{
+	{Name: "whitespace", Pattern: `[ \t\n\r]+`, Skip: true},
+	{Name: "begin-object", Pattern: `\{`, Kind: jsonlex.KindBeginObject},
+	{Name: "end-object", Pattern: `\}`, Kind: jsonlex.KindEndObject},
+	{Name: "begin-array", Pattern: `\[`, Kind: jsonlex.KindBeginArray},
+	{Name: "end-array", Pattern: `\]`, Kind: jsonlex.KindEndArray},
+	{Name: "name-separator", Pattern: `:`, Kind: jsonlex.KindNameSeparator},
+	{Name: "value-separator", Pattern: `,`, Kind: jsonlex.KindValueSeparator},
+	{
+		Name:    "string",
+		Pattern: `"([^"\\\x00-\x1f]|\\(["\\/bfnrt]|u[0-9a-fA-F]{4}))*"`,
+		Kind:    jsonlex.KindString,
+	},
+	{
+		Name:    "number",
+		Pattern: `-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?`,
+		Kind:    jsonlex.KindNumber,
+	},
+	{Name: "true", Pattern: `true`, Kind: jsonlex.KindTrue},
+	{Name: "false", Pattern: `false`, Kind: jsonlex.KindFalse},
+	{Name: "null", Pattern: `null`, Kind: jsonlex.KindNull},
+}
+
+// Grammar is the compiled form of Rules.
+var Grammar = lexgen.MustCompile(Rules)
+
+// NewLexer returns a lexer.Lexer that splits rd into JSON tokens with
+// Grammar. The options configure the underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return Grammar.NewLexer(rd, opts...)
+}
@@ -0,0 +1,76 @@
+package jsonrules_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/jsonrules"
+	"github.com/andrieee44/langengine/jsonlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexer/lextest"
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(lx *lexer.Lexer) []lexer.Token {
+	var (
+		tokens []lexer.Token
+		tok    lexer.Token
+	)
+
+	for tok = range lx.Tokens() {
+		tokens = append(tokens, tok)
+	}
+
+	return tokens
+}
+
+func TestLexer(t *testing.T) {
+	var (
+		corpus []string
+		src    string
+	)
+
+	t.Parallel()
+
+	assert.Empty(t, jsonrules.Grammar.Conflicts())
+
+	corpus = []string{
+		`{"name": "xé\n", "tags": ["a", "b"]}`,
+		"[-0, 1.5e+3, 2E-2, 10]\r\n",
+		`{"ok": true, "err": false, "v": null}`,
+		"",
+	}
+
+	for _, src = range corpus {
+		assert.Equal(
+			t,
+			collect(jsonlex.NewLexer(strings.NewReader(src))),
+			collect(jsonrules.NewLexer(strings.NewReader(src))),
+			"source %q",
+			src,
+		)
+	}
+}
+
+func TestLexerError(t *testing.T) {
+	t.Parallel()
+
+	// Unlike jsonlex, the rules split a number with a leading zero
+	// rather than rejecting it, leaving the error to the parser.
+	lextest.Expect(
+		t,
+		jsonrules.NewLexer(strings.NewReader(`[01]`)),
+		lextest.Kind(jsonlex.KindBeginArray, "BeginArray")("["),
+		lextest.Kind(jsonlex.KindNumber, "Number")("0"),
+		lextest.Kind(jsonlex.KindNumber, "Number")("1"),
+		lextest.Kind(jsonlex.KindEndArray, "EndArray")("]"),
+		lextest.EOF,
+	)
+	lextest.Expect(
+		t,
+		jsonrules.NewLexer(strings.NewReader(`["a`)),
+		lextest.Kind(jsonlex.KindBeginArray, "BeginArray")("["),
+		lextest.Error(`no rule matches "\""`).At(1, 2),
+		lextest.EOF,
+	)
+}
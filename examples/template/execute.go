@@ -0,0 +1,244 @@
+package template
+
+import (
+	"errors"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/filter"
+	"github.com/andrieee44/langengine/parser"
+)
+
+// ErrUndefined is wrapped by the *eval.RuntimeError returned by Execute
+// for a tag whose name is not defined in any context.
+var ErrUndefined = errors.New("template: undefined name")
+
+var rules = map[string]eval.Rule{
+	ruleTemplate: func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+		return render(ev, node.Children, env)
+	},
+	ruleText: func(_ *eval.Evaluator, node *parser.Node, _ *eval.Env) (eval.Value, error) {
+		return eval.String(node.Text), nil
+	},
+	ruleVar: func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+		return interpolate(ev, node, env, html.EscapeString)
+	},
+	ruleRaw: func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+		return interpolate(ev, node, env, func(s string) string {
+			return s
+		})
+	},
+	ruleSection: renderSection,
+	ruleInverted: func(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+		var (
+			v   eval.Value
+			err error
+		)
+
+		v, err = lookup(ev, node, env)
+		if err != nil || !falsy(v) {
+			return eval.String(""), err
+		}
+
+		return render(ev, node.Children, env)
+	},
+}
+
+// Execute renders the template with data as the outermost context and
+// writes the result to w. Names look up the fields of contexts that are
+// filter.Object values, such as filter.Value converts a map of the host
+// to.
+//
+// Returns an *eval.RuntimeError wrapping ErrUndefined, spanning the
+// tag, for a name not defined in any context, an *eval.RuntimeError for
+// an error of a callable value, or the error of w.
+func (tmpl *Template) Execute(w io.Writer, data eval.Value) error {
+	var (
+		ev  *eval.Evaluator
+		env *eval.Env
+		v   eval.Value
+		err error
+	)
+
+	ev = eval.New(rules)
+	env = eval.NewEnv(ev.Globals)
+	env.Define(".", data)
+
+	v, err = ev.Eval(tmpl.root, env)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, v.String())
+
+	return err
+}
+
+// renderSection renders the body of a section for every element of a
+// list, or once for a truthy value, with the element or the value as
+// the context.
+func renderSection(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		v, elem eval.Value
+		list    eval.List
+		sb      strings.Builder
+		out     eval.Value
+		ok      bool
+		err     error
+	)
+
+	v, err = lookup(ev, node, env)
+	if err != nil || falsy(v) {
+		return eval.String(""), err
+	}
+
+	list, ok = v.(eval.List)
+	if !ok {
+		list = eval.List{v}
+	}
+
+	for _, elem = range list {
+		out, err = render(ev, node.Children, context(env, elem))
+		if err != nil {
+			return nil, err
+		}
+
+		sb.WriteString(out.String())
+	}
+
+	return eval.String(sb.String()), nil
+}
+
+// interpolate renders the value of the name of node with escape.
+func interpolate(
+	ev *eval.Evaluator,
+	node *parser.Node,
+	env *eval.Env,
+	escape func(string) string,
+) (eval.Value, error) {
+	var (
+		v   eval.Value
+		err error
+	)
+
+	v, err = lookup(ev, node, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil || v == (eval.Nil{}) {
+		return eval.String(""), nil
+	}
+
+	return eval.String(escape(v.String())), nil
+}
+
+// render renders nodes in env and concatenates the results.
+func render(ev *eval.Evaluator, nodes []*parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		sb   strings.Builder
+		node *parser.Node
+		v    eval.Value
+		err  error
+	)
+
+	for _, node = range nodes {
+		v, err = ev.Eval(node, env)
+		if err != nil {
+			return nil, err
+		}
+
+		sb.WriteString(v.String())
+	}
+
+	return eval.String(sb.String()), nil
+}
+
+// lookup returns the value of the name of node: the field of the first
+// context defining its first part, from the innermost outwards, and the
+// fields of that for the other parts. A callable value is called.
+func lookup(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		parts []string
+		part  string
+		ctx   eval.Value
+		v     eval.Value
+		obj   filter.Object
+		ok    bool
+	)
+
+	if node.Text == "." {
+		v, _ = env.Lookup(".")
+
+		return call(ev, node, v)
+	}
+
+	parts = strings.Split(node.Text, ".")
+
+	for ; env != nil && !ok; env = env.Parent() {
+		ctx, ok = env.Lookup(".")
+		if !ok {
+			break
+		}
+
+		obj, ok = ctx.(filter.Object)
+		if ok {
+			v, ok = obj[parts[0]]
+		}
+	}
+
+	if !ok {
+		return nil, ev.Errorf(node.Span, "%w: %s", ErrUndefined, parts[0])
+	}
+
+	for _, part = range parts[1:] {
+		obj, ok = v.(filter.Object)
+		if ok {
+			v, ok = obj[part]
+		}
+
+		if !ok {
+			return nil, ev.Errorf(node.Span, "%w: %s", ErrUndefined, node.Text)
+		}
+	}
+
+	return call(ev, node, v)
+}
+
+// call returns the result of calling v without arguments if it is
+// callable, or else v.
+func call(ev *eval.Evaluator, node *parser.Node, v eval.Value) (eval.Value, error) {
+	var ok bool
+
+	_, ok = v.(eval.Callable)
+	if !ok {
+		return v, nil
+	}
+
+	return ev.Call(node.Text, v, nil, node.Span)
+}
+
+// context returns a new environment in env with v as the context.
+func context(env *eval.Env, v eval.Value) *eval.Env {
+	env = eval.NewEnv(env)
+	env.Define(".", v)
+
+	return env
+}
+
+// falsy reports whether a section is skipped for v.
+func falsy(v eval.Value) bool {
+	var (
+		list eval.List
+		ok   bool
+	)
+
+	list, ok = v.(eval.List)
+	if ok {
+		return len(list) == 0
+	}
+
+	return !eval.Truthy(v)
+}
@@ -0,0 +1,276 @@
+package template
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+// The rules of the nodes of a parsed template. Sections and variables
+// hold their name as Text.
+const (
+	ruleTemplate = "Template"
+	ruleText     = "Text"
+	ruleVar      = "Var"
+	ruleRaw      = "Raw"
+	ruleSection  = "Section"
+	ruleInverted = "Inverted"
+)
+
+// Template is a parsed template. A new Template is constructed with
+// Parse and is safe for concurrent use.
+type Template struct {
+	root *parser.Node
+}
+
+// tag is a parsed tag.
+type tag struct {
+	sigil string
+	name  string
+	span  lexer.Span
+}
+
+// parseState holds the state of a Parse.
+type parseState struct {
+	trd  *lexer.TokenReader
+	span lexer.Span
+	read bool
+}
+
+// Parse parses the template read from rd. The options configure the
+// underlying lexer.Reader.
+//
+// Returns a *parser.SyntaxError for the first lexical error, malformed
+// tag, or unbalanced section.
+func Parse(rd io.Reader, opts ...lexer.Option) (*Template, error) {
+	var (
+		ps    *parseState
+		nodes []*parser.Node
+		err   error
+	)
+
+	ps = &parseState{trd: lexer.NewTokenReader(NewLexer(rd, opts...))}
+
+	nodes, _, err = ps.block(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{root: &parser.Node{
+		Rule:     ruleTemplate,
+		Span:     ps.span,
+		Children: nodes,
+	}}, nil
+}
+
+// MustParse is like Parse but reads src and panics if it cannot be
+// parsed. It simplifies the initialization of global templates.
+func MustParse(src string) *Template {
+	var (
+		tmpl *Template
+		err  error
+	)
+
+	tmpl, err = Parse(strings.NewReader(src))
+	if err != nil {
+		panic(err)
+	}
+
+	return tmpl
+}
+
+// Tree returns the parse tree of the template, as for dumping it with
+// parser.WriteSExpr. It must not be modified.
+func (tmpl *Template) Tree() *parser.Node {
+	return tmpl.root
+}
+
+// block parses nodes up to the end of the input, or up to the closing
+// tag of the section opened by open, which it returns.
+func (ps *parseState) block(open *tag) ([]*parser.Node, *tag, error) {
+	var (
+		nodes  []*parser.Node
+		tok    lexer.Token
+		t, end *tag
+		body   []*parser.Node
+		ok     bool
+		err    error
+	)
+
+	for {
+		tok, ok = ps.next()
+
+		switch {
+		case !ok && open != nil:
+			return nil, nil, syntaxError(open.span, "unclosed section %q", open.name)
+		case !ok:
+			return nodes, nil, nil
+		case tok.Kind == lexer.KindError:
+			return nil, nil, syntaxError(tok.Span, "%s", tok.Text)
+		case tok.Kind == KindText:
+			nodes = append(nodes, &parser.Node{
+				Rule: ruleText,
+				Text: tok.Text,
+				Span: tok.Span,
+			})
+
+			continue
+		}
+
+		t, err = ps.tag(tok)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if t == nil {
+			continue
+		}
+
+		switch t.sigil {
+		case "":
+			nodes = append(nodes, &parser.Node{Rule: ruleVar, Text: t.name, Span: t.span})
+		case "&":
+			nodes = append(nodes, &parser.Node{Rule: ruleRaw, Text: t.name, Span: t.span})
+		case "/":
+			if open == nil {
+				return nil, nil, syntaxError(t.span, "unexpected closing tag %q", t.name)
+			}
+
+			if t.name != open.name {
+				return nil, nil, syntaxError(
+					t.span,
+					"closing tag %q does not match section %q",
+					t.name,
+					open.name,
+				)
+			}
+
+			return nodes, t, nil
+		default:
+			body, end, err = ps.block(t)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			nodes = append(nodes, &parser.Node{
+				Rule:     ruleSection,
+				Text:     t.name,
+				Span:     lexer.Span{Start: t.span.Start, End: end.span.End},
+				Children: body,
+			})
+
+			if t.sigil == "^" {
+				nodes[len(nodes)-1].Rule = ruleInverted
+			}
+		}
+	}
+}
+
+// tag parses the rest of the tag opened by open. It returns a nil *tag
+// for a comment.
+func (ps *parseState) tag(open lexer.Token) (*tag, error) {
+	var (
+		t   *tag
+		tok lexer.Token
+	)
+
+	t = &tag{span: open.Span}
+
+	tok = ps.expect(open)
+	if tok.Kind == KindComment {
+		tok = ps.expect(tok)
+		if tok.Kind != KindClose {
+			return nil, unexpected(tok, "}}")
+		}
+
+		return nil, nil
+	}
+
+	if tok.Kind == KindSigil {
+		t.sigil = tok.Text
+		tok = ps.expect(tok)
+	}
+
+	if tok.Kind != KindName {
+		return nil, unexpected(tok, "name")
+	}
+
+	t.name = tok.Text
+
+	tok = ps.expect(tok)
+	if tok.Kind != KindClose {
+		return nil, unexpected(tok, "}}")
+	}
+
+	t.span.End = tok.Span.End
+
+	return t, nil
+}
+
+// next returns the next token that is not trivia, or the next token
+// if it is a comment.
+func (ps *parseState) next() (lexer.Token, bool) {
+	var (
+		tok lexer.Token
+		ok  bool
+	)
+
+	for {
+		tok, ok = ps.trd.NextToken()
+		if !ok {
+			return tok, false
+		}
+
+		if !ps.read {
+			ps.span.Start = tok.Span.Start
+			ps.read = true
+		}
+
+		ps.span.End = tok.Span.End
+
+		if !tok.Trivia || tok.Kind == KindComment {
+			return tok, true
+		}
+	}
+}
+
+// expect returns the next token within a tag, or an empty KindEOF token
+// after prev if the input ends.
+func (ps *parseState) expect(prev lexer.Token) lexer.Token {
+	var (
+		tok lexer.Token
+		ok  bool
+	)
+
+	tok, ok = ps.next()
+	if !ok {
+		return lexer.Token{
+			Kind: lexer.KindEOF,
+			Span: lexer.Span{Start: prev.Span.End, End: prev.Span.End},
+		}
+	}
+
+	return tok
+}
+
+// unexpected returns the error for tok where want was expected.
+func unexpected(tok lexer.Token, want string) error {
+	switch tok.Kind {
+	case lexer.KindError:
+		return syntaxError(tok.Span, "%s", tok.Text)
+	case lexer.KindEOF:
+		return syntaxError(tok.Span, "expected %s, found end of input", want)
+	default:
+		return syntaxError(tok.Span, "expected %s, found %q", want, tok.Text)
+	}
+}
+
+func syntaxError(span lexer.Span, format string, args ...any) *parser.SyntaxError {
+	return &parser.SyntaxError{Diagnostic: lexer.Diagnostic{
+		Span:    span,
+		Message: fmt.Sprintf(format, args...),
+	}}
+}
@@ -0,0 +1,142 @@
+// Package template is an example of a complete language built on the
+// engine, from lexing through parsing to evaluation: a logic-less
+// template language in the style of Mustache.
+//
+//	Hello, {{name}}!
+//	{{#items}}
+//	- {{title}} ({{&html}})
+//	{{/items}}
+//	{{^items}}Nothing to show.{{/items}}
+//	{{! A comment. }}
+//
+// Outside of tags, text is copied as is. {{name}} is replaced by the
+// value of name, HTML-escaped, and {{&name}} by the value unescaped.
+// Names are looked up in the stack of contexts opened by sections, from
+// the innermost outwards; a dotted name such as user.name looks up
+// fields of the value found, and . is the current context. A section
+// {{#name}}...{{/name}} renders its body once for every element of a
+// list, with the element as context, and once for any other value that
+// is truthy, with the value as context. An inverted section
+// {{^name}}...{{/name}} renders its body if the value is falsy or an
+// empty list. A value that is callable, such as an eval.Builtin, is
+// called without arguments and its result used instead.
+//
+// The lexer enters the "tag" mode between {{ and }}, where it splits
+// names and sigils, rather than copying text. The parser turns the
+// tokens into a tree of parser.Node values, reporting malformed tags
+// and unbalanced sections as *parser.SyntaxError values, and rendering
+// evaluates the tree with the eval package, reporting names that are
+// not defined as *eval.RuntimeError values spanning the tag.
+package template // import "github.com/andrieee44/langengine/examples/template"
+
+import (
+	"io"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindText is the kind of the text between tags.
+	KindText lexer.Kind = lexer.KindUser + iota
+
+	// KindOpen is the kind of the {{ opening a tag.
+	KindOpen
+
+	// KindClose is the kind of the }} closing a tag.
+	KindClose
+
+	// KindSigil is the kind of the #, ^, /, or & marking the type of a
+	// tag.
+	KindSigil
+
+	// KindName is the kind of a name, such as user.name or the dot.
+	KindName
+
+	// KindComment is the kind of a comment, from the ! after {{ to the
+	// closing }}. It is emitted as trivia.
+	KindComment
+)
+
+// tagMode is the mode of the Lexer between {{ and }}.
+const tagMode = "tag"
+
+// NewLexer returns a lexer.Lexer that splits rd into template tokens.
+// A tag left open at the end of the input, and runes that cannot
+// appear in a tag, are reported with lexer.KindError tokens. The
+// options configure the underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexToken, opts...)
+}
+
+// lexToken lexes a token in the current mode.
+func lexToken(lx *lexer.Lexer) lexer.StateFn {
+	if lx.Mode() == tagMode {
+		return lexTag(lx)
+	}
+
+	return lexText(lx)
+}
+
+// lexText lexes the text up to the next tag, and the {{ opening it.
+func lexText(lx *lexer.Lexer) lexer.StateFn {
+	var (
+		n     int
+		found bool
+	)
+
+	n, found = lx.UntilSeq("{{")
+	if n != 0 {
+		lx.Emit(KindText)
+	}
+
+	if !found {
+		return nil
+	}
+
+	lx.AcceptSeq("{{")
+	lx.Emit(KindOpen)
+	lx.PushMode(tagMode)
+
+	return lexToken
+}
+
+// lexTag lexes a token within a tag.
+func lexTag(lx *lexer.Lexer) lexer.StateFn {
+	var found bool
+
+	lx.AcceptRunFunc(unicode.IsSpace)
+	lx.Ignore()
+
+	switch {
+	case lx.AcceptSeq("}}"):
+		lx.Emit(KindClose)
+		lx.PopMode()
+	case lx.Accept("!"):
+		_, found = lx.UntilSeq("}}")
+		if !found {
+			return lx.Errorf("unclosed comment")
+		}
+
+		lx.EmitTrivia(KindComment)
+	case lx.Accept("#^/&"):
+		lx.Emit(KindSigil)
+	case lx.Accept("."), lx.AcceptIdentifier(isNameStart, isNamePart):
+		lx.Emit(KindName)
+	case lx.AtEOF():
+		return lx.Errorf("unclosed tag")
+	default:
+		lx.Next()
+		lx.Errorf("unexpected %q in tag", lx.PeekToken())
+	}
+
+	return lexToken
+}
+
+func isNameStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isNamePart(char rune) bool {
+	return isNameStart(char) || char == '.' || unicode.IsDigit(char)
+}
@@ -0,0 +1,221 @@
+package template_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/examples/template"
+	"github.com/andrieee44/langengine/filter"
+	"github.com/andrieee44/langengine/lexer/lextest"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	text    = lextest.Kind(template.KindText, "Text")
+	open    = lextest.Kind(template.KindOpen, "Open")
+	closing = lextest.Kind(template.KindClose, "Close")
+	sigil   = lextest.Kind(template.KindSigil, "Sigil")
+	name    = lextest.Kind(template.KindName, "Name")
+	comment = lextest.Kind(template.KindComment, "Comment")
+)
+
+func TestLexer(t *testing.T) {
+	var (
+		testTbl map[string][]lextest.Want
+		src     string
+		wants   []lextest.Want
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]lextest.Want{
+		"Hi {{ user.name }}!\n{{#items}}{{.}}{{/items}}": {
+			text("Hi "),
+			open("{{"),
+			name("user.name").At(1, 7),
+			closing("}}"),
+			text("!\n"),
+			open("{{").At(2, 1),
+			sigil("#"),
+			name("items"),
+			closing("}}"),
+			open("{{"),
+			name("."),
+			closing("}}"),
+			open("{{"),
+			sigil("/"),
+			name("items"),
+			closing("}}"),
+			lextest.EOF,
+		},
+		"{{! note }}a{{&x}}": {
+			open("{{"),
+			comment("! note "),
+			closing("}}"),
+			text("a"),
+			open("{{"),
+			sigil("&"),
+			name("x"),
+			closing("}}"),
+			lextest.EOF,
+		},
+		"{{x + y}}{{z": {
+			open("{{"),
+			name("x"),
+			lextest.Error(`unexpected "+" in tag`).At(1, 5),
+			name("y"),
+			closing("}}"),
+			open("{{"),
+			name("z"),
+			lextest.Error("unclosed tag"),
+			lextest.EOF,
+		},
+	}
+
+	for src, wants = range testTbl {
+		lextest.Expect(t, template.NewLexer(strings.NewReader(src)), wants...)
+	}
+}
+
+func TestTemplateExecute(t *testing.T) {
+	type testData struct {
+		src  string
+		want string
+	}
+
+	var (
+		testTbl map[string]testData
+		data    filter.Object
+		caseNm  string
+		test    testData
+		sb      strings.Builder
+	)
+
+	t.Parallel()
+
+	data = filter.Object{
+		"name": eval.String("<Ann>"),
+		"user": filter.Object{"name": eval.String("Bo")},
+		"items": eval.List{
+			filter.Object{"title": eval.String("a"), "n": eval.Int(1)},
+			filter.Object{"title": eval.String("b")},
+		},
+		"none":  eval.List{},
+		"flag":  eval.Bool(true),
+		"nil":   eval.Nil{},
+		"n":     eval.Int(0),
+		"tags":  eval.List{eval.String("x"), eval.String("y")},
+		"shout": &eval.Builtin{Name: "shout", Fn: shout},
+	}
+
+	testTbl = map[string]testData{
+		"text":     {src: "plain {text}", want: "plain {text}"},
+		"escape":   {src: "{{name}} {{&name}}", want: "&lt;Ann&gt; <Ann>"},
+		"dotted":   {src: "{{ user.name }}", want: "Bo"},
+		"comment":  {src: "a{{! ignored }}b", want: "ab"},
+		"nil":      {src: "[{{nil}}]", want: "[]"},
+		"list":     {src: "{{#items}}{{title}}{{n}};{{/items}}", want: "a1;b0;"},
+		"dot":      {src: "{{#tags}}<{{.}}>{{/tags}}", want: "<x><y>"},
+		"truthy":   {src: "{{#flag}}yes{{/flag}}{{#n}}no{{/n}}", want: "yes"},
+		"context":  {src: "{{#user}}{{name}}/{{flag}}{{/user}}", want: "Bo/true"},
+		"inverted": {src: "{{^none}}empty{{/none}}{{^items}}full{{/items}}", want: "empty"},
+		"lambda":   {src: "{{shout}}", want: "HEY"},
+	}
+
+	for caseNm, test = range testTbl {
+		sb.Reset()
+
+		assert.NoError(t, template.MustParse(test.src).Execute(&sb, data), caseNm)
+		assert.Equal(t, test.want, sb.String(), caseNm)
+	}
+}
+
+func shout(_ *eval.Evaluator, _ []eval.Value) (eval.Value, error) {
+	return eval.String("HEY"), nil
+}
+
+func TestParseErrors(t *testing.T) {
+	var (
+		testTbl map[string]string
+		src     string
+		msg     string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]string{
+		"{{#a}}x":             `1:1: unclosed section "a"`,
+		"{{#a}}{{/b}}":        `1:7: closing tag "b" does not match section "a"`,
+		"x\n{{/a}}":           `2:1: unexpected closing tag "a"`,
+		"{{}}":                "1:3: expected name, found \"}}\"",
+		"{{a b}}":             "1:5: expected }}, found \"b\"",
+		"{{#":                 "1:4: unclosed tag",
+		"{{a + b}}":           `1:5: unexpected "+" in tag`,
+		"{{! never closed":    "1:3: unclosed comment",
+		"{{#a}}{{^b}}{{/a}}":  `1:13: closing tag "a" does not match section "b"`,
+		"ok {{#a}}{{/a}} {{x": "1:20: unclosed tag",
+	}
+
+	for src, msg = range testTbl {
+		_, err = template.Parse(strings.NewReader(src))
+
+		assert.EqualError(t, err, msg, src)
+		assert.IsType(t, &parser.SyntaxError{}, err, src)
+	}
+
+	assert.Panics(t, func() {
+		template.MustParse("{{")
+	})
+}
+
+func TestTemplateExecuteErrors(t *testing.T) {
+	var (
+		tmpl  *template.Template
+		data  filter.Object
+		rterr *eval.RuntimeError
+		err   error
+	)
+
+	t.Parallel()
+
+	data = filter.Object{
+		"user": filter.Object{"name": eval.String("Bo")},
+		"fail": &eval.Builtin{
+			Name: "fail",
+			Fn: func(_ *eval.Evaluator, _ []eval.Value) (eval.Value, error) {
+				return nil, errors.New("no value")
+			},
+		},
+	}
+
+	tmpl = template.MustParse("Hi\n{{#user}}\n  {{nmae}}\n{{/user}}")
+	err = tmpl.Execute(&strings.Builder{}, data)
+
+	assert.ErrorIs(t, err, template.ErrUndefined)
+	assert.EqualError(t, err, "3:3: template: undefined name: nmae")
+	assert.ErrorAs(t, err, &rterr)
+	assert.Equal(t, 11, rterr.Diagnostic.Span.End.Column)
+
+	err = template.MustParse("{{user.email}}").Execute(&strings.Builder{}, data)
+	assert.EqualError(t, err, "1:1: template: undefined name: user.email")
+
+	err = template.MustParse("{{fail}}").Execute(&strings.Builder{}, data)
+	assert.EqualError(t, err, "1:1: no value\n\tin fail called at 1:1")
+}
+
+func TestTemplateTree(t *testing.T) {
+	var sb strings.Builder
+
+	t.Parallel()
+
+	assert.NoError(t, parser.WriteSExpr(&sb, template.MustParse("a{{#b}}{{c}}{{/b}}").Tree()))
+	assert.Equal(t, `(Template 1:1-1:19
+	(Text 1:1-1:2 "a")
+	(Section 1:2-1:19
+		(Var 1:8-1:13 "c")))
+`, sb.String())
+}
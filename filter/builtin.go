@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/eval"
+)
+
+var (
+	// builtins holds the predefined functions.
+	builtins = map[string]*eval.Builtin{
+		"len":        {Name: "len", Fn: builtinLen},
+		"lower":      stringFunc("lower", strings.ToLower),
+		"upper":      stringFunc("upper", strings.ToUpper),
+		"trim":       stringFunc("trim", strings.TrimSpace),
+		"contains":   predicate("contains", strings.Contains),
+		"startsWith": predicate("startsWith", strings.HasPrefix),
+		"endsWith":   predicate("endsWith", strings.HasSuffix),
+		"matches":    {Name: "matches", Fn: builtinMatches},
+	}
+
+	// patterns caches the regular expressions compiled by matches.
+	patterns sync.Map
+)
+
+// builtinLen returns the number of runes of a string, elements of a
+// list, or fields of an object.
+func builtinLen(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+	var (
+		s   eval.String
+		l   eval.List
+		obj Object
+		ok  bool
+		err error
+	)
+
+	err = arity("len", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok = args[0].(eval.String)
+	if ok {
+		return eval.Int(utf8.RuneCountInString(string(s))), nil
+	}
+
+	l, ok = args[0].(eval.List)
+	if ok {
+		return eval.Int(len(l)), nil
+	}
+
+	obj, ok = args[0].(Object)
+	if ok {
+		return eval.Int(len(obj)), nil
+	}
+
+	return nil, fmt.Errorf("%w: len of %s", ErrType, args[0].Type())
+}
+
+// builtinMatches reports whether a string contains a match of a regular
+// expression.
+func builtinMatches(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+	var (
+		strs []string
+		re   any
+		ok   bool
+		err  error
+	)
+
+	strs, err = stringArgs("matches", args)
+	if err != nil {
+		return nil, err
+	}
+
+	re, ok = patterns.Load(strs[1])
+	if !ok {
+		re, err = regexp.Compile(strs[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		re, _ = patterns.LoadOrStore(strs[1], re)
+	}
+
+	return eval.Bool(re.(*regexp.Regexp).MatchString(strs[0])), nil
+}
+
+// stringFunc returns the builtin name applying fn to a string.
+func stringFunc(name string, fn func(string) string) *eval.Builtin {
+	return &eval.Builtin{
+		Name: name,
+		Fn: func(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+			var (
+				s   eval.String
+				ok  bool
+				err error
+			)
+
+			err = arity(name, args, 1)
+			if err != nil {
+				return nil, err
+			}
+
+			s, ok = args[0].(eval.String)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s of %s", ErrType, name, args[0].Type())
+			}
+
+			return eval.String(fn(string(s))), nil
+		},
+	}
+}
+
+// predicate returns the builtin name applying fn to two strings.
+func predicate(name string, fn func(s, sub string) bool) *eval.Builtin {
+	return &eval.Builtin{
+		Name: name,
+		Fn: func(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+			var (
+				strs []string
+				err  error
+			)
+
+			strs, err = stringArgs(name, args)
+			if err != nil {
+				return nil, err
+			}
+
+			return eval.Bool(fn(strs[0], strs[1])), nil
+		},
+	}
+}
+
+// stringArgs returns the two string arguments of the builtin name.
+func stringArgs(name string, args []eval.Value) ([]string, error) {
+	var (
+		strs []string
+		arg  eval.Value
+		s    eval.String
+		ok   bool
+		err  error
+	)
+
+	err = arity(name, args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arg = range args {
+		s, ok = arg.(eval.String)
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: %s of %s and %s",
+				ErrType,
+				name,
+				args[0].Type(),
+				args[1].Type(),
+			)
+		}
+
+		strs = append(strs, string(s))
+	}
+
+	return strs, nil
+}
+
+// arity checks that the builtin name was called with n arguments.
+func arity(name string, args []eval.Value, n int) error {
+	if len(args) != n {
+		return fmt.Errorf(
+			"%w: %s takes %d arguments, got %d",
+			eval.ErrArity,
+			name,
+			n,
+			len(args),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package filter
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+// evalName evaluates a name to the value bound to it.
+func evalName(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	return lookup(ev, node.Span, env, node.Text)
+}
+
+// evalList evaluates a list literal to its elements.
+func evalList(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	return values(ev, node.Children, env)
+}
+
+// values evaluates nodes in env.
+func values(ev *eval.Evaluator, nodes []*parser.Node, env *eval.Env) (eval.List, error) {
+	var (
+		l     eval.List
+		child *parser.Node
+		v     eval.Value
+		err   error
+	)
+
+	l = make(eval.List, 0, len(nodes))
+
+	for _, child = range nodes {
+		v, err = ev.Eval(child, env)
+		if err != nil {
+			return nil, err
+		}
+
+		l = append(l, v)
+	}
+
+	return l, nil
+}
+
+// evalCall calls the function named by a call with its arguments.
+func evalCall(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		callee eval.Value
+		args   eval.List
+		err    error
+	)
+
+	callee, err = lookup(ev, node.Span, env, node.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err = values(ev, node.Children, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return ev.Call(node.Text, callee, args, node.Span)
+}
+
+// evalUnary applies a prefix operator.
+func evalUnary(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		v   eval.Value
+		i   eval.Int
+		f   eval.Float
+		ok  bool
+		err error
+	)
+
+	v, err = ev.Eval(node.Children[0], env)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Text != "-" {
+		return eval.Bool(!eval.Truthy(v)), nil
+	}
+
+	i, ok = v.(eval.Int)
+	if ok {
+		return -i, nil
+	}
+
+	f, ok = v.(eval.Float)
+	if ok {
+		return -f, nil
+	}
+
+	return nil, ev.Errorf(node.Span, "%w: -%s", ErrType, v.Type())
+}
+
+// evalBinary applies an infix operator. The boolean operators evaluate
+// their right operand only if the left one does not decide the result.
+func evalBinary(ev *eval.Evaluator, node *parser.Node, env *eval.Env) (eval.Value, error) {
+	var (
+		left, right eval.Value
+		result      bool
+		ok          bool
+		err         error
+	)
+
+	left, err = ev.Eval(node.Children[0], env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.Text {
+	case "and", "&&":
+		if !eval.Truthy(left) {
+			return eval.Bool(false), nil
+		}
+	case "or", "||":
+		if eval.Truthy(left) {
+			return eval.Bool(true), nil
+		}
+	}
+
+	right, err = ev.Eval(node.Children[1], env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.Text {
+	case "and", "&&", "or", "||":
+		return eval.Bool(eval.Truthy(right)), nil
+	case "==":
+		return eval.Bool(equal(left, right)), nil
+	case "!=":
+		return eval.Bool(!equal(left, right)), nil
+	case "in":
+		result, ok = contains(right, left)
+	default:
+		result, ok = order(node.Text, left, right)
+	}
+
+	if !ok {
+		return nil, ev.Errorf(
+			node.Span,
+			"%w: %s %s %s",
+			ErrType,
+			left.Type(),
+			node.Text,
+			right.Type(),
+		)
+	}
+
+	return eval.Bool(result), nil
+}
+
+// lookup returns the value of the dotted name: the variable named by its
+// first part, and the fields of that for the other parts.
+func lookup(ev *eval.Evaluator, span lexer.Span, env *eval.Env, name string) (eval.Value, error) {
+	var (
+		parts []string
+		part  string
+		v     eval.Value
+		obj   Object
+		ok    bool
+		err   error
+	)
+
+	parts = strings.Split(name, ".")
+
+	v, ok = env.Lookup(parts[0])
+	if !ok {
+		return nil, ev.Errorf(span, "%w: %s", ErrUndefined, parts[0])
+	}
+
+	v, err = resolve(v)
+	if err != nil {
+		return nil, ev.Error(span, err)
+	}
+
+	for _, part = range parts[1:] {
+		obj, ok = v.(Object)
+		if !ok {
+			return nil, ev.Errorf(span, "%w: field %s of %s", ErrType, part, v.Type())
+		}
+
+		v, ok = obj[part]
+		if !ok {
+			return nil, ev.Errorf(span, "%w: %s", ErrUndefined, name)
+		}
+	}
+
+	return v, nil
+}
+
+// resolve converts v with Value if it is a variable of the host.
+func resolve(v eval.Value) (eval.Value, error) {
+	var (
+		vr variable
+		ok bool
+	)
+
+	vr, ok = v.(variable)
+	if !ok {
+		return v, nil
+	}
+
+	return Value(vr.x)
+}
+
+// equal reports whether a and b are equal: numbers by value, lists and
+// objects by their elements, and other values if they are of the same
+// comparable type and equal.
+func equal(a, b eval.Value) bool {
+	var (
+		c         int
+		la, lb    eval.List
+		oa, ob    Object
+		ok, isNum bool
+	)
+
+	c, isNum = compareNumbers(a, b)
+	if isNum {
+		return c == 0
+	}
+
+	la, ok = a.(eval.List)
+	if ok {
+		lb, ok = b.(eval.List)
+
+		return ok && slices.EqualFunc(la, lb, equal)
+	}
+
+	oa, ok = a.(Object)
+	if ok {
+		ob, ok = b.(Object)
+
+		return ok && maps.EqualFunc(oa, ob, equal)
+	}
+
+	return a != nil && reflect.TypeOf(a).Comparable() && a == b
+}
+
+// contains reports whether the list haystack holds needle, whether the
+// string haystack contains the string needle, or whether the object
+// haystack has the field named by the string needle. It reports false
+// for ok if haystack and needle are of other types.
+func contains(haystack, needle eval.Value) (bool, bool) {
+	var (
+		l       eval.List
+		s, sub  eval.String
+		obj     Object
+		isList  bool
+		isStr   bool
+		isObj   bool
+		present bool
+	)
+
+	l, isList = haystack.(eval.List)
+	if isList {
+		return slices.ContainsFunc(l, func(v eval.Value) bool {
+			return equal(v, needle)
+		}), true
+	}
+
+	sub, isStr = needle.(eval.String)
+	if !isStr {
+		return false, false
+	}
+
+	s, isStr = haystack.(eval.String)
+	if isStr {
+		return strings.Contains(string(s), string(sub)), true
+	}
+
+	obj, isObj = haystack.(Object)
+	if isObj {
+		_, present = obj[string(sub)]
+
+		return present, true
+	}
+
+	return false, false
+}
+
+// order applies the ordering operator op to two numbers or two strings.
+// It reports false for ok if a and b are not.
+func order(op string, a, b eval.Value) (bool, bool) {
+	var (
+		c      int
+		sa, sb eval.String
+		ok     bool
+	)
+
+	c, ok = compareNumbers(a, b)
+	if !ok {
+		sa, ok = a.(eval.String)
+		if ok {
+			sb, ok = b.(eval.String)
+			c = strings.Compare(string(sa), string(sb))
+		}
+	}
+
+	if !ok {
+		return false, false
+	}
+
+	switch op {
+	case "<":
+		return c < 0, true
+	case "<=":
+		return c <= 0, true
+	case ">":
+		return c > 0, true
+	default:
+		return c >= 0, true
+	}
+}
+
+// compareNumbers compares a and b if both are numbers, exactly if both
+// are integers.
+func compareNumbers(a, b eval.Value) (int, bool) {
+	var (
+		ia, ib eval.Int
+		fa, fb float64
+		okA    bool
+		okB    bool
+	)
+
+	ia, okA = a.(eval.Int)
+	ib, okB = b.(eval.Int)
+
+	if okA && okB {
+		return cmp.Compare(ia, ib), true
+	}
+
+	fa, okA = float(a)
+	fb, okB = float(b)
+
+	if !okA || !okB {
+		return 0, false
+	}
+
+	return cmp.Compare(fa, fb), true
+}
+
+// float returns the number v as a float64.
+func float(v eval.Value) (float64, bool) {
+	var (
+		i  eval.Int
+		f  eval.Float
+		ok bool
+	)
+
+	i, ok = v.(eval.Int)
+	if ok {
+		return float64(i), true
+	}
+
+	f, ok = v.(eval.Float)
+
+	return float64(f), ok
+}
@@ -0,0 +1,206 @@
+// Package filter implements a small expression language for the
+// conditions and filters of host applications, such as the rules of a
+// configuration file or a query flag:
+//
+//	env == "prod" and (region in ["eu", "us"] or startsWith(host, "edge-"))
+//	not matches(user.email, '@example\.com$') && retries <= 3
+//
+// An expression combines names, bound by the host, with literals,
+// comparisons, boolean operators, and function calls. Names are looked
+// up in the variables passed to Expr.Eval, and a dotted name such as
+// user.name looks up a field of a map. Literals are decimal numbers,
+// strings in double quotes with the escapes of Go, strings in single
+// quotes without escapes, lists in brackets, and true, false, and nil.
+//
+// The operators are, from the loosest binding:
+//
+//	or  ||
+//	and &&
+//	==  !=  <  <=  >  >=  in
+//	not !  -  (prefix)
+//
+// Comparisons do not chain, so a < b < c is a syntax error. Numbers
+// compare by value whether they are integers or not, and strings
+// compare by bytes; ordering values of other types is an error. The
+// operator in tests whether a list holds a value, or whether a string
+// contains another. The boolean operators evaluate their right operand
+// only when needed, and take any value as a condition, as eval.Truthy
+// does.
+//
+// The functions len, lower, upper, trim, contains, startsWith,
+// endsWith, and matches are predefined; a variable bound to an
+// eval.Callable, such as an *eval.Builtin, adds or replaces one.
+//
+// A Compile reports syntax errors as *parser.SyntaxError values, and
+// evaluation reports errors as *eval.RuntimeError values, both spanning
+// the offending part of the expression, so that a host can point at it
+// in its own source file.
+package filter // import "github.com/andrieee44/langengine/filter"
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+var (
+	// ErrUndefined is wrapped by the *eval.RuntimeError returned by
+	// Expr.Eval for a name that is not bound, or a field that a map does
+	// not have.
+	ErrUndefined = errors.New("filter: undefined name")
+
+	// ErrType is wrapped by the *eval.RuntimeError returned by Expr.Eval
+	// for an operator or function applied to values of the wrong type,
+	// and for a variable of a Go type with no eval.Value counterpart.
+	ErrType = errors.New("filter: invalid type")
+)
+
+// Expr is a compiled expression. A new Expr is constructed with Compile
+// and is safe for concurrent use.
+type Expr struct {
+	root   *parser.Node
+	consts map[*parser.Node]eval.Value
+	rules  map[string]eval.Rule
+}
+
+// Compile compiles the expression src. The options configure the
+// underlying lexer.Reader.
+//
+// Returns a *parser.SyntaxError for the first lexical or syntax error,
+// or for a literal pattern of matches that is not a valid regular
+// expression, and a *literal.Error for a number out of range or a
+// string with an invalid escape.
+func Compile(src string, opts ...lexer.Option) (*Expr, error) {
+	var (
+		expr *Expr
+		ps   *parseState
+		err  error
+	)
+
+	expr = &Expr{consts: make(map[*parser.Node]eval.Value)}
+	ps = &parseState{consts: expr.consts}
+	ps.trd = lexer.NewTokenReader(ps.read(NewLexer(strings.NewReader(src), opts...)))
+
+	expr.root, err = ps.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	expr.rules = map[string]eval.Rule{
+		ruleLiteral: expr.literal,
+		ruleName:    evalName,
+		ruleList:    evalList,
+		ruleCall:    evalCall,
+		ruleUnary:   evalUnary,
+		ruleBinary:  evalBinary,
+	}
+
+	return expr, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be
+// compiled. It simplifies the initialization of global expressions.
+func MustCompile(src string, opts ...lexer.Option) *Expr {
+	var (
+		expr *Expr
+		err  error
+	)
+
+	expr, err = Compile(src, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return expr
+}
+
+// Tree returns the parse tree of the expression, as for dumping it with
+// parser.WriteSExpr. It must not be modified.
+func (expr *Expr) Tree() *parser.Node {
+	return expr.root
+}
+
+// Eval evaluates the expression with vars bound as variables. A
+// variable is an eval.Value, or a Go value converted by Value when it
+// is looked up.
+//
+// Returns an *eval.RuntimeError wrapping ErrUndefined or ErrType, or
+// the error of a function.
+func (expr *Expr) Eval(vars map[string]any) (eval.Value, error) {
+	var (
+		v   eval.Value
+		err error
+	)
+
+	_, v, err = expr.run(vars)
+
+	return v, err
+}
+
+// Match evaluates the expression as a condition with vars bound as
+// variables, as Eval does.
+//
+// Returns an *eval.RuntimeError wrapping ErrType if the expression does
+// not evaluate to a bool, or the error of Eval.
+func (expr *Expr) Match(vars map[string]any) (bool, error) {
+	var (
+		ev  *eval.Evaluator
+		v   eval.Value
+		b   eval.Bool
+		ok  bool
+		err error
+	)
+
+	ev, v, err = expr.run(vars)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok = v.(eval.Bool)
+	if !ok {
+		return false, ev.Errorf(
+			expr.root.Span,
+			"%w: condition is %s, not bool",
+			ErrType,
+			v.Type(),
+		)
+	}
+
+	return bool(b), nil
+}
+
+// run evaluates the expression with a new Evaluator.
+func (expr *Expr) run(vars map[string]any) (*eval.Evaluator, eval.Value, error) {
+	var (
+		ev   *eval.Evaluator
+		env  *eval.Env
+		name string
+		x    any
+		v    eval.Value
+		err  error
+	)
+
+	ev = eval.New(expr.rules)
+
+	for name = range builtins {
+		ev.Globals.Define(name, builtins[name])
+	}
+
+	env = eval.NewEnv(ev.Globals)
+
+	for name, x = range vars {
+		env.Define(name, variable{x})
+	}
+
+	v, err = ev.Eval(expr.root, env)
+
+	return ev, v, err
+}
+
+// literal evaluates a literal to its value, computed by Compile.
+func (expr *Expr) literal(_ *eval.Evaluator, node *parser.Node, _ *eval.Env) (eval.Value, error) {
+	return expr.consts[node], nil
+}
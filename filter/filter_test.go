@@ -0,0 +1,339 @@
+package filter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/filter"
+	"github.com/andrieee44/langengine/lexer/lextest"
+	"github.com/andrieee44/langengine/literal"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ident    = lextest.Kind(filter.KindIdent, "Ident")
+	number   = lextest.Kind(filter.KindNumber, "Number")
+	str      = lextest.Kind(filter.KindString, "String")
+	operator = lextest.Kind(filter.KindOperator, "Operator")
+	boolean  = lextest.Kind(filter.KindBool, "Bool")
+	lbracket = lextest.Kind(filter.KindLeftBracket, "LeftBracket")
+	rbracket = lextest.Kind(filter.KindRightBracket, "RightBracket")
+	comma    = lextest.Kind(filter.KindComma, "Comma")
+)
+
+// vars are the variables of the tests.
+var vars = map[string]any{
+	"env":     "prod",
+	"region":  "eu",
+	"retries": uint8(2),
+	"ratio":   0.5,
+	"tags":    []string{"web", "edge"},
+	"user": map[string]any{
+		"name":  "Ann",
+		"email": "ann@example.com",
+		"admin": false,
+	},
+	"none": nil,
+}
+
+func TestLexer(t *testing.T) {
+	var (
+		testTbl map[string][]lextest.Want
+		src     string
+		wants   []lextest.Want
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]lextest.Want{
+		`user.name in ["a", 'b\'] and !true`: {
+			ident("user.name"),
+			operator("in").At(1, 11),
+			lbracket("["),
+			str(`"a"`),
+			comma(","),
+			str(`'b\'`),
+			rbracket("]"),
+			operator("and"),
+			operator("!"),
+			boolean("true"),
+			lextest.EOF,
+		},
+		"x >= -1.5e3 @ 1.": {
+			ident("x"),
+			operator(">="),
+			operator("-"),
+			number("1.5e3"),
+			lextest.Error(`unexpected "@"`).At(1, 13),
+			lextest.Error("missing digits after decimal point"),
+			lextest.EOF,
+		},
+		"\"a\nb": {
+			lextest.Error("unterminated string literal"),
+			ident("b").At(2, 1),
+			lextest.EOF,
+		},
+	}
+
+	for src, wants = range testTbl {
+		lextest.Expect(t, filter.NewLexer(strings.NewReader(src)), wants...)
+	}
+}
+
+func TestExprEval(t *testing.T) {
+	var (
+		testTbl map[string]eval.Value
+		src     string
+		want    eval.Value
+		got     eval.Value
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]eval.Value{
+		`env == "prod" and region in ["eu", "us"]`: eval.Bool(true),
+		`env != 'prod' || retries > 1`:             eval.Bool(true),
+		`retries == 2.0 and ratio < 1`:             eval.Bool(true),
+		`not user.admin && user.name >= "Al"`:      eval.Bool(true),
+		`"edge" in tags and "dev" in user.email`:   eval.Bool(false),
+		`"email" in user`:                          eval.Bool(true),
+		`none == nil and tags == ["web", "edge"]`:  eval.Bool(true),
+		`-retries`:                                      eval.Int(-2),
+		`len("é") == 1 and len(tags) == 2`:              eval.Bool(true),
+		`lower(user.name)`:                              eval.String("ann"),
+		`upper(trim("  a "))`:                           eval.String("A"),
+		`startsWith(env, "pr") and endsWith(env, "od")`: eval.Bool(true),
+		`matches(user.email, '^\w+@example\.com$')`:     eval.Bool(true),
+		`contains(user.email, "@")`:                     eval.Bool(true),
+		`true or nosuch`:                                eval.Bool(true),
+		`false and 1 < a`:                               eval.Bool(false),
+	}
+
+	for src, want = range testTbl {
+		got, err = filter.MustCompile(src).Eval(vars)
+
+		assert.NoError(t, err, src)
+		assert.Equal(t, want, got, src)
+	}
+}
+
+func TestExprMatch(t *testing.T) {
+	var (
+		expr  *filter.Expr
+		match bool
+		err   error
+	)
+
+	t.Parallel()
+
+	expr = filter.MustCompile(`region in ["eu", "us"] and retries < limit`)
+
+	match, err = expr.Match(map[string]any{"region": "us", "retries": 1, "limit": 3})
+	assert.NoError(t, err)
+	assert.True(t, match)
+
+	match, err = expr.Match(map[string]any{"region": "ap", "retries": 1})
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	_, err = filter.MustCompile("lower(env)").Match(vars)
+	assert.ErrorIs(t, err, filter.ErrType)
+	assert.EqualError(t, err, "1:1: filter: invalid type: condition is string, not bool")
+}
+
+func TestExprCustomFunction(t *testing.T) {
+	var (
+		expr  *filter.Expr
+		match bool
+		err   error
+	)
+
+	t.Parallel()
+
+	expr = filter.MustCompile(`inNet(addr, "10.") and len(addr) > 3`)
+
+	match, err = expr.Match(map[string]any{
+		"addr": "10.1.2.3",
+		"inNet": &eval.Builtin{
+			Name: "inNet",
+			Fn: func(_ *eval.Evaluator, args []eval.Value) (eval.Value, error) {
+				return eval.Bool(strings.HasPrefix(args[0].String(), args[1].String())), nil
+			},
+		},
+		"len": &eval.Builtin{
+			Name: "len",
+			Fn: func(_ *eval.Evaluator, _ []eval.Value) (eval.Value, error) {
+				return nil, errors.New("len is disabled")
+			},
+		},
+	})
+
+	assert.False(t, match)
+	assert.EqualError(t, err, "1:24: len is disabled\n\tin len called at 1:24")
+}
+
+func TestCompileErrors(t *testing.T) {
+	var (
+		testTbl map[string]string
+		src     string
+		msg     string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]string{
+		"":                    "0:0: expected expression, found end of input",
+		"a <":                 "1:4: expected expression, found end of input",
+		"a < b == c":          `1:7: operator "==" is not associative and cannot follow "<"`,
+		"(a or b":             "1:8: expected ), found end of input",
+		"f(a b)":              `1:5: expected , or ), found "b"`,
+		"[1, 2":               "1:6: expected , or ], found end of input",
+		"a b":                 `1:3: expected operator, found "b"`,
+		"a @ b":               `1:3: unexpected "@"`,
+		`matches(a, "a(")`:    "1:12: invalid pattern: missing closing ): `a(`",
+		`a == "unterminated`:  "1:6: unterminated string literal",
+		"x > 1.":              "1:5: missing digits after decimal point",
+		"and":                 `1:1: expected expression, found "and"`,
+		"contains(a, ) or b":  `1:13: expected expression, found ")"`,
+		"len(a) in [1, 2] or": "1:20: expected expression, found end of input",
+	}
+
+	for src, msg = range testTbl {
+		_, err = filter.Compile(src)
+
+		assert.EqualError(t, err, msg, src)
+		assert.IsType(t, &parser.SyntaxError{}, err, src)
+	}
+
+	_, err = filter.Compile(`a == "\q" or b > 99999999999999999999`)
+	assert.ErrorIs(t, err, literal.ErrSyntax)
+	assert.EqualError(t, err, `1:7: invalid escape sequence \q in string literal`)
+
+	_, err = filter.Compile(`b > 99999999999999999999`)
+	assert.ErrorIs(t, err, literal.ErrRange)
+
+	assert.Panics(t, func() {
+		filter.MustCompile("a ==")
+	})
+}
+
+func TestEvalErrors(t *testing.T) {
+	type testData struct {
+		err error
+		msg string
+	}
+
+	var (
+		testTbl map[string]testData
+		src     string
+		test    testData
+		rterr   *eval.RuntimeError
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"nosuch or true": {
+			err: filter.ErrUndefined,
+			msg: "1:1: filter: undefined name: nosuch",
+		},
+		"user.phone == nil": {
+			err: filter.ErrUndefined,
+			msg: "1:1: filter: undefined name: user.phone",
+		},
+		"env.name": {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: field name of string",
+		},
+		`env < 1`: {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: string < int",
+		},
+		`1 in env`: {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: int in string",
+		},
+		`-env`: {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: -string",
+		},
+		`lower(retries)`: {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: lower of int\n\tin lower called at 1:1",
+		},
+		`contains(env)`: {
+			err: eval.ErrArity,
+			msg: "1:1: eval: wrong number of arguments: contains takes 2 arguments, got 1" +
+				"\n\tin contains called at 1:1",
+		},
+		`env()`: {
+			err: eval.ErrNotCallable,
+			msg: "1:1: eval: value is not callable: env of type string",
+		},
+		`bad == 1`: {
+			err: filter.ErrType,
+			msg: "1:1: filter: invalid type: unsupported Go type struct {}",
+		},
+	}
+
+	for src, test = range testTbl {
+		_, err = filter.MustCompile(src).Eval(map[string]any{
+			"env":     "prod",
+			"retries": 2,
+			"user":    map[string]string{"name": "Ann"},
+			"bad":     struct{}{},
+		})
+
+		assert.ErrorIs(t, err, test.err, src)
+		assert.EqualError(t, err, test.msg, src)
+		assert.ErrorAs(t, err, &rterr, src)
+	}
+}
+
+func TestValue(t *testing.T) {
+	var (
+		v   eval.Value
+		err error
+	)
+
+	t.Parallel()
+
+	v, err = filter.Value(map[string]any{
+		"n":    int16(-3),
+		"f":    float32(0.5),
+		"list": [2]bool{true, false},
+		"nil":  []int(nil),
+		"v":    eval.String("x"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, filter.Object{
+		"n":    eval.Int(-3),
+		"f":    eval.Float(0.5),
+		"list": eval.List{eval.Bool(true), eval.Bool(false)},
+		"nil":  eval.Nil{},
+		"v":    eval.String("x"),
+	}, v)
+	assert.Equal(t, `{f: 0.5, list: [true, false], n: -3, nil: nil, v: x}`, v.String())
+
+	_, err = filter.Value(uint64(1) << 63)
+	assert.EqualError(t, err, "filter: invalid type: 9223372036854775808 overflows int")
+
+	_, err = filter.Value(map[int]string{})
+	assert.ErrorIs(t, err, filter.ErrType)
+}
+
+func TestExprTree(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		`(Binary 1:1-1:23 (Unary 1:1-1:6 (Name 1:5-1:6 "a")) (Call 1:10-1:23 (Name 1:14-1:15 "b") (List 1:17-1:22 (Literal 1:18-1:19 "1") (Literal 1:20-1:21 "2"))))`,
+		filter.MustCompile("not a or len(b, [1,2])").Tree().String(),
+	)
+}
@@ -0,0 +1,177 @@
+package filter
+
+import (
+	"io"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindIdent is the kind of a name, such as user.name, whose parts
+	// are separated by dots.
+	KindIdent lexer.Kind = lexer.KindUser + iota
+
+	// KindNumber is the kind of a decimal number literal, with an
+	// optional fraction and exponent.
+	KindNumber
+
+	// KindString is the kind of a string literal, including its quotes:
+	// in double quotes with the escapes of Go, or in single quotes
+	// without escapes, which suits regular expressions.
+	KindString
+
+	// KindOperator is the kind of an operator, such as ">=" or the
+	// keyword and.
+	KindOperator
+
+	// KindBool is the kind of the true and false keywords.
+	KindBool
+
+	// KindNil is the kind of the nil keyword.
+	KindNil
+
+	// KindLeftParen is the kind of the '(' token.
+	KindLeftParen
+
+	// KindRightParen is the kind of the ')' token.
+	KindRightParen
+
+	// KindLeftBracket is the kind of the '[' token.
+	KindLeftBracket
+
+	// KindRightBracket is the kind of the ']' token.
+	KindRightBracket
+
+	// KindComma is the kind of the ',' token.
+	KindComma
+)
+
+var (
+	keywords = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"and":   KindOperator,
+		"or":    KindOperator,
+		"not":   KindOperator,
+		"in":    KindOperator,
+		"true":  KindBool,
+		"false": KindBool,
+		"nil":   KindNil,
+	})
+
+	operators = lexer.NewOperatorTable([]string{
+		"==", "!=", "<", "<=", ">", ">=", "!", "&&", "||", "-",
+	})
+)
+
+// NewLexer returns a lexer.Lexer that splits rd into filter tokens.
+// Malformed literals and runes that cannot start a token are reported
+// with lexer.KindError tokens. The options configure the underlying
+// lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexToken, opts...)
+}
+
+// lexToken lexes the token starting at the current position.
+func lexToken(lx *lexer.Lexer) lexer.StateFn {
+	var ok bool
+
+	lx.AcceptRunFunc(unicode.IsSpace)
+	lx.Ignore()
+
+	switch {
+	case lx.AtEOF():
+		return nil
+	case lx.Accept("("):
+		lx.Emit(KindLeftParen)
+	case lx.Accept(")"):
+		lx.Emit(KindRightParen)
+	case lx.Accept("["):
+		lx.Emit(KindLeftBracket)
+	case lx.Accept("]"):
+		lx.Emit(KindRightBracket)
+	case lx.Accept(","):
+		lx.Emit(KindComma)
+	case lx.Peek() == '"', lx.Peek() == '\'':
+		return lexString
+	case lx.AcceptFunc(isDigit):
+		return lexNumber
+	case lx.AcceptIdentifier(isIdentStart, isIdentPart):
+		lx.EmitIdentifier(KindIdent, keywords)
+	default:
+		_, ok = lx.AcceptOperator(operators)
+		if ok {
+			lx.Emit(KindOperator)
+
+			return lexToken
+		}
+
+		lx.Next()
+		lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexToken
+}
+
+// lexNumber lexes the rest of a number literal after its first digit.
+func lexNumber(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(isDigit)
+
+	if lx.Accept(".") && lx.AcceptRunFunc(isDigit) == 0 {
+		lx.Errorf("missing digits after decimal point")
+
+		return lexToken
+	}
+
+	if lx.Accept("eE") {
+		lx.Accept("+-")
+
+		if lx.AcceptRunFunc(isDigit) == 0 {
+			lx.Errorf("missing digits in exponent")
+
+			return lexToken
+		}
+	}
+
+	lx.Emit(KindNumber)
+
+	return lexToken
+}
+
+// lexString lexes a string literal, leaving its escapes to the parser.
+// An unterminated literal is reported up to the end of its line.
+func lexString(lx *lexer.Lexer) lexer.StateFn {
+	var stop string
+
+	stop = "\"\\\n"
+	if lx.Next() == '\'' {
+		stop = "'\n"
+	}
+
+	for {
+		lx.Until(stop)
+
+		switch {
+		case lx.Accept(stop[:1]):
+			lx.Emit(KindString)
+
+			return lexToken
+		case lx.Accept(`\`) && lx.Next() != lexer.EOF:
+		default:
+			lx.Errorf("unterminated string literal")
+
+			return lexToken
+		}
+	}
+}
+
+func isDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+func isIdentStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isIdentPart(char rune) bool {
+	return isIdentStart(char) || char == '.' || unicode.IsDigit(char)
+}
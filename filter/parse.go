@@ -0,0 +1,308 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andrieee44/langengine/eval"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/literal"
+	"github.com/andrieee44/langengine/parser"
+)
+
+// The rules of the nodes of a compiled expression. Operators, names,
+// and literals hold their text as Text; a call holds the name of the
+// function.
+const (
+	ruleBinary  = "Binary"
+	ruleUnary   = "Unary"
+	ruleName    = "Name"
+	ruleCall    = "Call"
+	ruleList    = "List"
+	ruleLiteral = "Literal"
+)
+
+// precedence declares the operators, from the loosest binding.
+var precedence = parser.MustPrecedenceTable(
+	parser.Operator{Text: "or", Level: 1},
+	parser.Operator{Text: "||", Level: 1},
+	parser.Operator{Text: "and", Level: 2},
+	parser.Operator{Text: "&&", Level: 2},
+	parser.Operator{Text: "==", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: "!=", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: "<", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: "<=", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: ">", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: ">=", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: "in", Level: 3, Assoc: parser.AssocNone},
+	parser.Operator{Text: "not", Fixity: parser.Prefix, Level: 4},
+	parser.Operator{Text: "!", Fixity: parser.Prefix, Level: 4},
+	parser.Operator{Text: "-", Fixity: parser.Prefix, Level: 4},
+)
+
+// parseState holds the state of a Compile.
+type parseState struct {
+	trd    *lexer.TokenReader
+	pratt  *parser.Pratt[*parser.Node]
+	consts map[*parser.Node]eval.Value
+	end    lexer.Position
+}
+
+// parse parses a complete expression.
+func (ps *parseState) parse() (*parser.Node, error) {
+	var (
+		node *parser.Node
+		tok  lexer.Token
+		ok   bool
+		err  error
+	)
+
+	ps.pratt = &parser.Pratt[*parser.Node]{
+		Table:   precedence,
+		Kinds:   lexer.NewKindSet(KindOperator),
+		Operand: ps.operand,
+		Prefix: func(op lexer.Token, operand *parser.Node) *parser.Node {
+			return &parser.Node{
+				Rule:     ruleUnary,
+				Text:     op.Text,
+				Span:     lexer.Span{Start: op.Span.Start, End: operand.Span.End},
+				Children: []*parser.Node{operand},
+			}
+		},
+		Infix: func(op lexer.Token, left, right *parser.Node) *parser.Node {
+			return &parser.Node{
+				Rule:     ruleBinary,
+				Text:     op.Text,
+				Span:     lexer.Span{Start: left.Span.Start, End: right.Span.End},
+				Children: []*parser.Node{left, right},
+			}
+		},
+	}
+
+	node, err = ps.pratt.Parse(ps.trd)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, ok = ps.trd.NextToken()
+	if ok {
+		return nil, unexpected(tok, "operator")
+	}
+
+	return node, nil
+}
+
+// operand parses a literal, a name, a call, a list, or a parenthesized
+// expression.
+func (ps *parseState) operand(trd *lexer.TokenReader) (*parser.Node, error) {
+	var (
+		tok  lexer.Token
+		node *parser.Node
+		err  error
+	)
+
+	tok = ps.next()
+	node = &parser.Node{Text: tok.Text, Span: tok.Span}
+
+	switch tok.Kind {
+	case KindNumber, KindString, KindBool, KindNil:
+		node.Rule = ruleLiteral
+		ps.consts[node], err = constant(tok)
+		if err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	case KindIdent:
+		node.Rule = ruleName
+
+		tok, _ = trd.Peek()
+		if tok.Kind != KindLeftParen {
+			return node, nil
+		}
+
+		trd.NextToken()
+		node.Rule = ruleCall
+
+		return ps.elements(node, KindRightParen, ")")
+	case KindLeftBracket:
+		node.Rule = ruleList
+		node.Text = ""
+
+		return ps.elements(node, KindRightBracket, "]")
+	case KindLeftParen:
+		node, err = ps.pratt.Parse(trd)
+		if err != nil {
+			return nil, err
+		}
+
+		tok = ps.next()
+		if tok.Kind != KindRightParen {
+			return nil, unexpected(tok, ")")
+		}
+
+		return node, nil
+	default:
+		return nil, unexpected(tok, "expression")
+	}
+}
+
+// elements parses the comma-separated expressions up to the closing
+// token of kind end and text endText, as the arguments of a call or the
+// elements of a list, appending them to the children of node.
+func (ps *parseState) elements(
+	node *parser.Node,
+	end lexer.Kind,
+	endText string,
+) (*parser.Node, error) {
+	var (
+		tok  lexer.Token
+		elem *parser.Node
+		err  error
+	)
+
+	tok, _ = ps.trd.Peek()
+	if tok.Kind == end {
+		tok, _ = ps.trd.NextToken()
+		node.Span.End = tok.Span.End
+
+		return node, nil
+	}
+
+	for {
+		elem, err = ps.pratt.Parse(ps.trd)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Children = append(node.Children, elem)
+
+		tok = ps.next()
+		switch tok.Kind {
+		case KindComma:
+		case end:
+			node.Span.End = tok.Span.End
+
+			return ps.check(node)
+		default:
+			return nil, unexpected(tok, ", or "+endText)
+		}
+	}
+}
+
+// check validates the literal patterns of calls to matches, so that a
+// malformed regular expression is reported before evaluation.
+func (ps *parseState) check(node *parser.Node) (*parser.Node, error) {
+	var (
+		pattern eval.String
+		ok      bool
+		err     error
+	)
+
+	if node.Rule != ruleCall || node.Text != "matches" || len(node.Children) != 2 {
+		return node, nil
+	}
+
+	pattern, ok = ps.consts[node.Children[1]].(eval.String)
+	if !ok {
+		return node, nil
+	}
+
+	_, err = regexp.Compile(string(pattern))
+	if err != nil {
+		return nil, syntaxError(
+			node.Children[1].Span,
+			"invalid pattern: %s",
+			strings.TrimPrefix(err.Error(), "error parsing regexp: "),
+		)
+	}
+
+	return node, nil
+}
+
+// read returns the next token of stream, recording its end.
+func (ps *parseState) read(stream lexer.TokenStream) lexer.StreamFunc {
+	return func() (lexer.Token, bool) {
+		var (
+			tok lexer.Token
+			ok  bool
+		)
+
+		tok, ok = stream.NextToken()
+		if ok {
+			ps.end = tok.Span.End
+		}
+
+		return tok, ok
+	}
+}
+
+// next returns the next token, or an empty KindEOF token at the end of
+// the last token if the input ends.
+func (ps *parseState) next() lexer.Token {
+	var (
+		tok lexer.Token
+		ok  bool
+	)
+
+	tok, ok = ps.trd.NextToken()
+	if !ok {
+		return lexer.Token{
+			Kind: lexer.KindEOF,
+			Span: lexer.Span{Start: ps.end, End: ps.end},
+		}
+	}
+
+	return tok
+}
+
+// constant returns the value of the literal tok.
+func constant(tok lexer.Token) (eval.Value, error) {
+	var (
+		i   int64
+		f   float64
+		s   string
+		err error
+	)
+
+	switch {
+	case tok.Kind == KindBool:
+		return eval.Bool(tok.Text == "true"), nil
+	case tok.Kind == KindNil:
+		return eval.Nil{}, nil
+	case tok.Kind == KindString && tok.Text[0] == '\'':
+		return eval.String(tok.Text[1 : len(tok.Text)-1]), nil
+	case tok.Kind == KindString:
+		s, err = literal.String(tok)
+
+		return eval.String(s), err
+	case strings.ContainsAny(tok.Text, ".eE"):
+		f, err = literal.Float(tok, 64)
+
+		return eval.Float(f), err
+	default:
+		i, err = literal.Int(tok, 64)
+
+		return eval.Int(i), err
+	}
+}
+
+// unexpected returns the error for tok where want was expected.
+func unexpected(tok lexer.Token, want string) error {
+	switch tok.Kind {
+	case lexer.KindError:
+		return syntaxError(tok.Span, "%s", tok.Text)
+	case lexer.KindEOF:
+		return syntaxError(tok.Span, "expected %s, found end of input", want)
+	default:
+		return syntaxError(tok.Span, "expected %s, found %q", want, tok.Text)
+	}
+}
+
+func syntaxError(span lexer.Span, format string, args ...any) *parser.SyntaxError {
+	return &parser.SyntaxError{Diagnostic: lexer.Diagnostic{
+		Span:    span,
+		Message: fmt.Sprintf(format, args...),
+	}}
+}
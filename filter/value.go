@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/andrieee44/langengine/eval"
+)
+
+// Object is a value with named fields, converted from a map of the
+// host.
+type Object map[string]eval.Value
+
+// variable is a variable bound by the host, converted by Value when it
+// is looked up, so that variables the expression does not use are never
+// converted.
+type variable struct {
+	x any
+}
+
+// Value converts the Go value x to an eval.Value: an eval.Value is
+// returned as is, nil becomes eval.Nil, booleans, integers,
+// floating-point numbers, and strings become eval.Bool, eval.Int,
+// eval.Float, and eval.String, slices and arrays become eval.List, and
+// maps keyed by strings become Object values, converting their elements
+// likewise.
+//
+// Returns an error wrapping ErrType for a value of another type, or an
+// unsigned integer beyond the range of eval.Int.
+func Value(x any) (eval.Value, error) {
+	var (
+		v  eval.Value
+		ok bool
+		rv reflect.Value
+		u  uint64
+	)
+
+	v, ok = x.(eval.Value)
+	if ok {
+		return v, nil
+	}
+
+	if x == nil {
+		return eval.Nil{}, nil
+	}
+
+	rv = reflect.ValueOf(x)
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return eval.Bool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return eval.Int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u = rv.Uint()
+		if u > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: %d overflows int", ErrType, u)
+		}
+
+		return eval.Int(u), nil
+	case reflect.Float32, reflect.Float64:
+		return eval.Float(rv.Float()), nil
+	case reflect.String:
+		return eval.String(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		return list(rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			return object(rv)
+		}
+	default:
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return eval.Nil{}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unsupported Go type %T", ErrType, x)
+}
+
+// list converts the slice or array rv to an eval.List.
+func list(rv reflect.Value) (eval.Value, error) {
+	var (
+		l   eval.List
+		i   int
+		err error
+	)
+
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return eval.Nil{}, nil
+	}
+
+	l = make(eval.List, rv.Len())
+
+	for i = range l {
+		l[i], err = Value(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// object converts the map rv, keyed by strings, to an Object.
+func object(rv reflect.Value) (eval.Value, error) {
+	var (
+		obj  Object
+		iter *reflect.MapIter
+		err  error
+	)
+
+	if rv.IsNil() {
+		return eval.Nil{}, nil
+	}
+
+	obj = make(Object, rv.Len())
+
+	for iter = rv.MapRange(); iter.Next(); {
+		obj[iter.Key().String()], err = Value(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+// Type returns "map".
+func (Object) Type() string {
+	return "map"
+}
+
+// String returns the fields of obj sorted by name, as in {a: 1, b: x}.
+func (obj Object) String() string {
+	var (
+		parts []string
+		name  string
+	)
+
+	for _, name = range slices.Sorted(maps.Keys(obj)) {
+		parts = append(parts, name+": "+obj[name].String())
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// Type returns "variable".
+func (variable) Type() string {
+	return "variable"
+}
+
+// String formats the unconverted value.
+func (v variable) String() string {
+	return fmt.Sprint(v.x)
+}
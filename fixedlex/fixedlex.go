@@ -0,0 +1,177 @@
+// Package fixedlex implements a lexer for fixed-width record formats on
+// top of the lexer package, such as the data feeds of mainframe batch
+// jobs, where every line is a record and every field occupies the same
+// columns on every line.
+//
+// Every field is reported as a lexer.Token carrying its exact span, so
+// a misaligned record can be diagnosed down to the offending column,
+// and the padding trimmed off fields is kept as trivia, so the source
+// can still be reconstructed from the tokens.
+package fixedlex // import "github.com/andrieee44/langengine/fixedlex"
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindField is the kind of a single field. Its text is the field
+	// without the padding trimmed off according to Layout.Trim.
+	KindField lexer.Kind = lexer.KindUser + iota
+
+	// KindPad is the kind of the trivia tokens holding the padding
+	// trimmed off a field.
+	KindPad
+
+	// KindRecord is the kind of the token that terminates a record. Its
+	// text is the line terminator ("\n" or "\r\n"), or empty for a final
+	// record that is not followed by a line terminator.
+	KindRecord
+)
+
+// Trim selects which padding Layout trims off the fields.
+type Trim uint8
+
+const (
+	// TrimLeft trims the padding preceding a field, as found in
+	// right-aligned numeric fields.
+	TrimLeft Trim = 1 << iota
+
+	// TrimRight trims the padding following a field, as found in
+	// left-aligned text fields.
+	TrimRight
+
+	// TrimBoth trims the padding on both sides of a field.
+	TrimBoth = TrimLeft | TrimRight
+)
+
+// Layout describes the columns of a fixed-width record format.
+type Layout struct {
+	// Widths lists the width of every field in runes, from the first
+	// column to the last. A width of zero or less in the last position
+	// makes the last field extend to the end of the line.
+	Widths []int
+
+	// Trim selects the padding trimmed off every field.
+	Trim Trim
+
+	// Pad is the padding rune. A zero Pad selects the space.
+	Pad rune
+}
+
+// NewLexer returns a lexer.Lexer that splits rd into KindField, KindPad,
+// and KindRecord tokens according to layout. Every record yields one
+// KindField token per column, empty for the columns a short line does
+// not reach. A line that is longer than the columns of layout stops the
+// Lexer with a lexer.KindError token spanning the excess text. The
+// options configure the underlying lexer.Reader.
+func NewLexer(
+	rd io.Reader,
+	layout Layout,
+	opts ...lexer.Option,
+) *lexer.Lexer {
+	return lexer.NewLexer(rd, layout.lexRecord, opts...)
+}
+
+func (layout Layout) lexRecord(lx *lexer.Lexer) lexer.StateFn {
+	if lx.AtEOF() {
+		return nil
+	}
+
+	return layout.lexField(0)
+}
+
+func (layout Layout) lexField(idx int) lexer.StateFn {
+	return func(lx *lexer.Lexer) lexer.StateFn {
+		var (
+			width   int
+			count   int
+			padding string
+		)
+
+		if idx == len(layout.Widths) {
+			return layout.lexEnd
+		}
+
+		width = layout.Widths[idx]
+		if width <= 0 && idx == len(layout.Widths)-1 {
+			width = -1
+		}
+
+		if layout.Trim&TrimLeft != 0 {
+			for count != width && lx.Peek() == layout.pad() {
+				lx.Next()
+				count++
+			}
+
+			if count != 0 {
+				lx.EmitTrivia(KindPad)
+			}
+		}
+
+		for count != width && !atEOL(lx) {
+			lx.Next()
+			count++
+		}
+
+		if lx.Last() == '\r' && lx.Peek() == '\n' && lx.PeekToken() != "" {
+			lx.Backup(1)
+		}
+
+		if layout.Trim&TrimRight != 0 {
+			padding = lx.PeekToken()
+			padding = padding[len(strings.TrimRight(padding, string(layout.pad()))):]
+			lx.Backup(utf8.RuneCountInString(padding))
+		}
+
+		lx.Emit(KindField)
+
+		if padding != "" {
+			lx.AcceptSeq(padding)
+			lx.EmitTrivia(KindPad)
+		}
+
+		return layout.lexField(idx + 1)
+	}
+}
+
+func (layout Layout) lexEnd(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.Accept("\n"), lx.AcceptSeq("\r\n"):
+		lx.Emit(KindRecord)
+	case lx.AtEOF():
+		lx.Emit(KindRecord)
+
+		return nil
+	default:
+		lx.UntilSeq("\n")
+
+		if lx.Last() == '\r' && lx.Peek() == '\n' {
+			lx.Backup(1)
+		}
+
+		return lx.Errorf(
+			"record has more than %d fields",
+			len(layout.Widths),
+		)
+	}
+
+	return layout.lexRecord
+}
+
+func (layout Layout) pad() rune {
+	if layout.Pad == 0 {
+		return ' '
+	}
+
+	return layout.Pad
+}
+
+// atEOL reports whether lx is at the end of a line or of the input. A
+// NUL byte is field data like any other rune.
+func atEOL(lx *lexer.Lexer) bool {
+	return lx.AtEOF() || lx.Peek() == '\n'
+}
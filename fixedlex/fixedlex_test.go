@@ -0,0 +1,167 @@
+package fixedlex_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/fixedlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+type kindText struct {
+	kind lexer.Kind
+	text string
+}
+
+func TestNewLexer(t *testing.T) {
+	type testData struct {
+		content string
+		layout  fixedlex.Layout
+		tokens  []kindText
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Base": {
+			content: "ab123\r\ncd456\n",
+			layout:  fixedlex.Layout{Widths: []int{2, 3}},
+			tokens: []kindText{
+				{fixedlex.KindField, "ab"},
+				{fixedlex.KindField, "123"},
+				{fixedlex.KindRecord, "\r\n"},
+				{fixedlex.KindField, "cd"},
+				{fixedlex.KindField, "456"},
+				{fixedlex.KindRecord, "\n"},
+			},
+		},
+		"TrimBoth": {
+			content: "a   42",
+			layout: fixedlex.Layout{
+				Widths: []int{4, 2},
+				Trim:   fixedlex.TrimBoth,
+			},
+			tokens: []kindText{
+				{fixedlex.KindField, "a"},
+				{fixedlex.KindPad, "   "},
+				{fixedlex.KindField, "42"},
+				{fixedlex.KindRecord, ""},
+			},
+		},
+		"Pad": {
+			content: "0042x\n",
+			layout: fixedlex.Layout{
+				Widths: []int{4, 1},
+				Trim:   fixedlex.TrimLeft,
+				Pad:    '0',
+			},
+			tokens: []kindText{
+				{fixedlex.KindPad, "00"},
+				{fixedlex.KindField, "42"},
+				{fixedlex.KindField, "x"},
+				{fixedlex.KindRecord, "\n"},
+			},
+		},
+		"ShortLine": {
+			content: "héllo\r\n",
+			layout:  fixedlex.Layout{Widths: []int{3, 3, 3}},
+			tokens: []kindText{
+				{fixedlex.KindField, "hél"},
+				{fixedlex.KindField, "lo"},
+				{fixedlex.KindField, ""},
+				{fixedlex.KindRecord, "\r\n"},
+			},
+		},
+		"Rest": {
+			content: "ab rest of line\n",
+			layout:  fixedlex.Layout{Widths: []int{2, 0}},
+			tokens: []kindText{
+				{fixedlex.KindField, "ab"},
+				{fixedlex.KindField, " rest of line"},
+				{fixedlex.KindRecord, "\n"},
+			},
+		},
+		"NUL": {
+			content: "a\x00\x00b\n\x00c\n",
+			layout:  fixedlex.Layout{Widths: []int{2, 2}},
+			tokens: []kindText{
+				{fixedlex.KindField, "a\x00"},
+				{fixedlex.KindField, "\x00b"},
+				{fixedlex.KindRecord, "\n"},
+				{fixedlex.KindField, "\x00c"},
+				{fixedlex.KindField, ""},
+				{fixedlex.KindRecord, "\n"},
+			},
+		},
+		"LongLine": {
+			content: "abcd\r\n",
+			layout:  fixedlex.Layout{Widths: []int{1, 1}},
+			tokens: []kindText{
+				{fixedlex.KindField, "a"},
+				{fixedlex.KindField, "b"},
+				{lexer.KindError, "record has more than 2 fields"},
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				tokens []kindText
+				tok    lexer.Token
+			)
+
+			t.Parallel()
+
+			for tok = range lexer.Tokens(fixedlex.NewLexer(
+				strings.NewReader(test.content),
+				test.layout,
+			)) {
+				tokens = append(tokens, kindText{tok.Kind, tok.Text})
+			}
+
+			assert.Equal(t, test.tokens, tokens)
+		})
+	}
+}
+
+func TestNewLexerRoundTrip(t *testing.T) {
+	var (
+		src    string
+		tokens []lexer.Token
+	)
+
+	t.Parallel()
+
+	src = "  a  b\n 12 3\r\nx\n"
+	tokens = slices.Collect(lexer.Tokens(fixedlex.NewLexer(
+		strings.NewReader(src),
+		fixedlex.Layout{
+			Widths: []int{3, 3},
+			Trim:   fixedlex.TrimBoth,
+		},
+	)))
+
+	assert.NoError(t, lexer.VerifyRoundTrip(
+		[]byte(src),
+		lexer.StreamFunc(func() (lexer.Token, bool) {
+			var tok lexer.Token
+
+			if len(tokens) == 0 {
+				return lexer.Token{}, false
+			}
+
+			tok, tokens = tokens[0], tokens[1:]
+
+			return tok, true
+		}),
+	))
+}
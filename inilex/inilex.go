@@ -0,0 +1,191 @@
+// Package inilex provides helpers for lexing INI and TOML-style
+// configuration files on top of a lexer.Reader. Each helper recognises
+// one recurring shape, such as a section header, a bare or quoted key,
+// a comment, or a value continued across lines with a trailing
+// backslash, and consumes it into the current token of the Reader.
+package inilex // import "github.com/andrieee44/langengine/inilex"
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// ErrUnterminated is returned by the helpers for delimited constructs
+// when the opening delimiter was consumed but the line or the input
+// ended before the closing delimiter was found.
+var ErrUnterminated = errors.New("inilex: unterminated construct")
+
+// AcceptSection consumes a section header such as "[server]", a dotted
+// header such as "[server.tls]", or a TOML array-of-tables header such
+// as "[[servers]]", through its closing brackets. Whitespace inside the
+// brackets is preserved in the token.
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// next rune is not '['. Returns true with ErrUnterminated if the line
+// or the input ended before the closing brackets.
+func AcceptSection(lrd *lexer.Reader) (bool, error) {
+	var closer string
+
+	if !lrd.Accept("[") {
+		return false, nil
+	}
+
+	closer = "]"
+	if lrd.Accept("[") {
+		closer = "]]"
+	}
+
+	lrd.Until("]\r\n")
+
+	if !lrd.AcceptSeq(closer) {
+		return true, ErrUnterminated
+	}
+
+	return true, nil
+}
+
+// AcceptBareKey consumes a bare key made of ASCII letters, ASCII
+// digits, '_' and '-', as permitted by TOML.
+//
+// Returns true if at least one rune was consumed.
+func AcceptBareKey(lrd *lexer.Reader) bool {
+	return lrd.AcceptRunFunc(isBareKeyChar) != 0
+}
+
+// AcceptQuotedKey consumes a quoted key along with its quotes. Keys in
+// double quotes may contain backslash escapes, including escaped double
+// quotes; keys in single quotes are literal and end at the next single
+// quote.
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// next rune is not a quote. Returns true with ErrUnterminated if the
+// line or the input ended before the closing quote.
+func AcceptQuotedKey(lrd *lexer.Reader) (bool, error) {
+	var char rune
+
+	switch {
+	case lrd.Accept("'"):
+		lrd.Until("'\r\n")
+
+		if !lrd.Accept("'") {
+			return true, ErrUnterminated
+		}
+
+		return true, nil
+	case !lrd.Accept(`"`):
+		return false, nil
+	}
+
+	for {
+		lrd.Until("\"\\\r\n")
+
+		char = lrd.Next()
+
+		switch char {
+		case '"':
+			return true, nil
+		case '\\':
+			if lrd.AcceptFunc(isNotNewline) {
+				continue
+			}
+
+			return true, ErrUnterminated
+		case '\r', '\n':
+			lrd.Backup(1)
+
+			return true, ErrUnterminated
+		default:
+			return true, ErrUnterminated
+		}
+	}
+}
+
+// AcceptComment consumes a comment introduced by any rune in prefixes,
+// such as ";#", up to but excluding the line terminator.
+//
+// Returns true if the next rune was a comment prefix.
+func AcceptComment(lrd *lexer.Reader, prefixes string) bool {
+	if !lrd.Accept(prefixes) {
+		return false
+	}
+
+	lrd.UntilFunc(isNewline)
+
+	return true
+}
+
+// AcceptValue consumes the rest of a value up to but excluding the
+// line terminator. A backslash immediately before the line terminator
+// continues the value onto the next line, in which case the backslash
+// and the terminator become part of the token; use JoinContinuations
+// to obtain the logical value.
+//
+// Returns the number of physical lines the value spans, or 0 if the
+// value is empty.
+func AcceptValue(lrd *lexer.Reader) int {
+	var lines int
+
+	for {
+		if lrd.UntilFunc(isNewline) == 0 && lines == 0 {
+			return 0
+		}
+
+		lines++
+
+		if !strings.HasSuffix(lrd.PeekToken(), `\`) ||
+			!lrd.AcceptSeq("\r\n") && !lrd.Accept("\n") {
+			return lines
+		}
+	}
+}
+
+// JoinContinuations returns the logical value of text consumed by
+// AcceptValue by removing every backslash that immediately precedes a
+// line terminator, together with the terminator and the leading
+// whitespace of the following line.
+func JoinContinuations(text string) string {
+	var (
+		sb      strings.Builder
+		line    string
+		trimmed string
+		more    bool
+	)
+
+	for {
+		line, text, more = strings.Cut(text, "\n")
+		if !more {
+			sb.WriteString(line)
+
+			return sb.String()
+		}
+
+		trimmed = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(trimmed, `\`) {
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+
+			continue
+		}
+
+		sb.WriteString(strings.TrimSuffix(trimmed, `\`))
+		text = strings.TrimLeft(text, " \t")
+	}
+}
+
+func isBareKeyChar(char rune) bool {
+	return 'a' <= char && char <= 'z' ||
+		'A' <= char && char <= 'Z' ||
+		'0' <= char && char <= '9' ||
+		char == '_' ||
+		char == '-'
+}
+
+func isNewline(char rune) bool {
+	return char == '\n' || char == '\r'
+}
+
+func isNotNewline(char rune) bool {
+	return !isNewline(char)
+}
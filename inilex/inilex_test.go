@@ -0,0 +1,207 @@
+package inilex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/inilex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+type testData[T comparable] struct {
+	content string
+	afterOp string
+	result  T
+}
+
+type result struct {
+	matched bool
+	err     error
+}
+
+func assertTestDataTbl[T comparable](
+	t *testing.T,
+	op func(*lexer.Reader) T,
+	testTbl map[string]testData[T],
+) {
+	var (
+		name string
+		test testData[T]
+	)
+
+	t.Helper()
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var lrd *lexer.Reader
+
+			lrd = lexer.NewReader(strings.NewReader(test.content))
+
+			assert.Equal(t, test.result, op(lrd))
+			assert.Equal(t, test.afterOp, lrd.PeekToken())
+		})
+	}
+}
+
+func withErr(
+	fn func(*lexer.Reader) (bool, error),
+) func(*lexer.Reader) result {
+	return func(lrd *lexer.Reader) result {
+		var res result
+
+		res.matched, res.err = fn(lrd)
+
+		return res
+	}
+}
+
+func TestAcceptSection(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, withErr(inilex.AcceptSection), map[string]testData[result]{
+		"Base": {
+			content: "[server]\nport",
+			afterOp: "[server]",
+			result:  result{matched: true},
+		},
+		"Dotted": {
+			content: "[ server.tls ]",
+			afterOp: "[ server.tls ]",
+			result:  result{matched: true},
+		},
+		"ArrayOfTables": {
+			content: "[[servers]]\n",
+			afterOp: "[[servers]]",
+			result:  result{matched: true},
+		},
+		"Unterminated": {
+			content: "[server\nport",
+			afterOp: "[server",
+			result:  result{true, inilex.ErrUnterminated},
+		},
+		"UnterminatedArray": {
+			content: "[[servers]",
+			afterOp: "[[servers",
+			result:  result{true, inilex.ErrUnterminated},
+		},
+		"NoMatch": {
+			content: "server",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptBareKey(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, inilex.AcceptBareKey, map[string]testData[bool]{
+		"Base": {
+			content: "max_conn-2 = 1",
+			afterOp: "max_conn-2",
+			result:  true,
+		},
+		"NoMatch": {
+			content: "= 1",
+			afterOp: "",
+			result:  false,
+		},
+	})
+}
+
+func TestAcceptQuotedKey(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, withErr(inilex.AcceptQuotedKey), map[string]testData[result]{
+		"Double": {
+			content: `"a \"b\" c" = 1`,
+			afterOp: `"a \"b\" c"`,
+			result:  result{matched: true},
+		},
+		"Single": {
+			content: `'a\b' = 1`,
+			afterOp: `'a\b'`,
+			result:  result{matched: true},
+		},
+		"UnterminatedDouble": {
+			content: "\"ab\n\"",
+			afterOp: `"ab`,
+			result:  result{true, inilex.ErrUnterminated},
+		},
+		"UnterminatedEscape": {
+			content: `"ab\`,
+			afterOp: `"ab\`,
+			result:  result{true, inilex.ErrUnterminated},
+		},
+		"UnterminatedSingle": {
+			content: "'ab",
+			afterOp: "'ab",
+			result:  result{true, inilex.ErrUnterminated},
+		},
+		"NoMatch": {
+			content: "ab",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptComment(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, func(lrd *lexer.Reader) bool {
+		return inilex.AcceptComment(lrd, ";#")
+	}, map[string]testData[bool]{
+		"Semicolon": {
+			content: "; note\r\nkey",
+			afterOp: "; note",
+			result:  true,
+		},
+		"Hash": {
+			content: "# note",
+			afterOp: "# note",
+			result:  true,
+		},
+		"NoMatch": {
+			content: "// note",
+			afterOp: "",
+			result:  false,
+		},
+	})
+}
+
+func TestAcceptValue(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, inilex.AcceptValue, map[string]testData[int]{
+		"Base": {
+			content: "a b c\nkey",
+			afterOp: "a b c",
+			result:  1,
+		},
+		"Continued": {
+			content: "a \\\n  b \\\r\n  c\nkey",
+			afterOp: "a \\\n  b \\\r\n  c",
+			result:  3,
+		},
+		"ContinuedEOF": {
+			content: "a \\",
+			afterOp: "a \\",
+			result:  1,
+		},
+		"Empty": {
+			content: "\nkey",
+			afterOp: "",
+			result:  0,
+		},
+	})
+}
+
+func TestJoinContinuations(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a b c", inilex.JoinContinuations("a \\\n  b \\\r\n  c"))
+	assert.Equal(t, "a\r\nb", inilex.JoinContinuations("a\r\nb"))
+	assert.Equal(t, "a", inilex.JoinContinuations("a\\\n"))
+}
@@ -0,0 +1,324 @@
+// Package jsonlex implements a lexer for JSON text as specified by
+// RFC 8259. It serves both as a ready-to-use component and as the
+// canonical example of building a grammar from lexer.StateFn states.
+//
+// Insignificant whitespace is skipped. Every other token, including
+// string and number literals, is reported with its exact source text
+// and span; use Unquote to decode the value of a KindString token.
+package jsonlex // import "github.com/andrieee44/langengine/jsonlex"
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindBeginObject is the kind of the '{' structural token.
+	KindBeginObject lexer.Kind = lexer.KindUser + iota
+
+	// KindEndObject is the kind of the '}' structural token.
+	KindEndObject
+
+	// KindBeginArray is the kind of the '[' structural token.
+	KindBeginArray
+
+	// KindEndArray is the kind of the ']' structural token.
+	KindEndArray
+
+	// KindNameSeparator is the kind of the ':' structural token.
+	KindNameSeparator
+
+	// KindValueSeparator is the kind of the ',' structural token.
+	KindValueSeparator
+
+	// KindString is the kind of a string literal, including its quotes.
+	KindString
+
+	// KindNumber is the kind of a number literal.
+	KindNumber
+
+	// KindTrue is the kind of the true literal.
+	KindTrue
+
+	// KindFalse is the kind of the false literal.
+	KindFalse
+
+	// KindNull is the kind of the null literal.
+	KindNull
+)
+
+// ErrSyntax is returned by Unquote when its argument is not a valid
+// JSON string literal.
+var ErrSyntax = errors.New("jsonlex: invalid string literal")
+
+// NewLexer returns a lexer.Lexer that splits rd into JSON tokens.
+// Malformed input stops the Lexer with a lexer.KindError token spanning
+// the offending text. The options configure the underlying
+// lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexValue, opts...)
+}
+
+// Unquote decodes the value of a KindString token. Escaped UTF-16
+// surrogate pairs are combined into a single code point, while unpaired
+// surrogates decode to utf8.RuneError, as in encoding/json.
+func Unquote(text string) (string, error) {
+	var (
+		sb    strings.Builder
+		char  rune
+		size  int
+		value rune
+		low   rune
+		ok    bool
+	)
+
+	if len(text) < 2 || text[0] != '"' || text[len(text)-1] != '"' {
+		return "", ErrSyntax
+	}
+
+	text = text[1 : len(text)-1]
+
+	for len(text) != 0 {
+		char, size = utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		switch {
+		case char == '"' || char < ' ':
+			return "", ErrSyntax
+		case char != '\\':
+			sb.WriteRune(char)
+
+			continue
+		case text == "":
+			return "", ErrSyntax
+		}
+
+		char = rune(text[0])
+		text = text[1:]
+
+		if char != 'u' {
+			value, ok = simpleEscape(char)
+			if !ok {
+				return "", ErrSyntax
+			}
+
+			sb.WriteRune(value)
+
+			continue
+		}
+
+		value, ok = hex4(text)
+		if !ok {
+			return "", ErrSyntax
+		}
+
+		text = text[4:]
+
+		if utf16.IsSurrogate(value) && strings.HasPrefix(text, `\u`) {
+			low, ok = hex4(text[2:])
+			if ok && utf16.DecodeRune(value, low) != utf8.RuneError {
+				value = utf16.DecodeRune(value, low)
+				text = text[6:]
+			}
+		}
+
+		if utf16.IsSurrogate(value) {
+			value = utf8.RuneError
+		}
+
+		sb.WriteRune(value)
+	}
+
+	return sb.String(), nil
+}
+
+func lexValue(lx *lexer.Lexer) lexer.StateFn {
+	var char rune
+
+	lx.AcceptRun(" \t\r\n")
+	lx.Ignore()
+
+	if lx.AtEOF() {
+		return nil
+	}
+
+	char = lx.Next()
+
+	switch {
+	case char == '{':
+		lx.Emit(KindBeginObject)
+	case char == '}':
+		lx.Emit(KindEndObject)
+	case char == '[':
+		lx.Emit(KindBeginArray)
+	case char == ']':
+		lx.Emit(KindEndArray)
+	case char == ':':
+		lx.Emit(KindNameSeparator)
+	case char == ',':
+		lx.Emit(KindValueSeparator)
+	case char == '"':
+		return lexString
+	case char == '-' || isDigit(char):
+		lx.Backup(1)
+
+		return lexNumber
+	case 'a' <= char && char <= 'z':
+		lx.Backup(1)
+
+		return lexLiteral
+	default:
+		return lx.Errorf("unexpected %q", char)
+	}
+
+	return lexValue
+}
+
+func lexString(lx *lexer.Lexer) lexer.StateFn {
+	var (
+		char  rune
+		count int
+		ok    bool
+	)
+
+	for {
+		if lx.AtEOF() {
+			return lx.Errorf("unterminated string")
+		}
+
+		char = lx.Next()
+
+		switch {
+		case char == '"':
+			lx.Emit(KindString)
+
+			return lexValue
+		case char < ' ':
+			return lx.Errorf("invalid control character %q in string", char)
+		case char != '\\':
+			continue
+		}
+
+		if lx.AtEOF() {
+			return lx.Errorf("unterminated string")
+		}
+
+		char = lx.Next()
+
+		switch char {
+		case 'u':
+			count = lx.AcceptRunFunc(isHexDigit)
+			if count < 4 {
+				return lx.Errorf(`invalid \u escape in string`)
+			}
+
+			lx.Backup(count - 4)
+		default:
+			_, ok = simpleEscape(char)
+			if !ok {
+				return lx.Errorf("invalid escape %q in string", char)
+			}
+		}
+	}
+}
+
+func lexNumber(lx *lexer.Lexer) lexer.StateFn {
+	lx.Accept("-")
+
+	switch {
+	case lx.Accept("0"):
+		if lx.AcceptRunFunc(isDigit) != 0 {
+			return lx.Errorf("invalid number: leading zero")
+		}
+	case lx.AcceptRunFunc(isDigit) == 0:
+		return lx.Errorf("invalid number: missing integer digits")
+	}
+
+	if lx.Accept(".") && lx.AcceptRunFunc(isDigit) == 0 {
+		return lx.Errorf("invalid number: missing fraction digits")
+	}
+
+	if lx.Accept("eE") {
+		lx.Accept("+-")
+
+		if lx.AcceptRunFunc(isDigit) == 0 {
+			return lx.Errorf("invalid number: missing exponent digits")
+		}
+	}
+
+	lx.Emit(KindNumber)
+
+	return lexValue
+}
+
+func lexLiteral(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(func(char rune) bool {
+		return 'a' <= char && char <= 'z'
+	})
+
+	switch lx.PeekToken() {
+	case "true":
+		lx.Emit(KindTrue)
+	case "false":
+		lx.Emit(KindFalse)
+	case "null":
+		lx.Emit(KindNull)
+	default:
+		return lx.Errorf("invalid literal %q", lx.PeekToken())
+	}
+
+	return lexValue
+}
+
+func simpleEscape(char rune) (rune, bool) {
+	switch char {
+	case '"', '\\', '/':
+		return char, true
+	case 'b':
+		return '\b', true
+	case 'f':
+		return '\f', true
+	case 'n':
+		return '\n', true
+	case 'r':
+		return '\r', true
+	case 't':
+		return '\t', true
+	default:
+		return 0, false
+	}
+}
+
+func hex4(text string) (rune, bool) {
+	var (
+		value uint64
+		err   error
+	)
+
+	if len(text) < 4 {
+		return 0, false
+	}
+
+	value, err = strconv.ParseUint(text[:4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(value), true
+}
+
+func isDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+func isHexDigit(char rune) bool {
+	return isDigit(char) ||
+		'a' <= char && char <= 'f' ||
+		'A' <= char && char <= 'F'
+}
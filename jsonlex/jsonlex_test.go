@@ -0,0 +1,214 @@
+package jsonlex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/jsonlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+type kindText struct {
+	kind lexer.Kind
+	text string
+}
+
+func lexAll(content string) []kindText {
+	var (
+		tokens []kindText
+		tok    lexer.Token
+	)
+
+	for tok = range jsonlex.NewLexer(strings.NewReader(content)).Tokens() {
+		tokens = append(tokens, kindText{tok.Kind, tok.Text})
+	}
+
+	return tokens
+}
+
+func pos(line, column, offset int) lexer.Position {
+	return lexer.Position{
+		Line:   line,
+		Column: column,
+		Offset: offset,
+	}
+}
+
+func TestNewLexer(t *testing.T) {
+	var (
+		testTbl map[string][]kindText
+		content string
+		tokens  []kindText
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]kindText{
+		`{"a": [1, -2.5e+3, true, false, null]}`: {
+			{jsonlex.KindBeginObject, "{"},
+			{jsonlex.KindString, `"a"`},
+			{jsonlex.KindNameSeparator, ":"},
+			{jsonlex.KindBeginArray, "["},
+			{jsonlex.KindNumber, "1"},
+			{jsonlex.KindValueSeparator, ","},
+			{jsonlex.KindNumber, "-2.5e+3"},
+			{jsonlex.KindValueSeparator, ","},
+			{jsonlex.KindTrue, "true"},
+			{jsonlex.KindValueSeparator, ","},
+			{jsonlex.KindFalse, "false"},
+			{jsonlex.KindValueSeparator, ","},
+			{jsonlex.KindNull, "null"},
+			{jsonlex.KindEndArray, "]"},
+			{jsonlex.KindEndObject, "}"},
+		},
+		" \t\r\n": nil,
+		`"\"\\\/\b\f\n\r\té😀"`: {
+			{jsonlex.KindString, `"\"\\\/\b\f\n\r\té😀"`},
+		},
+		"0 0.5 1E9": {
+			{jsonlex.KindNumber, "0"},
+			{jsonlex.KindNumber, "0.5"},
+			{jsonlex.KindNumber, "1E9"},
+		},
+		`"abc`: {
+			{lexer.KindError, "unterminated string"},
+		},
+		"\"a\tb\"": {
+			{lexer.KindError, `invalid control character '\t' in string`},
+		},
+		`"\x"`: {
+			{lexer.KindError, `invalid escape 'x' in string`},
+		},
+		`"\u12G4"`: {
+			{lexer.KindError, `invalid \u escape in string`},
+		},
+		"01": {
+			{lexer.KindError, "invalid number: leading zero"},
+		},
+		"-": {
+			{lexer.KindError, "invalid number: missing integer digits"},
+		},
+		"1.": {
+			{lexer.KindError, "invalid number: missing fraction digits"},
+		},
+		"1e+": {
+			{lexer.KindError, "invalid number: missing exponent digits"},
+		},
+		"nil": {
+			{lexer.KindError, `invalid literal "nil"`},
+		},
+		"[@]": {
+			{jsonlex.KindBeginArray, "["},
+			{lexer.KindError, "unexpected '@'"},
+		},
+		"[1,\x00 2, 3]": {
+			{jsonlex.KindBeginArray, "["},
+			{jsonlex.KindNumber, "1"},
+			{jsonlex.KindValueSeparator, ","},
+			{lexer.KindError, `unexpected '\x00'`},
+		},
+		"\"a\x00b\"": {
+			{lexer.KindError, `invalid control character '\x00' in string`},
+		},
+		"\"a\\\x00\"": {
+			{lexer.KindError, `invalid escape '\x00' in string`},
+		},
+		`"a\`: {
+			{lexer.KindError, "unterminated string"},
+		},
+	}
+
+	for content, tokens = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			assert.Equal(t, tokens, lexAll(content))
+		})
+	}
+}
+
+func TestNewLexerSpan(t *testing.T) {
+	var (
+		spans []lexer.Span
+		tok   lexer.Token
+	)
+
+	t.Parallel()
+
+	for tok = range jsonlex.NewLexer(strings.NewReader("[\n  \"é\"]")).Tokens() {
+		spans = append(spans, tok.Span)
+	}
+
+	assert.Equal(t, []lexer.Span{
+		{Start: pos(1, 1, 0), End: pos(1, 2, 1)},
+		{Start: pos(2, 3, 4), End: pos(2, 6, 8)},
+		{Start: pos(2, 6, 8), End: pos(2, 7, 9)},
+	}, spans)
+}
+
+func TestNewLexerOptions(t *testing.T) {
+	var (
+		spans []lexer.Span
+		tok   lexer.Token
+	)
+
+	t.Parallel()
+
+	for tok = range jsonlex.NewLexer(
+		strings.NewReader("[\n  1]"),
+		lexer.WithZeroBased(),
+	).Tokens() {
+		spans = append(spans, tok.Span)
+	}
+
+	assert.Equal(t, []lexer.Span{
+		{Start: pos(0, 0, 0), End: pos(0, 1, 1)},
+		{Start: pos(1, 2, 4), End: pos(1, 3, 5)},
+		{Start: pos(1, 3, 5), End: pos(1, 4, 6)},
+	}, spans)
+}
+
+func TestUnquote(t *testing.T) {
+	type testData struct {
+		value string
+		err   error
+	}
+
+	var (
+		testTbl map[string]testData
+		text    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		`"abc"`:          {"abc", nil},
+		`""`:             {"", nil},
+		`"a\"b\\c\/d\n"`: {"a\"b\\c/d\n", nil},
+		`"é中"`:           {"é中", nil},
+		`"\uD83D\uDE00"`: {"😀", nil},
+		`"\uD83D"`:       {"�", nil},
+		`"\uDE00\uD83D"`: {"��", nil},
+		`"\uD83Dx"`:      {"�x", nil},
+		`"abc`:           {"", jsonlex.ErrSyntax},
+		`"a"b"`:          {"", jsonlex.ErrSyntax},
+		`"\x"`:           {"", jsonlex.ErrSyntax},
+		`"\u12"`:         {"", jsonlex.ErrSyntax},
+		`"\`:             {"", jsonlex.ErrSyntax},
+		"\"a\tb\"":       {"", jsonlex.ErrSyntax},
+	}
+
+	for text, test = range testTbl {
+		t.Run(text, func(t *testing.T) {
+			var (
+				value string
+				err   error
+			)
+
+			value, err = jsonlex.Unquote(text)
+
+			assert.Equal(t, test.value, value)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package lexer
+
+import "unicode/utf8"
+
+// ASCIISet is a set of ASCII characters stored as a lookup table, for
+// use with AcceptRunASCII. Testing a byte against an ASCIISet costs a
+// single load, where a predicate such as unicode.IsLetter costs a
+// function call and a table search per rune.
+type ASCIISet [utf8.RuneSelf]bool
+
+// NewASCIISet returns the set of the characters of chars. The boolean is
+// false, and the set is nil, if chars contains a rune outside ASCII.
+func NewASCIISet(chars string) (*ASCIISet, bool) {
+	var (
+		set ASCIISet
+		ok  bool
+	)
+
+	set, ok = asciiSet(chars)
+	if !ok {
+		return nil, false
+	}
+
+	return &set, true
+}
+
+// ASCIISetFunc returns the set of the ASCII characters for which fn
+// returns true, such as the ASCII letters for unicode.IsLetter. Runes
+// outside ASCII are not represented, so the set only stands in for fn
+// in grammars restricted to ASCII.
+func ASCIISetFunc(fn func(rune) bool) *ASCIISet {
+	var (
+		set  ASCIISet
+		char rune
+	)
+
+	for char = 0; char < utf8.RuneSelf; char++ {
+		set[char] = fn(char)
+	}
+
+	return &set
+}
+
+// AcceptRunASCII consumes consecutive bytes while they are ASCII
+// characters in set. It behaves like AcceptRunFunc with a predicate
+// testing membership in set, but inspects the buffered bytes directly
+// instead of decoding and testing one rune at a time. AcceptRun uses it
+// automatically when its match string is ASCII-only. A NUL byte is
+// never accepted, since it reads as EOF.
+//
+// Returns the number of bytes consumed, each of which can be restored
+// with Backup.
+func (lrd *Reader) AcceptRunASCII(set *ASCIISet) int {
+	var (
+		b     byte
+		count int
+	)
+
+	for {
+		if lrd.current == lrd.head {
+			lrd.fill()
+
+			if lrd.current == lrd.head {
+				return count
+			}
+		}
+
+		b = lrd.buf[lrd.current]
+		if b == 0 || b >= utf8.RuneSelf || !set[b] {
+			return count
+		}
+
+		lrd.step(rune(b), 1)
+		count++
+	}
+}
+
+// asciiSet is NewASCIISet returning the set by value, so that callers
+// can keep it on the stack.
+func asciiSet(chars string) (ASCIISet, bool) {
+	var (
+		set  ASCIISet
+		char rune
+	)
+
+	for _, char = range chars {
+		if char >= utf8.RuneSelf {
+			return ASCIISet{}, false
+		}
+
+		set[char] = true
+	}
+
+	return set, true
+}
@@ -0,0 +1,157 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderAcceptRunASCII(t *testing.T) {
+	var (
+		testTbl map[string]helperTestData[int]
+		letters *lexer.ASCIISet
+	)
+
+	t.Parallel()
+
+	letters = lexer.ASCIISetFunc(unicode.IsLetter)
+
+	testTbl = map[string]helperTestData[int]{
+		"Run": {
+			content: "abc1",
+			afterOp: "abc",
+			op: func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(letters)
+			},
+			result: 3,
+		},
+		"NonASCII": {
+			content: "abé",
+			afterOp: "ab",
+			op: func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(letters)
+			},
+			result: 2,
+		},
+		"NUL": {
+			content: "a\x00b",
+			afterOp: "a",
+			op: func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(lexer.ASCIISetFunc(func(rune) bool {
+					return true
+				}))
+			},
+			result: 1,
+		},
+		"Empty": {
+			content: "",
+			afterOp: "",
+			op: func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(letters)
+			},
+			result: 0,
+		},
+		"Long": {
+			content: strings.Repeat("ab", 5000) + "!",
+			afterOp: strings.Repeat("ab", 5000),
+			op: func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(letters)
+			},
+			result: 10000,
+		},
+	}
+
+	assertHelperTestDataTbl(t, testTbl)
+}
+
+func TestReaderAcceptRunASCIIBackup(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\ncd"))
+
+	assert.Equal(t, 5, lrd.AcceptRun("abcd\n"))
+	assert.Equal(t, lexer.Position{2, 3, 5}, lrd.CurrentPosition())
+
+	lrd.Backup(3)
+
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+	assert.Equal(t, "ab", lrd.PeekToken())
+}
+
+func TestNewASCIISet(t *testing.T) {
+	var (
+		set *lexer.ASCIISet
+		ok  bool
+	)
+
+	t.Parallel()
+
+	set, ok = lexer.NewASCIISet("a-z")
+
+	assert.True(t, ok)
+	assert.True(t, set['-'])
+	assert.False(t, set['b'])
+
+	set, ok = lexer.NewASCIISet("aé")
+
+	assert.False(t, ok)
+	assert.Nil(t, set)
+}
+
+func TestReaderAcceptRunAllocs(t *testing.T) {
+	var lrd *lexer.Reader
+
+	lrd = lexer.NewReader(strings.NewReader(strings.Repeat("0123456789 ", 100)))
+	lrd.Next()
+
+	assert.Zero(t, testing.AllocsPerRun(50, func() {
+		lrd.AcceptRun("0123456789")
+		lrd.AcceptRun(" ")
+		lrd.Ignore()
+	}))
+}
+
+func BenchmarkReaderAcceptRun(b *testing.B) {
+	var content string
+
+	content = strings.Repeat("identifier_with_letters ", 1000)
+
+	b.Run("Func", func(b *testing.B) {
+		for b.Loop() {
+			acceptAll(content, func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunFunc(func(char rune) bool {
+					return unicode.IsLetter(char) || char == '_'
+				})
+			})
+		}
+	})
+
+	b.Run("ASCII", func(b *testing.B) {
+		var set *lexer.ASCIISet
+
+		set = lexer.ASCIISetFunc(func(char rune) bool {
+			return unicode.IsLetter(char) || char == '_'
+		})
+
+		for b.Loop() {
+			acceptAll(content, func(lrd *lexer.Reader) int {
+				return lrd.AcceptRunASCII(set)
+			})
+		}
+	})
+}
+
+func acceptAll(content string, run func(*lexer.Reader) int) {
+	var lrd *lexer.Reader
+
+	lrd = lexer.NewReader(strings.NewReader(content))
+
+	for run(lrd) != 0 || lrd.Next() != lexer.EOF {
+		lrd.Ignore()
+	}
+}
@@ -0,0 +1,139 @@
+package lexer
+
+import "fmt"
+
+// BracketPair is a pair of delimiters that open and close a nested
+// region, such as "(" and ")" or "begin" and "end", matched against
+// the text of tokens. Open and Close must differ.
+type BracketPair struct {
+	// Open is the text of the opening delimiter.
+	Open string
+
+	// Close is the text of the closing delimiter.
+	Close string
+}
+
+// Brackets is the pairing of the delimiters of a token stream computed
+// by MatchBrackets.
+type Brackets struct {
+	// Partners holds, for the token at every index, the index of the
+	// delimiter it is paired with, or -1 if the token is not a paired
+	// delimiter.
+	Partners []int
+
+	// Errors lists the unbalanced delimiters in the order they were
+	// found.
+	Errors []BracketError
+}
+
+// BracketError reports an unbalanced delimiter: a closing delimiter
+// that closes nothing, or an opening delimiter that is never closed.
+type BracketError struct {
+	Diagnostic
+
+	// Index is the index of the offending token.
+	Index int
+}
+
+// MatchBrackets pairs the opening and closing delimiters of tokens, as
+// needed by editors highlighting the partner of the bracket under the
+// cursor or by formatters computing indentation. Tokens are matched by
+// text against pairs; trivia and KindError tokens are ignored.
+//
+// A closing delimiter that does not match the innermost open one, but
+// matches one further out, closes that one and leaves the delimiters
+// opened in between unclosed, which is how a missing closer is usually
+// best explained. A closing delimiter that matches no open one is
+// reported and otherwise ignored. Delimiters still open at the end of
+// tokens are reported as unclosed.
+func MatchBrackets(tokens []Token, pairs []BracketPair) Brackets {
+	var (
+		brackets Brackets
+		opens    map[string]string
+		closes   map[string]bool
+		stack    []int
+		pair     BracketPair
+		depth    int
+		tok      Token
+		i, j     int
+	)
+
+	opens = make(map[string]string)
+	closes = make(map[string]bool)
+
+	for _, pair = range pairs {
+		opens[pair.Open] = pair.Close
+		closes[pair.Close] = true
+	}
+
+	brackets.Partners = make([]int, len(tokens))
+
+	for i, tok = range tokens {
+		brackets.Partners[i] = -1
+
+		switch {
+		case tok.Trivia || tok.Kind == KindError:
+		case opens[tok.Text] != "":
+			stack = append(stack, i)
+		case closes[tok.Text]:
+			depth = -1
+
+			for j = len(stack) - 1; j >= 0; j-- {
+				if opens[tokens[stack[j]].Text] == tok.Text {
+					depth = j
+
+					break
+				}
+			}
+
+			if depth < 0 {
+				brackets.report(tokens, i, "unexpected %q", tok.Text)
+
+				break
+			}
+
+			for j = len(stack) - 1; j > depth; j-- {
+				brackets.unclosed(tokens, stack[j], opens)
+			}
+
+			brackets.Partners[i] = stack[depth]
+			brackets.Partners[stack[depth]] = i
+			stack = stack[:depth]
+		}
+	}
+
+	for _, i = range stack {
+		brackets.unclosed(tokens, i, opens)
+	}
+
+	return brackets
+}
+
+func (brackets *Brackets) unclosed(
+	tokens []Token,
+	i int,
+	opens map[string]string,
+) {
+	brackets.report(
+		tokens,
+		i,
+		"unclosed %q, expected %q",
+		tokens[i].Text,
+		opens[tokens[i].Text],
+	)
+}
+
+func (brackets *Brackets) report(
+	tokens []Token,
+	i int,
+	format string,
+	args ...any,
+) {
+	brackets.Errors = append(brackets.Errors, BracketError{
+		Diagnostic: Diagnostic{
+			Span:    tokens[i].Span,
+			Message: fmt.Sprintf(format, args...),
+		},
+		Index: i,
+	})
+}
@@ -0,0 +1,94 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexFields(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(unicode.IsSpace)
+	lx.Ignore()
+
+	if lx.AcceptRunFunc(func(char rune) bool {
+		return !unicode.IsSpace(char)
+	}) == 0 {
+		return nil
+	}
+
+	lx.Emit(kindWord)
+
+	return lexFields
+}
+
+func TestMatchBrackets(t *testing.T) {
+	type testData struct {
+		partners []int
+		errors   []string
+	}
+
+	var (
+		pairs   []lexer.BracketPair
+		testTbl map[string]testData
+		content string
+		test    testData
+	)
+
+	t.Parallel()
+
+	pairs = []lexer.BracketPair{
+		{Open: "(", Close: ")"},
+		{Open: "[", Close: "]"},
+		{Open: "begin", Close: "end"},
+	}
+
+	testTbl = map[string]testData{
+		"( a [ b ] ) begin end": {
+			partners: []int{5, -1, 4, -1, 2, 0, 7, 6},
+		},
+		"( [ )": {
+			partners: []int{2, -1, 0},
+			errors:   []string{`1:3: unclosed "[", expected "]"`},
+		},
+		") ( ]": {
+			partners: []int{-1, -1, -1},
+			errors: []string{
+				`1:1: unexpected ")"`,
+				`1:5: unexpected "]"`,
+				`1:3: unclosed "(", expected ")"`,
+			},
+		},
+		"": {
+			partners: []int{},
+		},
+	}
+
+	for content, test = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			var (
+				tokens   []lexer.Token
+				brackets lexer.Brackets
+				msgs     []string
+				bErr     lexer.BracketError
+			)
+
+			tokens = slices.Collect(lexer.NewLexer(
+				strings.NewReader(content),
+				lexFields,
+			).Tokens())
+			brackets = lexer.MatchBrackets(tokens, pairs)
+
+			for _, bErr = range brackets.Errors {
+				msgs = append(msgs, bErr.String())
+				assert.Equal(t, tokens[bErr.Index].Span, bErr.Span)
+			}
+
+			assert.Equal(t, test.partners, brackets.Partners)
+			assert.Equal(t, test.errors, msgs)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBudgetExceeded is wrapped by the *BudgetError returned by
+// Lexer.Err once a Lexer has stopped because the document exceeded a
+// limit set with WithMaxTokens or WithMaxDuration.
+var ErrBudgetExceeded = errors.New("lexer: budget exceeded")
+
+// BudgetError records that a Lexer stopped before the end of a document
+// because the document exceeded one of its limits, along with how far
+// lexing got.
+type BudgetError struct {
+	// Source is the source name set with Reader.SetSource, if any.
+	Source string
+
+	// Pos is the position where lexing stopped: the start of the first
+	// token that was not delivered.
+	Pos Position
+
+	// MaxTokens is the limit of WithMaxTokens if it was exceeded, and
+	// zero otherwise.
+	MaxTokens int
+
+	// MaxDuration is the limit of WithMaxDuration if it was exceeded,
+	// and zero otherwise.
+	MaxDuration time.Duration
+
+	// Tokens is the number of tokens delivered before stopping.
+	Tokens int
+
+	// Elapsed is the wall time spent lexing before stopping.
+	Elapsed time.Duration
+}
+
+// budgetState is the consumption of a Lexer against the limits of its
+// Reader.
+type budgetState struct {
+	tokens int
+	stop   Position
+	began  time.Time
+	err    *BudgetError
+}
+
+// WithMaxTokens makes a Lexer stop once the states have emitted more
+// than limit tokens, trivia included, so that a service lexing untrusted
+// documents bounds the memory and work a pathological one, such as
+// billions of one-rune tokens, can take. The first limit tokens are
+// delivered as usual, followed by a KindError token describing the
+// limit; from then on the Lexer behaves as if its final state had
+// returned, and Err returns a *BudgetError. The limit is enforced as
+// tokens are emitted: the tokens beyond it are dropped at once, so
+// that a state emitting in a loop never queues more than limit tokens,
+// and the Lexer stops when the state returns. The tokens a Lexer adds
+// by itself at the end of input, such as the KindEOF token, are not
+// counted. A limit of zero or less means no limit.
+func WithMaxTokens(limit int) Option {
+	return func(lrd *Reader) {
+		lrd.maxTokens = limit
+	}
+}
+
+// WithMaxDuration makes a Lexer stop, as WithMaxTokens describes, once
+// it has spent more than limit of wall time lexing, counted from the
+// first call to NextToken. The time waiting for the consumer between
+// calls counts too, which suits a deadline per document. The limit is
+// checked each time a state returns, so a single state that never
+// returns is not interrupted. A limit of zero or less means no limit.
+func WithMaxDuration(limit time.Duration) Option {
+	return func(lrd *Reader) {
+		lrd.maxDuration = limit
+	}
+}
+
+// Err returns the *BudgetError that stopped the Lexer if it exceeded a
+// limit set with WithMaxTokens or WithMaxDuration, and the error of the
+// underlying Reader otherwise, as Reader.Err does.
+func (lx *Lexer) Err() error {
+	if lx.budget.err != nil {
+		return lx.budget.err
+	}
+
+	return lx.Reader.Err()
+}
+
+// startBudget starts the clock of WithMaxDuration.
+func (lx *Lexer) startBudget() {
+	if lx.maxDuration > 0 && lx.budget.began.IsZero() {
+		lx.budget.began = time.Now()
+	}
+}
+
+// withinBudget counts tok against the limit of WithMaxTokens and
+// reports whether it is within the limit, recording where lexing stops
+// otherwise. The tokens queued once the Lexer has finished are always
+// within it.
+func (lx *Lexer) withinBudget(tok Token) bool {
+	var budget *budgetState
+
+	budget = &lx.budget
+	budget.tokens++
+
+	if lx.maxTokens <= 0 || budget.tokens <= lx.maxTokens || lx.finished {
+		return true
+	}
+
+	if budget.tokens == lx.maxTokens+1 {
+		budget.stop = tok.Span.Start
+	}
+
+	return false
+}
+
+// checkBudget stops the Lexer if the tokens it has emitted or the time
+// it has spent exceed the limits of its Reader, queueing a KindError
+// token in place of the tokens dropped beyond the token limit.
+func (lx *Lexer) checkBudget() {
+	var (
+		budget  *budgetState
+		elapsed time.Duration
+		tok     Token
+	)
+
+	budget = &lx.budget
+
+	if lx.maxDuration > 0 {
+		elapsed = time.Since(budget.began)
+	}
+
+	switch {
+	case lx.maxTokens > 0 && budget.tokens > lx.maxTokens:
+		tok.Text = fmt.Sprintf("token budget of %d exceeded", lx.maxTokens)
+		budget.err = &BudgetError{
+			Pos:       budget.stop,
+			MaxTokens: lx.maxTokens,
+			Tokens:    lx.maxTokens,
+		}
+	case lx.maxDuration > 0 && lx.state != nil && elapsed > lx.maxDuration:
+		tok.Text = fmt.Sprintf("time budget of %v exceeded", lx.maxDuration)
+		budget.err = &BudgetError{
+			Pos:         lx.CurrentPosition(),
+			MaxDuration: lx.maxDuration,
+			Tokens:      budget.tokens,
+		}
+	default:
+		return
+	}
+
+	budget.err.Source = lx.source
+	budget.err.Elapsed = elapsed
+
+	tok.Kind = KindError
+	tok.Span = Span{Start: budget.err.Pos, End: budget.err.Pos}
+
+	lx.queue = append(lx.queue, tok)
+	lx.state = nil
+	lx.finished = true
+	lx.logError(tok)
+}
+
+// Error formats the error as "source:line:column: message", omitting
+// the source when it is empty.
+func (budgetErr *BudgetError) Error() string {
+	var (
+		prefix string
+		limit  string
+	)
+
+	if budgetErr.Source != "" {
+		prefix = budgetErr.Source + ":"
+	}
+
+	limit = fmt.Sprintf("more than %d tokens", budgetErr.MaxTokens)
+	if budgetErr.MaxTokens == 0 {
+		limit = fmt.Sprintf(
+			"lexing took %v, more than %v",
+			budgetErr.Elapsed,
+			budgetErr.MaxDuration,
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s%d:%d: %v: %s",
+		prefix,
+		budgetErr.Pos.Line,
+		budgetErr.Pos.Column,
+		ErrBudgetExceeded,
+		limit,
+	)
+}
+
+// Unwrap returns ErrBudgetExceeded.
+func (budgetErr *BudgetError) Unwrap() error {
+	return ErrBudgetExceeded
+}
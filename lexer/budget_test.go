@@ -0,0 +1,176 @@
+package lexer_test
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerMaxTokens(t *testing.T) {
+	type testData struct {
+		start lexer.StateFn
+		want  []kindText
+		pos   lexer.Position
+		msg   string
+	}
+
+	var (
+		testTbl   map[string]testData
+		name      string
+		test      testData
+		lx        *lexer.Lexer
+		budgetErr *lexer.BudgetError
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"one per state": {
+			start: lexWords,
+			want: []kindText{
+				{kindWord, "ab"},
+				{kindSpace, " "},
+				{kindWord, "cd"},
+				{lexer.KindError, "token budget of 3 exceeded"},
+				{lexer.KindEOF, ""},
+			},
+			pos: lexer.Position{1, 6, 5},
+			msg: "1:6: lexer: budget exceeded: more than 3 tokens",
+		},
+		"many per state": {
+			start: func(lx *lexer.Lexer) lexer.StateFn {
+				for lx.Next() != lexer.EOF {
+					lx.Emit(kindWord)
+				}
+
+				return nil
+			},
+			want: []kindText{
+				{kindWord, "a"},
+				{kindWord, "b"},
+				{kindWord, " "},
+				{lexer.KindError, "token budget of 3 exceeded"},
+				{lexer.KindEOF, ""},
+			},
+			pos: lexer.Position{1, 4, 3},
+			msg: "1:4: lexer: budget exceeded: more than 3 tokens",
+		},
+	}
+
+	for name, test = range testTbl {
+		lx = lexer.NewLexer(
+			strings.NewReader("ab cd ef"),
+			test.start,
+			lexer.WithMaxTokens(3),
+			lexer.WithEOFToken(),
+		)
+
+		assert.Equal(t, test.want, collectKinds(lx), name)
+
+		assert.ErrorIs(t, lx.Err(), lexer.ErrBudgetExceeded, name)
+		assert.ErrorAs(t, lx.Err(), &budgetErr, name)
+		assert.Equal(t, test.pos, budgetErr.Pos, name)
+		assert.Equal(t, 3, budgetErr.MaxTokens, name)
+		assert.Equal(t, 3, budgetErr.Tokens, name)
+		assert.Zero(t, budgetErr.MaxDuration, name)
+		assert.EqualError(t, lx.Err(), test.msg, name)
+	}
+}
+
+// TestLexerMaxTokensLoop checks that the tokens a single state emits
+// beyond the limit are dropped rather than queued. It does not run in
+// parallel, so that the allocations it measures are its own.
+func TestLexerMaxTokensLoop(t *testing.T) {
+	var (
+		lx     *lexer.Lexer
+		before runtime.MemStats
+		after  runtime.MemStats
+		i      int
+	)
+
+	lx = lexer.NewLexer(
+		strings.NewReader("a"),
+		func(lx *lexer.Lexer) lexer.StateFn {
+			for i = 0; i < 1<<20; i++ {
+				lx.Emit(kindWord)
+			}
+
+			return nil
+		},
+		lexer.WithMaxTokens(3),
+	)
+
+	runtime.ReadMemStats(&before)
+
+	assert.Equal(t, []kindText{
+		{kindWord, ""},
+		{kindWord, ""},
+		{kindWord, ""},
+		{lexer.KindError, "token budget of 3 exceeded"},
+	}, collectKinds(lx))
+
+	runtime.ReadMemStats(&after)
+
+	// Queueing every token would allocate over 64 MiB.
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
+
+func TestLexerMaxTokensNotExceeded(t *testing.T) {
+	var lx *lexer.Lexer
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		lexWords,
+		lexer.WithMaxTokens(3),
+	)
+
+	assert.Equal(t, []kindText{
+		{kindWord, "ab"},
+		{kindSpace, " "},
+		{kindWord, "cd"},
+	}, collectKinds(lx))
+	assert.Equal(t, io.EOF, lx.Err())
+}
+
+func TestLexerMaxDuration(t *testing.T) {
+	var (
+		lx        *lexer.Lexer
+		budgetErr *lexer.BudgetError
+		slow      lexer.StateFn
+	)
+
+	t.Parallel()
+
+	slow = func(lx *lexer.Lexer) lexer.StateFn {
+		time.Sleep(2 * time.Millisecond)
+
+		return lexWords(lx)
+	}
+
+	lx = lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		slow,
+		lexer.WithMaxDuration(time.Millisecond),
+	)
+	lx.SetSource("doc")
+
+	assert.Equal(t, []kindText{
+		{kindWord, "ab"},
+		{lexer.KindError, "time budget of 1ms exceeded"},
+	}, collectKinds(lx))
+
+	assert.ErrorAs(t, lx.Err(), &budgetErr)
+	assert.Equal(t, "doc", budgetErr.Source)
+	assert.Equal(t, lexer.Position{1, 3, 2}, budgetErr.Pos)
+	assert.Equal(t, time.Millisecond, budgetErr.MaxDuration)
+	assert.Equal(t, 1, budgetErr.Tokens)
+	assert.Greater(t, budgetErr.Elapsed, time.Millisecond)
+	assert.Contains(t, lx.Err().Error(), "doc:1:3: lexer: budget exceeded: lexing took ")
+}
@@ -0,0 +1,170 @@
+package lexer
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// NextByte returns the next byte from the input stream without decoding
+// UTF-8, for binary or mixed text and binary protocols, such as lexing
+// a frame header before switching back to text. The boolean is false at
+// the end of input, as a zero byte is valid data.
+//
+// The byte is recorded like a rune read with Next, so Backup(1) undoes
+// it and it becomes part of the current token. A line feed advances the
+// line as usual; any other byte advances the column by one, or by its
+// column width for ASCII bytes. Mixing NextByte and Next is safe as long
+// as Next is not called in the middle of a multi-byte UTF-8 sequence,
+// which it would decode as invalid bytes.
+func (lrd *Reader) NextByte() (byte, bool) {
+	var b byte
+
+	lrd.fill()
+
+	if lrd.head-lrd.current <= 0 {
+		return 0, false
+	}
+
+	b = lrd.buf[lrd.current]
+
+	if b < utf8.RuneSelf {
+		lrd.step(rune(b), 1)
+	} else {
+		lrd.step(utf8.RuneError, 1)
+	}
+
+	return b, true
+}
+
+// AcceptBytes consumes bytes matching the exact sequence of seq, like
+// AcceptSeq but without decoding UTF-8. On a match, every byte is
+// recorded as if read with NextByte.
+//
+// Returns true if the entire sequence was successfully consumed, which
+// the empty sequence always is. Returns false, consuming nothing, if EOF
+// is reached or a mismatch occurs.
+func (lrd *Reader) AcceptBytes(seq []byte) bool {
+	var b byte
+
+	if !bytes.Equal(lrd.lookahead(len(seq)), seq) {
+		return false
+	}
+
+	for _, b = range seq {
+		if b < utf8.RuneSelf {
+			lrd.step(rune(b), 1)
+		} else {
+			lrd.step(utf8.RuneError, 1)
+		}
+	}
+
+	return true
+}
+
+// UntilByte consumes bytes until EOF or until the byte b is found, like
+// Until but without decoding UTF-8. The byte b itself is not consumed.
+//
+// Returns the number of bytes successfully consumed.
+func (lrd *Reader) UntilByte(b byte) int {
+	var (
+		next  byte
+		ok    bool
+		count int
+	)
+
+	for {
+		next, ok = lrd.NextByte()
+		if !ok {
+			return count
+		}
+
+		if next == b {
+			lrd.Backup(1)
+
+			return count
+		}
+
+		count++
+	}
+}
+
+// ReadN consumes exactly n bytes into the current token, regardless of
+// their UTF-8 validity, and returns a copy of them. It is meant for
+// protocols such as netstrings or Redis RESP, where the length of a
+// payload precedes its data.
+//
+// The bytes are consumed as a single unit: Backup(1) restores the
+// position preceding the whole payload, and the history used by Backup
+// does not grow with n. Within the payload, positions are tracked in
+// bytes: a line feed starts a new line and every other byte advances
+// the column by one.
+//
+// A negative n is treated as zero.
+//
+// Returns io.ErrUnexpectedEOF, leaving the Reader untouched, if the
+// input ends before n bytes are available, or the error of the
+// underlying io.Reader if it fails first.
+func (lrd *Reader) ReadN(n int) ([]byte, error) {
+	var (
+		chunk int
+		count int
+		err   error
+	)
+
+	n = max(n, 0)
+	lrd.save()
+
+	for count < n {
+		lrd.fill()
+
+		if lrd.head-lrd.current <= 0 {
+			err = lrd.Err()
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			lrd.Backup(1)
+
+			return nil, err
+		}
+
+		chunk = min(lrd.head-lrd.current, n-count)
+		lrd.skipBytes(chunk)
+		count += chunk
+	}
+
+	// The buffer may have slid while reading, so the payload is located
+	// from the current index rather than from where it began.
+	return bytes.Clone(lrd.buf[lrd.current-n : lrd.current]), nil
+}
+
+// skipBytes advances past the next n buffered bytes without recording
+// history, tracking positions in bytes.
+func (lrd *Reader) skipBytes(n int) {
+	var (
+		b   byte
+		end int
+	)
+
+	end = lrd.current + n
+
+	for _, b = range lrd.buf[lrd.current:end] {
+		lrd.current++
+		lrd.currentPos.Offset++
+
+		switch {
+		case b == '\n':
+			lrd.lineStart = lrd.current
+
+			if !lrd.noPositions {
+				lrd.currentPos.Line++
+				lrd.currentPos.Column = lrd.columnBase
+			}
+		case !lrd.noPositions:
+			lrd.currentPos.Column++
+		}
+	}
+
+	lrd.record("bytes", 0, n)
+}
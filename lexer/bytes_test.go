@@ -0,0 +1,163 @@
+package lexer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderNextByte(t *testing.T) {
+	var (
+		lrd      *lexer.Reader
+		expected byte
+		b        byte
+		ok       bool
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("\x00\x01\n中x"))
+
+	for _, expected = range []byte("\x00\x01\n\xe4\xb8") {
+		b, ok = lrd.NextByte()
+
+		assert.True(t, ok)
+		assert.Equal(t, expected, b)
+	}
+
+	assert.Equal(t, lexer.Position{2, 3, 5}, lrd.CurrentPosition())
+
+	lrd.Backup(2)
+
+	assert.Equal(t, lexer.Position{2, 1, 3}, lrd.CurrentPosition())
+	assert.Equal(t, "\x00\x01\n", lrd.PeekToken())
+	assert.Equal(t, '中', lrd.Next())
+	assert.Equal(t, 'x', lrd.Next())
+
+	_, ok = lrd.NextByte()
+
+	assert.False(t, ok)
+}
+
+func TestReaderAcceptBytes(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[bool]{
+		"Base": {
+			content: "\x00\x00\x00\x05hello",
+			afterOp: "\x00\x00\x00\x05",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptBytes([]byte{0, 0, 0, 5})
+			},
+		},
+		"Mismatch": {
+			content: "\x00\x00\x01\x05hello",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptBytes([]byte{0, 0, 0, 5})
+			},
+		},
+		"PartialRune": {
+			content: "中",
+			afterOp: "\xe4",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptBytes([]byte{0xe4})
+			},
+		},
+		"EOF": {
+			content: "\x00",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptBytes([]byte{0, 0})
+			},
+		},
+	})
+}
+
+func TestReaderUntilByte(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[int]{
+		"Base": {
+			content: "5:hello,",
+			afterOp: "5",
+			result:  1,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.UntilByte(':')
+			},
+		},
+		"Binary": {
+			content: "\xff\xfe\x00rest",
+			afterOp: "\xff\xfe",
+			result:  2,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.UntilByte(0)
+			},
+		},
+		"NoMatch": {
+			content: "abc",
+			afterOp: "abc",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.UntilByte(':')
+			},
+		},
+	})
+}
+
+func TestReaderReadN(t *testing.T) {
+	var (
+		lrd     *lexer.Reader
+		payload []byte
+		large   string
+		err     error
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("5:\xff\n\x00ab,"))
+	lrd.AcceptSeq("5:")
+
+	payload, err = lrd.ReadN(5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("\xff\n\x00ab"), payload)
+	assert.Equal(t, lexer.Position{2, 4, 7}, lrd.CurrentPosition())
+	assert.Equal(t, ',', lrd.Peek())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+	assert.Equal(t, "5:", lrd.PeekToken())
+
+	payload, err = lrd.ReadN(7)
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Nil(t, payload)
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+
+	payload, err = lrd.ReadN(-1)
+
+	assert.NoError(t, err)
+	assert.Empty(t, payload)
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+
+	large = strings.Repeat("0123456789", 3000)
+	lrd = lexer.NewReader(strings.NewReader("x" + large + "y"))
+	lrd.Next()
+	lrd.Ignore()
+
+	payload, err = lrd.ReadN(len(large))
+
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(payload))
+	assert.Equal(t, large, lrd.PeekToken())
+	assert.Equal(t, 'y', lrd.Next())
+}
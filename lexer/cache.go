@@ -0,0 +1,121 @@
+package lexer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+)
+
+// Cache stores the tokens of previously lexed inputs, keyed by a hash of
+// the input and of the grammar that lexed it, so that unchanged inputs
+// need not be lexed again. Implementations must be safe for concurrent
+// use. Caching is best effort: a Cache may forget any entry at any time.
+type Cache interface {
+	// Load returns the tokens stored under key. The boolean is false if
+	// there is no such entry.
+	Load(key string) ([]Token, bool)
+
+	// Store records tokens under key, replacing any previous entry.
+	Store(key string, tokens []Token)
+}
+
+// MemoryCache is a Cache that keeps every entry in memory for as long as
+// it is reachable. The zero value is an empty cache ready to use.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]Token
+}
+
+// DirCache is a Cache that keeps one file per entry in a directory, so
+// that cached tokens survive across processes, as for a build tool
+// lexing the same files on every run. Entries that cannot be read or
+// written are treated as missing, as is every entry in builds with
+// the tinygo or lexertiny tag.
+type DirCache struct {
+	dir string
+}
+
+// NewDirCache returns a DirCache storing its entries in dir, which is
+// created on the first Store if it does not exist.
+func NewDirCache(dir string) *DirCache {
+	return &DirCache{dir: dir}
+}
+
+// CachedTokens returns the tokens of src lexed from the start state with
+// the given options, loading them from cache if src was lexed before
+// under the same version, and lexing and storing them otherwise. The
+// tokens are identical to those of a fresh Lexer, positions included.
+//
+// The cache key is derived from version and the contents of src, since
+// state functions and options cannot be compared. Version must
+// therefore identify the grammar and options completely, and change
+// whenever they change.
+func CachedTokens(
+	cache Cache,
+	version string,
+	src []byte,
+	start StateFn,
+	opts ...Option,
+) []Token {
+	var (
+		key    string
+		tokens []Token
+		tok    Token
+		ok     bool
+	)
+
+	key = CacheKey(version, src)
+
+	tokens, ok = cache.Load(key)
+	if ok {
+		return tokens
+	}
+
+	for tok = range NewLexer(bytes.NewReader(src), start, opts...).Tokens() {
+		tokens = append(tokens, tok)
+	}
+
+	cache.Store(key, tokens)
+
+	return tokens
+}
+
+// CacheKey returns the key under which CachedTokens stores the tokens of
+// src lexed by the grammar identified by version: the hexadecimal
+// SHA-256 digest of both.
+func CacheKey(version string, src []byte) string {
+	var digest [sha256.Size]byte
+
+	digest = sha256.Sum256(slices.Concat([]byte(version), []byte{0}, src))
+
+	return hex.EncodeToString(digest[:])
+}
+
+// Load implements Cache. The returned slice is a copy of the entry.
+func (cache *MemoryCache) Load(key string) ([]Token, bool) {
+	var (
+		tokens []Token
+		ok     bool
+	)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	tokens, ok = cache.entries[key]
+
+	return slices.Clone(tokens), ok
+}
+
+// Store implements Cache. The entry is a copy of tokens.
+func (cache *MemoryCache) Store(key string, tokens []Token) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.entries == nil {
+		cache.entries = make(map[string][]Token)
+	}
+
+	cache.entries[key] = slices.Clone(tokens)
+}
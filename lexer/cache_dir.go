@@ -0,0 +1,73 @@
+//go:build !tinygo && !lexertiny
+
+package lexer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Load implements Cache.
+func (cache *DirCache) Load(key string) ([]Token, bool) {
+	var (
+		data   []byte
+		tokens []Token
+		err    error
+	)
+
+	data, err = os.ReadFile(cache.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&tokens)
+	if err != nil {
+		return nil, false
+	}
+
+	return tokens, true
+}
+
+// Store implements Cache. The entry is written to a temporary file that
+// is renamed into place, so that concurrent readers never observe a
+// partial entry.
+func (cache *DirCache) Store(key string, tokens []Token) {
+	var (
+		buf  bytes.Buffer
+		file *os.File
+		err  error
+	)
+
+	err = gob.NewEncoder(&buf).Encode(tokens)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(cache.dir, 0o755)
+	if err != nil {
+		return
+	}
+
+	file, err = os.CreateTemp(cache.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+
+	_, err = file.Write(buf.Bytes())
+	err = errors.Join(err, file.Close())
+
+	if err == nil {
+		err = os.Rename(file.Name(), cache.path(key))
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+}
+
+func (cache *DirCache) path(key string) string {
+	return filepath.Join(cache.dir, key+".tokens")
+}
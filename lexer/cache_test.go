@@ -0,0 +1,144 @@
+package lexer_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCache wraps a Cache and counts its misses.
+type countingCache struct {
+	lexer.Cache
+
+	misses int
+}
+
+func (cache *countingCache) Load(key string) ([]lexer.Token, bool) {
+	var (
+		tokens []lexer.Token
+		ok     bool
+	)
+
+	tokens, ok = cache.Cache.Load(key)
+	if !ok {
+		cache.misses++
+	}
+
+	return tokens, ok
+}
+
+func TestCachedTokens(t *testing.T) {
+	type testData struct {
+		cache lexer.Cache
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Memory": {&lexer.MemoryCache{}},
+	}
+
+	if persistentDirCache {
+		testTbl["Dir"] = testData{
+			lexer.NewDirCache(filepath.Join(t.TempDir(), "cache")),
+		}
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				cache    *countingCache
+				src      []byte
+				expected []lexer.Token
+			)
+
+			cache = &countingCache{Cache: test.cache}
+			src = []byte("ab cd\n?")
+			expected = slices.Collect(lexer.NewLexer(
+				strings.NewReader(string(src)),
+				lexWords,
+			).Tokens())
+
+			assert.Equal(t, expected, lexer.CachedTokens(cache, "v1", src, lexWords))
+			assert.Equal(t, 1, cache.misses)
+
+			assert.Equal(t, expected, lexer.CachedTokens(cache, "v1", src, lexWords))
+			assert.Equal(t, 1, cache.misses)
+
+			lexer.CachedTokens(cache, "v2", src, lexWords)
+			assert.Equal(t, 2, cache.misses)
+
+			lexer.CachedTokens(cache, "v1", []byte("ab cd\n!"), lexWords)
+			assert.Equal(t, 3, cache.misses)
+		})
+	}
+}
+
+func TestMemoryCacheCopies(t *testing.T) {
+	var (
+		cache  lexer.MemoryCache
+		tokens []lexer.Token
+	)
+
+	t.Parallel()
+
+	tokens = []lexer.Token{{Kind: kindWord, Text: "a"}}
+	cache.Store("k", tokens)
+	tokens[0].Text = "changed"
+
+	tokens, _ = cache.Load("k")
+	assert.Equal(t, "a", tokens[0].Text)
+
+	tokens[0].Text = "changed"
+
+	tokens, _ = cache.Load("k")
+	assert.Equal(t, "a", tokens[0].Text)
+}
+
+func TestDirCacheCorrupt(t *testing.T) {
+	var (
+		dir   string
+		cache *lexer.DirCache
+		key   string
+		ok    bool
+	)
+
+	t.Parallel()
+
+	dir = t.TempDir()
+	cache = lexer.NewDirCache(dir)
+	key = lexer.CacheKey("v1", []byte("ab"))
+
+	cache.Store(key, []lexer.Token{{Kind: kindWord, Text: "ab"}})
+
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, key+".tokens"),
+		[]byte("garbage"),
+		0o644,
+	))
+
+	_, ok = cache.Load(key)
+	assert.False(t, ok)
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Len(t, lexer.CacheKey("v1", []byte("ab")), 64)
+	assert.NotEqual(
+		t,
+		lexer.CacheKey("v1", []byte("ab")),
+		lexer.CacheKey("v1a", []byte("b")),
+	)
+}
@@ -0,0 +1,127 @@
+package lexer
+
+import "sync"
+
+// TokenChan delivers the tokens of a Lexer over a channel, from a
+// goroutine that lexes ahead of the consumer. It suits pipelines that
+// lex and parse concurrently or that select over several sources. A
+// TokenChan is started with Lexer.Chan and must eventually be stopped
+// with Stop or Drain, or read until C is closed, so that its goroutine
+// exits.
+type TokenChan struct {
+	// C delivers the tokens in order and is closed once the Lexer is
+	// exhausted or the TokenChan is stopped.
+	C <-chan Token
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// WithChanBuffer sets the capacity of the channel of a TokenChan started
+// with Lexer.Chan, which is unbuffered by default. A larger buffer lets
+// the Lexer run further ahead of a consumer that reads in bursts,
+// trading memory for throughput. Values below zero count as zero.
+func WithChanBuffer(size int) Option {
+	return func(lrd *Reader) {
+		lrd.chanSize = max(size, 0)
+	}
+}
+
+// Chan starts lexing in a new goroutine and returns the TokenChan
+// delivering the tokens. The Lexer must not be used otherwise once Chan
+// is called.
+func (lx *Lexer) Chan() *TokenChan {
+	var (
+		tc     *TokenChan
+		tokens chan Token
+	)
+
+	tokens = make(chan Token, lx.chanSize)
+	tc = &TokenChan{
+		C:    tokens,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go tc.run(lx, tokens)
+
+	return tc
+}
+
+// NextToken receives the next token from C. The boolean is false once C
+// is closed. TokenChan therefore satisfies TokenStream.
+func (tc *TokenChan) NextToken() (Token, bool) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	tok, ok = <-tc.C
+
+	return tok, ok
+}
+
+// TryNext receives the next token from C if one is ready, without
+// blocking, for consumers that poll the Lexer from their own event
+// loop. The first boolean reports whether a token was received, and
+// the second whether C is still open, so that more tokens may follow.
+func (tc *TokenChan) TryNext() (Token, bool, bool) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	select {
+	case tok, ok = <-tc.C:
+		return tok, ok, ok
+	default:
+		return Token{}, false, true
+	}
+}
+
+// Stop abandons lexing and waits for the goroutine to exit, after which
+// C is closed. It is meant for consumers that give up early, such as a
+// parser that stops at the first syntax error. Stop may be called any
+// number of times, including after C was closed.
+func (tc *TokenChan) Stop() {
+	tc.once.Do(func() {
+		close(tc.stop)
+	})
+
+	<-tc.done
+}
+
+// Drain discards the remaining tokens, letting the Lexer run to the end
+// of input, and waits for the goroutine to exit. Unlike Stop, it
+// completes the side effects of lexing, such as profiling, logging, or
+// a read error reported by Err.
+func (tc *TokenChan) Drain() {
+	for range tc.C {
+	}
+
+	<-tc.done
+}
+
+func (tc *TokenChan) run(lx *Lexer, tokens chan<- Token) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	defer close(tc.done)
+	defer close(tokens)
+
+	for {
+		tok, ok = lx.NextToken()
+		if !ok {
+			return
+		}
+
+		select {
+		case tokens <- tok:
+		case <-tc.stop:
+			return
+		}
+	}
+}
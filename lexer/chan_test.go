@@ -0,0 +1,106 @@
+package lexer_test
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenChan(t *testing.T) {
+	var (
+		tc  *lexer.TokenChan
+		tok lexer.Token
+		ok  bool
+	)
+
+	t.Parallel()
+
+	tc = lexer.NewLexer(strings.NewReader("ab cd"), lexWords).Chan()
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		mkToken(kindSpace, " ", lexer.Position{1, 3, 2}, lexer.Position{1, 4, 3}),
+		mkToken(kindWord, "cd", lexer.Position{1, 4, 3}, lexer.Position{1, 6, 5}),
+	}, slices.Collect(lexer.Tokens(tc)))
+
+	tok, ok = tc.NextToken()
+	assert.False(t, ok)
+	assert.Equal(t, lexer.Token{}, tok)
+
+	tc.Stop()
+	tc.Drain()
+}
+
+func TestTokenChanStop(t *testing.T) {
+	var (
+		content string
+		before  int
+		tc      *lexer.TokenChan
+		ok      bool
+		i       int
+	)
+
+	content = strings.Repeat("word ", 10000)
+	before = runtime.NumGoroutine()
+
+	for i = 0; i < 10; i++ {
+		tc = lexer.NewLexer(strings.NewReader(content), lexWords).Chan()
+		tc.NextToken()
+		tc.Stop()
+		tc.Stop()
+
+		_, ok = tc.NextToken()
+		assert.False(t, ok)
+	}
+
+	tc = lexer.NewLexer(strings.NewReader(content), lexWords).Chan()
+	tc.NextToken()
+	tc.Drain()
+
+	for i = 0; i < 1000 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestTokenChanTryNext(t *testing.T) {
+	var (
+		tc       *lexer.TokenChan
+		tok      lexer.Token
+		got      []string
+		ok, open bool
+	)
+
+	t.Parallel()
+
+	tc = lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		lexWords,
+		lexer.WithChanBuffer(8),
+	).Chan()
+
+	for open = true; open; {
+		tok, ok, open = tc.TryNext()
+		if ok {
+			got = append(got, tok.Text)
+		}
+
+		if !ok && open {
+			runtime.Gosched()
+		}
+	}
+
+	assert.Equal(t, []string{"ab", " ", "cd"}, got)
+	assert.Equal(t, 8, cap(tc.C))
+
+	tok, ok, open = tc.TryNext()
+	assert.False(t, ok)
+	assert.False(t, open)
+	assert.Equal(t, lexer.Token{}, tok)
+}
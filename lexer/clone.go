@@ -0,0 +1,164 @@
+package lexer
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// tape shares the input of an io.Reader among Readers cloned from one
+// another. Bytes read from the io.Reader are retained until every clone
+// still in use has consumed them.
+type tape struct {
+	mu      sync.Mutex
+	rd      io.Reader
+	data    []byte
+	base    int
+	err     error
+	readers map[*tapeReader]struct{}
+}
+
+// tapeReader reads a tape from offset off, counted from the point the
+// first clone was made.
+type tapeReader struct {
+	tape *tape
+	off  int
+}
+
+// Clone returns an independent Reader positioned exactly like lrd, with
+// the same current token, history, position, and options. Input is read
+// once and shared by both Readers, as are the bytes already buffered,
+// which are copied only once either Reader needs to read further. This
+// makes Clone cheap enough to fork the Reader at every ambiguous point
+// of a grammar, lex each alternative on its own Reader, and discard the
+// ones that lose.
+//
+// A clone records no events into the Recording of lrd, if any. Bytes
+// read after cloning are retained until every Reader sharing them has
+// consumed them or has been garbage collected, so discarded clones do
+// not pin the rest of the input in memory. The Readers may be used
+// from different goroutines.
+func (lrd *Reader) Clone() *Reader {
+	var (
+		clone *Reader
+		src   *tapeReader
+		ok    bool
+	)
+
+	src, ok = lrd.rd.(*tapeReader)
+	if !ok {
+		src = newTape(lrd.rd).reader(0)
+		lrd.track(src)
+	}
+
+	clone = &Reader{}
+	*clone = *lrd
+	clone.track(src.tape.reader(src.off))
+	clone.history = slices.Clone(lrd.history)
+	clone.values = maps.Clone(lrd.values)
+	clone.recording = nil
+	clone.guard = guard{}
+	clone.shared = true
+	lrd.shared = true
+
+	if lrd.lines != nil {
+		clone.lines = &lineIndex{}
+		*clone.lines = *lrd.lines
+		clone.lines.starts = slices.Clone(lrd.lines.starts)
+		clone.lines.fixes = slices.Clone(lrd.lines.fixes)
+	}
+
+	return clone
+}
+
+func newTape(rd io.Reader) *tape {
+	return &tape{
+		rd:      rd,
+		readers: make(map[*tapeReader]struct{}),
+	}
+}
+
+// reader returns a new tapeReader reading t from off.
+func (t *tape) reader(off int) *tapeReader {
+	var src *tapeReader
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	src = &tapeReader{
+		tape: t,
+		off:  off,
+	}
+
+	t.readers[src] = struct{}{}
+
+	return src
+}
+
+func (t *tape) release(src *tapeReader) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.readers, src)
+	t.trim()
+}
+
+// trim drops the retained bytes every reader has consumed.
+func (t *tape) trim() {
+	var (
+		low int
+		src *tapeReader
+	)
+
+	low = t.base + len(t.data)
+
+	for src = range t.readers {
+		low = min(low, src.off)
+	}
+
+	t.data = t.data[low-t.base:]
+	t.base = low
+}
+
+func (src *tapeReader) Read(buf []byte) (int, error) {
+	var (
+		t   *tape
+		n   int
+		err error
+	)
+
+	t = src.tape
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if src.off < t.base+len(t.data) {
+		n = copy(buf, t.data[src.off-t.base:])
+		src.off += n
+		t.trim()
+
+		return n, nil
+	}
+
+	if t.err != nil {
+		return 0, t.err
+	}
+
+	n, err = t.rd.Read(buf)
+	if n < 0 || n > len(buf) {
+		return n, err
+	}
+
+	t.err = err
+	src.off += n
+
+	if len(t.readers) > 1 {
+		t.data = append(t.data, buf[:n]...)
+		t.trim()
+	} else {
+		t.base += n
+	}
+
+	return n, err
+}
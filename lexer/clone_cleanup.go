@@ -0,0 +1,14 @@
+//go:build !tinygo && !lexertiny
+
+package lexer
+
+import "runtime"
+
+// track makes lrd read from src, releasing src once lrd is unreachable.
+func (lrd *Reader) track(src *tapeReader) {
+	lrd.rd = src
+
+	runtime.AddCleanup(lrd, func(src *tapeReader) {
+		src.tape.release(src)
+	}, src)
+}
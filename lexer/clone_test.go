@@ -0,0 +1,92 @@
+package lexer_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderClone(t *testing.T) {
+	var (
+		lrd, clone *lexer.Reader
+		long       string
+	)
+
+	t.Parallel()
+
+	long = strings.Repeat("abcdefghij", 3000)
+	lrd = lexer.NewReader(
+		iotest.OneByteReader(strings.NewReader("x = " + long + "\ny")),
+	)
+
+	lrd.AcceptSeq("x =")
+	clone = lrd.Clone()
+
+	assert.Equal(t, "x =", clone.PeekToken())
+	assert.Equal(t, lrd.CurrentPosition(), clone.CurrentPosition())
+
+	clone.Accept(" ")
+	clone.AcceptRun("abcdefghij")
+	clone.Accept("\n")
+
+	assert.Equal(t, "x = "+long+"\n", clone.PeekToken())
+	assert.Equal(t, lexer.Position{2, 1, len(long) + 5}, clone.CurrentPosition())
+	assert.Equal(t, 'y', clone.Next())
+
+	assert.Equal(t, 3, lrd.Backup(3))
+	assert.Equal(t, "x = ", string([]rune{lrd.Next(), lrd.Next(), lrd.Next(), lrd.Next()}))
+	lrd.Ignore()
+	assert.Equal(t, len(long), lrd.AcceptRun("abcdefghij"))
+	assert.Equal(t, long, lrd.PeekToken())
+	assert.Equal(t, "\ny", lrd.AcceptRunString("\ny"))
+	assert.True(t, lrd.AtEOF())
+	assert.True(t, clone.AtEOF())
+}
+
+func TestReaderCloneConcurrent(t *testing.T) {
+	var (
+		lrd     *lexer.Reader
+		content string
+		clones  []*lexer.Reader
+		texts   []string
+		wg      sync.WaitGroup
+		i       int
+	)
+
+	t.Parallel()
+
+	content = strings.Repeat("lorem ipsum dolor sit amet\n", 2000)
+	lrd = lexer.NewReader(strings.NewReader(content))
+	lrd.Next()
+
+	for i = 0; i < 4; i++ {
+		clones = append(clones, lrd.Clone())
+	}
+
+	texts = make([]string, len(clones))
+
+	for i = range clones {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			clones[i].UntilFunc(func(rune) bool { return false })
+			texts[i] = clones[i].PeekToken()
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i = range texts {
+		assert.Equal(t, content, texts[i])
+	}
+
+	lrd.UntilFunc(func(rune) bool { return false })
+
+	assert.Equal(t, content, lrd.PeekToken())
+}
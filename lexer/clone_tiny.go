@@ -0,0 +1,10 @@
+//go:build tinygo || lexertiny
+
+package lexer
+
+// track makes lrd read from src. Without runtime.AddCleanup, src is
+// never released, so the tape keeps all the input read since the
+// oldest Reader sharing it was created.
+func (lrd *Reader) track(src *tapeReader) {
+	lrd.rd = src
+}
@@ -0,0 +1,290 @@
+package lexer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Conditional configures the conditional compilation Pass returned by
+// its Pass method, which elides the regions of a token stream disabled
+// by directives such as "#if", "#elif", "#else", and "#endif" in C-like
+// languages, or "{% if %}" and friends in templating languages.
+type Conditional struct {
+	// Directive is the kind of the directive tokens, as produced by the
+	// Directives pass.
+	Directive Kind
+
+	// Prefixes lists the prefixes that introduce a directive, as passed
+	// to ParseDirective, such as "#".
+	Prefixes []string
+
+	// If lists the names of the directives that open a conditional
+	// block, such as "if", "ifdef", and "ifndef".
+	If []string
+
+	// Elif lists the names of the directives that open an alternative
+	// branch with a condition of its own, such as "elif".
+	Elif []string
+
+	// Else lists the names of the directives that open the final
+	// alternative branch, such as "else".
+	Else []string
+
+	// End lists the names of the directives that close a conditional
+	// block, such as "endif".
+	End []string
+
+	// Eval evaluates the condition of an If or Elif directive. It is
+	// only called for directives that are not themselves disabled.
+	Eval func(Directive) bool
+
+	// Skipped is the kind of the tokens standing for disabled regions.
+	Skipped Kind
+}
+
+// condFrame is an open conditional block.
+type condFrame struct {
+	// open is the directive that opened the block.
+	open Token
+
+	// elseDir is the Else directive of the block, if any.
+	elseDir *Token
+
+	// outer reports whether the region enclosing the block is enabled,
+	// active whether the current branch is, and taken whether any
+	// branch so far was.
+	outer, active, taken bool
+}
+
+type condStream struct {
+	cond    Conditional
+	src     TokenStream
+	frames  []condFrame
+	skipped []Token
+	queue   []Token
+	done    bool
+}
+
+// Pass returns a Pass that applies the conditional directives of cond.
+// Tokens in enabled branches are delivered unchanged, and so are the
+// conditional directives that are not inside a disabled region. Each
+// disabled region is replaced by a single trivia token of kind Skipped,
+// whose span covers the region and whose text is the concatenated text
+// of the tokens it replaces, so that the source can still be
+// reconstructed from the stream.
+//
+// An Elif, Else, or End directive without a matching If, a directive
+// other than End following an Else, and a block left open at the end
+// of input are reported with KindError tokens spanning the offending
+// directive.
+func (cond Conditional) Pass() Pass {
+	return func(src TokenStream) TokenStream {
+		return &condStream{
+			cond: cond,
+			src:  src,
+		}
+	}
+}
+
+func (stream *condStream) NextToken() (Token, bool) {
+	var (
+		tok Token
+		ok  bool
+		dir Directive
+	)
+
+	for len(stream.queue) == 0 {
+		if stream.done {
+			return Token{}, false
+		}
+
+		tok, ok = stream.src.NextToken()
+		if !ok {
+			stream.finish()
+
+			continue
+		}
+
+		dir, ok = stream.cond.parse(tok)
+
+		switch {
+		case !ok:
+			stream.deliver(tok, stream.active())
+		case slices.Contains(stream.cond.If, dir.Name):
+			stream.open(tok, dir)
+		case len(stream.frames) == 0:
+			stream.flush()
+			stream.queue = append(stream.queue, condError(
+				tok,
+				"%q without matching conditional",
+				tok.Text,
+			))
+		default:
+			stream.branch(tok, dir)
+		}
+	}
+
+	tok = stream.queue[0]
+	stream.queue = stream.queue[1:]
+
+	return tok, true
+}
+
+// active reports whether the current region is enabled.
+func (stream *condStream) active() bool {
+	return len(stream.frames) == 0 ||
+		stream.frames[len(stream.frames)-1].active
+}
+
+// deliver queues tok if enabled is true, or else adds it to the
+// current disabled region.
+func (stream *condStream) deliver(tok Token, enabled bool) {
+	if !enabled {
+		stream.skipped = append(stream.skipped, tok)
+
+		return
+	}
+
+	stream.flush()
+	stream.queue = append(stream.queue, tok)
+}
+
+// flush queues the token standing for the current disabled region, if
+// any.
+func (stream *condStream) flush() {
+	if len(stream.skipped) == 0 {
+		return
+	}
+
+	stream.queue = append(stream.queue, stream.cond.skipped(stream.skipped))
+	stream.skipped = nil
+}
+
+func (stream *condStream) open(tok Token, dir Directive) {
+	var frame condFrame
+
+	frame.open = tok
+	frame.outer = stream.active()
+	frame.active = frame.outer && stream.cond.Eval(dir)
+	frame.taken = frame.active
+
+	stream.deliver(tok, frame.outer)
+	stream.frames = append(stream.frames, frame)
+}
+
+// branch handles an Elif, Else, or End directive of the innermost open
+// block.
+func (stream *condStream) branch(tok Token, dir Directive) {
+	var (
+		frame *condFrame
+		end   bool
+	)
+
+	frame = &stream.frames[len(stream.frames)-1]
+	end = slices.Contains(stream.cond.End, dir.Name)
+
+	switch {
+	case !frame.outer:
+		stream.deliver(tok, false)
+	case frame.elseDir != nil && !end:
+		stream.flush()
+		stream.queue = append(stream.queue, condError(
+			tok,
+			"%q after %q",
+			tok.Text,
+			frame.elseDir.Text,
+		))
+
+		return
+	case slices.Contains(stream.cond.Elif, dir.Name):
+		stream.deliver(tok, true)
+		frame.active = !frame.taken && stream.cond.Eval(dir)
+		frame.taken = frame.taken || frame.active
+	case slices.Contains(stream.cond.Else, dir.Name):
+		stream.deliver(tok, true)
+		frame.active = !frame.taken
+		frame.taken = true
+		frame.elseDir = &tok
+	default:
+		stream.deliver(tok, true)
+	}
+
+	if end {
+		stream.frames = stream.frames[:len(stream.frames)-1]
+	}
+}
+
+// finish queues what remains at the end of the input stream.
+func (stream *condStream) finish() {
+	var frame condFrame
+
+	stream.done = true
+	stream.flush()
+
+	for _, frame = range stream.frames {
+		stream.queue = append(stream.queue, condError(
+			frame.open,
+			"unterminated %q block",
+			frame.open.Text,
+		))
+	}
+
+	stream.frames = nil
+}
+
+// parse returns the directive held by tok if it is one of the
+// conditional directives of cond.
+func (cond Conditional) parse(tok Token) (Directive, bool) {
+	var (
+		dir Directive
+		ok  bool
+	)
+
+	if tok.Kind != cond.Directive {
+		return Directive{}, false
+	}
+
+	dir, ok = ParseDirective(tok, cond.Prefixes...)
+	if !ok {
+		return Directive{}, false
+	}
+
+	ok = slices.Contains(cond.If, dir.Name) ||
+		slices.Contains(cond.Elif, dir.Name) ||
+		slices.Contains(cond.Else, dir.Name) ||
+		slices.Contains(cond.End, dir.Name)
+
+	return dir, ok
+}
+
+// skipped returns the token standing for the disabled region made of
+// tokens.
+func (cond Conditional) skipped(tokens []Token) Token {
+	var (
+		sb  strings.Builder
+		tok Token
+	)
+
+	for _, tok = range tokens {
+		sb.WriteString(tok.Text)
+	}
+
+	return Token{
+		Kind: cond.Skipped,
+		Text: sb.String(),
+		Span: Span{
+			Start: tokens[0].Span.Start,
+			End:   tokens[len(tokens)-1].Span.End,
+		},
+		Trivia: true,
+	}
+}
+
+func condError(tok Token, format string, args ...any) Token {
+	return Token{
+		Kind: KindError,
+		Text: fmt.Sprintf(format, args...),
+		Span: tok.Span,
+	}
+}
@@ -0,0 +1,130 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexPreprocessed(lx *lexer.Lexer) lexer.StateFn {
+	if lx.Accept("#") {
+		lx.Until("\n")
+		lx.Emit(kindDirective)
+
+		return lexPreprocessed
+	}
+
+	if lexWords(lx) == nil {
+		return nil
+	}
+
+	return lexPreprocessed
+}
+
+func TestConditional(t *testing.T) {
+	var (
+		cond    lexer.Conditional
+		testTbl map[string][]kindText
+		content string
+		tokens  []kindText
+	)
+
+	t.Parallel()
+
+	cond = lexer.Conditional{
+		Directive: kindDirective,
+		Prefixes:  []string{"#"},
+		If:        []string{"if"},
+		Elif:      []string{"elif"},
+		Else:      []string{"else"},
+		End:       []string{"endif"},
+		Eval: func(dir lexer.Directive) bool {
+			return len(dir.Args) > 0 && dir.Args[0] == "1"
+		},
+		Skipped: kindSkipped,
+	}
+
+	testTbl = map[string][]kindText{
+		"a\n#if 1\nb\n#else\nc\n#endif\nd": {
+			{kindWord, "a"},
+			{kindSpace, "\n"},
+			{kindDirective, "#if 1"},
+			{kindSpace, "\n"},
+			{kindWord, "b"},
+			{kindSpace, "\n"},
+			{kindDirective, "#else"},
+			{kindSkipped, "\nc\n"},
+			{kindDirective, "#endif"},
+			{kindSpace, "\n"},
+			{kindWord, "d"},
+		},
+		"#if 0\n#if 1\nx\n#endif\n#elif 1\ny\n#elif 1\nz\n#endif": {
+			{kindDirective, "#if 0"},
+			{kindSkipped, "\n#if 1\nx\n#endif\n"},
+			{kindDirective, "#elif 1"},
+			{kindSpace, "\n"},
+			{kindWord, "y"},
+			{kindSpace, "\n"},
+			{kindDirective, "#elif 1"},
+			{kindSkipped, "\nz\n"},
+			{kindDirective, "#endif"},
+		},
+		"#pragma once\n#endif\n#if 0\n#else\n#else\n": {
+			{kindDirective, "#pragma once"},
+			{kindSpace, "\n"},
+			{lexer.KindError, `"#endif" without matching conditional`},
+			{kindSpace, "\n"},
+			{kindDirective, "#if 0"},
+			{kindSkipped, "\n"},
+			{kindDirective, "#else"},
+			{kindSpace, "\n"},
+			{lexer.KindError, `"#else" after "#else"`},
+			{kindSpace, "\n"},
+			{lexer.KindError, `unterminated "#if 0" block`},
+		},
+	}
+
+	for content, tokens = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			assert.Equal(t, tokens, collectKinds(lexer.Chain(
+				lexer.NewLexer(strings.NewReader(content), lexPreprocessed),
+				cond.Pass(),
+			)))
+		})
+	}
+}
+
+func TestConditionalSpan(t *testing.T) {
+	var (
+		cond   lexer.Conditional
+		tokens []lexer.Token
+		tok    lexer.Token
+	)
+
+	t.Parallel()
+
+	cond = lexer.Conditional{
+		Directive: kindDirective,
+		Prefixes:  []string{"#"},
+		If:        []string{"if"},
+		End:       []string{"endif"},
+		Eval:      func(lexer.Directive) bool { return false },
+		Skipped:   kindSkipped,
+	}
+
+	for tok = range lexer.Tokens(lexer.Chain(
+		lexer.NewLexer(strings.NewReader("#if\nab cd\n#endif"), lexPreprocessed),
+		cond.Pass(),
+	)) {
+		tokens = append(tokens, tok)
+	}
+
+	assert.Len(t, tokens, 3)
+	assert.True(t, tokens[1].Trivia)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{1, 4, 3},
+		End:   lexer.Position{3, 1, 10},
+	}, tokens[1].Span)
+}
@@ -0,0 +1,48 @@
+package lexer
+
+// DebugState is a snapshot of the internal bookkeeping of a Reader, as
+// returned by Reader.DebugState. It lets tests and monitoring assert on
+// buffering behavior, such as how much input a grammar keeps buffered,
+// without depending on unexported fields. Indices are relative to the
+// start of the buffer, which moves as consumed input is discarded.
+type DebugState struct {
+	// Buffered is the number of bytes read from the io.Reader and not
+	// consumed yet, which is Head minus Current.
+	Buffered int
+
+	// Start is the index of the first byte of the current token.
+	Start int
+
+	// Current is the index of the next byte to be consumed.
+	Current int
+
+	// Head is the index one past the last byte read from the io.Reader.
+	Head int
+
+	// BufferOffset is the Offset of the first byte of the buffer, as
+	// positions report it, so that BufferOffset+Current is the Offset of
+	// the current position.
+	BufferOffset int
+
+	// History is the number of runes Backup can restore, as reported
+	// by BackupLimit.
+	History int
+
+	// Capacity is the size of the buffer.
+	Capacity int
+}
+
+// DebugState returns a snapshot of the internal state of the Reader.
+// It is meant for tests and diagnostics, not for driving lexing
+// decisions.
+func (lrd *Reader) DebugState() DebugState {
+	return DebugState{
+		Buffered:     lrd.head - lrd.current,
+		Start:        lrd.start,
+		Current:      lrd.current,
+		Head:         lrd.head,
+		BufferOffset: lrd.currentPos.Offset - lrd.current,
+		History:      len(lrd.history),
+		Capacity:     len(lrd.buf),
+	}
+}
@@ -0,0 +1,50 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderDebugState(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		state lexer.DebugState
+		long  string
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("abc"))
+
+	assert.Equal(t, lexer.DebugState{}, lrd.DebugState())
+
+	lrd.Next()
+	lrd.Ignore()
+	lrd.Next()
+
+	assert.Equal(t, lexer.DebugState{
+		Buffered: 1,
+		Start:    1,
+		Current:  2,
+		Head:     3,
+		History:  1,
+		Capacity: 8192,
+	}, lrd.DebugState())
+
+	long = strings.Repeat("x", 20000)
+	lrd = lexer.NewReader(strings.NewReader(long))
+
+	for lrd.Next() != lexer.EOF {
+		lrd.Ignore()
+	}
+
+	state = lrd.DebugState()
+
+	assert.Equal(t, 0, state.Buffered)
+	assert.Equal(t, len(long), state.BufferOffset+state.Current)
+	assert.Positive(t, state.BufferOffset)
+	assert.LessOrEqual(t, state.Capacity, 16384)
+}
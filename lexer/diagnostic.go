@@ -0,0 +1,189 @@
+package lexer
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// Severity classifies a Diagnostic, from errors that make the input
+// invalid down to hints about style, as in the Language Server
+// Protocol. The zero value is SeverityError.
+type Severity int
+
+const (
+	// SeverityError marks input that is invalid.
+	SeverityError Severity = iota
+
+	// SeverityWarning marks valid input that is likely a mistake, such
+	// as a deprecated escape sequence.
+	SeverityWarning
+
+	// SeverityInfo marks information about the input.
+	SeverityInfo
+
+	// SeverityHint marks a suggestion, such as a simpler spelling.
+	SeverityHint
+)
+
+// Diagnostic is a message about a region of the input, such as a
+// lexical error or a suspicious construct found by an analysis pass.
+type Diagnostic struct {
+	// Span is the region of the input the diagnostic refers to.
+	Span Span
+
+	// Message describes the problem.
+	Message string
+
+	// Severity is the severity of the problem.
+	Severity Severity
+
+	// Fixes lists the corrections offered for the problem, if any.
+	Fixes []Fix
+
+	// Source is the name of the file an Include state lexed the
+	// diagnosed text from, as in Token.Source. It is empty for
+	// diagnostics about the input of the Lexer itself.
+	Source string
+}
+
+// WithDiagnostics makes a Lexer built on the Reader pass the
+// diagnostics reported with Lexer.Report and Lexer.Warnf to handle, in
+// the order they are reported, including those of the child Lexers of
+// Embed, Include, and SubLexer. Without it, they are only logged.
+func WithDiagnostics(handle func(Diagnostic)) Option {
+	return func(lrd *Reader) {
+		lrd.diagnostics = handle
+	}
+}
+
+// String formats the diagnostic as "source:line:column: message" using
+// the start of its span, omitting the source when it is empty, with the
+// severity before the message, as in "line:column: warning: message",
+// unless it is SeverityError.
+func (diag Diagnostic) String() string {
+	var prefix string
+
+	if diag.Source != "" {
+		prefix = diag.Source + ":"
+	}
+
+	if diag.Severity == SeverityError {
+		return fmt.Sprintf(
+			"%s%d:%d: %s",
+			prefix,
+			diag.Span.Start.Line,
+			diag.Span.Start.Column,
+			diag.Message,
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s%d:%d: %s: %s",
+		prefix,
+		diag.Span.Start.Line,
+		diag.Span.Start.Column,
+		diag.Severity,
+		diag.Message,
+	)
+}
+
+// String returns the lower-case name of the severity, such as
+// "warning".
+func (sev Severity) String() string {
+	switch sev {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "Severity(" + strconv.Itoa(int(sev)) + ")"
+	}
+}
+
+// Report reports a diagnostic of the given severity whose message is
+// the formatted text and whose span covers the runes accumulated since
+// the last call to Ignore or Emit. Unlike Errorf, it neither emits a
+// token nor resets the token boundaries, so a state function can flag a
+// construct, such as a deprecated escape sequence, and go on lexing it.
+//
+// The diagnostic goes to the handler set with WithDiagnostics and is
+// logged at the slog level matching its severity. Diagnostics reported
+// by candidates of Speculate are held back, and only those of the
+// committed candidate are reported. Those of the child Lexer of an
+// Embed or Include state are reported through the parent, the ones
+// about an included file carrying its name in Diagnostic.Source.
+func (lx *Lexer) Report(sev Severity, format string, args ...any) {
+	lx.report(lx.diagnostic(sev, format, args...))
+}
+
+// Warnf reports a diagnostic of SeverityWarning, as Report does.
+func (lx *Lexer) Warnf(format string, args ...any) {
+	lx.Report(SeverityWarning, format, args...)
+}
+
+// diagnostic returns the diagnostic reported by Report.
+func (lx *Lexer) diagnostic(
+	sev Severity,
+	format string,
+	args ...any,
+) Diagnostic {
+	return Diagnostic{
+		Span: Span{
+			Start: lx.StartPosition(),
+			End:   lx.CurrentPosition(),
+		},
+		Message:  fmt.Sprintf(format, args...),
+		Severity: sev,
+	}
+}
+
+func (lx *Lexer) report(diag Diagnostic) {
+	if lx.speculative {
+		lx.held = append(lx.held, diag)
+
+		return
+	}
+
+	if lx.parent != nil {
+		if diag.Source == "" && lx.Source() != lx.parent.Source() {
+			diag.Source = lx.Source()
+		}
+
+		lx.parent.report(diag)
+
+		return
+	}
+
+	lx.log(
+		diag.Severity.level(),
+		"lexer diagnostic",
+		slog.String("severity", diag.Severity.String()),
+		slog.String("message", diag.Message),
+		slog.Any("start", diag.Span.Start),
+		slog.Any("end", diag.Span.End),
+	)
+
+	if lx.diagnostics != nil {
+		lx.diagnostics(diag)
+	}
+}
+
+// level returns the slog level diagnostics of the severity are logged
+// at.
+func (sev Severity) level() slog.Level {
+	switch sev {
+	case SeverityError:
+		return slog.LevelError
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
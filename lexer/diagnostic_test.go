@@ -0,0 +1,198 @@
+package lexer_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "3:7: unexpected '!'", lexer.Diagnostic{
+		Span: lexer.Span{
+			Start: lexer.Position{3, 7, 20},
+			End:   lexer.Position{3, 8, 21},
+		},
+		Message: "unexpected '!'",
+	}.String())
+	assert.Equal(t, "1:2: hint: use 'x'", lexer.Diagnostic{
+		Span:     lexer.Span{Start: lexer.Position{1, 2, 1}},
+		Message:  "use 'x'",
+		Severity: lexer.SeverityHint,
+	}.String())
+	assert.Equal(t, "inc/a.conf:1:2: hint: use 'x'", lexer.Diagnostic{
+		Span:     lexer.Span{Start: lexer.Position{1, 2, 1}},
+		Message:  "use 'x'",
+		Severity: lexer.SeverityHint,
+		Source:   "inc/a.conf",
+	}.String())
+	assert.Equal(t, "Severity(7)", lexer.Severity(7).String())
+}
+
+func TestLexerReport(t *testing.T) {
+	var (
+		diags []lexer.Diagnostic
+		lx    *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader(`"a\qb" c`),
+		func(lx *lexer.Lexer) lexer.StateFn {
+			lx.Accept(`"`)
+
+			for !lx.Accept(`"`) {
+				if lx.AcceptSeq(`\q`) {
+					lx.Warnf("deprecated escape sequence %q", `\q`)
+					lx.Report(lexer.SeverityInfo, "string continues")
+				}
+
+				lx.Next()
+			}
+
+			lx.Emit(kindWord)
+
+			return lexWords
+		},
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag)
+		}),
+	)
+
+	assert.Equal(t, []kindText{
+		{kindWord, `"a\qb"`},
+		{kindSpace, " "},
+		{kindWord, "c"},
+	}, collectKinds(lx))
+	assert.Equal(t, []lexer.Diagnostic{
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{1, 1, 0},
+				End:   lexer.Position{1, 5, 4},
+			},
+			Message:  `deprecated escape sequence "\\q"`,
+			Severity: lexer.SeverityWarning,
+		},
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{1, 1, 0},
+				End:   lexer.Position{1, 5, 4},
+			},
+			Message:  "string continues",
+			Severity: lexer.SeverityInfo,
+		},
+	}, diags)
+}
+
+func TestLexerReportChildren(t *testing.T) {
+	var (
+		fsys   fstest.MapFS
+		diags  []string
+		sb     strings.Builder
+		opts   []lexer.Option
+		warn   lexer.StateFn
+		resume lexer.StateFn
+		lx     *lexer.Lexer
+		err    error
+	)
+
+	t.Parallel()
+
+	fsys = fstest.MapFS{
+		"main.txt":  {Data: []byte("ab")},
+		"inc/x.txt": {Data: []byte("cd!")},
+	}
+
+	opts = []lexer.Option{
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag.String())
+		}),
+		lexer.WithLogger(newTestLogger(&sb, slog.LevelError)),
+	}
+
+	warn = func(lx *lexer.Lexer) lexer.StateFn {
+		lx.Warnf("child")
+
+		return lexWords
+	}
+
+	resume = func(lx *lexer.Lexer) lexer.StateFn {
+		lx.Next()
+		lx.Ignore()
+
+		return lexWords
+	}
+
+	collectKinds(lexer.NewLexer(
+		strings.NewReader("ab;cd"),
+		lexer.Embed(warn, ";", resume),
+		opts...,
+	))
+
+	lx, err = lexer.NewLexerFS(fsys, "main.txt", lexer.Include(fsys, "inc/x.txt", warn, lexWords), opts...)
+	assert.NoError(t, err)
+	collectKinds(lx)
+
+	lx = lexer.NewLexer(strings.NewReader("ab"), lexWords, opts...)
+	collectKinds(lx.SubLexer(
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		warn,
+	))
+
+	assert.Equal(t, []string{
+		"1:1: warning: child",
+		"inc/x.txt:1:1: warning: child",
+		"1:1: warning: child",
+	}, diags)
+	assert.Equal(
+		t,
+		`level=ERROR msg="lexer error" error="unexpected \"!\"" `+
+			"start.line=1 start.column=3 start.offset=2 end.line=1 end.column=4 end.offset=3 source=inc/x.txt\n",
+		sb.String(),
+	)
+}
+
+func TestSpeculateReport(t *testing.T) {
+	var (
+		diags   []string
+		results []lexer.Speculation
+		lx      *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("12ab"),
+		lexer.Speculate(
+			func(res []lexer.Speculation) int {
+				results = res
+
+				return 1
+			},
+			func(lx *lexer.Lexer) lexer.StateFn {
+				lx.Warnf("digits")
+
+				return lexDigits
+			},
+			func(lx *lexer.Lexer) lexer.StateFn {
+				lx.Warnf("alnum")
+
+				return lexAlnum
+			},
+		),
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag.Message)
+		}),
+	)
+
+	assert.Equal(t, []kindText{{kindWord, "12ab"}}, collectKinds(lx))
+	assert.Equal(t, []string{"alnum"}, diags)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "digits", results[0].Diagnostics[0].Message)
+}
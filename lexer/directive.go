@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"slices"
+	"strings"
+)
+
+// Directive is a pragma comment, such as "//lint:disable unused" or
+// "#pragma once", split into its parts.
+type Directive struct {
+	// Prefix is the prefix that introduced the directive, such as
+	// "//lint:" or "#pragma".
+	Prefix string
+
+	// Name is the first word following the prefix, such as "disable" or
+	// "once".
+	Name string
+
+	// Args lists the remaining words, split on white space.
+	Args []string
+
+	// Span is the region of the input the directive occupies.
+	Span Span
+}
+
+// Directives returns a Pass that turns pragma comments into directive
+// tokens. A pragma comment is a token whose kind is listed in comments
+// and whose text starts with one of prefixes followed by a name, as
+// recognized by ParseDirective. Its kind is replaced with directive and
+// it is no longer marked as trivia, so that directives reach consumers
+// that skip comments. Other tokens are delivered unchanged.
+//
+// The arguments of a directive token are recovered by passing it to
+// ParseDirective with the same prefixes.
+func Directives(comments []Kind, directive Kind, prefixes ...string) Pass {
+	return Map(func(tok Token) Token {
+		var ok bool
+
+		if !slices.Contains(comments, tok.Kind) {
+			return tok
+		}
+
+		_, ok = ParseDirective(tok, prefixes...)
+		if ok {
+			tok.Kind = directive
+			tok.Trivia = false
+		}
+
+		return tok
+	})
+}
+
+// ParseDirective splits the text of tok into a Directive if it starts
+// with one of prefixes, trying them in order. The text following the
+// prefix is split on white space: the first word is the name and the
+// rest are the arguments. Prefixes are matched exactly, so a directive
+// written as "//lint:" is not recognized in "// lint:".
+//
+// Returns false if no prefix matches or if no name follows the prefix.
+func ParseDirective(tok Token, prefixes ...string) (Directive, bool) {
+	var (
+		prefix string
+		rest   string
+		fields []string
+		ok     bool
+	)
+
+	for _, prefix = range prefixes {
+		rest, ok = strings.CutPrefix(tok.Text, prefix)
+		if !ok {
+			continue
+		}
+
+		fields = strings.Fields(rest)
+		if len(fields) == 0 {
+			return Directive{}, false
+		}
+
+		return Directive{
+			Prefix: prefix,
+			Name:   fields[0],
+			Args:   fields[1:],
+			Span:   tok.Span,
+		}, true
+	}
+
+	return Directive{}, false
+}
@@ -0,0 +1,121 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectives(t *testing.T) {
+	var (
+		kinds []lexer.Kind
+		tok   lexer.Token
+	)
+
+	t.Parallel()
+
+	for tok = range lexer.Tokens(lexer.Chain(
+		lexer.NewLexer(
+			strings.NewReader("//lint:disable a\n// lint:x\n#pragma once\nfoo"),
+			lexComments,
+		),
+		lexer.Filter(func(tok lexer.Token) bool {
+			return tok.Kind != kindSpace
+		}),
+		lexer.Directives(
+			[]lexer.Kind{kindComment},
+			kindDirective,
+			"//lint:",
+			"#pragma",
+		),
+	)) {
+		kinds = append(kinds, tok.Kind)
+
+		if tok.Kind == kindDirective {
+			assert.False(t, tok.Trivia)
+		}
+	}
+
+	assert.Equal(t, []lexer.Kind{
+		kindDirective,
+		kindComment,
+		kindDirective,
+		kindWord,
+	}, kinds)
+}
+
+func TestParseDirective(t *testing.T) {
+	type testData struct {
+		text      string
+		directive lexer.Directive
+		ok        bool
+	}
+
+	var (
+		testTbl  map[string]testData
+		prefixes []string
+		name     string
+		test     testData
+	)
+
+	t.Parallel()
+
+	prefixes = []string{"//lint:", "#pragma", "//go:"}
+	testTbl = map[string]testData{
+		"Base": {
+			text: "//lint:disable unused shadow\n",
+			directive: lexer.Directive{
+				Prefix: "//lint:",
+				Name:   "disable",
+				Args:   []string{"unused", "shadow"},
+			},
+			ok: true,
+		},
+		"NoArgs": {
+			text: "#pragma once",
+			directive: lexer.Directive{
+				Prefix: "#pragma",
+				Name:   "once",
+				Args:   []string{},
+			},
+			ok: true,
+		},
+		"Build": {
+			text: "//go:build linux && amd64",
+			directive: lexer.Directive{
+				Prefix: "//go:",
+				Name:   "build",
+				Args:   []string{"linux", "&&", "amd64"},
+			},
+			ok: true,
+		},
+		"NoName": {
+			text: "#pragma  \n",
+		},
+		"Spaced": {
+			text: "// lint:disable",
+		},
+		"Ordinary": {
+			text: "// a comment",
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				directive lexer.Directive
+				ok        bool
+			)
+
+			directive, ok = lexer.ParseDirective(lexer.Token{
+				Kind: kindComment,
+				Text: test.text,
+			}, prefixes...)
+
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.directive, directive)
+		})
+	}
+}
@@ -1,5 +1,37 @@
 // Package lexer provides primitives for building lexical analyzers over
 // arbitrary input streams. It exposes a Reader type that manages buffered
 // input, position tracking, and token emission, along with helper methods
-// for consuming runes according to user-defined rules.
+// for consuming runes according to user-defined rules. On top of the
+// Reader, the Lexer type drives state functions that classify the input
+// into a stream of Tokens, which can be further rewritten by chaining
+// Passes over the resulting TokenStream.
+//
+// Reader methods follow one convention for empty arguments. A sequence
+// that must match in full, as taken by AcceptSeq, AcceptBytes, UntilSeq,
+// UntilSeqInclusive, and HasPrefix, matches immediately when empty,
+// consuming nothing and leaving the history used by Backup untouched. A
+// set of runes, as taken by Accept, AcceptRun, and Until, matches no
+// rune when empty. Functions for which an empty argument makes no sense,
+// such as AcceptFrontMatter, return ErrEmptyPattern.
+//
+// No exported function or method of the package panics, whatever the
+// input: malformed UTF-8 is reported as utf8.RuneError, indices outside
+// of their input, as passed to Token.SubSpan or Splice, are reported as
+// ErrRange, and failures of the underlying io.Reader, including readers
+// that violate the io.Reader contract, are reported through Reader.Err.
+// Panics raised by user-supplied state functions and predicates
+// propagate unchanged. The only exception is in builds with the
+// lexerdebug tag, where using a Reader or Lexer from several goroutines
+// at once panics.
+//
+// The package builds for js/wasm and wasip1, for lexers embedded in
+// browsers, and avoids what TinyGo does not support. TinyGo builds, and
+// builds with the lexertiny tag, which selects the same code with the
+// standard toolchain so that it can be tested, leave out the features
+// relying on reflection, the operating system, or runtime hooks that
+// TinyGo lacks: WithPprofLabels has no effect, Profile names every state
+// function "state" unless it is labeled with Lexer.ProfileLabel, a
+// DirCache stores nothing, and the input shared by a Reader and its
+// clones is kept until all of them are unreachable rather than released
+// as they advance.
 package lexer // import "github.com/andrieee44/langengine/lexer"
@@ -0,0 +1,94 @@
+package lexer
+
+import (
+	"slices"
+	"strings"
+)
+
+// DocComments returns a Pass that attaches doc comments to the token
+// they document. A doc comment is a token whose kind is listed in
+// comments and whose text starts with one of prefixes, such as "///"
+// and "/**" in Rust-like grammars or "#:" in some scripting languages.
+//
+// Consecutive doc comments form a block, which is attached as the Doc
+// field of the next token that is neither trivia nor a comment, provided
+// that the token starts on the line immediately following the block or
+// on the line where the block ends. A blank line, or an ordinary
+// comment occupying a line of its own, detaches the block, which is then
+// dropped. Every token, doc comments included, is still delivered in
+// order; only the Doc field of the documented tokens is changed.
+func DocComments(comments []Kind, prefixes ...string) Pass {
+	return func(src TokenStream) TokenStream {
+		var docs []Token
+
+		return StreamFunc(func() (Token, bool) {
+			var (
+				tok Token
+				ok  bool
+			)
+
+			tok, ok = src.NextToken()
+			if !ok {
+				return tok, false
+			}
+
+			switch {
+			case slices.Contains(comments, tok.Kind):
+				if !hasAnyPrefix(tok.Text, prefixes) {
+					break
+				}
+
+				if !followsDocs(docs, tok) {
+					docs = nil
+				}
+
+				docs = append(docs, tok)
+			case tok.Trivia:
+				// Whitespace neither attaches nor detaches a block;
+				// line numbers alone decide.
+			default:
+				if followsDocs(docs, tok) {
+					tok.Doc = docs
+				}
+
+				docs = nil
+			}
+
+			return tok, true
+		})
+	}
+}
+
+// followsDocs reports whether tok starts no later than the line after
+// the last line occupied by docs.
+func followsDocs(docs []Token, tok Token) bool {
+	var (
+		last    Token
+		endLine int
+	)
+
+	if len(docs) == 0 {
+		return false
+	}
+
+	last = docs[len(docs)-1]
+	endLine = last.Span.End.Line
+
+	if strings.HasSuffix(last.Text, "\n") {
+		endLine--
+	}
+
+	return tok.Span.Start.Line <= endLine+1
+}
+
+func hasAnyPrefix(text string, prefixes []string) bool {
+	var prefix string
+
+	for _, prefix = range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexComments(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.AcceptRunFunc(unicode.IsSpace) != 0:
+		lx.EmitTrivia(kindSpace)
+	case lx.AcceptSeq("/*"):
+		lx.UntilSeqInclusive("*/")
+		lx.EmitTrivia(kindComment)
+	case lx.AcceptSeq("//"), lx.Accept("#"):
+		lx.UntilInclusive("\n")
+		lx.EmitTrivia(kindComment)
+	case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+		lx.Emit(kindWord)
+	default:
+		return nil
+	}
+
+	return lexComments
+}
+
+func TestDocComments(t *testing.T) {
+	type testData struct {
+		content string
+		docs    map[string][]string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Line": {
+			content: "/// a\n/// b\nfoo bar",
+			docs: map[string][]string{
+				"foo": {"/// a\n", "/// b\n"},
+				"bar": nil,
+			},
+		},
+		"Block": {
+			content: "/** a\n * b\n */\nfoo",
+			docs: map[string][]string{
+				"foo": {"/** a\n * b\n */"},
+			},
+		},
+		"SameLine": {
+			content: "/** a */ foo",
+			docs: map[string][]string{
+				"foo": {"/** a */"},
+			},
+		},
+		"BlankLine": {
+			content: "/// a\n\nfoo",
+			docs: map[string][]string{
+				"foo": nil,
+			},
+		},
+		"BlankLineInBlock": {
+			content: "/// a\n\n/// b\nfoo",
+			docs: map[string][]string{
+				"foo": {"/// b\n"},
+			},
+		},
+		"OrdinaryComment": {
+			content: "// a\n/// b\n// c\nfoo",
+			docs: map[string][]string{
+				"foo": nil,
+			},
+		},
+		"TrailingOrdinaryComment": {
+			content: "/** a */ // b\nfoo",
+			docs: map[string][]string{
+				"foo": {"/** a */"},
+			},
+		},
+		"OtherPrefix": {
+			content: "#: a\nfoo",
+			docs: map[string][]string{
+				"foo": nil,
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				docs map[string][]string
+				tok  lexer.Token
+				doc  lexer.Token
+			)
+
+			docs = make(map[string][]string)
+
+			for tok = range lexer.Tokens(lexer.Chain(
+				lexer.NewLexer(strings.NewReader(test.content), lexComments),
+				lexer.DocComments([]lexer.Kind{kindComment}, "///", "/**"),
+			)) {
+				if tok.Kind != kindWord {
+					assert.Nil(t, tok.Doc)
+
+					continue
+				}
+
+				docs[tok.Text] = nil
+
+				for _, doc = range tok.Doc {
+					docs[tok.Text] = append(docs[tok.Text], doc.Text)
+				}
+			}
+
+			assert.Equal(t, test.docs, docs)
+		})
+	}
+}
+
+func TestDocCommentsSpan(t *testing.T) {
+	var (
+		tokens []lexer.Token
+		tok    lexer.Token
+	)
+
+	t.Parallel()
+
+	for tok = range lexer.Tokens(lexer.Chain(
+		lexer.NewLexer(strings.NewReader("  #: a\nfoo"), lexComments),
+		lexer.DocComments([]lexer.Kind{kindComment}, "#:"),
+	)) {
+		tokens = append(tokens, tok)
+	}
+
+	assert.Equal(t, []lexer.Token{
+		{
+			Kind: kindComment,
+			Text: "#: a\n",
+			Span: lexer.Span{
+				Start: lexer.Position{1, 3, 2},
+				End:   lexer.Position{2, 1, 7},
+			},
+			Trivia: true,
+		},
+	}, tokens[2].Doc)
+}
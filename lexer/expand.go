@@ -0,0 +1,123 @@
+package lexer
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// maxExpandDepth bounds how deeply the Expand pass nests expansions.
+	maxExpandDepth = 64
+
+	// maxExpandTokens bounds the number of replacement tokens the Expand
+	// pass produces for a stream.
+	maxExpandTokens = 1 << 20
+)
+
+// expansion is a token pending in the Expand pass, with the texts of
+// the tokens whose expansions produced it.
+type expansion struct {
+	tok    Token
+	depth  int
+	hidden []string
+}
+
+// Expand returns a Pass that expands tokens, as the preprocessors of
+// C-like languages expand macros. Every token for which match returns
+// true is replaced by the tokens returned by expand, which may be
+// taken from a table of definitions or lexed from new text, and may be
+// empty to delete the token. Other tokens are delivered unchanged.
+//
+// The replacement tokens are rescanned, so that a replacement may
+// contain tokens that expand in turn. Each carries in Origin the span
+// of the token in the input that the outermost expansion started from,
+// so diagnostics can point at the invocation. As in the C
+// preprocessor, a token produced by the expansion of a token with the
+// same text, such as a macro referring to itself directly or through
+// others, is delivered unchanged rather than expanded again.
+//
+// Expansion nested more than 64 levels deep, or producing more than
+// 1048576 replacement tokens for the stream, as a chain of macros each
+// referring twice to the next would, is reported with a KindError token
+// in place of the token that would expand further.
+func Expand(match func(Token) bool, expand func(Token) []Token) Pass {
+	return func(src TokenStream) TokenStream {
+		var (
+			// pending is a stack of the tokens still to rescan, the
+			// next one last.
+			pending  []expansion
+			produced int
+		)
+
+		return StreamFunc(func() (Token, bool) {
+			var (
+				item   expansion
+				origin *Span
+				hidden []string
+				toks   []Token
+				tok    Token
+				ok     bool
+				i      int
+			)
+
+			for {
+				if len(pending) > 0 {
+					item = pending[len(pending)-1]
+					pending = pending[:len(pending)-1]
+				} else {
+					tok, ok = src.NextToken()
+					if !ok {
+						return Token{}, false
+					}
+
+					item = expansion{tok: tok}
+				}
+
+				if !match(item.tok) || slices.Contains(item.hidden, item.tok.Text) {
+					return item.tok, true
+				}
+
+				if item.depth >= maxExpandDepth {
+					return expandError(item.tok, "expansion nested too deeply"), true
+				}
+
+				// Once over budget, no token is expanded anymore.
+				if produced <= maxExpandTokens {
+					toks = expand(item.tok)
+					produced += len(toks)
+				}
+
+				if produced > maxExpandTokens {
+					return expandError(item.tok, fmt.Sprintf(
+						"expansion produces more than %d tokens",
+						maxExpandTokens,
+					)), true
+				}
+
+				origin = item.tok.Origin
+				if origin == nil {
+					origin = new(Span)
+					*origin = item.tok.Span
+				}
+
+				hidden = append(slices.Clip(item.hidden), item.tok.Text)
+
+				for i = len(toks) - 1; i >= 0; i-- {
+					tok = toks[i]
+					tok.Origin = origin
+					pending = append(pending, expansion{tok, item.depth + 1, hidden})
+				}
+			}
+		})
+	}
+}
+
+// expandError returns the KindError token reporting msg in place of tok.
+func expandError(tok Token, msg string) Token {
+	return Token{
+		Kind:   KindError,
+		Text:   msg,
+		Span:   tok.Span,
+		Origin: tok.Origin,
+	}
+}
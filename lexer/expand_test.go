@@ -0,0 +1,139 @@
+package lexer_test
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	var (
+		macros map[string]string
+		pass   lexer.Pass
+		tokens []lexer.Token
+		origin lexer.Span
+		texts  []string
+		tok    lexer.Token
+		src    string
+		want   []string
+	)
+
+	t.Parallel()
+
+	macros = map[string]string{
+		"greet": "hello name",
+		"name":  "world",
+		"none":  "",
+		"loop":  "loop",
+		"twice": "twice twice",
+		"ping":  "pong",
+		"pong":  "ping ping",
+	}
+
+	pass = lexer.Expand(func(tok lexer.Token) bool {
+		var ok bool
+
+		_, ok = macros[tok.Text]
+
+		return ok
+	}, func(tok lexer.Token) []lexer.Token {
+		return slices.Collect(lexer.NewLexer(
+			strings.NewReader(macros[tok.Text]),
+			lexWords,
+		).Tokens())
+	})
+
+	tokens = slices.Collect(lexer.Tokens(lexer.Chain(
+		lexer.NewLexer(strings.NewReader("a greet none b"), lexWords),
+		pass,
+	)))
+
+	for _, tok = range tokens {
+		texts = append(texts, tok.Text)
+	}
+
+	assert.Equal(t, []string{"a", " ", "hello", " ", "world", " ", " ", "b"}, texts)
+	assert.Nil(t, tokens[0].Origin)
+
+	origin = lexer.Span{
+		Start: lexer.Position{1, 3, 2},
+		End:   lexer.Position{1, 8, 7},
+	}
+
+	assert.Equal(t, &origin, tokens[2].Origin)
+	assert.Equal(t, &origin, tokens[4].Origin)
+	assert.Equal(t, lexer.Position{1, 1, 0}, tokens[4].Span.Start)
+	assert.Nil(t, tokens[7].Origin)
+
+	for src, want = range map[string][]string{
+		"loop":  {"loop"},
+		"twice": {"twice", " ", "twice"},
+		"ping":  {"ping", " ", "ping"},
+	} {
+		texts = nil
+
+		for tok = range lexer.Tokens(lexer.Chain(
+			lexer.NewLexer(strings.NewReader(src), lexWords),
+			pass,
+		)) {
+			assert.NotNil(t, tok.Origin, src)
+			texts = append(texts, tok.Text)
+		}
+
+		assert.Equal(t, want, texts, src)
+	}
+}
+
+func TestExpandLimits(t *testing.T) {
+	var (
+		testTbl map[string]string
+		expand  func(lexer.Token) []lexer.Token
+		src     string
+		msg     string
+		tok     lexer.Token
+		last    lexer.Token
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]string{
+		"d0": "expansion nested too deeply",
+		"w0": "expansion produces more than 1048576 tokens",
+	}
+
+	// Level n of a chain expands to level n+1, once for d and twice for
+	// w, whose expansion doubles at every level.
+	expand = func(tok lexer.Token) []lexer.Token {
+		var (
+			next  lexer.Token
+			level int
+		)
+
+		level, _ = strconv.Atoi(tok.Text[1:])
+		next = lexer.Token{Kind: kindWord, Text: tok.Text[:1] + strconv.Itoa(level+1)}
+
+		if tok.Text[0] == 'w' {
+			return []lexer.Token{next, next}
+		}
+
+		return []lexer.Token{next}
+	}
+
+	for src, msg = range testTbl {
+		for tok = range lexer.Tokens(lexer.Chain(
+			lexer.NewLexer(strings.NewReader(src), lexAlnum),
+			lexer.Expand(func(tok lexer.Token) bool {
+				return tok.Kind == kindWord
+			}, expand),
+		)) {
+			last = tok
+		}
+
+		assert.Equal(t, lexer.KindError, last.Kind, src)
+		assert.Equal(t, msg, last.Text, src)
+	}
+}
@@ -0,0 +1,118 @@
+package lexer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrEditOverlap is returned by ApplyEdits for edits whose spans
+// overlap.
+var ErrEditOverlap = errors.New("lexer: overlapping edits")
+
+// Edit replaces a region of the source with new text. An edit with an
+// empty span inserts text; an edit with empty text deletes the span.
+type Edit struct {
+	// Span is the region replaced. Only the offsets of its positions
+	// are used to apply the edit.
+	Span Span
+
+	// NewText is the text replacing the region.
+	NewText string
+}
+
+// Fix is a machine-applicable correction attached to a Diagnostic, such
+// as replacing "=!" with "!=", offered to users as a quick fix by
+// editors.
+type Fix struct {
+	// Title describes the fix, such as "replace with \"!=\"".
+	Title string
+
+	// Edits are the changes making up the fix, to be applied together.
+	Edits []Edit
+}
+
+// ReplaceToken returns the Edit replacing the runes accumulated since
+// the last call to Ignore or Emit with text, for building a Fix of the
+// current token.
+func (lrd *Reader) ReplaceToken(text string) Edit {
+	return Edit{
+		Span:    Span{Start: lrd.StartPosition(), End: lrd.CurrentPosition()},
+		NewText: text,
+	}
+}
+
+// ReportFix reports a diagnostic as Report does, offering fix as a
+// correction for it.
+func (lx *Lexer) ReportFix(
+	sev Severity,
+	fix Fix,
+	format string,
+	args ...any,
+) {
+	var diag Diagnostic
+
+	diag = lx.diagnostic(sev, format, args...)
+	diag.Fixes = []Fix{fix}
+
+	lx.report(diag)
+}
+
+// Apply returns a copy of src with the edits of the fix applied, as
+// ApplyEdits does.
+func (fix Fix) Apply(src []byte) ([]byte, error) {
+	return ApplyEdits(src, fix.Edits...)
+}
+
+// ApplyEdits returns a copy of src with edits applied, in the manner of
+// applying a Fix or the fixes of several diagnostics at once. The edits
+// may be given in any order, and their spans refer to src as it was
+// before any edit. Edits inserting at the same offset are applied in
+// the order given.
+//
+// Returns an error wrapping ErrRange if a span lies outside src or ends
+// before it starts, or ErrEditOverlap if two spans overlap, leaving src
+// unchanged.
+func ApplyEdits(src []byte, edits ...Edit) ([]byte, error) {
+	var (
+		sorted []Edit
+		out    bytes.Buffer
+		edit   Edit
+		last   int
+		i      int
+	)
+
+	sorted = slices.Clone(edits)
+	slices.SortStableFunc(sorted, func(a, b Edit) int {
+		return a.Span.Start.Offset - b.Span.Start.Offset
+	})
+
+	for i, edit = range sorted {
+		if edit.Span.Start.Offset < 0 ||
+			edit.Span.End.Offset < edit.Span.Start.Offset ||
+			edit.Span.End.Offset > len(src) {
+			return nil, fmt.Errorf(
+				"%w: [%d:%d] of %d bytes",
+				ErrRange,
+				edit.Span.Start.Offset,
+				edit.Span.End.Offset,
+				len(src),
+			)
+		}
+
+		if i > 0 && edit.Span.Start.Offset < sorted[i-1].Span.End.Offset {
+			return nil, ErrEditOverlap
+		}
+	}
+
+	for _, edit = range sorted {
+		out.Write(src[last:edit.Span.Start.Offset])
+		out.WriteString(edit.NewText)
+		last = edit.Span.End.Offset
+	}
+
+	out.Write(src[last:])
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,137 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func edit(start, end int, text string) lexer.Edit {
+	return lexer.Edit{
+		Span: lexer.Span{
+			Start: lexer.Position{Offset: start},
+			End:   lexer.Position{Offset: end},
+		},
+		NewText: text,
+	}
+}
+
+// lexOperators lexes like lexWords, suggesting a fix for every "=!".
+func lexOperators(lx *lexer.Lexer) lexer.StateFn {
+	if lx.AcceptSeq("=!") {
+		lx.ReportFix(
+			lexer.SeverityWarning,
+			lexer.Fix{
+				Title: `replace with "!="`,
+				Edits: []lexer.Edit{lx.ReplaceToken("!=")},
+			},
+			"did you mean %q?",
+			"!=",
+		)
+		lx.Emit(kindWord)
+
+		return lexOperators
+	}
+
+	if lexWords(lx) == nil {
+		return nil
+	}
+
+	return lexOperators
+}
+
+func TestApplyEdits(t *testing.T) {
+	type testData struct {
+		edits []lexer.Edit
+		want  string
+		err   error
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		got     []byte
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"none": {
+			want: "a =! b",
+		},
+		"replace": {
+			edits: []lexer.Edit{edit(2, 4, "!=")},
+			want:  "a != b",
+		},
+		"unordered": {
+			edits: []lexer.Edit{edit(5, 6, "c"), edit(0, 1, "x"), edit(2, 2, "(")},
+			want:  "x (=! c",
+		},
+		"same insertion point": {
+			edits: []lexer.Edit{edit(6, 6, ")"), edit(6, 6, ";")},
+			want:  "a =! b);",
+		},
+		"delete adjacent": {
+			edits: []lexer.Edit{edit(1, 2, ""), edit(2, 4, "")},
+			want:  "a b",
+		},
+		"overlap": {
+			edits: []lexer.Edit{edit(0, 3, ""), edit(2, 4, "")},
+			err:   lexer.ErrEditOverlap,
+		},
+		"past end": {
+			edits: []lexer.Edit{edit(5, 7, "")},
+			err:   lexer.ErrRange,
+		},
+		"reversed": {
+			edits: []lexer.Edit{edit(3, 2, "")},
+			err:   lexer.ErrRange,
+		},
+	}
+
+	for name, test = range testTbl {
+		got, err = lexer.ApplyEdits([]byte("a =! b"), test.edits...)
+
+		assert.ErrorIs(t, err, test.err, name)
+
+		if test.err == nil {
+			assert.Equal(t, test.want, string(got), name)
+		}
+	}
+	_, err = lexer.ApplyEdits([]byte("a =! b"), edit(3, 2, ""))
+	assert.EqualError(t, err, "lexer: index out of range: [3:2] of 6 bytes")
+}
+
+func TestLexerReportFix(t *testing.T) {
+	var (
+		src   string
+		diags []lexer.Diagnostic
+		lx    *lexer.Lexer
+		fixed []byte
+		err   error
+	)
+
+	t.Parallel()
+
+	src = "a =! b"
+	lx = lexer.NewLexer(
+		strings.NewReader(src),
+		lexOperators,
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag)
+		}),
+	)
+
+	collectKinds(lx)
+
+	assert.Len(t, diags, 1)
+	assert.Equal(t, `1:3: warning: did you mean "!="?`, diags[0].String())
+
+	fixed, err = diags[0].Fixes[0].Apply([]byte(src))
+	assert.NoError(t, err)
+	assert.Equal(t, "a != b", string(fixed))
+}
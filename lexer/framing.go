@@ -0,0 +1,182 @@
+package lexer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrLineTooLong is returned by AcceptLine when a line exceeds the
+// maximum length allowed by the caller.
+var ErrLineTooLong = errors.New("lexer: line too long")
+
+// AcceptLine consumes a line of a network text protocol such as SMTP,
+// IMAP, or HTTP headers, up to and including its terminator, which is a
+// line feed optionally preceded by a carriage return. It returns the
+// line without the terminator. A carriage return that is not followed
+// by a line feed is part of the line.
+//
+// A positive limit caps the length of the line in bytes, excluding the
+// terminator, and also bounds how much input is buffered while looking
+// for the terminator; a limit of zero or less means no limit. The line is
+// consumed as a single unit: Backup(1) restores the position preceding
+// the whole line.
+//
+// On error, the Reader is left untouched. Returns ErrLineTooLong if the
+// line exceeds limit, io.EOF if the input ended before any byte of the
+// line, io.ErrUnexpectedEOF if it ended before the terminator, or the
+// error of the underlying io.Reader if it fails first.
+func (lrd *Reader) AcceptLine(limit int) (string, error) {
+	var (
+		begin int
+		end   int
+		idx   int
+		line  []byte
+		char  rune
+		size  int
+		err   error
+	)
+
+	lrd.save()
+
+	for {
+		lrd.fill()
+		begin = lrd.history[len(lrd.history)-1].current
+
+		idx = bytes.IndexByte(lrd.buf[lrd.current:lrd.head], '\n')
+		if idx >= 0 {
+			end = lrd.current + idx + 1
+
+			break
+		}
+
+		if lrd.head == lrd.current {
+			err = lrd.lineErr(lrd.current == begin)
+			lrd.Backup(1)
+
+			return "", err
+		}
+
+		lrd.current = lrd.head
+
+		if limit > 0 && lrd.current-begin > limit+1 {
+			lrd.Backup(1)
+
+			return "", ErrLineTooLong
+		}
+	}
+
+	line = lrd.buf[begin : end-1]
+	line = bytes.TrimSuffix(line, []byte("\r"))
+
+	if limit > 0 && len(line) > limit {
+		lrd.Backup(1)
+
+		return "", ErrLineTooLong
+	}
+
+	lrd.current = begin
+
+	for lrd.current < end {
+		char, size = utf8.DecodeRune(lrd.buf[lrd.current:end])
+		lrd.move(char, size)
+	}
+
+	lrd.record("bytes", 0, end-begin)
+
+	return string(line), nil
+}
+
+// Lines returns an iterator over the remaining lines of input, for
+// line-oriented formats such as diffs and logs that need no grammar. It
+// yields every line without its terminator, which is a line feed
+// optionally preceded by a carriage return, together with the Position
+// of its first rune. Lines are counted as for positions, so Line of the
+// yielded Position increases by one from a line to the next. A final
+// line without a terminator is yielded unless it is empty.
+//
+// Runes consumed but not yet emitted when iteration starts are
+// discarded as by Ignore. Every line is consumed as it is yielded, so
+// stopping the iteration early leaves the Reader at the start of the
+// following line. As after Next returns EOF, check Err once the
+// iteration is over.
+func (lrd *Reader) Lines() iter.Seq2[string, Position] {
+	return func(yield func(string, Position) bool) {
+		var (
+			line string
+			pos  Position
+			ok   bool
+		)
+
+		lrd.Ignore()
+
+		for !lrd.AtEOF() {
+			lrd.skipLine()
+			line, pos = lrd.Emit()
+
+			line, ok = strings.CutSuffix(line, "\n")
+			if ok {
+				line = strings.TrimSuffix(line, "\r")
+			}
+
+			if !yield(line, pos) {
+				return
+			}
+		}
+	}
+}
+
+// skipLine consumes the input from the start of the current token up
+// to and including the next line feed, or to the end of input. The
+// buffer is scanned for the line feed directly, so that a NUL byte is
+// consumed like any other rather than mistaken for the end of input.
+func (lrd *Reader) skipLine() {
+	var (
+		end  int
+		idx  int
+		char rune
+		size int
+	)
+
+	for {
+		lrd.fill()
+
+		idx = bytes.IndexByte(lrd.buf[lrd.current:lrd.head], '\n')
+		if idx >= 0 {
+			end = lrd.current + idx + 1
+
+			break
+		}
+
+		if lrd.head == lrd.current {
+			end = lrd.head
+
+			break
+		}
+
+		lrd.current = lrd.head
+	}
+
+	lrd.current = lrd.start
+
+	for lrd.current < end {
+		char, size = utf8.DecodeRune(lrd.buf[lrd.current:end])
+		lrd.move(char, size)
+	}
+
+	lrd.record("bytes", 0, end-lrd.start)
+}
+
+func (lrd *Reader) lineErr(empty bool) error {
+	switch {
+	case lrd.err == io.EOF && empty:
+		return io.EOF
+	case lrd.err == nil || lrd.err == io.EOF:
+		return io.ErrUnexpectedEOF
+	default:
+		return lrd.err
+	}
+}
@@ -0,0 +1,179 @@
+package lexer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+func acceptLine(limit int) func(*lexer.Reader) lineResult {
+	return func(lrd *lexer.Reader) lineResult {
+		var result lineResult
+
+		result.line, result.err = lrd.AcceptLine(limit)
+
+		return result
+	}
+}
+
+func TestReaderAcceptLine(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[lineResult]{
+		"CRLF": {
+			content: "EHLO example.com\r\nMAIL",
+			afterOp: "EHLO example.com\r\n",
+			result:  lineResult{line: "EHLO example.com"},
+			op:      acceptLine(0),
+		},
+		"LF": {
+			content: "a: b\nc",
+			afterOp: "a: b\n",
+			result:  lineResult{line: "a: b"},
+			op:      acceptLine(4),
+		},
+		"Empty": {
+			content: "\r\nrest",
+			afterOp: "\r\n",
+			result:  lineResult{line: ""},
+			op:      acceptLine(0),
+		},
+		"BareCR": {
+			content: "a\rb\n",
+			afterOp: "a\rb\n",
+			result:  lineResult{line: "a\rb"},
+			op:      acceptLine(0),
+		},
+		"TooLong": {
+			content: "abcde\r\n",
+			afterOp: "",
+			result:  lineResult{err: lexer.ErrLineTooLong},
+			op:      acceptLine(4),
+		},
+		"TooLongUnterminated": {
+			content: strings.Repeat("a", 10000),
+			afterOp: "",
+			result:  lineResult{err: lexer.ErrLineTooLong},
+			op:      acceptLine(100),
+		},
+		"Unterminated": {
+			content: "abc",
+			afterOp: "",
+			result:  lineResult{err: io.ErrUnexpectedEOF},
+			op:      acceptLine(0),
+		},
+		"EOF": {
+			content: "",
+			afterOp: "",
+			result:  lineResult{err: io.EOF},
+			op:      acceptLine(0),
+		},
+	})
+}
+
+func TestReaderAcceptLineStream(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		lines []string
+		line  string
+		long  string
+		err   error
+	)
+
+	t.Parallel()
+
+	long = strings.Repeat("x", 10000)
+	lrd = lexer.NewReader(strings.NewReader("a\r\n中\n" + long + "\n"))
+
+	for {
+		line, err = lrd.AcceptLine(0)
+		if err != nil {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"a", "中", long}, lines)
+	assert.Equal(t, lexer.Position{4, 1, 10008}, lrd.CurrentPosition())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, lexer.Position{3, 1, 7}, lrd.CurrentPosition())
+}
+
+func TestReaderLines(t *testing.T) {
+	type line struct {
+		text string
+		pos  lexer.Position
+	}
+
+	var (
+		lrd   *lexer.Reader
+		lines []line
+		text  string
+		pos   lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("a b\r\n\nc\r"))
+
+	for text, pos = range lrd.Lines() {
+		lines = append(lines, line{text, pos})
+	}
+
+	assert.Equal(t, []line{
+		{"a b", lexer.Position{Line: 1, Column: 1}},
+		{"", lexer.Position{Line: 2, Column: 1, Offset: 5}},
+		{"c\r", lexer.Position{Line: 3, Column: 1, Offset: 6}},
+	}, lines)
+	assert.Equal(t, io.EOF, lrd.Err())
+
+	lrd = lexer.NewReader(strings.NewReader("x\ny\nz"))
+	lrd.Next()
+
+	for text = range lrd.Lines() {
+		assert.Equal(t, "", text)
+
+		break
+	}
+
+	assert.Equal(t, "y\n", lrd.AcceptRunString("y\n"))
+
+	lrd = lexer.NewReader(strings.NewReader("a\x00b\nc\n\x00"))
+	lines = nil
+
+	for text, pos = range lrd.Lines() {
+		lines = append(lines, line{text, pos})
+	}
+
+	assert.Equal(t, []line{
+		{"a\x00b", lexer.Position{Line: 1, Column: 1}},
+		{"c", lexer.Position{Line: 2, Column: 1, Offset: 4}},
+		{"\x00", lexer.Position{Line: 3, Column: 1, Offset: 6}},
+	}, lines)
+
+	text = strings.Repeat("\x00é", 5000)
+	lrd = lexer.NewReader(iotest.HalfReader(strings.NewReader(text + "\nz")))
+	lines = nil
+
+	for text, pos = range lrd.Lines() {
+		lines = append(lines, line{text, pos})
+	}
+
+	assert.Equal(t, []line{
+		{strings.Repeat("\x00é", 5000), lexer.Position{Line: 1, Column: 1}},
+		{"z", lexer.Position{Line: 2, Column: 1, Offset: 15001}},
+	}, lines)
+}
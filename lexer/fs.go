@@ -0,0 +1,120 @@
+package lexer
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// NewReaderFS constructs and returns a new Reader over the file name of
+// fsys, such as an embed.FS or a testing/fstest.MapFS, with its source
+// set to name. The file is read whole before the Reader is returned,
+// so no file is left open however much of it is lexed.
+//
+// Returns the error of fsys if the file cannot be read.
+func NewReaderFS(fsys fs.FS, name string, opts ...Option) (*Reader, error) {
+	var (
+		lrd  *Reader
+		data []byte
+		err  error
+	)
+
+	data, err = fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	lrd = NewReader(bytes.NewReader(data), opts...)
+	lrd.SetSource(name)
+
+	return lrd, nil
+}
+
+// NewLexerFS constructs and returns a new Lexer over the file name of
+// fsys, starting in the given state. It is to NewLexer what NewReaderFS
+// is to NewReader.
+//
+// Returns the error of fsys if the file cannot be read.
+func NewLexerFS(
+	fsys fs.FS,
+	name string,
+	start StateFn,
+	opts ...Option,
+) (*Lexer, error) {
+	var (
+		lrd *Reader
+		err error
+	)
+
+	lrd, err = NewReaderFS(fsys, name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLexer(lrd, start), nil
+}
+
+// Include returns a state that lexes the file name of fsys in place, as
+// for the include directives of C, Make, or many configuration
+// formats. A state typically returns Include right after emitting the
+// directive. A name that does not start with a slash is resolved
+// relative to the directory of the current source, as set by
+// NewReaderFS or SetSource.
+//
+// The file is lexed by a child Lexer starting in the start state, with
+// the resolved name as its source and the whitespace profile of the
+// parent, and every token it emits is delivered through the parent in
+// order. Positions of the included tokens are relative to the included
+// file, whose resolved name each carries in Token.Source so that
+// diagnostics can tell the files apart; tokens of nested includes carry
+// the innermost file. Once the child stops, the parent resumes in the
+// resume state. Includes may nest.
+//
+// If the file cannot be read, or includes itself directly or through
+// other files, Include emits a KindError token describing the problem
+// and resumes in the resume state without lexing the file.
+func Include(fsys fs.FS, name string, start StateFn, resume StateFn) StateFn {
+	return func(lx *Lexer) StateFn {
+		var (
+			resolved string
+			lrd      *Reader
+			child    *Lexer
+			err      error
+		)
+
+		resolved = resolveInclude(lx.Source(), name)
+
+		if resolved == lx.Source() || slices.Contains(lx.includes, resolved) {
+			lx.Errorf(
+				"include cycle: %s -> %s",
+				strings.Join(append(slices.Clone(lx.includes), lx.Source()), " -> "),
+				resolved,
+			)
+
+			return resume
+		}
+
+		lrd, err = NewReaderFS(fsys, resolved, lx.inherit)
+		if err != nil {
+			lx.Errorf("include %q: %v", name, err)
+
+			return resume
+		}
+
+		child = newLexer(lrd, start)
+		child.includes = append(slices.Clone(lx.includes), lx.Source())
+
+		return embedded(child, resolved, resume)
+	}
+}
+
+// resolveInclude returns the path of name included from source.
+func resolveInclude(source, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return path.Clean(strings.TrimPrefix(name, "/"))
+	}
+
+	return path.Join(path.Dir(source), name)
+}
@@ -0,0 +1,173 @@
+package lexer_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+var includeFS = fstest.MapFS{
+	"main.conf":       {Data: []byte("a @inc/b.conf z")},
+	"inc/b.conf":      {Data: []byte("b @c.conf\nb")},
+	"inc/c.conf":      {Data: []byte("c")},
+	"loop/x.conf":     {Data: []byte("@y.conf")},
+	"loop/y.conf":     {Data: []byte("@/loop/x.conf")},
+	"missing.conf":    {Data: []byte("@nowhere.conf m")},
+	"zero/based.conf": {Data: []byte("@../inc/c.conf")},
+}
+
+// lexIncludes lexes words and spaces, and includes the file named by
+// every "@name" directive.
+func lexIncludes(lx *lexer.Lexer) lexer.StateFn {
+	var name string
+
+	switch {
+	case lx.Accept("@"):
+		lx.AcceptRunFunc(func(char rune) bool {
+			return !unicode.IsSpace(char)
+		})
+
+		name = strings.TrimPrefix(lx.PeekToken(), "@")
+		lx.Emit(kindDirective)
+
+		return lexer.Include(includeFS, name, lexIncludes, lexIncludes)
+	case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+		lx.Emit(kindWord)
+	case lx.AcceptRunFunc(unicode.IsSpace) != 0:
+		lx.Emit(kindSpace)
+	case lx.Peek() == lexer.EOF:
+		return nil
+	default:
+		lx.Next()
+
+		return lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexIncludes
+}
+
+// included returns tok as delivered from the included file source.
+func included(source string, tok lexer.Token) lexer.Token {
+	tok.Source = source
+
+	return tok
+}
+
+func TestNewReaderFS(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		err error
+	)
+
+	t.Parallel()
+
+	lrd, err = lexer.NewReaderFS(includeFS, "inc/b.conf")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "inc/b.conf", lrd.Source())
+	assert.Equal(t, 'b', lrd.Next())
+
+	_, err = lexer.NewReaderFS(includeFS, "absent")
+
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestInclude(t *testing.T) {
+	type testData struct {
+		name   string
+		opts   []lexer.Option
+		tokens []lexer.Token
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Nested": {
+			name: "main.conf",
+			tokens: []lexer.Token{
+				mkToken(kindWord, "a", lexer.Position{1, 1, 0}, lexer.Position{1, 2, 1}),
+				mkToken(kindSpace, " ", lexer.Position{1, 2, 1}, lexer.Position{1, 3, 2}),
+				mkToken(kindDirective, "@inc/b.conf", lexer.Position{1, 3, 2}, lexer.Position{1, 14, 13}),
+				included("inc/b.conf", mkToken(kindWord, "b", lexer.Position{1, 1, 0}, lexer.Position{1, 2, 1})),
+				included("inc/b.conf", mkToken(kindSpace, " ", lexer.Position{1, 2, 1}, lexer.Position{1, 3, 2})),
+				included("inc/b.conf", mkToken(kindDirective, "@c.conf", lexer.Position{1, 3, 2}, lexer.Position{1, 10, 9})),
+				included("inc/c.conf", mkToken(kindWord, "c", lexer.Position{1, 1, 0}, lexer.Position{1, 2, 1})),
+				included("inc/b.conf", mkToken(kindSpace, "\n", lexer.Position{1, 10, 9}, lexer.Position{2, 1, 10})),
+				included("inc/b.conf", mkToken(kindWord, "b", lexer.Position{2, 1, 10}, lexer.Position{2, 2, 11})),
+				mkToken(kindSpace, " ", lexer.Position{1, 14, 13}, lexer.Position{1, 15, 14}),
+				mkToken(kindWord, "z", lexer.Position{1, 15, 14}, lexer.Position{1, 16, 15}),
+			},
+		},
+		"Cycle": {
+			name: "loop/x.conf",
+			tokens: []lexer.Token{
+				mkToken(kindDirective, "@y.conf", lexer.Position{1, 1, 0}, lexer.Position{1, 8, 7}),
+				included(
+					"loop/y.conf",
+					mkToken(kindDirective, "@/loop/x.conf", lexer.Position{1, 1, 0}, lexer.Position{1, 14, 13}),
+				),
+				included("loop/y.conf", mkToken(
+					lexer.KindError,
+					"include cycle: loop/x.conf -> loop/y.conf -> loop/x.conf",
+					lexer.Position{1, 14, 13},
+					lexer.Position{1, 14, 13},
+				)),
+			},
+		},
+		"Missing": {
+			name: "missing.conf",
+			tokens: []lexer.Token{
+				mkToken(kindDirective, "@nowhere.conf", lexer.Position{1, 1, 0}, lexer.Position{1, 14, 13}),
+				mkToken(
+					lexer.KindError,
+					`include "nowhere.conf": open nowhere.conf: file does not exist`,
+					lexer.Position{1, 14, 13},
+					lexer.Position{1, 14, 13},
+				),
+				mkToken(kindSpace, " ", lexer.Position{1, 14, 13}, lexer.Position{1, 15, 14}),
+				mkToken(kindWord, "m", lexer.Position{1, 15, 14}, lexer.Position{1, 16, 15}),
+			},
+		},
+		"ZeroBased": {
+			name: "zero/based.conf",
+			opts: []lexer.Option{lexer.WithZeroBased()},
+			tokens: []lexer.Token{
+				mkToken(kindDirective, "@../inc/c.conf", lexer.Position{0, 0, 0}, lexer.Position{0, 14, 14}),
+				included("inc/c.conf", mkToken(kindWord, "c", lexer.Position{0, 0, 0}, lexer.Position{0, 1, 1})),
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				lx     *lexer.Lexer
+				tokens []lexer.Token
+				tok    lexer.Token
+				err    error
+			)
+
+			lx, err = lexer.NewLexerFS(includeFS, test.name, lexIncludes, test.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for tok = range lx.Tokens() {
+				tokens = append(tokens, tok)
+			}
+
+			assert.Equal(t, test.tokens, tokens)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+//go:build lexerdebug
+
+package lexer
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// poison is the byte that overwrites slices returned by EmitBytes once
+// they are no longer valid.
+const poison = 0xde
+
+// guard holds the misuse checks of builds with the lexerdebug tag. A
+// method entered while another goroutine is still inside one panics,
+// pointing at the misuse directly rather than at the corrupted state it
+// leads to. Slices leased out of the buffer are handed out as copies,
+// which are poisoned as soon as the buffer may change, so that using
+// them too late shows up deterministically.
+type guard struct {
+	busy   int32
+	leases [][]byte
+}
+
+func (guard *guard) enter(what string) {
+	if !atomic.CompareAndSwapInt32(&guard.busy, 0, 1) {
+		panic("lexer: concurrent use of " + what)
+	}
+}
+
+func (guard *guard) leave() {
+	atomic.StoreInt32(&guard.busy, 0)
+}
+
+func (guard *guard) lease(b []byte) []byte {
+	b = bytes.Clone(b)
+	guard.leases = append(guard.leases, b)
+
+	return b
+}
+
+func (guard *guard) expire() {
+	var (
+		b []byte
+		i int
+	)
+
+	for _, b = range guard.leases {
+		for i = range b {
+			b[i] = poison
+		}
+	}
+
+	guard.leases = guard.leases[:0]
+}
@@ -0,0 +1,46 @@
+//go:build lexerdebug
+
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitBytesPoison(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		token []byte
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab"))
+	lrd.Next()
+	token, _ = lrd.EmitBytes()
+
+	assert.Equal(t, []byte("a"), token)
+
+	lrd.Peek()
+
+	assert.Equal(t, []byte{0xde}, token)
+}
+
+func TestGuard(t *testing.T) {
+	var lx *lexer.Lexer
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader("a"), func(lx *lexer.Lexer) lexer.StateFn {
+		lx.NextToken()
+
+		return nil
+	})
+
+	assert.PanicsWithValue(t, "lexer: concurrent use of Lexer", func() {
+		lx.NextToken()
+	})
+}
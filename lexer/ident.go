@@ -0,0 +1,140 @@
+package lexer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidEscape is returned by AcceptEscapedIdentifier when a
+// backslash inside an identifier does not introduce a well-formed
+// Unicode escape, or when the escaped rune is not allowed at its
+// position in the identifier.
+var ErrInvalidEscape = errors.New("lexer: invalid escape in identifier")
+
+// AcceptIdentifier consumes an identifier whose first rune satisfies
+// start and whose remaining runes satisfy part, such as
+// AcceptIdentifier(unicode.IsLetter, isLetterOrDigit).
+//
+// Returns true if an identifier was consumed. Returns false, leaving
+// the reader untouched, if the next rune does not satisfy start.
+func (lrd *Reader) AcceptIdentifier(start, part func(rune) bool) bool {
+	if !lrd.AcceptFunc(start) {
+		return false
+	}
+
+	lrd.AcceptRunFunc(part)
+
+	return true
+}
+
+// AcceptEscapedIdentifier behaves like AcceptIdentifier but also allows
+// any rune of the identifier to be written as a Unicode escape, either
+// \uXXXX with exactly four hexadecimal digits or \u{X...} with one or
+// more, as in JavaScript and Java. Escaped runes must satisfy the same
+// predicates as literal ones.
+//
+// Returns the identifier as written and its decoded form with every
+// escape replaced by the rune it denotes. Both are empty, with the
+// reader untouched, if no identifier starts at the current position.
+// If an escape is malformed or denotes a disallowed rune, the runes up
+// to the offending backslash stay consumed, the raw form so far is
+// returned, and the error is ErrInvalidEscape.
+func (lrd *Reader) AcceptEscapedIdentifier(
+	start, part func(rune) bool,
+) (string, string, error) {
+	var (
+		raw     strings.Builder
+		decoded strings.Builder
+		pred    func(rune) bool
+		char    rune
+		text    string
+		ok      bool
+	)
+
+	pred = start
+
+	for {
+		switch lrd.Peek() {
+		case '\\':
+			char, text, ok = lrd.acceptUnicodeEscape()
+			if !ok || !pred(char) {
+				lrd.Backup(len([]rune(text)))
+
+				return raw.String(), "", ErrInvalidEscape
+			}
+
+			raw.WriteString(text)
+		default:
+			char = lrd.Peek()
+			if char == EOF || !pred(char) {
+				return raw.String(), decoded.String(), nil
+			}
+
+			lrd.Next()
+			raw.WriteRune(char)
+		}
+
+		decoded.WriteRune(char)
+		pred = part
+	}
+}
+
+func (lrd *Reader) acceptUnicodeEscape() (rune, string, bool) {
+	var (
+		text   strings.Builder
+		digits string
+		value  uint64
+		err    error
+		braced bool
+	)
+
+	text.WriteRune(lrd.Next())
+
+	if !lrd.Accept("u") {
+		return 0, text.String(), false
+	}
+
+	text.WriteByte('u')
+
+	braced = lrd.Accept("{")
+	if braced {
+		text.WriteByte('{')
+	}
+
+	for len(digits) < 8 && isHexDigit(lrd.Peek()) {
+		digits += string(lrd.Next())
+
+		if !braced && len(digits) == 4 {
+			break
+		}
+	}
+
+	text.WriteString(digits)
+
+	if braced {
+		if !lrd.Accept("}") {
+			return 0, text.String(), false
+		}
+
+		text.WriteByte('}')
+	}
+
+	if digits == "" || !braced && len(digits) != 4 {
+		return 0, text.String(), false
+	}
+
+	value, err = strconv.ParseUint(digits, 16, 32)
+	if err != nil || value > unicode.MaxRune {
+		return 0, text.String(), false
+	}
+
+	return rune(value), text.String(), true
+}
+
+func isHexDigit(char rune) bool {
+	return '0' <= char && char <= '9' ||
+		'a' <= char && char <= 'f' ||
+		'A' <= char && char <= 'F'
+}
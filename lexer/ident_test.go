@@ -0,0 +1,139 @@
+package lexer_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+type escapedIdentResult struct {
+	raw     string
+	decoded string
+	err     error
+}
+
+func isIdentStart(char rune) bool {
+	return char == '_' || char == '$' || unicode.IsLetter(char)
+}
+
+func isIdentPart(char rune) bool {
+	return isIdentStart(char) || unicode.IsDigit(char)
+}
+
+func acceptEscapedIdent(lrd *lexer.Reader) escapedIdentResult {
+	var res escapedIdentResult
+
+	res.raw, res.decoded, res.err = lrd.AcceptEscapedIdentifier(
+		isIdentStart,
+		isIdentPart,
+	)
+
+	return res
+}
+
+func TestReaderAcceptIdentifier(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[bool]{
+		"Base": {
+			content: "_abc1 x",
+			afterOp: "_abc1",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptIdentifier(isIdentStart, isIdentPart)
+			},
+		},
+		"LeadingDigit": {
+			content: "1abc",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptIdentifier(isIdentStart, isIdentPart)
+			},
+		},
+		"Unicode": {
+			content: "café=",
+			afterOp: "café",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptIdentifier(isIdentStart, isIdentPart)
+			},
+		},
+	})
+}
+
+func TestReaderAcceptEscapedIdentifier(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[escapedIdentResult]{
+		"Plain": {
+			content: "abc1 x",
+			afterOp: "abc1",
+			result:  escapedIdentResult{"abc1", "abc1", nil},
+			op:      acceptEscapedIdent,
+		},
+		"FourDigits": {
+			content: `\u0061bc=`,
+			afterOp: `\u0061bc`,
+			result:  escapedIdentResult{`\u0061bc`, "abc", nil},
+			op:      acceptEscapedIdent,
+		},
+		"FourDigitsThenHex": {
+			content: `a\u00e9f`,
+			afterOp: `a\u00e9f`,
+			result:  escapedIdentResult{`a\u00e9f`, "aéf", nil},
+			op:      acceptEscapedIdent,
+		},
+		"Braced": {
+			content: `x\u{1D49C}y`,
+			afterOp: `x\u{1D49C}y`,
+			result:  escapedIdentResult{`x\u{1D49C}y`, "x𝒜y", nil},
+			op:      acceptEscapedIdent,
+		},
+		"NotIdentifier": {
+			content: "1abc",
+			afterOp: "",
+			result:  escapedIdentResult{"", "", nil},
+			op:      acceptEscapedIdent,
+		},
+		"ShortEscape": {
+			content: `ab\u61`,
+			afterOp: "ab",
+			result:  escapedIdentResult{"ab", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+		"NotUnicodeEscape": {
+			content: `ab\n`,
+			afterOp: "ab",
+			result:  escapedIdentResult{"ab", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+		"UnterminatedBrace": {
+			content: `ab\u{61`,
+			afterOp: "ab",
+			result:  escapedIdentResult{"ab", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+		"OutOfRange": {
+			content: `ab\u{110000}`,
+			afterOp: "ab",
+			result:  escapedIdentResult{"ab", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+		"DisallowedRune": {
+			// U+002D HYPHEN-MINUS
+			content: `ab\u002D`,
+			afterOp: "ab",
+			result:  escapedIdentResult{"ab", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+		"DisallowedStart": {
+			// U+0031 DIGIT ONE
+			content: `\u0031a`,
+			afterOp: "",
+			result:  escapedIdentResult{"", "", lexer.ErrInvalidEscape},
+			op:      acceptEscapedIdent,
+		},
+	})
+}
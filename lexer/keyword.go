@@ -0,0 +1,211 @@
+package lexer
+
+import (
+	"slices"
+	"strings"
+)
+
+// KeywordSet reclassifies identifiers as keywords. Grammars lex every
+// identifier-shaped word with a single generic rule and then resolve it
+// against a KeywordSet, instead of teaching the lexer each keyword
+// individually. A new KeywordSet is constructed with NewKeywordSet or
+// NewKeywordSetFold and is safe for concurrent lookups once built.
+type KeywordSet struct {
+	kinds      map[string]Kind
+	contextual map[string][]contextualKeyword
+	fold       bool
+}
+
+// KeywordContext describes where an identifier occurs, for deciding
+// whether a contextual keyword applies.
+type KeywordContext struct {
+	// Mode is the Lexer mode the identifier was lexed in.
+	Mode string
+
+	// Prev is the previous significant token. It is only meaningful
+	// when HasPrev is true.
+	Prev Token
+
+	// HasPrev reports whether a significant token precedes the
+	// identifier.
+	HasPrev bool
+}
+
+type contextualKeyword struct {
+	kind Kind
+	when func(KeywordContext) bool
+}
+
+// NewKeywordSet returns a case-sensitive KeywordSet mapping each
+// keyword text to the Kind it should be reclassified as.
+func NewKeywordSet(keywords map[string]Kind) *KeywordSet {
+	return newKeywordSet(keywords, false)
+}
+
+// NewKeywordSetFold returns a case-insensitive KeywordSet, as needed by
+// languages such as SQL or Pascal where "SELECT" and "select" are the
+// same keyword. Keywords are compared after conversion to lower case.
+func NewKeywordSetFold(keywords map[string]Kind) *KeywordSet {
+	return newKeywordSet(keywords, true)
+}
+
+// Lookup returns the Kind registered for text. The boolean is false if
+// text is not a keyword.
+func (set *KeywordSet) Lookup(text string) (Kind, bool) {
+	var (
+		kind Kind
+		ok   bool
+	)
+
+	if set.fold {
+		text = strings.ToLower(text)
+	}
+
+	kind, ok = set.kinds[text]
+
+	return kind, ok
+}
+
+// Resolve returns tok with its Kind replaced by the keyword kind its
+// text is registered under, or tok unchanged if its text is not a
+// keyword.
+func (set *KeywordSet) Resolve(tok Token) Token {
+	var (
+		kind Kind
+		ok   bool
+	)
+
+	kind, ok = set.Lookup(tok.Text)
+	if ok {
+		tok.Kind = kind
+	}
+
+	return tok
+}
+
+// AddContextual registers text as a contextual keyword: it is
+// reclassified as kind only where when reports true, such as "async"
+// before a function, "of" inside a for-loop header, or "where" in a
+// query mode, and remains an identifier elsewhere. Several contextual
+// registrations of the same text are tried in order, after the
+// unconditional keywords.
+//
+// AddContextual must not be called concurrently with lookups.
+func (set *KeywordSet) AddContextual(
+	text string,
+	kind Kind,
+	when func(KeywordContext) bool,
+) {
+	if set.fold {
+		text = strings.ToLower(text)
+	}
+
+	if set.contextual == nil {
+		set.contextual = make(map[string][]contextualKeyword)
+	}
+
+	set.contextual[text] = append(set.contextual[text], contextualKeyword{
+		kind: kind,
+		when: when,
+	})
+}
+
+// ResolveContext behaves like Resolve but also considers contextual
+// keywords, reclassifying tok by the first contextual registration for
+// its text whose predicate accepts ctx.
+func (set *KeywordSet) ResolveContext(tok Token, ctx KeywordContext) Token {
+	var (
+		text  string
+		kind  Kind
+		entry contextualKeyword
+		ok    bool
+	)
+
+	kind, ok = set.Lookup(tok.Text)
+	if ok {
+		tok.Kind = kind
+
+		return tok
+	}
+
+	text = tok.Text
+	if set.fold {
+		text = strings.ToLower(text)
+	}
+
+	for _, entry = range set.contextual[text] {
+		if entry.when(ctx) {
+			tok.Kind = entry.kind
+
+			break
+		}
+	}
+
+	return tok
+}
+
+// EmitIdentifier emits the runes accumulated since the last call to
+// Ignore or Emit as a keyword if set contains them, or as a token of
+// the ident kind otherwise. Contextual keywords are resolved against
+// the current mode and the previous significant token.
+func (lx *Lexer) EmitIdentifier(ident Kind, set *KeywordSet) {
+	lx.push(set.ResolveContext(lx.token(ident), lx.keywordContext()))
+}
+
+// InMode returns a contextual keyword predicate that accepts any of the
+// given Lexer modes.
+func InMode(modes ...string) func(KeywordContext) bool {
+	return func(ctx KeywordContext) bool {
+		return slices.Contains(modes, ctx.Mode)
+	}
+}
+
+// AfterKind returns a contextual keyword predicate that accepts
+// identifiers whose previous significant token has one of the given
+// kinds.
+func AfterKind(kinds ...Kind) func(KeywordContext) bool {
+	return func(ctx KeywordContext) bool {
+		return ctx.HasPrev && slices.Contains(kinds, ctx.Prev.Kind)
+	}
+}
+
+// AfterText returns a contextual keyword predicate that accepts
+// identifiers whose previous significant token has one of the given
+// texts.
+func AfterText(texts ...string) func(KeywordContext) bool {
+	return func(ctx KeywordContext) bool {
+		return ctx.HasPrev && slices.Contains(texts, ctx.Prev.Text)
+	}
+}
+
+func (lx *Lexer) keywordContext() KeywordContext {
+	var ctx KeywordContext
+
+	ctx.Mode = lx.Mode()
+	ctx.Prev, ctx.HasPrev = lx.LastToken()
+
+	return ctx
+}
+
+func newKeywordSet(keywords map[string]Kind, fold bool) *KeywordSet {
+	var (
+		set  *KeywordSet
+		text string
+		kind Kind
+	)
+
+	set = &KeywordSet{
+		kinds: make(map[string]Kind, len(keywords)),
+		fold:  fold,
+	}
+
+	for text, kind = range keywords {
+		if fold {
+			text = strings.ToLower(text)
+		}
+
+		set.kinds[text] = kind
+	}
+
+	return set
+}
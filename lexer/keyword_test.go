@@ -0,0 +1,274 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexIdentifiers(set *lexer.KeywordSet) lexer.StateFn {
+	var state lexer.StateFn
+
+	state = func(lx *lexer.Lexer) lexer.StateFn {
+		lx.AcceptRunFunc(unicode.IsSpace)
+		lx.Ignore()
+
+		if lx.AcceptRunFunc(unicode.IsLetter) == 0 {
+			return nil
+		}
+
+		lx.EmitIdentifier(kindWord, set)
+
+		return state
+	}
+
+	return state
+}
+
+func TestKeywordSetLookup(t *testing.T) {
+	var (
+		set  *lexer.KeywordSet
+		kind lexer.Kind
+		ok   bool
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"if":   kindIf,
+		"else": kindElse,
+	})
+
+	kind, ok = set.Lookup("if")
+	assert.True(t, ok)
+	assert.Equal(t, kindIf, kind)
+
+	_, ok = set.Lookup("IF")
+	assert.False(t, ok)
+
+	_, ok = set.Lookup("iff")
+	assert.False(t, ok)
+}
+
+func TestKeywordSetFold(t *testing.T) {
+	var (
+		set  *lexer.KeywordSet
+		kind lexer.Kind
+		ok   bool
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSetFold(map[string]lexer.Kind{
+		"Select": kindSelect,
+	})
+
+	kind, ok = set.Lookup("SELECT")
+	assert.True(t, ok)
+	assert.Equal(t, kindSelect, kind)
+
+	kind, ok = set.Lookup("select")
+	assert.True(t, ok)
+	assert.Equal(t, kindSelect, kind)
+}
+
+func TestKeywordSetResolve(t *testing.T) {
+	var set *lexer.KeywordSet
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"if": kindIf,
+	})
+
+	assert.Equal(
+		t,
+		lexer.Token{Kind: kindIf, Text: "if"},
+		set.Resolve(lexer.Token{Kind: kindWord, Text: "if"}),
+	)
+	assert.Equal(
+		t,
+		lexer.Token{Kind: kindWord, Text: "x"},
+		set.Resolve(lexer.Token{Kind: kindWord, Text: "x"}),
+	)
+}
+
+func TestLexerEmitIdentifier(t *testing.T) {
+	var (
+		set   *lexer.KeywordSet
+		lx    *lexer.Lexer
+		tok   lexer.Token
+		kinds []lexer.Kind
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"if":   kindIf,
+		"else": kindElse,
+	})
+	lx = lexer.NewLexer(
+		strings.NewReader("if x else elsewhere"),
+		lexIdentifiers(set),
+	)
+
+	for tok = range lx.Tokens() {
+		kinds = append(kinds, tok.Kind)
+	}
+
+	assert.Equal(t, []lexer.Kind{kindIf, kindWord, kindElse, kindWord}, kinds)
+}
+
+func BenchmarkKeywordSetLookup(b *testing.B) {
+	var (
+		set   *lexer.KeywordSet
+		words []string
+		word  string
+	)
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"break": kindIf, "case": kindIf, "chan": kindIf, "const": kindIf,
+		"continue": kindIf, "default": kindIf, "defer": kindIf,
+		"else": kindIf, "fallthrough": kindIf, "for": kindIf,
+		"func": kindIf, "go": kindIf, "goto": kindIf, "if": kindIf,
+		"import": kindIf, "interface": kindIf, "map": kindIf,
+		"package": kindIf, "range": kindIf, "return": kindIf,
+		"select": kindIf, "struct": kindIf, "switch": kindIf,
+		"type": kindIf, "var": kindIf,
+	})
+	words = strings.Fields("func main ( ) { for i = range items { if i return x } }")
+
+	for b.Loop() {
+		for _, word = range words {
+			set.Lookup(word)
+		}
+	}
+}
+
+func BenchmarkLexerEmitIdentifier(b *testing.B) {
+	var (
+		set     *lexer.KeywordSet
+		content string
+		lx      *lexer.Lexer
+	)
+
+	set = lexer.NewKeywordSetFold(map[string]lexer.Kind{
+		"select": kindSelect,
+		"from":   kindSelect,
+		"where":  kindSelect,
+	})
+	content = strings.Repeat("SELECT name FROM users WHERE id ", 256)
+
+	for b.Loop() {
+		lx = lexer.NewLexer(strings.NewReader(content), lexIdentifiers(set))
+
+		for range lx.Tokens() {
+		}
+	}
+}
+
+func TestKeywordSetAddContextual(t *testing.T) {
+	var (
+		set   *lexer.KeywordSet
+		lx    *lexer.Lexer
+		tok   lexer.Token
+		kinds []lexer.Kind
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"if": kindIf,
+	})
+	set.AddContextual("else", kindElse, lexer.AfterKind(kindIf))
+	set.AddContextual("select", kindSelect, lexer.InMode("query"))
+	set.AddContextual("select", kindElse, lexer.AfterText("x"))
+
+	lx = lexer.NewLexer(
+		strings.NewReader("else if else x select select"),
+		func(lx *lexer.Lexer) lexer.StateFn {
+			lx.PushMode("query")
+
+			return lexIdentifiers(set)
+		},
+	)
+
+	for tok = range lx.Tokens() {
+		kinds = append(kinds, tok.Kind)
+	}
+
+	assert.Equal(t, []lexer.Kind{
+		kindWord,
+		kindIf,
+		kindElse,
+		kindWord,
+		kindSelect,
+		kindSelect,
+	}, kinds)
+}
+
+func TestKeywordSetResolveContext(t *testing.T) {
+	type testData struct {
+		tok  lexer.Token
+		ctx  lexer.KeywordContext
+		kind lexer.Kind
+	}
+
+	var (
+		set     *lexer.KeywordSet
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSetFold(map[string]lexer.Kind{
+		"if": kindIf,
+	})
+	set.AddContextual("Of", kindElse, lexer.AfterText(")"))
+	set.AddContextual("of", kindSelect, lexer.AfterKind(kindWord))
+
+	testTbl = map[string]testData{
+		"Unconditional": {
+			tok:  lexer.Token{Kind: kindWord, Text: "IF"},
+			kind: kindIf,
+		},
+		"NoContext": {
+			tok:  lexer.Token{Kind: kindWord, Text: "OF"},
+			kind: kindWord,
+		},
+		"FirstPredicate": {
+			tok: lexer.Token{Kind: kindWord, Text: "OF"},
+			ctx: lexer.KeywordContext{
+				Prev:    lexer.Token{Kind: kindPunct, Text: ")"},
+				HasPrev: true,
+			},
+			kind: kindElse,
+		},
+		"SecondPredicate": {
+			tok: lexer.Token{Kind: kindWord, Text: "of"},
+			ctx: lexer.KeywordContext{
+				Prev:    lexer.Token{Kind: kindWord, Text: "x"},
+				HasPrev: true,
+			},
+			kind: kindSelect,
+		},
+		"NoPrev": {
+			tok: lexer.Token{Kind: kindWord, Text: "of"},
+			ctx: lexer.KeywordContext{
+				Prev: lexer.Token{Kind: kindWord, Text: ")"},
+			},
+			kind: kindWord,
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.kind, set.ResolveContext(test.tok, test.ctx).Kind)
+		})
+	}
+}
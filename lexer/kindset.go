@@ -0,0 +1,179 @@
+package lexer
+
+import (
+	"iter"
+	"math/bits"
+	"strings"
+)
+
+// KindSet is an immutable set of kinds, stored as a bitset so that
+// membership tests cost a shift and a mask. Parsers use KindSets for
+// the kinds they accept at a point of the grammar and for the
+// synchronizing kinds of error recovery, which are tested against every
+// token read. The zero value is the empty set. Negative kinds are never
+// members.
+type KindSet struct {
+	words []uint64
+}
+
+// NewKindSet returns the set of the given kinds.
+func NewKindSet(kinds ...Kind) KindSet {
+	return KindSet{}.With(kinds...)
+}
+
+// Has reports whether kind is in the set.
+func (set KindSet) Has(kind Kind) bool {
+	var word int
+
+	if kind < 0 {
+		return false
+	}
+
+	word = int(kind) / 64
+
+	return word < len(set.words) && set.words[word]&(1<<(uint(kind)%64)) != 0
+}
+
+// With returns the set of the kinds in set and the given kinds. Set is
+// left unchanged.
+func (set KindSet) With(kinds ...Kind) KindSet {
+	var (
+		words []uint64
+		kind  Kind
+		size  int
+	)
+
+	size = len(set.words)
+
+	for _, kind = range kinds {
+		if kind >= 0 {
+			size = max(size, int(kind)/64+1)
+		}
+	}
+
+	words = make([]uint64, size)
+	copy(words, set.words)
+
+	for _, kind = range kinds {
+		if kind >= 0 {
+			words[kind/64] |= 1 << (uint(kind) % 64)
+		}
+	}
+
+	return KindSet{words: words}
+}
+
+// Union returns the set of the kinds in set or other.
+func (set KindSet) Union(other KindSet) KindSet {
+	var (
+		words []uint64
+		i     int
+	)
+
+	if len(other.words) > len(set.words) {
+		set, other = other, set
+	}
+
+	words = make([]uint64, len(set.words))
+	copy(words, set.words)
+
+	for i = range other.words {
+		words[i] |= other.words[i]
+	}
+
+	return KindSet{words: words}
+}
+
+// Without returns the set of the kinds in set but not in other.
+func (set KindSet) Without(other KindSet) KindSet {
+	var (
+		words []uint64
+		i     int
+	)
+
+	words = make([]uint64, len(set.words))
+	copy(words, set.words)
+
+	for i = range min(len(words), len(other.words)) {
+		words[i] &^= other.words[i]
+	}
+
+	return KindSet{words: words}
+}
+
+// Len returns the number of kinds in the set.
+func (set KindSet) Len() int {
+	var (
+		n    int
+		word uint64
+	)
+
+	for _, word = range set.words {
+		n += bits.OnesCount64(word)
+	}
+
+	return n
+}
+
+// All returns an iterator over the kinds in the set, in increasing
+// order.
+func (set KindSet) All() iter.Seq[Kind] {
+	return func(yield func(Kind) bool) {
+		var (
+			word uint64
+			i    int
+		)
+
+		for i, word = range set.words {
+			for word != 0 {
+				if !yield(Kind(i*64 + bits.TrailingZeros64(word))) {
+					return
+				}
+
+				word &= word - 1
+			}
+		}
+	}
+}
+
+// String returns the kinds in the set, in increasing order, as in
+// "{EOF, Error}".
+func (set KindSet) String() string {
+	var (
+		sb   strings.Builder
+		kind Kind
+	)
+
+	sb.WriteByte('{')
+
+	for kind = range set.All() {
+		if sb.Len() > 1 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString(kind.String())
+	}
+
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+// Accept consumes and returns the next token if its kind is in set. The
+// boolean is false, and nothing is consumed, if the next token is of
+// another kind or the stream is exhausted.
+func (trd *TokenReader) Accept(set KindSet) (Token, bool) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	tok, ok = trd.Peek()
+	if !ok || !set.Has(tok.Kind) {
+		return Token{}, false
+	}
+
+	trd.NextToken()
+
+	return tok, true
+}
@@ -0,0 +1,95 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindSet(t *testing.T) {
+	var (
+		set, more lexer.KindSet
+		zero      lexer.KindSet
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKindSet(kindWord, lexer.KindEOF, 130, -1)
+	more = set.With(kindPunct)
+
+	assert.True(t, set.Has(kindWord))
+	assert.True(t, set.Has(lexer.KindEOF))
+	assert.True(t, set.Has(130))
+	assert.False(t, set.Has(kindPunct))
+	assert.False(t, set.Has(-1))
+	assert.False(t, set.Has(1000))
+	assert.True(t, more.Has(kindPunct))
+	assert.Equal(t, 3, set.Len())
+	assert.Equal(t, 4, more.Len())
+
+	assert.Equal(
+		t,
+		[]lexer.Kind{lexer.KindEOF, kindWord, 130},
+		slices.Collect(set.All()),
+	)
+	assert.Equal(t, "{EOF, Error}", lexer.NewKindSet(1, 0).String())
+	assert.Equal(t, "{}", zero.String())
+	assert.Equal(t, 0, zero.Len())
+	assert.False(t, zero.Has(lexer.KindEOF))
+
+	assert.Equal(
+		t,
+		[]lexer.Kind{lexer.KindError, kindWord, 130},
+		slices.Collect(lexer.NewKindSet(lexer.KindError).Union(set).
+			Without(lexer.NewKindSet(lexer.KindEOF)).All()),
+	)
+	assert.False(t, more.Without(lexer.NewKindSet(kindPunct, 500)).Has(kindPunct))
+}
+
+func TestTokenReaderAccept(t *testing.T) {
+	var (
+		trd *lexer.TokenReader
+		tok lexer.Token
+		ok  bool
+	)
+
+	t.Parallel()
+
+	trd = lexer.NewTokenReader(lexer.NewLexer(strings.NewReader("ab cd"), lexWords))
+
+	_, ok = trd.Accept(lexer.NewKindSet(kindSpace))
+	assert.False(t, ok)
+
+	tok, ok = trd.Accept(lexer.NewKindSet(kindWord, kindSpace))
+	assert.True(t, ok)
+	assert.Equal(t, "ab", tok.Text)
+
+	tok, ok = trd.Accept(lexer.NewKindSet(kindSpace))
+	assert.True(t, ok)
+	assert.Equal(t, " ", tok.Text)
+
+	assert.Equal(t, []kindText{{kindWord, "cd"}}, collectKinds(trd))
+
+	_, ok = trd.Accept(lexer.NewKindSet(lexer.KindEOF))
+	assert.False(t, ok)
+}
+
+func BenchmarkKindSetHas(b *testing.B) {
+	var (
+		set   lexer.KindSet
+		kinds []lexer.Kind
+		kind  lexer.Kind
+	)
+
+	set = lexer.NewKindSet(kindWord, kindPunct, kindIf, kindElse)
+	kinds = []lexer.Kind{kindWord, kindSpace, kindIf, lexer.KindEOF, kindElse}
+
+	for b.Loop() {
+		for _, kind = range kinds {
+			set.Has(kind)
+		}
+	}
+}
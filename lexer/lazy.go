@@ -0,0 +1,185 @@
+package lexer
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// lineIndex maps byte offsets to lines and columns for a Reader created
+// with WithLazyPositions. It is built incrementally as input is read.
+type lineIndex struct {
+	// starts holds the offset at which every line begins.
+	starts []int
+
+	// fixes records, in increasing order of offset, the cumulative
+	// difference between bytes and columns up to every rune whose
+	// column width differs from its size in bytes.
+	fixes []columnFix
+
+	// ascii caches the column width of every ASCII character.
+	ascii [utf8.RuneSelf]int
+
+	// base is the offset of the first byte of the Reader's buffer, and
+	// scanned the offset up to which the input has been indexed.
+	base, scanned int
+
+	// origin is the position of the first byte of the input, for a
+	// Reader derived from another over a region of its input, or nil
+	// for the start of a document.
+	origin *Position
+
+	ready bool
+}
+
+type columnFix struct {
+	offset, extra int
+}
+
+// WithLazyPositions makes the Reader track only the Offset of a
+// Position while lexing, like WithoutPositions, and build instead an
+// index of line starts as input is read, from which PositionAt computes
+// the Line and Column of any offset on demand. It moves the cost of
+// position tracking off the per-rune path to a single pass over each
+// chunk of input, for pipelines that need exact positions only for the
+// few tokens they report, such as diagnostics.
+//
+// The index grows with the number of lines and of runes whose column
+// width differs from their size in bytes, so it suits mostly ASCII
+// input. Positions resolved by PositionAt are relative to the start of
+// the input and are not affected by SetPosition, except on the Readers
+// lexing a region of another, as for SubReader or Embed, which resolve
+// the offsets of the document the region was taken from.
+func WithLazyPositions() Option {
+	return func(lrd *Reader) {
+		lrd.noPositions = true
+		lrd.lines = &lineIndex{starts: []int{0}}
+	}
+}
+
+// PositionAt returns the complete position of the byte at offset, as
+// reported in the Offset of the positions of the Reader, for a Reader
+// created with WithLazyPositions.
+// Line and Column are exactly those that the Reader would have tracked
+// without the option, given the same width function. The boolean is
+// false if the Reader was created without the option, or if offset lies
+// beyond the input read so far.
+func (lrd *Reader) PositionAt(offset int) (Position, bool) {
+	var (
+		origin Position
+		local  int
+		line   int
+		start  int
+		column int
+		found  bool
+	)
+
+	if lrd.lines == nil {
+		return Position{}, false
+	}
+
+	// Lines and columns share the same base: one, or zero with
+	// WithZeroBased.
+	origin = Position{Line: lrd.columnBase, Column: lrd.columnBase}
+	if lrd.lines.origin != nil {
+		origin = *lrd.lines.origin
+	}
+
+	local = offset - origin.Offset
+	if local < 0 || local > lrd.lines.scanned {
+		return Position{}, false
+	}
+
+	line, found = slices.BinarySearch(lrd.lines.starts, local)
+	if !found {
+		line--
+	}
+
+	start = lrd.lines.starts[line]
+
+	column = lrd.columnBase
+	if line == 0 {
+		column = origin.Column
+	}
+
+	return Position{
+		Line:   origin.Line + line,
+		Column: column + local - start - (lrd.lines.extra(local) - lrd.lines.extra(start)),
+		Offset: offset,
+	}, true
+}
+
+// scan indexes the bytes of buf, whose first byte is at offset
+// index.base, that have not been indexed yet. A trailing incomplete
+// rune is left for the next scan unless final is true.
+func (index *lineIndex) scan(buf []byte, width func(rune) int, final bool) {
+	var (
+		b    byte
+		char rune
+		size int
+		i    int
+	)
+
+	if !index.ready {
+		for i = range index.ascii {
+			index.ascii[i] = width(rune(i))
+		}
+
+		index.ready = true
+	}
+
+	for i = max(index.scanned-index.base, 0); i < len(buf); i += size {
+		b = buf[i]
+		size = 1
+
+		switch {
+		case b == '\n':
+			index.starts = append(index.starts, index.base+i+1)
+		case b < utf8.RuneSelf:
+			if index.ascii[b] != 1 {
+				index.fix(index.base+i+1, 1-index.ascii[b])
+			}
+		case !final && !utf8.FullRune(buf[i:]):
+			index.scanned = index.base + i
+
+			return
+		default:
+			char, size = utf8.DecodeRune(buf[i:])
+			if char == utf8.RuneError && size == 1 {
+				break
+			}
+
+			index.fix(index.base+i+size, size-width(char))
+		}
+	}
+
+	index.scanned = index.base + len(buf)
+}
+
+// fix records that the rune ending at offset is delta bytes longer than
+// it is wide.
+func (index *lineIndex) fix(offset, delta int) {
+	if delta == 0 {
+		return
+	}
+
+	index.fixes = append(index.fixes, columnFix{
+		offset: offset,
+		extra:  index.extra(offset) + delta,
+	})
+}
+
+// extra returns the difference between bytes and columns accumulated up
+// to offset.
+func (index *lineIndex) extra(offset int) int {
+	var i int
+
+	i, _ = slices.BinarySearchFunc(index.fixes, offset+1, func(fix columnFix, target int) int {
+		return fix.offset - target
+	})
+
+	if i == 0 {
+		return 0
+	}
+
+	return index.fixes[i-1].extra
+}
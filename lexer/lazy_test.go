@@ -0,0 +1,123 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderPositionAt(t *testing.T) {
+	type testData struct {
+		content string
+		opts    []lexer.Option
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"ASCII": {
+			content: "ab\ncd\n\nef",
+		},
+		"MultiByte": {
+			content: "héllo\n世界 x\n",
+		},
+		"Invalid": {
+			content: "a\xffb\n\xe4\xb8c",
+		},
+		"TruncatedRune": {
+			content: "ab\xe4\xb8",
+		},
+		"DisplayWidth": {
+			content: "世界\té\nあ",
+			opts:    []lexer.Option{lexer.WithColumnWidth(lexer.DisplayWidth)},
+		},
+		"UTF16": {
+			content: "a\U0001F600b\nc",
+			opts:    []lexer.Option{lexer.WithColumnWidth(lexer.UTF16Width)},
+		},
+		"ZeroBased": {
+			content: "ab\néc",
+			opts:    []lexer.Option{lexer.WithZeroBased()},
+		},
+		"Long": {
+			content: strings.Repeat("abc é世\n", 3000) + strings.Repeat("x", 9000),
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				eager    *lexer.Reader
+				lazy     *lexer.Reader
+				expected []lexer.Position
+				pos      lexer.Position
+				ok       bool
+				i        int
+			)
+
+			eager = lexer.NewReader(strings.NewReader(test.content), test.opts...)
+			lazy = lexer.NewReader(
+				iotest.HalfReader(strings.NewReader(test.content)),
+				append(test.opts, lexer.WithLazyPositions())...,
+			)
+
+			expected = append(expected, eager.CurrentPosition())
+
+			for eager.Next() != lexer.EOF {
+				eager.Ignore()
+				expected = append(expected, eager.CurrentPosition())
+			}
+
+			for i = 0; lazy.Next() != lexer.EOF; i++ {
+				lazy.Ignore()
+			}
+
+			assert.Equal(t, len(expected)-1, i)
+
+			for _, pos = range expected {
+				assert.Equal(
+					t,
+					pos,
+					resolved(lazy.PositionAt(pos.Offset)),
+					"offset %d",
+					pos.Offset,
+				)
+			}
+
+			_, ok = lazy.PositionAt(len(test.content) + 1)
+			assert.False(t, ok)
+
+			_, ok = eager.PositionAt(0)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestWithLazyPositions(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\ncd"), lexer.WithLazyPositions())
+	lrd.Until("d")
+
+	assert.Equal(t, lexer.Position{1, 1, 4}, lrd.CurrentPosition())
+	assert.Equal(t, lexer.Position{2, 2, 4}, resolved(lrd.PositionAt(4)))
+}
+
+func resolved(pos lexer.Position, ok bool) lexer.Position {
+	if !ok {
+		return lexer.Position{Line: -1, Column: -1, Offset: -1}
+	}
+
+	return pos
+}
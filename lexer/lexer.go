@@ -0,0 +1,339 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"iter"
+)
+
+// StateFn represents a single state of a Lexer. Each state consumes
+// input through the embedded Reader, emits zero or more tokens, and
+// returns the state to run next. Returning nil stops the Lexer.
+type StateFn func(*Lexer) StateFn
+
+// Lexer drives a set of state functions over a Reader and collects the
+// tokens they emit. Tokens are produced lazily: states only run when
+// NextToken is asked for a token that has not been emitted yet.
+// A new Lexer is constructed with NewLexer.
+//
+// Like its Reader, a Lexer is not safe for concurrent use. Consumers
+// that share one token stream across goroutines can wrap it with
+// Synchronized, or read it through a TokenChan.
+type Lexer struct {
+	*Reader
+
+	guard       guard
+	state       StateFn
+	queue       []Token
+	last        Token
+	hasLast     bool
+	modes       []string
+	layout      layoutState
+	budget      budgetState
+	label       string
+	includes    []string
+	parent      *Lexer
+	finished    bool
+	eofSent     bool
+	speculative bool
+	held        []Diagnostic
+}
+
+// NewLexer constructs and returns a new Lexer reading from rd and
+// starting in the given state. The options configure the underlying
+// Reader.
+func NewLexer(rd io.Reader, start StateFn, opts ...Option) *Lexer {
+	return newLexer(NewReader(rd, opts...), start)
+}
+
+// Emit produces a token of the given kind from the runes accumulated
+// since the last call to Ignore or Emit. The token carries the span
+// from the start of the token to the current position.
+func (lx *Lexer) Emit(kind Kind) {
+	lx.push(lx.token(kind))
+}
+
+// EmitTrivia behaves like Emit but marks the token as trivia, such as
+// whitespace or comments. Trivia tokens are delivered like any other
+// token but are skipped by LastToken, so they never influence decisions
+// that depend on the previous significant token.
+func (lx *Lexer) EmitTrivia(kind Kind) {
+	var tok Token
+
+	tok = lx.token(kind)
+	tok.Trivia = true
+
+	lx.push(tok)
+}
+
+// Errorf emits a KindError token whose text is the formatted message
+// and whose span covers the runes accumulated since the last call to
+// Ignore or Emit. It returns nil so that state functions can stop the
+// Lexer with a single return statement.
+func (lx *Lexer) Errorf(format string, args ...any) StateFn {
+	var tok Token
+
+	tok = lx.token(KindError)
+	tok.Text = fmt.Sprintf(format, args...)
+
+	lx.push(tok)
+
+	return nil
+}
+
+// LastToken returns the most recently emitted token that is not
+// trivia, whether or not it has been delivered by NextToken yet. The
+// boolean is false if no such token has been emitted.
+func (lx *Lexer) LastToken() (Token, bool) {
+	return lx.last, lx.hasLast
+}
+
+// PushMode enters the named lexer mode, such as "string" while inside
+// an interpolated string literal or "tag" while inside a markup tag.
+// Modes nest: the previous mode becomes current again on PopMode.
+// Modes carry no behavior of their own; they exist so that state
+// functions and keyword predicates can share where the Lexer is.
+func (lx *Lexer) PushMode(mode string) {
+	lx.modes = append(lx.modes, mode)
+	lx.logMode("lexer push mode", mode)
+}
+
+// PopMode leaves the current mode and returns its name. Popping when no
+// mode has been pushed is a no-op that returns the empty string.
+func (lx *Lexer) PopMode() string {
+	var mode string
+
+	if len(lx.modes) == 0 {
+		return ""
+	}
+
+	mode = lx.modes[len(lx.modes)-1]
+	lx.modes = lx.modes[:len(lx.modes)-1]
+	lx.logMode("lexer pop mode", mode)
+
+	return mode
+}
+
+// Mode returns the name of the current mode, or the empty string when
+// no mode has been pushed.
+func (lx *Lexer) Mode() string {
+	if len(lx.modes) == 0 {
+		return ""
+	}
+
+	return lx.modes[len(lx.modes)-1]
+}
+
+// NextToken returns the next token, running state functions until one
+// is emitted. The boolean is false once the final state has returned
+// and every emitted token has been delivered, including the final
+// KindEOF token of a Lexer created with WithEOFToken.
+func (lx *Lexer) NextToken() (Token, bool) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	lx.guard.enter("Lexer")
+	tok, ok = lx.nextToken()
+	lx.guard.leave()
+
+	return tok, ok
+}
+
+func (lx *Lexer) nextToken() (Token, bool) {
+	var tok Token
+
+	lx.startBudget()
+
+	for len(lx.queue) == 0 {
+		if lx.state == nil && !lx.finished {
+			lx.finished = true
+			lx.finish()
+
+			continue
+		}
+
+		if lx.state == nil {
+			return lx.eof()
+		}
+
+		lx.skipBetween()
+		if len(lx.queue) == 0 {
+			lx.state = lx.run(lx.state)
+		}
+
+		lx.checkBudget()
+	}
+
+	tok = lx.queue[0]
+	lx.queue = lx.queue[1:]
+
+	return tok, true
+}
+
+// Tokens returns an iterator over the remaining tokens of the Lexer,
+// calling NextToken until it reports that the stream is exhausted.
+func (lx *Lexer) Tokens() iter.Seq[Token] {
+	return Tokens(lx)
+}
+
+// WithEOFToken makes a Lexer deliver a final token of kind KindEOF once
+// its final state has returned, with an empty span at the position
+// where lexing stopped. Parsers that look ahead one token are often
+// simpler when the end of input is a token like any other. Without it,
+// the end of the stream is only signaled by NextToken returning false.
+func WithEOFToken() Option {
+	return func(lrd *Reader) {
+		lrd.eofToken = true
+	}
+}
+
+// Embed returns a state that lexes a region of input with a child
+// grammar, such as JavaScript inside an HTML script element or SQL
+// inside a string literal. The region extends from the start of the
+// current token up to, but excluding, the first occurrence of
+// terminator, or to the end of input when terminator never occurs.
+//
+// The region is lexed by a child Lexer starting in the start state,
+// with the whitespace profile of the parent, and every token it emits
+// is delivered through the parent in order. Child positions are
+// reported relative to the whole input rather than the region. Once the
+// child stops, the parent resumes in the resume state with the
+// terminator still unconsumed.
+func Embed(start StateFn, terminator string, resume StateFn) StateFn {
+	return func(lx *Lexer) StateFn {
+		var (
+			text  string
+			pos   Position
+			child *Lexer
+		)
+
+		lx.UntilSeq(terminator)
+		text, pos = lx.Reader.Emit()
+
+		child = newLexer(lx.fragment(text, pos), start)
+
+		return embedded(child, "", resume)
+	}
+}
+
+func (lx *Lexer) run(state StateFn) StateFn {
+	if lx.labels != nil {
+		return lx.runLabeled(state)
+	}
+
+	return lx.runState(state)
+}
+
+func (lx *Lexer) runState(state StateFn) StateFn {
+	if lx.profile != nil {
+		return lx.profile.run(lx, state)
+	}
+
+	return state(lx)
+}
+
+func (lx *Lexer) token(kind Kind) Token {
+	var (
+		text     string
+		startPos Position
+		endPos   Position
+	)
+
+	endPos = lx.CurrentPosition()
+	text, startPos = lx.Reader.Emit()
+
+	return Token{
+		Kind: kind,
+		Text: text,
+		Span: Span{
+			Start: startPos,
+			End:   endPos,
+		},
+	}
+}
+
+func (lx *Lexer) push(tok Token) {
+	if lx.enqueue(tok) && tok.Kind == KindError {
+		lx.logError(tok)
+	}
+}
+
+// enqueue queues tok as push does, without logging it, for the tokens
+// of a child Lexer, which logs them itself. Returns false if tok is
+// dropped for exceeding the limit of WithMaxTokens.
+func (lx *Lexer) enqueue(tok Token) bool {
+	if lx.whitespace != nil && lx.whitespace.policy >= significantNewlines && !tok.Trivia {
+		lx.layoutBefore(tok)
+	}
+
+	if !lx.withinBudget(tok) {
+		return false
+	}
+
+	lx.queue = append(lx.queue, tok)
+
+	if !tok.Trivia {
+		lx.last = tok
+		lx.hasLast = true
+	}
+
+	return true
+}
+
+// eof returns the KindEOF token closing the stream, once, if the Lexer
+// was created with WithEOFToken.
+func (lx *Lexer) eof() (Token, bool) {
+	var pos Position
+
+	if !lx.eofToken || lx.eofSent {
+		return Token{}, false
+	}
+
+	lx.eofSent = true
+	pos = lx.CurrentPosition()
+
+	return Token{
+		Kind: KindEOF,
+		Span: Span{
+			Start: pos,
+			End:   pos,
+		},
+	}, true
+}
+
+func newLexer(lrd *Reader, start StateFn) *Lexer {
+	return &Lexer{
+		Reader: lrd,
+		state:  start,
+	}
+}
+
+func embedded(child *Lexer, source string, resume StateFn) StateFn {
+	var state StateFn
+
+	state = func(lx *Lexer) StateFn {
+		var (
+			tok Token
+			ok  bool
+		)
+
+		child.parent = lx
+
+		tok, ok = child.NextToken()
+		if !ok {
+			return resume
+		}
+
+		if tok.Source == "" {
+			tok.Source = source
+		}
+
+		lx.enqueue(tok)
+
+		return state
+	}
+
+	return state
+}
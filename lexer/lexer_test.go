@@ -0,0 +1,232 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindWord lexer.Kind = lexer.KindUser + iota
+	kindSpace
+	kindTag
+	kindNumber
+	kindPunct
+	kindRegexp
+	kindIf
+	kindElse
+	kindSelect
+	kindComment
+	kindDirective
+	kindShebang
+	kindFrontMatter
+	kindSkipped
+)
+
+type kindText struct {
+	kind lexer.Kind
+	text string
+}
+
+func collectKinds(stream lexer.TokenStream) []kindText {
+	var (
+		tokens []kindText
+		tok    lexer.Token
+	)
+
+	for tok = range lexer.Tokens(stream) {
+		tokens = append(tokens, kindText{tok.Kind, tok.Text})
+	}
+
+	return tokens
+}
+
+func mkToken(
+	kind lexer.Kind,
+	text string,
+	start, end lexer.Position,
+) lexer.Token {
+	return lexer.Token{
+		Kind: kind,
+		Text: text,
+		Span: lexer.Span{
+			Start: start,
+			End:   end,
+		},
+	}
+}
+
+func lexWords(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+		lx.Emit(kindWord)
+	case lx.AcceptRunFunc(unicode.IsSpace) != 0:
+		lx.Emit(kindSpace)
+	case lx.Peek() == lexer.EOF:
+		return nil
+	default:
+		lx.Next()
+
+		return lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexWords
+}
+
+func TestLexerNextToken(t *testing.T) {
+	var (
+		lx *lexer.Lexer
+		ok bool
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader("ab cd"), lexWords)
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		mkToken(kindSpace, " ", lexer.Position{1, 3, 2}, lexer.Position{1, 4, 3}),
+		mkToken(kindWord, "cd", lexer.Position{1, 4, 3}, lexer.Position{1, 6, 5}),
+	}, slices.Collect(lx.Tokens()))
+
+	_, ok = lx.NextToken()
+	assert.False(t, ok)
+}
+
+func TestLexerErrorf(t *testing.T) {
+	var lx *lexer.Lexer
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader("ab\n!cd"), lexWords)
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		mkToken(kindSpace, "\n", lexer.Position{1, 3, 2}, lexer.Position{2, 1, 3}),
+		mkToken(
+			lexer.KindError,
+			`unexpected "!"`,
+			lexer.Position{2, 1, 3},
+			lexer.Position{2, 2, 4},
+		),
+	}, slices.Collect(lx.Tokens()))
+}
+
+func TestLexerLastToken(t *testing.T) {
+	var (
+		lx  *lexer.Lexer
+		tok lexer.Token
+		ok  bool
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader("a  "), lexSlash)
+
+	_, ok = lx.LastToken()
+	assert.False(t, ok)
+
+	tok, ok = lx.NextToken()
+	assert.True(t, ok)
+	assert.False(t, tok.Trivia)
+
+	tok, ok = lx.NextToken()
+	assert.True(t, ok)
+	assert.True(t, tok.Trivia)
+
+	tok, ok = lx.LastToken()
+	assert.True(t, ok)
+	assert.Equal(t, "a", tok.Text)
+}
+
+func TestLexerMode(t *testing.T) {
+	var lx *lexer.Lexer
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader(""), nil)
+
+	assert.Equal(t, "", lx.Mode())
+	assert.Equal(t, "", lx.PopMode())
+
+	lx.PushMode("a")
+	lx.PushMode("b")
+
+	assert.Equal(t, "b", lx.Mode())
+	assert.Equal(t, "b", lx.PopMode())
+	assert.Equal(t, "a", lx.Mode())
+	assert.Equal(t, "a", lx.PopMode())
+	assert.Equal(t, "", lx.Mode())
+}
+
+func TestEmbed(t *testing.T) {
+	var (
+		lexOpen  lexer.StateFn
+		lexClose lexer.StateFn
+		lx       *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	lexClose = func(lx *lexer.Lexer) lexer.StateFn {
+		if lx.AcceptSeq("</s>") {
+			lx.Emit(kindTag)
+
+			return lexOpen
+		}
+
+		return nil
+	}
+
+	lexOpen = func(lx *lexer.Lexer) lexer.StateFn {
+		if lx.AcceptSeq("<s>") {
+			lx.Emit(kindTag)
+
+			return lexer.Embed(lexWords, "</s>", lexClose)
+		}
+
+		return nil
+	}
+
+	lx = lexer.NewLexer(strings.NewReader("<s>ab\ncd</s><s></s><s>ef"), lexOpen)
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindTag, "<s>", lexer.Position{1, 1, 0}, lexer.Position{1, 4, 3}),
+		mkToken(kindWord, "ab", lexer.Position{1, 4, 3}, lexer.Position{1, 6, 5}),
+		mkToken(kindSpace, "\n", lexer.Position{1, 6, 5}, lexer.Position{2, 1, 6}),
+		mkToken(kindWord, "cd", lexer.Position{2, 1, 6}, lexer.Position{2, 3, 8}),
+		mkToken(kindTag, "</s>", lexer.Position{2, 3, 8}, lexer.Position{2, 7, 12}),
+		mkToken(kindTag, "<s>", lexer.Position{2, 7, 12}, lexer.Position{2, 10, 15}),
+		mkToken(kindTag, "</s>", lexer.Position{2, 10, 15}, lexer.Position{2, 14, 19}),
+		mkToken(kindTag, "<s>", lexer.Position{2, 14, 19}, lexer.Position{2, 17, 22}),
+		mkToken(kindWord, "ef", lexer.Position{2, 17, 22}, lexer.Position{2, 19, 24}),
+	}, slices.Collect(lx.Tokens()))
+}
+
+func TestLexerEOFToken(t *testing.T) {
+	var (
+		lx *lexer.Lexer
+		ok bool
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("ab\n"),
+		lexWords,
+		lexer.WithEOFToken(),
+	)
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		mkToken(kindSpace, "\n", lexer.Position{1, 3, 2}, lexer.Position{2, 1, 3}),
+		mkToken(lexer.KindEOF, "", lexer.Position{2, 1, 3}, lexer.Position{2, 1, 3}),
+	}, slices.Collect(lx.Tokens()))
+
+	_, ok = lx.NextToken()
+	assert.False(t, ok)
+}
@@ -0,0 +1,227 @@
+// Package lextest provides assertions on token streams for the unit
+// tests of lexers, written as the sequence of tokens expected:
+//
+//	var (
+//		Ident  = lextest.Kind(kindIdent, "Ident")
+//		Op     = lextest.Kind(kindOp, "Op")
+//		Number = lextest.Kind(kindNumber, "Number")
+//	)
+//
+//	lextest.Expect(t, lx, Ident("foo"), Op("=").At(1, 5), Number("42"), lextest.EOF)
+//
+// On a mismatch, the test fails with a listing of the expected tokens
+// beside the tokens produced, positions included, with the first
+// mismatch marked.
+package lextest // import "github.com/andrieee44/langengine/lexer/lextest"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Want describes a token expected by Expect.
+type Want struct {
+	kind   lexer.Kind
+	name   string
+	text   string
+	line   int
+	column int
+	eof    bool
+}
+
+// EOF expects the end of the stream. Without it, Expect ignores the
+// tokens following the expected ones.
+var EOF = Want{eof: true, name: "EOF"}
+
+// Kind returns a function building the Want for a token of kind with
+// the given text. The name stands for kind in failure messages; kinds
+// of tokens that no Want expects are shown with lexer.Kind.String.
+func Kind(kind lexer.Kind, name string) func(text string) Want {
+	return func(text string) Want {
+		return Want{kind: kind, name: name, text: text}
+	}
+}
+
+// Error returns the Want for a lexer.KindError token with the message
+// msg.
+func Error(msg string) Want {
+	return Want{kind: lexer.KindError, name: "Error", text: msg}
+}
+
+// At returns a copy of want that also expects the token to start at the
+// given line and column.
+func (want Want) At(line, column int) Want {
+	want.line, want.column = line, column
+
+	return want
+}
+
+// Expect reads tokens from stream and reports a failure through tb
+// unless they match wants in order: a token matches if it has the kind
+// and text expected and, for a Want built with At, starts at the
+// position expected. Once wants are exhausted, the remaining tokens are
+// left unread, unless the last Want is EOF, which expects the stream to
+// end.
+//
+// Returns true if the tokens match.
+func Expect(tb testing.TB, stream lexer.TokenStream, wants ...Want) bool {
+	var (
+		got      []lexer.Token
+		tok      lexer.Token
+		ok       bool
+		mismatch int
+		i        int
+	)
+
+	tb.Helper()
+
+	mismatch = -1
+
+	for i = 0; i < len(wants) && mismatch < 0; i++ {
+		tok, ok = stream.NextToken()
+		if ok {
+			got = append(got, tok)
+		}
+
+		if !wants[i].matches(tok, ok) {
+			mismatch = i
+		}
+	}
+
+	if mismatch < 0 {
+		return true
+	}
+
+	// The remaining tokens are listed to show where the lexer went.
+	for len(got) < len(wants) {
+		tok, ok = stream.NextToken()
+		if !ok {
+			break
+		}
+
+		got = append(got, tok)
+	}
+
+	tb.Errorf(
+		"token %d: want %s, got %s\n%s",
+		mismatch,
+		wants[mismatch],
+		describe(got, mismatch, wants),
+		listing(got, mismatch, wants),
+	)
+
+	return false
+}
+
+// String returns the description of want used in failure messages.
+func (want Want) String() string {
+	var desc string
+
+	if want.eof {
+		return "EOF"
+	}
+
+	desc = want.name + " " + strconv.Quote(want.text)
+
+	if want.line != 0 || want.column != 0 {
+		desc += fmt.Sprintf(" at %d:%d", want.line, want.column)
+	}
+
+	return desc
+}
+
+func (want Want) matches(tok lexer.Token, ok bool) bool {
+	if want.eof || !ok {
+		return want.eof == !ok
+	}
+
+	if want.line != 0 || want.column != 0 {
+		if tok.Span.Start.Line != want.line || tok.Span.Start.Column != want.column {
+			return false
+		}
+	}
+
+	return tok.Kind == want.kind && tok.Text == want.text
+}
+
+// describe returns the description of the token at index i of got,
+// using the names given by wants for its kind.
+func describe(got []lexer.Token, i int, wants []Want) string {
+	var (
+		tok  lexer.Token
+		name string
+		want Want
+	)
+
+	if i >= len(got) {
+		return "end of stream"
+	}
+
+	tok = got[i]
+	name = tok.Kind.String()
+
+	for _, want = range wants {
+		if !want.eof && want.kind == tok.Kind {
+			name = want.name
+
+			break
+		}
+	}
+
+	return fmt.Sprintf(
+		"%s %q at %d:%d",
+		name,
+		tok.Text,
+		tok.Span.Start.Line,
+		tok.Span.Start.Column,
+	)
+}
+
+// listing returns the expected tokens beside the tokens produced, one
+// pair per line, with the line of the mismatch marked.
+func listing(got []lexer.Token, mismatch int, wants []Want) string {
+	var (
+		sb     strings.Builder
+		tw     *tabwriter.Writer
+		marker string
+		i      int
+	)
+
+	tw = tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\t#\twant\tgot")
+
+	for i = 0; i < max(len(wants), len(got)); i++ {
+		marker = ""
+		if i == mismatch {
+			marker = ">"
+		}
+
+		fmt.Fprintf(
+			tw,
+			"%s\t%d\t%s\t%s\n",
+			marker,
+			i,
+			wantAt(wants, i),
+			describe(got, i, wants),
+		)
+	}
+
+	tw.Flush()
+
+	return sb.String()
+}
+
+// wantAt returns the description of the Want at index i, or an empty
+// string past the end of wants.
+func wantAt(wants []Want, i int) string {
+	if i >= len(wants) {
+		return ""
+	}
+
+	return wants[i].String()
+}
@@ -0,0 +1,155 @@
+package lextest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexer/lextest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindIdent lexer.Kind = lexer.KindUser + iota
+	kindOp
+	kindNumber
+)
+
+var (
+	ident  = lextest.Kind(kindIdent, "Ident")
+	op     = lextest.Kind(kindOp, "Op")
+	number = lextest.Kind(kindNumber, "Number")
+)
+
+// recorder is a testing.TB recording failures instead of reporting
+// them.
+type recorder struct {
+	testing.TB
+
+	msgs []string
+}
+
+func (rec *recorder) Helper() {}
+
+func (rec *recorder) Errorf(format string, args ...any) {
+	rec.msgs = append(rec.msgs, fmt.Sprintf(format, args...))
+}
+
+func lexAssign(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.AcceptRun(" ") > 0:
+		lx.Ignore()
+	case lx.AcceptRun("abcdefghijklmnopqrstuvwxyz") > 0:
+		lx.Emit(kindIdent)
+	case lx.AcceptRun("0123456789") > 0:
+		lx.Emit(kindNumber)
+	case lx.AcceptRun("=") > 0:
+		lx.Emit(kindOp)
+	case lx.Peek() == lexer.EOF:
+		return nil
+	default:
+		lx.Next()
+
+		return lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexAssign
+}
+
+func TestExpect(t *testing.T) {
+	type testData struct {
+		src   string
+		wants []lextest.Want
+		msgs  []string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"match": {
+			src: "foo = 42",
+			wants: []lextest.Want{
+				ident("foo"),
+				op("=").At(1, 5),
+				number("42"),
+				lextest.EOF,
+			},
+		},
+		"prefix": {
+			src:   "foo = 42",
+			wants: []lextest.Want{ident("foo")},
+		},
+		"error": {
+			src:   "a ?",
+			wants: []lextest.Want{ident("a"), lextest.Error(`unexpected "?"`)},
+		},
+		"text": {
+			src:   "foo == 42",
+			wants: []lextest.Want{ident("foo"), op("="), number("42")},
+			msgs: []string{strings.Join([]string{
+				`token 1: want Op "=", got Op "==" at 1:5`,
+				`   #  want         got`,
+				`   0  Ident "foo"  Ident "foo" at 1:1`,
+				`>  1  Op "="       Op "==" at 1:5`,
+				`   2  Number "42"  Number "42" at 1:8`,
+				``,
+			}, "\n")},
+		},
+		"position": {
+			src:   "foo  = 42",
+			wants: []lextest.Want{ident("foo"), op("=").At(1, 5)},
+			msgs: []string{strings.Join([]string{
+				`token 1: want Op "=" at 1:5, got Op "=" at 1:6`,
+				`   #  want           got`,
+				`   0  Ident "foo"    Ident "foo" at 1:1`,
+				`>  1  Op "=" at 1:5  Op "=" at 1:6`,
+				``,
+			}, "\n")},
+		},
+		"trailing": {
+			src:   "foo = 42",
+			wants: []lextest.Want{ident("foo"), op("="), lextest.EOF},
+			msgs: []string{strings.Join([]string{
+				`token 2: want EOF, got Kind(4) "42" at 1:7`,
+				`   #  want         got`,
+				`   0  Ident "foo"  Ident "foo" at 1:1`,
+				`   1  Op "="       Op "=" at 1:5`,
+				`>  2  EOF          Kind(4) "42" at 1:7`,
+				``,
+			}, "\n")},
+		},
+		"short": {
+			src:   "foo",
+			wants: []lextest.Want{ident("foo"), op("=")},
+			msgs: []string{strings.Join([]string{
+				`token 1: want Op "=", got end of stream`,
+				`   #  want         got`,
+				`   0  Ident "foo"  Ident "foo" at 1:1`,
+				`>  1  Op "="       end of stream`,
+				``,
+			}, "\n")},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var rec *recorder
+
+			rec = &recorder{TB: t}
+
+			assert.Equal(t, len(test.msgs) == 0, lextest.Expect(
+				rec,
+				lexer.NewLexer(strings.NewReader(test.src), lexAssign),
+				test.wants...,
+			))
+			assert.Equal(t, test.msgs, rec.msgs)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package lexer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the Reader, and any Lexer built on it, log to logger.
+// Error tokens are logged at slog.LevelError, error recovery through
+// SyncTo, SyncToFunc, and SkipToLineStart at slog.LevelInfo, and mode
+// transitions at slog.LevelDebug. Every record carries the positions
+// involved as structured attributes, along with the "source" attribute
+// when a source name is set with SetSource. Records are logged with the
+// context given to WithPprofLabels, if any. The child Lexers of Embed,
+// Include, and SubLexer log to logger too, under their own source.
+func WithLogger(logger *slog.Logger) Option {
+	return func(lrd *Reader) {
+		lrd.logger = logger
+	}
+}
+
+// LogValue implements slog.LogValuer, so that a Position is logged as a
+// group of its line, column, and offset.
+func (pos Position) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("line", pos.Line),
+		slog.Int("column", pos.Column),
+		slog.Int("offset", pos.Offset),
+	)
+}
+
+func (lrd *Reader) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	var ctx context.Context
+
+	if lrd.logger == nil {
+		return
+	}
+
+	ctx = lrd.labels
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !lrd.logger.Enabled(ctx, level) {
+		return
+	}
+
+	if lrd.source != "" {
+		attrs = append(attrs, slog.String("source", lrd.source))
+	}
+
+	lrd.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (lrd *Reader) logRecovery(from Position, count int) {
+	lrd.log(
+		slog.LevelInfo,
+		"lexer recovery",
+		slog.Int("skipped", count),
+		slog.Any("from", from),
+		slog.Any("to", lrd.currentPos),
+	)
+}
+
+func (lx *Lexer) logError(tok Token) {
+	lx.log(
+		slog.LevelError,
+		"lexer error",
+		slog.String("error", tok.Text),
+		slog.Any("start", tok.Span.Start),
+		slog.Any("end", tok.Span.End),
+	)
+}
+
+func (lx *Lexer) logMode(msg, mode string) {
+	lx.log(
+		slog.LevelDebug,
+		msg,
+		slog.String("mode", mode),
+		slog.Any("pos", lx.currentPos),
+	)
+}
@@ -0,0 +1,93 @@
+package lexer_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(sb *strings.Builder, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(sb, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	var (
+		sb    strings.Builder
+		lx    *lexer.Lexer
+		state lexer.StateFn
+		tok   lexer.Token
+	)
+
+	t.Parallel()
+
+	state = func(lx *lexer.Lexer) lexer.StateFn {
+		switch {
+		case lx.Peek() == lexer.EOF:
+			return nil
+		case lx.Accept("{"):
+			lx.PushMode("block")
+			lx.Emit(kindPunct)
+		case lx.Accept("}"):
+			lx.PopMode()
+			lx.Emit(kindPunct)
+		case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+			lx.Emit(kindWord)
+		default:
+			lx.Next()
+			lx.Errorf("unexpected %q", lx.PeekToken())
+			lx.SkipToLineStart()
+			lx.Ignore()
+		}
+
+		return state
+	}
+
+	lx = lexer.NewLexer(
+		strings.NewReader("{a}?b c\nd"),
+		state,
+		lexer.WithLogger(newTestLogger(&sb, slog.LevelDebug)),
+	)
+	lx.SetSource("input.txt")
+
+	for tok = range lx.Tokens() {
+		_ = tok
+	}
+
+	assert.Equal(t, strings.Join([]string{
+		`level=DEBUG msg="lexer push mode" mode=block pos.line=1 pos.column=2 pos.offset=1 source=input.txt`,
+		`level=DEBUG msg="lexer pop mode" mode=block pos.line=1 pos.column=4 pos.offset=3 source=input.txt`,
+		`level=ERROR msg="lexer error" error="unexpected \"?\"" start.line=1 start.column=4 start.offset=3 end.line=1 end.column=5 end.offset=4 source=input.txt`,
+		`level=INFO msg="lexer recovery" skipped=4 from.line=1 from.column=5 from.offset=4 to.line=2 to.column=1 to.offset=8 source=input.txt`,
+		"",
+	}, "\n"), sb.String())
+}
+
+func TestWithLoggerLevel(t *testing.T) {
+	var (
+		sb  strings.Builder
+		lrd *lexer.Reader
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("ab;c"),
+		lexer.WithLogger(newTestLogger(&sb, slog.LevelWarn)),
+	)
+
+	assert.Equal(t, 2, lrd.SyncTo(";"))
+	assert.Empty(t, sb.String())
+}
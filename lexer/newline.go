@@ -0,0 +1,59 @@
+package lexer
+
+// WithFinalNewline makes a Lexer emit a token of the given kind once
+// its final state has returned at the end of input that is not empty
+// and does not end with a line feed, so that a grammar assuming every
+// line to be terminated, such as one ending statements at newlines,
+// sees a terminator after the last line too. The token has empty text
+// and an empty span at the end of input, which tells it apart from a
+// newline of the input, and comes before the KindEOF token of
+// WithEOFToken. Nothing is emitted if the final state returned before
+// the end of input, as after an error.
+func WithFinalNewline(kind Kind) Option {
+	return func(lrd *Reader) {
+		lrd.finalNewline = &kind
+	}
+}
+
+// WithFinalNewlineCheck makes a Lexer report a diagnostic of the given
+// severity, spanning the end of input, in the cases where
+// WithFinalNewline emits a token, as style checkers requiring files to
+// end with a newline do. The diagnostic goes to the handler set with
+// WithDiagnostics, as those of Report do. The two options may be
+// combined to both flag the missing newline and recover from it.
+func WithFinalNewlineCheck(sev Severity) Option {
+	return func(lrd *Reader) {
+		lrd.newlineCheck = &sev
+	}
+}
+
+// finish closes the layout of a whitespace profile and applies the
+// final newline options once the final state has returned.
+func (lx *Lexer) finish() {
+	var (
+		pos    Position
+		closed bool
+	)
+
+	closed = lx.closeLayout()
+
+	if lx.finalNewline == nil && lx.newlineCheck == nil ||
+		!lx.AtEOF() ||
+		lx.AtLineStart() {
+		return
+	}
+
+	pos = lx.CurrentPosition()
+
+	if lx.newlineCheck != nil {
+		lx.report(Diagnostic{
+			Span:     Span{Start: pos, End: pos},
+			Message:  "missing newline at end of input",
+			Severity: *lx.newlineCheck,
+		})
+	}
+
+	if lx.finalNewline != nil && !closed {
+		lx.push(Token{Kind: *lx.finalNewline, Span: Span{Start: pos, End: pos}})
+	}
+}
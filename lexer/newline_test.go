@@ -0,0 +1,112 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerFinalNewline(t *testing.T) {
+	type testData struct {
+		src   string
+		want  []lexer.Token
+		diags []lexer.Diagnostic
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		diags   []lexer.Diagnostic
+		lx      *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"missing": {
+			src: "ab\ncd",
+			want: []lexer.Token{
+				mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+				mkToken(kindSpace, "\n", lexer.Position{1, 3, 2}, lexer.Position{2, 1, 3}),
+				mkToken(kindWord, "cd", lexer.Position{2, 1, 3}, lexer.Position{2, 3, 5}),
+				mkToken(kindSpace, "", lexer.Position{2, 3, 5}, lexer.Position{2, 3, 5}),
+				mkToken(lexer.KindEOF, "", lexer.Position{2, 3, 5}, lexer.Position{2, 3, 5}),
+			},
+			diags: []lexer.Diagnostic{{
+				Span: lexer.Span{
+					Start: lexer.Position{2, 3, 5},
+					End:   lexer.Position{2, 3, 5},
+				},
+				Message:  "missing newline at end of input",
+				Severity: lexer.SeverityWarning,
+			}},
+		},
+		"present": {
+			src: "ab\r\n",
+			want: []lexer.Token{
+				mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+				mkToken(kindSpace, "\r\n", lexer.Position{1, 3, 2}, lexer.Position{2, 1, 4}),
+				mkToken(lexer.KindEOF, "", lexer.Position{2, 1, 4}, lexer.Position{2, 1, 4}),
+			},
+		},
+		"empty": {
+			want: []lexer.Token{
+				mkToken(lexer.KindEOF, "", lexer.Position{1, 1, 0}, lexer.Position{1, 1, 0}),
+			},
+		},
+		"stopped early": {
+			src: "ab 1 cd",
+			want: []lexer.Token{
+				mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+				mkToken(kindSpace, " ", lexer.Position{1, 3, 2}, lexer.Position{1, 4, 3}),
+				mkToken(lexer.KindError, `unexpected "1"`, lexer.Position{1, 4, 3}, lexer.Position{1, 5, 4}),
+				mkToken(lexer.KindEOF, "", lexer.Position{1, 5, 4}, lexer.Position{1, 5, 4}),
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		diags = nil
+		lx = lexer.NewLexer(
+			strings.NewReader(test.src),
+			lexWords,
+			lexer.WithEOFToken(),
+			lexer.WithFinalNewline(kindSpace),
+			lexer.WithFinalNewlineCheck(lexer.SeverityWarning),
+			lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+				diags = append(diags, diag)
+			}),
+		)
+
+		assert.Equal(t, test.want, slices.Collect(lx.Tokens()), name)
+		assert.Equal(t, test.diags, diags, name)
+	}
+}
+
+func TestLexerFinalNewlineCheckOnly(t *testing.T) {
+	var (
+		lx    *lexer.Lexer
+		diags []lexer.Diagnostic
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("ab"),
+		lexWords,
+		lexer.WithFinalNewlineCheck(lexer.SeverityError),
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag)
+		}),
+	)
+
+	assert.Equal(t, []kindText{{kindWord, "ab"}}, collectKinds(lx))
+
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "1:3: missing newline at end of input", diags[0].String())
+	}
+}
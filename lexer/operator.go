@@ -0,0 +1,102 @@
+package lexer
+
+import "strings"
+
+// OperatorTable is a compiled set of operators, such as "+", "++", "+=",
+// and "->", matched by Reader.AcceptOperator using longest-match
+// semantics. A new OperatorTable is constructed with NewOperatorTable
+// and is safe for concurrent use once built.
+type OperatorTable struct {
+	root trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	terminal bool
+}
+
+// NewOperatorTable compiles the given operators into a trie. The order
+// of ops does not matter and duplicates are ignored, as are empty
+// strings.
+func NewOperatorTable(ops []string) *OperatorTable {
+	var (
+		tbl  *OperatorTable
+		op   string
+		node *trieNode
+		char rune
+	)
+
+	tbl = &OperatorTable{}
+
+	for _, op = range ops {
+		if op == "" {
+			continue
+		}
+
+		node = &tbl.root
+
+		for _, char = range op {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+
+			if node.children[char] == nil {
+				node.children[char] = &trieNode{}
+			}
+
+			node = node.children[char]
+		}
+
+		node.terminal = true
+	}
+
+	return tbl
+}
+
+// AcceptOperator consumes the longest operator in tbl that the input
+// starts with. It advances the reader rune by rune through the trie and
+// then backs up to the end of the longest complete operator seen.
+//
+// Returns the consumed operator and true on a match. Returns an empty
+// string and false if no operator matches, in which case the reader
+// position is restored via Backup.
+func (lrd *Reader) AcceptOperator(tbl *OperatorTable) (string, bool) {
+	var (
+		node    *trieNode
+		sb      strings.Builder
+		char    rune
+		depth   int
+		before  int
+		longest int
+		match   string
+	)
+
+	node = &tbl.root
+	depth = len(lrd.history)
+	longest = depth
+
+	for {
+		before = len(lrd.history)
+
+		char = lrd.Next()
+		if len(lrd.history) == before {
+			break
+		}
+
+		node = node.children[char]
+		if node == nil {
+			break
+		}
+
+		sb.WriteRune(char)
+
+		if node.terminal {
+			longest = len(lrd.history)
+			match = sb.String()
+		}
+	}
+
+	lrd.Backup(len(lrd.history) - longest)
+
+	return match, longest != depth
+}
@@ -0,0 +1,147 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+func TestReaderAcceptOperator(t *testing.T) {
+	var tbl *lexer.OperatorTable
+
+	t.Parallel()
+
+	tbl = lexer.NewOperatorTable([]string{
+		"=", "==", "===", "+", "++", "+=", "-", "->", "=>", "<<=", "",
+		"≠", "≠≠",
+	})
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[string]{
+		"Single": {
+			content: "+a",
+			afterOp: "+",
+			result:  "+",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"Longest": {
+			content: "===x",
+			afterOp: "===",
+			result:  "===",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"PartialOnly": {
+			content: "<<x",
+			afterOp: "",
+			result:  "!",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"PrefixOfLonger": {
+			content: "==!",
+			afterOp: "==",
+			result:  "==",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"Adjacent": {
+			content: "++=",
+			afterOp: "++",
+			result:  "++",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"AtEOF": {
+			content: "->",
+			afterOp: "->",
+			result:  "->",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"NUL": {
+			content: "+\x00b",
+			afterOp: "+",
+			result:  "+",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"NULOnly": {
+			content: "\x00+",
+			afterOp: "",
+			result:  "!",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"NoMatch": {
+			content: "abc",
+			afterOp: "",
+			result:  "!",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"EmptyContent": {
+			content: "",
+			afterOp: "",
+			result:  "!",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+		"Unicode": {
+			// ≠ U+2260 (3 bytes)
+			content: "≠≠≠",
+			afterOp: "≠≠",
+			result:  "≠≠",
+			op: func(lrd *lexer.Reader) string {
+				return mkOperatorResult(lrd.AcceptOperator(tbl))
+			},
+		},
+	})
+}
+
+func BenchmarkReaderAcceptOperator(b *testing.B) {
+	var (
+		tbl     *lexer.OperatorTable
+		content string
+		lrd     *lexer.Reader
+		ok      bool
+	)
+
+	tbl = lexer.NewOperatorTable([]string{
+		"=", "==", "!=", "<", "<=", "<<", "<<=", ">", ">=", ">>", ">>=",
+		"+", "++", "+=", "-", "--", "-=", "->", "*", "*=", "/", "/=",
+	})
+	content = strings.Repeat("<<= -> ++ != ", 1024)
+
+	for b.Loop() {
+		lrd = lexer.NewReader(strings.NewReader(content))
+
+		for {
+			_, ok = lrd.AcceptOperator(tbl)
+			if !ok && !lrd.Accept(" ") {
+				break
+			}
+
+			lrd.Ignore()
+		}
+	}
+}
+
+func mkOperatorResult(op string, ok bool) string {
+	if !ok {
+		return "!"
+	}
+
+	return op
+}
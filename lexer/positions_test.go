@@ -0,0 +1,85 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithoutPositions(t *testing.T) {
+	var (
+		lrd  *lexer.Reader
+		text string
+		pos  lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("ab\ncd\nef"),
+		lexer.WithoutPositions(),
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+
+	lrd.Until("e")
+	lrd.Ignore()
+	lrd.Next()
+
+	assert.Equal(t, lexer.Position{1, 1, 7}, lrd.CurrentPosition())
+	assert.Equal(t, "ef", lrd.LineText())
+
+	text, pos = lrd.Emit()
+
+	assert.Equal(t, "e", text)
+	assert.Equal(t, lexer.Position{1, 1, 6}, pos)
+
+	lrd = lexer.NewReader(strings.NewReader("a\nb"), lexer.WithoutPositions())
+	_, _ = lrd.ReadN(3)
+
+	assert.Equal(t, lexer.Position{1, 1, 3}, lrd.CurrentPosition())
+}
+
+func BenchmarkReaderNext(b *testing.B) {
+	type testData struct {
+		content string
+		opts    []lexer.Option
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		ascii   string
+		wide    string
+	)
+
+	ascii = strings.Repeat("the quick brown fox jumps over the lazy dog\n", 1000)
+	wide = strings.Repeat("日本語のテキストと English text\n", 1000)
+
+	testTbl = map[string]testData{
+		"ASCII":                        {ascii, nil},
+		"ASCIIWithoutPositions":        {ascii, []lexer.Option{lexer.WithoutPositions()}},
+		"DisplayWidth":                 {wide, []lexer.Option{lexer.WithColumnWidth(lexer.DisplayWidth)}},
+		"DisplayWidthLazyPositions":    {wide, []lexer.Option{lexer.WithColumnWidth(lexer.DisplayWidth), lexer.WithLazyPositions()}},
+		"DisplayWidthWithoutPositions": {wide, []lexer.Option{lexer.WithColumnWidth(lexer.DisplayWidth), lexer.WithoutPositions()}},
+		"ASCIILazyPositions":           {ascii, []lexer.Option{lexer.WithLazyPositions()}},
+	}
+
+	for name, test = range testTbl {
+		b.Run(name, func(b *testing.B) {
+			var lrd *lexer.Reader
+
+			b.SetBytes(int64(len(test.content)))
+
+			for b.Loop() {
+				lrd = lexer.NewReader(strings.NewReader(test.content), test.opts...)
+
+				for lrd.Next() != lexer.EOF {
+					lrd.Ignore()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package lexer
+
+// AcceptShebang consumes an interpreter line such as "#!/bin/sh" when
+// it opens the input, as allowed by most scripting languages. The line
+// feed ending the line, and a carriage return preceding it, are left
+// unconsumed.
+//
+// Returns true if a shebang line was consumed. Returns false, consuming
+// nothing, if the Reader is not at the start of input or the input does
+// not start with "#!".
+func (lrd *Reader) AcceptShebang() bool {
+	if !lrd.AtStart() || !lrd.HasPrefix("#!") {
+		return false
+	}
+
+	lrd.Until("\n")
+
+	if lrd.Last() == '\r' {
+		lrd.Backup(1)
+	}
+
+	return true
+}
+
+// AcceptFrontMatter consumes a front matter block opening the input, as
+// used by static site generators: a line consisting of delim, such as
+// "---" for YAML or "+++" for TOML, followed by any number of lines and
+// a closing line consisting of delim again. The block is consumed up to
+// and including the closing delimiter, leaving the line feed after it
+// unconsumed. Lines may end with a line feed or a carriage return and a
+// line feed.
+//
+// Returns true if a block was consumed. Returns false, consuming
+// nothing, if the Reader is not at the start of input or the first line
+// is not delim. Returns false and ErrUnterminated, consuming nothing, if
+// the input ends before the closing delimiter, or ErrEmptyPattern if
+// delim is empty.
+func (lrd *Reader) AcceptFrontMatter(delim string) (bool, error) {
+	var ok bool
+
+	if delim == "" {
+		return false, ErrEmptyPattern
+	}
+
+	if !lrd.AtStart() || !lrd.acceptDelimLine(delim) {
+		return false, nil
+	}
+
+	lrd.Accept("\r")
+	lrd.Accept("\n")
+
+	for !lrd.acceptDelimLine(delim) {
+		_, ok = lrd.UntilInclusive("\n")
+		if !ok {
+			lrd.Reject()
+
+			return false, ErrUnterminated
+		}
+	}
+
+	return true, nil
+}
+
+// Preamble returns a state that emits the shebang line opening the
+// input as a token of kind shebang or, failing that, the "---" front
+// matter block opening the input as a token of kind frontMatter, and
+// then continues in the next state. Input with neither is left to next
+// untouched. Unterminated front matter is reported with a KindError
+// token and lexed by next as ordinary input.
+func Preamble(shebang, frontMatter Kind, next StateFn) StateFn {
+	return func(lx *Lexer) StateFn {
+		var (
+			ok  bool
+			err error
+		)
+
+		if lx.AcceptShebang() {
+			lx.Emit(shebang)
+
+			return next
+		}
+
+		ok, err = lx.AcceptFrontMatter("---")
+
+		switch {
+		case err != nil:
+			lx.Errorf("front matter: %v", err)
+		case ok:
+			lx.Emit(frontMatter)
+		}
+
+		return next
+	}
+}
+
+// acceptDelimLine consumes delim if it makes up the whole of the line
+// starting at the current position.
+func (lrd *Reader) acceptDelimLine(delim string) bool {
+	var n int
+
+	n = lrd.BackupLimit()
+
+	if !lrd.AcceptSeq(delim) {
+		return false
+	}
+
+	if lrd.AtEOF() || lrd.HasPrefix("\n") || lrd.HasPrefix("\r\n") {
+		return true
+	}
+
+	lrd.Backup(lrd.BackupLimit() - n)
+
+	return false
+}
@@ -0,0 +1,109 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreamble(t *testing.T) {
+	type testData struct {
+		kinds []lexer.Kind
+		texts []string
+	}
+
+	var (
+		testTbl map[string]testData
+		content string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"#!/bin/sh\r\necho": {
+			kinds: []lexer.Kind{kindShebang, kindSpace, kindWord},
+			texts: []string{"#!/bin/sh", "\r\n", "echo"},
+		},
+		"#!": {
+			kinds: []lexer.Kind{kindShebang},
+			texts: []string{"#!"},
+		},
+		"---\ntitle: x\n--- no\n---\nbody": {
+			kinds: []lexer.Kind{kindFrontMatter, kindSpace, kindWord},
+			texts: []string{"---\ntitle: x\n--- no\n---", "\n", "body"},
+		},
+		"---\r\n---": {
+			kinds: []lexer.Kind{kindFrontMatter},
+			texts: []string{"---\r\n---"},
+		},
+		"----\nx": {
+			kinds: []lexer.Kind{lexer.KindError},
+			texts: []string{`unexpected "-"`},
+		},
+		"---\nx": {
+			kinds: []lexer.Kind{lexer.KindError, lexer.KindError},
+			texts: []string{
+				"front matter: lexer: unterminated construct",
+				`unexpected "-"`,
+			},
+		},
+		"a\n#!": {
+			kinds: []lexer.Kind{kindWord, kindSpace, lexer.KindError},
+			texts: []string{"a", "\n", `unexpected "#"`},
+		},
+	}
+
+	for content, test = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			var (
+				kinds []lexer.Kind
+				texts []string
+				tok   lexer.Token
+			)
+
+			for tok = range lexer.NewLexer(
+				strings.NewReader(content),
+				lexer.Preamble(kindShebang, kindFrontMatter, lexWords),
+			).Tokens() {
+				kinds = append(kinds, tok.Kind)
+				texts = append(texts, tok.Text)
+			}
+
+			assert.Equal(t, test.kinds, kinds)
+			assert.Equal(t, test.texts, texts)
+		})
+	}
+}
+
+func TestReaderAcceptFrontMatter(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		ok  bool
+		err error
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("+++\na = 1\n+++\n"))
+	ok, err = lrd.AcceptFrontMatter("+++")
+
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "+++\na = 1\n+++", lrd.PeekToken())
+
+	ok, err = lrd.AcceptFrontMatter("+++")
+
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	lrd = lexer.NewReader(strings.NewReader("+++\na = 1\n"))
+	ok, err = lrd.AcceptFrontMatter("+++")
+
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, lexer.ErrUnterminated)
+	assert.Empty(t, lrd.PeekToken())
+	assert.True(t, lrd.AtStart())
+}
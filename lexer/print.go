@@ -0,0 +1,130 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInexact is returned, wrapped with the offending position, when a
+// token stream does not reproduce its source byte for byte.
+var ErrInexact = errors.New("lexer: token stream is not exact")
+
+// WriteTokens writes the text of every token of stream to w, in order,
+// reconstructing the source the stream was lexed from. It is the
+// printer at the end of a formatter or refactoring tool that rewrites
+// the stream, and only reproduces the source exactly if the Lexer
+// emits every byte of input, trivia included, in some token.
+//
+// Every token is checked as it is written: it must start at the offset
+// where the previous token ended, its text must be as long as its span,
+// and it must come from the input rather than from the Expand pass or
+// an included file. At the first token failing a check, WriteTokens
+// stops and returns an error wrapping ErrInexact, without writing that
+// token. Returns the number of bytes written and the first error
+// encountered.
+func WriteTokens(w io.Writer, stream TokenStream) (int64, error) {
+	var (
+		total  int64
+		n      int
+		offset int
+		first  bool
+		tok    Token
+		err    error
+	)
+
+	first = true
+
+	for tok = range Tokens(stream) {
+		err = checkExact(tok, offset, first)
+		if err != nil {
+			return total, err
+		}
+
+		n, err = io.WriteString(w, tok.Text)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+
+		offset = tok.Span.End.Offset
+		first = false
+	}
+
+	return total, nil
+}
+
+// VerifyRoundTrip reports whether stream reproduces src byte for byte,
+// where src is the complete input the stream was lexed from. Beyond
+// the checks of WriteTokens, the text of every token must equal the
+// bytes of src its span covers, and the tokens must cover src from the
+// first byte to the last. It doubles as a thorough test of a grammar,
+// catching input that the Lexer drops or rewrites.
+//
+// Returns nil if the stream is exact, or an error wrapping ErrInexact
+// that locates the first discrepancy.
+func VerifyRoundTrip(src []byte, stream TokenStream) error {
+	var (
+		end Position
+		tok Token
+		err error
+	)
+
+	for tok = range Tokens(stream) {
+		err = checkExact(tok, end.Offset, false)
+		if err != nil {
+			return err
+		}
+
+		if string(tok.Span.Raw(src)) != tok.Text {
+			return inexact(
+				tok.Span.Start,
+				"token %q differs from the source",
+				tok.Text,
+			)
+		}
+
+		end = tok.Span.End
+	}
+
+	if end.Offset != len(src) {
+		return inexact(end, "%d bytes of source are not covered", len(src)-end.Offset)
+	}
+
+	return nil
+}
+
+// checkExact checks tok against the end offset of the previous token.
+func checkExact(tok Token, offset int, first bool) error {
+	switch {
+	case tok.Origin != nil:
+		return inexact(tok.Span.Start, "token %q comes from an expansion", tok.Text)
+	case tok.Source != "":
+		return inexact(tok.Span.Start, "token %q comes from %s", tok.Text, tok.Source)
+	case !first && tok.Span.Start.Offset != offset:
+		return inexact(
+			tok.Span.Start,
+			"token %q does not start where the previous one ended",
+			tok.Text,
+		)
+	case len(tok.Text) != tok.Span.End.Offset-tok.Span.Start.Offset:
+		return inexact(
+			tok.Span.Start,
+			"token %q does not match its span",
+			tok.Text,
+		)
+	default:
+		return nil
+	}
+}
+
+func inexact(pos Position, format string, args ...any) error {
+	return fmt.Errorf(
+		"%w: %d:%d: %s",
+		ErrInexact,
+		pos.Line,
+		pos.Column,
+		fmt.Sprintf(format, args...),
+	)
+}
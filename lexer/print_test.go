@@ -0,0 +1,142 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTokens(t *testing.T) {
+	var (
+		sb  strings.Builder
+		n   int64
+		err error
+	)
+
+	t.Parallel()
+
+	n, err = lexer.WriteTokens(&sb, lexer.NewLexer(
+		strings.NewReader("héllo  wörld\n"),
+		lexWords,
+	))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), n)
+	assert.Equal(t, "héllo  wörld\n", sb.String())
+
+	sb.Reset()
+
+	n, err = lexer.WriteTokens(&sb, lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		lexFields,
+	))
+
+	assert.EqualError(
+		t,
+		err,
+		`lexer: token stream is not exact: 1:4: token "cd" does not start where the previous one ended`,
+	)
+	assert.ErrorIs(t, err, lexer.ErrInexact)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, "ab", sb.String())
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	type testData struct {
+		stream func(src string) lexer.TokenStream
+		msg    string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Exact": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.NewLexer(strings.NewReader(src), lexWords)
+			},
+		},
+		"Error": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.NewLexer(strings.NewReader(src+"!"), lexWords)
+			},
+			msg: `1:12: token "unexpected \"!\"" does not match its span`,
+		},
+		"Rewritten": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.Chain(
+					lexer.NewLexer(strings.NewReader(src), lexWords),
+					lexer.Map(func(tok lexer.Token) lexer.Token {
+						tok.Text = strings.ToUpper(tok.Text)
+
+						return tok
+					}),
+				)
+			},
+			msg: `1:1: token "HELLO" differs from the source`,
+		},
+		"Truncated": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.NewLexer(strings.NewReader(src[:5]), lexWords)
+			},
+			msg: "1:6: 6 bytes of source are not covered",
+		},
+		"Expanded": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.Chain(
+					lexer.NewLexer(strings.NewReader(src), lexWords),
+					lexer.Expand(func(tok lexer.Token) bool {
+						return tok.Text == "world"
+					}, func(tok lexer.Token) []lexer.Token {
+						tok.Text = "earth"
+
+						return []lexer.Token{tok}
+					}),
+				)
+			},
+			msg: `1:7: token "earth" comes from an expansion`,
+		},
+		"Included": {
+			stream: func(src string) lexer.TokenStream {
+				return lexer.Chain(
+					lexer.NewLexer(strings.NewReader(src), lexWords),
+					lexer.Map(func(tok lexer.Token) lexer.Token {
+						if tok.Text == "world" {
+							tok.Source = "world.txt"
+						}
+
+						return tok
+					}),
+				)
+			},
+			msg: `1:7: token "world" comes from world.txt`,
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var err error
+
+			err = lexer.VerifyRoundTrip(
+				[]byte("hello world"),
+				test.stream("hello world"),
+			)
+
+			if test.msg == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, lexer.ErrInexact)
+			assert.EqualError(t, err, "lexer: token stream is not exact: "+test.msg)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package lexer
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Profile collects statistics about the state functions run by the
+// Lexers it is attached to with WithProfile, so that the states or
+// rules responsible for most of the lexing time can be found without a
+// CPU profile. A Profile may be shared by concurrently running Lexers,
+// and its statistics accumulate until Reset is called.
+type Profile struct {
+	mu      sync.Mutex
+	entries map[string]*ProfileEntry
+}
+
+// ProfileEntry holds the statistics of a single state function, or of
+// a single label set with Lexer.ProfileLabel.
+type ProfileEntry struct {
+	// Name is the label of the entry, which defaults to the name of the
+	// state function as reported by the runtime.
+	Name string
+
+	// Calls is the number of times the state function ran.
+	Calls int
+
+	// Tokens is the number of tokens emitted by the state function.
+	Tokens int
+
+	// Bytes is the number of input bytes consumed by the state function.
+	Bytes int
+
+	// Time is the total wall time spent in the state function.
+	Time time.Duration
+}
+
+// NewProfile constructs and returns an empty Profile.
+func NewProfile() *Profile {
+	return &Profile{
+		entries: make(map[string]*ProfileEntry),
+	}
+}
+
+// WithProfile makes a Lexer record into profile the time spent, the
+// tokens emitted, and the bytes consumed by every state function it
+// runs. The Reader itself is unaffected. Profiling adds a clock read
+// per state, so it is meant for diagnosing throughput rather than for
+// production use. Tokens delivered through Embed are attributed to the
+// embedding state.
+func WithProfile(profile *Profile) Option {
+	return func(lrd *Reader) {
+		lrd.profile = profile
+	}
+}
+
+// Entries returns a snapshot of the statistics collected so far,
+// sorted by decreasing time and then by name.
+func (profile *Profile) Entries() []ProfileEntry {
+	var (
+		entries []ProfileEntry
+		entry   *ProfileEntry
+	)
+
+	profile.mu.Lock()
+	defer profile.mu.Unlock()
+
+	for _, entry = range profile.entries {
+		entries = append(entries, *entry)
+	}
+
+	slices.SortFunc(entries, func(a, b ProfileEntry) int {
+		return cmp.Or(cmp.Compare(b.Time, a.Time), cmp.Compare(a.Name, b.Name))
+	})
+
+	return entries
+}
+
+// Reset discards the statistics collected so far.
+func (profile *Profile) Reset() {
+	profile.mu.Lock()
+	defer profile.mu.Unlock()
+
+	clear(profile.entries)
+}
+
+// WriteReport writes the entries of the profile to w as an aligned
+// table, one entry per line in the order returned by Entries, along
+// with each entry's share of the total time.
+func (profile *Profile) WriteReport(w io.Writer) error {
+	var (
+		tw      *tabwriter.Writer
+		entries []ProfileEntry
+		entry   ProfileEntry
+		total   time.Duration
+		share   float64
+	)
+
+	entries = profile.Entries()
+
+	for _, entry = range entries {
+		total += entry.Time
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "calls\ttokens\tbytes\ttime\t%\tstate\n")
+
+	for _, entry = range entries {
+		share = 0
+		if total > 0 {
+			share = 100 * float64(entry.Time) / float64(total)
+		}
+
+		fmt.Fprintf(
+			tw,
+			"%d\t%d\t%d\t%s\t%.1f\t%s\n",
+			entry.Calls,
+			entry.Tokens,
+			entry.Bytes,
+			entry.Time,
+			share,
+			entry.Name,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// ProfileLabel attributes the running state function to label in the
+// profile attached with WithProfile, instead of to the name of the state
+// function. It lets a state function that dispatches on the input, such
+// as a table-driven rule matcher, report each rule separately. The label
+// only applies to the current run of the state function, and
+// ProfileLabel does nothing when no profile is attached.
+func (lx *Lexer) ProfileLabel(label string) {
+	lx.label = label
+}
+
+func (profile *Profile) run(lx *Lexer, state StateFn) StateFn {
+	var (
+		next   StateFn
+		begin  time.Time
+		offset int
+		queued int
+		name   string
+	)
+
+	lx.label = ""
+	offset = lx.currentPos.Offset
+	queued = len(lx.queue)
+	begin = time.Now()
+
+	next = state(lx)
+
+	name = lx.label
+	if name == "" {
+		name = stateName(state)
+	}
+
+	profile.record(ProfileEntry{
+		Name:   name,
+		Calls:  1,
+		Tokens: len(lx.queue) - queued,
+		Bytes:  lx.currentPos.Offset - offset,
+		Time:   time.Since(begin),
+	})
+
+	return next
+}
+
+func (profile *Profile) record(sample ProfileEntry) {
+	var (
+		entry *ProfileEntry
+		ok    bool
+	)
+
+	profile.mu.Lock()
+	defer profile.mu.Unlock()
+
+	entry, ok = profile.entries[sample.Name]
+	if !ok {
+		entry = &ProfileEntry{Name: sample.Name}
+		profile.entries[sample.Name] = entry
+	}
+
+	entry.Calls += sample.Calls
+	entry.Tokens += sample.Tokens
+	entry.Bytes += sample.Bytes
+	entry.Time += sample.Time
+}
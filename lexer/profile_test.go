@@ -0,0 +1,107 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	var (
+		profile *lexer.Profile
+		entries []lexer.ProfileEntry
+		tok     lexer.Token
+	)
+
+	t.Parallel()
+
+	profile = lexer.NewProfile()
+
+	for tok = range lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		lexWords,
+		lexer.WithProfile(profile),
+	).Tokens() {
+		_ = tok
+	}
+
+	entries = profile.Entries()
+
+	assert.Len(t, entries, 1)
+	assert.Equal(
+		t,
+		lexWordsName,
+		entries[0].Name,
+	)
+	assert.Equal(t, 4, entries[0].Calls)
+	assert.Equal(t, 3, entries[0].Tokens)
+	assert.Equal(t, 5, entries[0].Bytes)
+
+	profile.Reset()
+	assert.Empty(t, profile.Entries())
+}
+
+func TestProfileLabel(t *testing.T) {
+	var (
+		profile *lexer.Profile
+		state   lexer.StateFn
+		names   []string
+		entry   lexer.ProfileEntry
+		tok     lexer.Token
+		sb      strings.Builder
+	)
+
+	t.Parallel()
+
+	profile = lexer.NewProfile()
+	state = func(lx *lexer.Lexer) lexer.StateFn {
+		var next lexer.StateFn
+
+		next = lexWords(lx)
+		if lx.PeekToken() == "" && next != nil {
+			lx.ProfileLabel("label")
+		}
+
+		return next
+	}
+
+	for tok = range lexer.NewLexer(
+		strings.NewReader("ab"),
+		state,
+		lexer.WithProfile(profile),
+	).Tokens() {
+		_ = tok
+	}
+
+	for _, entry = range profile.Entries() {
+		names = append(names, entry.Name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"label",
+		lexWordsName,
+	}, names)
+
+	assert.NoError(t, profile.WriteReport(&sb))
+	assert.True(t, strings.HasPrefix(sb.String(), "calls  tokens  bytes  time"))
+	assert.Contains(t, sb.String(), "  label\n")
+}
+
+func TestLexerProfileLabelWithoutProfile(t *testing.T) {
+	var lx *lexer.Lexer
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(strings.NewReader("ab"), func(lx *lexer.Lexer) lexer.StateFn {
+		lx.ProfileLabel("ignored")
+
+		return lexWords(lx)
+	})
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindWord, "ab", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+	}, slices.Collect(lx.Tokens()))
+}
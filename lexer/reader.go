@@ -1,39 +1,81 @@
 package lexer
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 // Position represents the location of a token in the input stream.
 // It tracks both the line and column numbers, with lines incremented
-// on newlines and columns incremented on each rune within a line.
+// on newlines and columns incremented on each rune within a line,
+// along with the byte offset from the beginning of the input.
 type Position struct {
 	// Line is the line number where the token begins.
 	Line int
 
 	// Column is the column number within the line where the token begins.
 	Column int
+
+	// Offset is the byte offset from the beginning of the input where
+	// the token begins. Unlike Line and Column, it refers to the bytes
+	// exactly as read, so it can be used to slice the original source.
+	Offset int
 }
 
 // Reader provides the core lexing primitives over an io.Reader.
 // It manages buffered input, position tracking, and token history,
 // exposing methods such as Next, Backup, Peek, Emit, and Ignore.
 // A new Reader is constructed with NewReader to set up the lexer state.
+//
+// A Reader is not safe for concurrent use: it must be used by a single
+// goroutine at a time, as must the Lexer embedding it. Building with
+// the lexerdebug tag makes Next panic when called concurrently, which
+// catches most accidental sharing in tests. To lex the same input on
+// several goroutines, give each its own Clone.
 type Reader struct {
+	guard                guard
 	buf                  []byte
 	history              []snapshot
 	rd                   io.Reader
 	err                  error
+	source               string
+	width                func(rune) int
+	profile              *Profile
+	labels               context.Context
+	logger               *slog.Logger
+	recording            *Recording
+	diagnostics          func(Diagnostic)
+	columnBase           int
+	noPositions          bool
+	lines                *lineIndex
+	dropped              rune
+	droppedSize          int
+	shared               bool
+	eofToken             bool
+	finalNewline         *Kind
+	newlineCheck         *Severity
+	whitespace           *whitespace
+	maxTokens            int
+	maxDuration          time.Duration
+	values               map[any]any
+	chanSize             int
 	startPos, currentPos Position
 	head                 int
 	start, current       int
+	lineStart            int
 }
 
 type snapshot struct {
 	currentPos Position
 	current    int
+	lineStart  int
 }
 
 const (
@@ -46,21 +88,104 @@ const (
 	initBufSize = readSize * 2
 )
 
+// ErrBadRead is reported by Reader.Err, wrapped in a *ReadError, when
+// the underlying io.Reader returns a byte count that is negative or
+// larger than the buffer it was given. The Reader stops reading from it
+// and behaves as if the input had ended.
+var ErrBadRead = errors.New("lexer: invalid byte count from io.Reader")
+
+// ErrEmptyPattern is returned by functions that are given an empty
+// delimiter or pattern where a non-empty one is required.
+var ErrEmptyPattern = errors.New("lexer: empty pattern")
+
+// ReadError records a failure of the underlying io.Reader along with
+// where in the input it occurred. Reader.Err returns a *ReadError for
+// every error other than io.EOF, so that errors.Is and errors.As see
+// through to the original error.
+type ReadError struct {
+	// Source is the source name set with Reader.SetSource, if any.
+	Source string
+
+	// Pos is the position of the first byte that could not be read,
+	// which directly follows the last byte delivered by the io.Reader.
+	Pos Position
+
+	// Err is the error returned by the io.Reader, or ErrBadRead.
+	Err error
+}
+
+// Option configures a Reader constructed with NewReader. Every knob of
+// the Reader and of the Lexer built on it, from column widths to
+// logging and profiling, is an Option, so that any combination of them
+// can be passed to a single constructor. Options are applied in order,
+// and a later Option overrides an earlier one setting the same knob.
+// Constructors built on NewReader, such as NewLexer, NewReaderFS, and
+// the NewLexer functions of the format packages, accept and forward
+// options too.
+type Option func(*Reader)
+
 // NewReader constructs and returns a new Reader bound to the given io.Reader.
 // The Reader is initialized with empty state and becomes ready for lexing
-// once input is consumed through calls such as Next.
-func NewReader(rd io.Reader) *Reader {
-	var startPos Position
+// once input is consumed through calls such as Next. The given options
+// are applied in order.
+func NewReader(rd io.Reader, opts ...Option) *Reader {
+	var (
+		lrd *Reader
+		opt Option
+	)
 
-	startPos = Position{
-		Line:   1,
-		Column: 1,
+	lrd = &Reader{
+		rd:         rd,
+		width:      runeWidth,
+		columnBase: 1,
+		startPos: Position{
+			Line:   1,
+			Column: 1,
+		},
 	}
 
-	return &Reader{
-		rd:         rd,
-		startPos:   startPos,
-		currentPos: startPos,
+	for _, opt = range opts {
+		opt(lrd)
+	}
+
+	lrd.currentPos = lrd.startPos
+
+	return lrd
+}
+
+// WithColumnWidth makes the Reader advance the column of a Position by
+// width(char) for every rune char it reads, instead of by one. Passing
+// DisplayWidth counts columns in terminal cells, so that diagnostics
+// point at the column an editor shows for source containing wide East
+// Asian characters or combining marks. Passing UTF16Width or ByteWidth
+// counts columns in UTF-16 code units or bytes respectively. A byte
+// that is not valid UTF-8 always advances the column by one.
+func WithColumnWidth(width func(rune) int) Option {
+	return func(lrd *Reader) {
+		lrd.width = width
+	}
+}
+
+// WithZeroBased makes the Reader number lines and columns from zero
+// instead of one, as required by protocols such as LSP.
+func WithZeroBased() Option {
+	return func(lrd *Reader) {
+		lrd.columnBase = 0
+		lrd.startPos.Line = 0
+		lrd.startPos.Column = 0
+	}
+}
+
+// WithoutPositions makes the Reader track only the Offset of a
+// Position, leaving Line and Column at their initial values, for
+// pipelines such as log ingestion that only need the text of tokens.
+// Lines are still recognized for LineText, but no column width is
+// computed. As measured by BenchmarkReaderNext, skipping the
+// bookkeeping makes Next about 15% faster on ASCII text with the
+// default width, and about twice as fast with DisplayWidth.
+func WithoutPositions() Option {
+	return func(lrd *Reader) {
+		lrd.noPositions = true
 	}
 }
 
@@ -80,6 +205,34 @@ func (lrd *Reader) CurrentPosition() Position {
 	return lrd.currentPos
 }
 
+// SetPosition moves the Reader to pos without consuming any input, so
+// that positions are reported relative to an enclosing document, such
+// as when lexing a code snippet extracted from a Markdown file or a
+// template expression extracted from an HTML page. The next rune read
+// is reported at pos, and later positions advance from there.
+//
+// Any runes accumulated since the last call to Ignore or Emit are
+// discarded as if by Ignore. SetPosition is usually called right after
+// NewReader, before any input is read.
+func (lrd *Reader) SetPosition(pos Position) {
+	lrd.Ignore()
+	lrd.startPos = pos
+	lrd.currentPos = pos
+	lrd.record("position", 0, 0)
+}
+
+// SetSource records the name of the input, typically a file name, for
+// use in diagnostics. The Reader itself does not interpret the name.
+func (lrd *Reader) SetSource(name string) {
+	lrd.source = name
+}
+
+// Source returns the name of the input recorded with SetSource, or the
+// empty string if none was recorded.
+func (lrd *Reader) Source() string {
+	return lrd.source
+}
+
 // Accept consumes the next rune if it is found in the given string.
 // It advances the reader by one rune and checks whether that rune
 // exists within the provided match string.
@@ -96,18 +249,19 @@ func (lrd *Reader) Accept(match string) bool {
 // returns true. It advances the reader by one rune and applies fn to it.
 //
 // Returns true if the next rune was successfully consumed (i.e., fn
-// returned true). Returns false if the next rune was EOF or if fn
-// returned false (in which case the reader position is restored via
-// Backup).
+// returned true). Returns false, consuming nothing, if the next rune
+// was EOF, a NUL byte included, or if fn returned false (in which case
+// the reader position is restored via Backup).
 func (lrd *Reader) AcceptFunc(fn func(rune) bool) bool {
 	var char rune
 
-	char = lrd.Next()
-
+	char = lrd.Peek()
 	if char == EOF {
 		return false
 	}
 
+	lrd.Next()
+
 	if !fn(char) {
 		lrd.Backup(1)
 
@@ -117,6 +271,48 @@ func (lrd *Reader) AcceptFunc(fn func(rune) bool) bool {
 	return true
 }
 
+// AcceptRune behaves like Accept but tells apart the two reasons for
+// not consuming a rune, so that diagnostics can say "expected digit"
+// rather than "unexpected end of input" only when that is what
+// happened.
+//
+// Returns the next rune and true if it was found in match and consumed.
+// Returns the next rune and false, consuming nothing, if it was not
+// found in match. Returns EOF, false, and a non-nil error if the input
+// is exhausted: the error is Err if set, such as a *ReadError, and
+// io.EOF otherwise. Unlike Accept, a NUL byte in the input counts as a
+// rune rather than as the end of input.
+func (lrd *Reader) AcceptRune(match string) (rune, bool, error) {
+	return lrd.AcceptRuneFunc(containsFn(match))
+}
+
+// AcceptRuneFunc behaves like AcceptRune but tests the next rune with
+// the provided predicate function instead of a set of runes.
+func (lrd *Reader) AcceptRuneFunc(fn func(rune) bool) (rune, bool, error) {
+	var (
+		char rune
+		err  error
+	)
+
+	if lrd.AtEOF() {
+		err = lrd.Err()
+		if err == nil {
+			err = io.EOF
+		}
+
+		return EOF, false, err
+	}
+
+	char = lrd.Next()
+	if !fn(char) {
+		lrd.Backup(1)
+
+		return char, false, nil
+	}
+
+	return char, true, nil
+}
+
 // AcceptRun consumes consecutive runes while they are found in the
 // given string. It advances the reader rune by rune and checks whether
 // each rune exists within the provided match string.
@@ -125,6 +321,16 @@ func (lrd *Reader) AcceptFunc(fn func(rune) bool) bool {
 // when the next rune is EOF or not present in match (in which case the
 // reader position is restored via Backup).
 func (lrd *Reader) AcceptRun(match string) int {
+	var (
+		set ASCIISet
+		ok  bool
+	)
+
+	set, ok = asciiSet(match)
+	if ok {
+		return lrd.AcceptRunASCII(&set)
+	}
+
 	return lrd.AcceptRunFunc(containsFn(match))
 }
 
@@ -145,31 +351,68 @@ func (lrd *Reader) AcceptRunFunc(fn func(rune) bool) int {
 	return count
 }
 
+// NextIf consumes the next rune if the provided predicate function
+// returns true for it, like AcceptFunc, but also returns the rune, as
+// when the opening quote decides which quote closes a string.
+//
+// Returns the rune and true if it was consumed. Returns EOF and false at
+// the end of input, or the rune and false if fn rejected it, in which
+// case it is left unconsumed.
+func (lrd *Reader) NextIf(fn func(rune) bool) (rune, bool) {
+	var char rune
+
+	char = lrd.Peek()
+	if char == EOF || !fn(char) {
+		return char, false
+	}
+
+	return lrd.Next(), true
+}
+
+// AcceptRunString consumes consecutive runes while they are found in
+// the given string, like AcceptRun, but returns the consumed text
+// rather than its length in runes.
+func (lrd *Reader) AcceptRunString(match string) string {
+	var offset int
+
+	offset = lrd.currentPos.Offset
+	lrd.AcceptRun(match)
+
+	return string(lrd.buf[lrd.current-(lrd.currentPos.Offset-offset) : lrd.current])
+}
+
 // AcceptSeq consumes runes matching the exact sequence of the given
-// string. It advances the reader rune by rune and checks whether each
-// rune matches in order.
+// string. The sequence is compared against the buffered input, which is
+// read ahead as needed, before anything is consumed, so a long sequence
+// that fails to match costs no history. On a match, every rune is
+// recorded as if read with Next, so Backup can undo it rune by rune.
 //
-// Returns true if the entire sequence was successfully consumed.
-// Returns false if EOF is reached or a mismatch occurs (in which case
-// the reader position is restored via Backup).
+// Returns true if the entire sequence was successfully consumed, which
+// the empty sequence always is. Returns false, consuming nothing, if EOF
+// is reached or a mismatch occurs.
 func (lrd *Reader) AcceptSeq(match string) bool {
 	var (
-		runes []rune
-		count int
+		ahead      []byte
+		want, char rune
+		size, n, i int
 	)
 
-	runes = []rune(match)
+	// An invalid byte of input decodes as a single-byte RuneError, so
+	// the matching input is never longer than match.
+	ahead = lrd.lookahead(len(match))
 
-	for count = 0; count < len(runes); count++ {
-		if lrd.Next() != runes[count] {
-			break
+	for _, want = range match {
+		char, size = utf8.DecodeRune(ahead[n:])
+		if size == 0 || char != want {
+			return false
 		}
-	}
 
-	if count != len(runes) {
-		lrd.Backup(count + 1)
+		n += size
+	}
 
-		return false
+	for i = 0; i < n; i += size {
+		char, size = utf8.DecodeRune(ahead[i:])
+		lrd.step(char, size)
 	}
 
 	return true
@@ -231,18 +474,15 @@ func (lrd *Reader) UntilFuncInclusive(fn func(rune) bool) (int, bool) {
 // UntilSeq consumes runes until EOF or until the exact sequence of the
 // given string is found. It advances the reader rune by rune until the
 // first rune of match is encountered, then checks whether the remainder
-// of the sequence follows.
+// of the sequence follows. The sequence itself is left unconsumed.
 //
-// Returns the number of runes successfully consumed before the start of
-// the matched sequence. Stops and returns when the next rune is EOF or
-// when the full sequence is found (in which case the reader position is
-// restored via Backup).
-func (lrd *Reader) UntilSeq(match string) int {
-	var count int
-
-	count, _ = lrd.untilSeq(match, false)
-
-	return count
+// Returns the number of runes consumed before the start of the matched
+// sequence, along with a boolean that is true if the sequence was found
+// and false if EOF was reached first. A count of zero is thus either an
+// immediate match, reported as true, or an exhausted input, reported as
+// false. The empty sequence is found immediately.
+func (lrd *Reader) UntilSeq(match string) (int, bool) {
+	return lrd.untilSeq(match, false)
 }
 
 // UntilSeqInclusive consumes runes until EOF or until the exact sequence
@@ -252,7 +492,8 @@ func (lrd *Reader) UntilSeq(match string) int {
 // Returns the number of runes successfully consumed before the start of
 // the matched sequence plus the length of the sequence itself. The second
 // return value is a boolean that is true if the sequence was found and
-// consumed, and false if EOF was encountered before a match.
+// consumed, and false if EOF was encountered before a match. The empty
+// sequence is found immediately.
 func (lrd *Reader) UntilSeqInclusive(match string) (int, bool) {
 	return lrd.untilSeq(match, true)
 }
@@ -266,69 +507,186 @@ func (lrd *Reader) Next() rune {
 		size int
 	)
 
+	lrd.guard.enter("Reader")
 	lrd.fill()
 
-	if lrd.head-lrd.current <= 0 {
-		return EOF
-	}
+	char = EOF
 
-	lrd.history = append(lrd.history, snapshot{
-		current:    lrd.current,
-		currentPos: lrd.currentPos,
-	})
-
-	char, size = utf8.DecodeRune(lrd.buf[lrd.current:lrd.head])
-	lrd.current += size
-
-	lrd.currentPos.Column++
-	if char == '\n' {
-		lrd.currentPos.Line++
-		lrd.currentPos.Column = 1
+	if lrd.head-lrd.current > 0 {
+		char, size = utf8.DecodeRune(lrd.buf[lrd.current:lrd.head])
+		lrd.step(char, size)
 	}
 
+	lrd.guard.leave()
+
 	return char
 }
 
 // Peek returns the next rune from the input stream without advancing
-// the Reader’s position. Unlike Next, it does not consume the rune.
+// the Reader’s position. Unlike Next, it does not consume the rune, and
+// it decodes the rune directly from the buffer without touching the
+// history used by Backup.
 func (lrd *Reader) Peek() rune {
 	var char rune
 
-	char = lrd.Next()
-	if char != EOF {
-		lrd.Backup(1)
+	lrd.fill()
+
+	if lrd.head-lrd.current <= 0 {
+		return EOF
 	}
 
+	char, _ = utf8.DecodeRune(lrd.buf[lrd.current:lrd.head])
+
 	return char
 }
 
+// Last returns the rune most recently consumed, which is the rune
+// preceding the current position, or EOF if nothing was consumed yet.
+// After Backup, it is the rune preceding the restored position. It lets
+// state functions branch on what they just consumed without threading
+// the value through helpers.
+func (lrd *Reader) Last() rune {
+	var char rune
+
+	char, _ = lrd.last()
+
+	return char
+}
+
+// Width returns the size in bytes of the rune returned by Last, or zero
+// if nothing was consumed yet. A malformed byte counts as a rune of
+// width one. Subtracting it from the current Offset gives the offset
+// of the last rune, which keeps byte-oriented slicing in step with the
+// rune-oriented API.
+func (lrd *Reader) Width() int {
+	var size int
+
+	_, size = lrd.last()
+
+	return size
+}
+
+// AtLineStart reports whether the current position is at the start of
+// a line, that is, at the start of input or right after a line feed.
+// Unlike comparing the Column against 1, it is unaffected by column
+// widths, zero-width runes, and zero-based columns. A Reader that has
+// consumed nothing yet defers to its position, so one started in the
+// middle of a line with SetPosition is not at a line start.
+func (lrd *Reader) AtLineStart() bool {
+	var (
+		char rune
+		size int
+	)
+
+	char, size = lrd.last()
+	if size == 0 {
+		return lrd.noPositions || lrd.currentPos.Column == lrd.columnBase
+	}
+
+	return char == '\n'
+}
+
+// AtStart reports whether nothing precedes the current position, that
+// is, whether no input was consumed yet or Backup restored everything
+// that was. It anchors rules to the beginning of a document, such as a
+// shebang line or a front matter block.
+func (lrd *Reader) AtStart() bool {
+	var size int
+
+	_, size = lrd.last()
+
+	return size == 0
+}
+
+// AtEOF reports whether the input is exhausted at the current position,
+// reading ahead if needed but consuming nothing and leaving the history
+// used by Backup intact. Unlike comparing Peek against EOF, it is not
+// fooled by a NUL byte in the input.
+func (lrd *Reader) AtEOF() bool {
+	lrd.fillTo(1)
+
+	return lrd.head <= lrd.current
+}
+
+// HasPrefix reports whether the upcoming input starts with prefix,
+// without consuming anything or touching the history used by Backup.
+// Input is read ahead as needed, so prefix may be arbitrarily long.
+// Every input starts with the empty prefix.
+func (lrd *Reader) HasPrefix(prefix string) bool {
+	var ahead []byte
+
+	ahead = lrd.lookahead(len(prefix))
+
+	return len(ahead) == len(prefix) && string(ahead) == prefix
+}
+
 // Backup rewinds the Reader’s position by up to n runes, restoring
 // previously consumed input. Supplying a value of n larger than the
 // available history is safe: Backup will stop automatically at the
 // starting rune without panicking.
-func (lrd *Reader) Backup(n int) {
-	var snap snapshot
-
-	for range n {
-		if len(lrd.history) == 0 {
-			return
-		}
+//
+// Backup never crosses the start of the current token. Emit and Ignore
+// commit the runes consumed so far and discard the history, as do the
+// recovery methods such as SyncTo, so a rune consumed before the last
+// call to any of them can never be restored; BackupLimit reports how
+// far Backup can go. Operations that consume several runes as a unit,
+// such as ReadN and NextRunes, count as a single rune.
+//
+// Returns the number of runes restored, which is less than n if and
+// only if Backup stopped at the start of the history, so speculative
+// lexers can detect that they emitted too eagerly.
+func (lrd *Reader) Backup(n int) int {
+	var (
+		snap  snapshot
+		count int
+	)
 
+	for count < n && len(lrd.history) != 0 {
 		snap = lrd.history[len(lrd.history)-1]
 		lrd.history = lrd.history[:len(lrd.history)-1]
 
 		lrd.current = snap.current
 		lrd.currentPos = snap.currentPos
+		lrd.lineStart = snap.lineStart
+		count++
 	}
+
+	lrd.record("backup", 0, n)
+
+	return count
+}
+
+// Reject rewinds the Reader to the start of the current token, undoing
+// every rune consumed since the last call to Emit or Ignore, as when a
+// speculative rule fails after consuming several runes. Unlike Backup,
+// it also rewinds past runes whose history was discarded by a recovery
+// method such as SyncTo.
+func (lrd *Reader) Reject() {
+	if len(lrd.history) != 0 {
+		lrd.lineStart = lrd.history[0].lineStart
+	} else if lrd.current != lrd.start {
+		lrd.lineStart = bytes.LastIndexByte(lrd.buf[:lrd.start], '\n') + 1
+	}
+
+	lrd.current = lrd.start
+	lrd.currentPos = lrd.startPos
+	lrd.history = lrd.history[:0]
+	lrd.record("reject", 0, 0)
+}
+
+// BackupLimit returns the number of runes Backup can currently restore,
+// which is the number of runes consumed since the last call to Emit or
+// Ignore, unless history was discarded by a recovery method.
+func (lrd *Reader) BackupLimit() int {
+	return len(lrd.history)
 }
 
 // Ignore discards the runes accumulated by successive calls to Next
 // since the last call to Ignore or Emit, resetting the start position
 // for the next token.
 func (lrd *Reader) Ignore() {
-	lrd.start = lrd.current
-	lrd.startPos = lrd.currentPos
-	lrd.history = lrd.history[:0]
+	lrd.discard()
+	lrd.record("ignore", 0, 0)
 }
 
 // PeekToken returns the sequence of runes accumulated by successive
@@ -351,58 +709,359 @@ func (lrd *Reader) Emit() (string, Position) {
 	token = lrd.PeekToken()
 	pos = lrd.startPos
 
-	lrd.Ignore()
+	lrd.discard()
+	lrd.record("emit", 0, 0)
 
 	return token, pos
 }
 
+// EmitBytes behaves like Emit but returns the token as a slice of the
+// internal buffer of the Reader rather than as a string, saving an
+// allocation per token in pipelines that only inspect or copy tokens.
+//
+// The slice is only valid until the next call to a method of the Reader
+// that reads input, such as Next, Peek, or Accept, which may overwrite
+// the buffer, and must not be modified. In builds with the lexerdebug
+// tag, the slice is a copy that such a call overwrites with 0xde bytes,
+// so that keeping it too long is caught by tests instead of silently
+// corrupting tokens.
+func (lrd *Reader) EmitBytes() ([]byte, Position) {
+	var (
+		token []byte
+		pos   Position
+	)
+
+	token = lrd.guard.lease(lrd.buf[lrd.start:lrd.current:lrd.current])
+	pos = lrd.startPos
+
+	lrd.discard()
+	lrd.record("emit", 0, 0)
+
+	return token, pos
+}
+
+// LineText returns the complete text of the line containing the current
+// position, without its line terminator. Input is read ahead as needed
+// to find the end of the line, but the Reader's position and the
+// current token are left untouched.
+//
+// The Reader retains the current line in its buffer for this purpose,
+// so LineText also reports the part of the line that precedes the
+// current token, even if it was discarded with Ignore or Emit. Only the
+// last 4096 bytes before the current token are retained, however: on
+// longer lines the result starts at the earliest retained byte.
+func (lrd *Reader) LineText() string {
+	var (
+		count int
+		line  string
+	)
+
+	count = lrd.Until("\n")
+	line = string(lrd.buf[lrd.lineStart:lrd.current])
+	lrd.Backup(count)
+
+	return strings.TrimSuffix(line, "\r")
+}
+
 // Err returns the first error encountered from the underlying io.Reader,
 // including io.EOF. This should be checked after Next returns EOF to
 // distinguish between a clean end of input and other error conditions.
 // A successful read sequence is indicated when Next returns EOF and
 // Err returns io.EOF. In cases where EOF is returned with a nil error,
 // the underlying reader may not yet be ready to provide data, and the
-// client can decide how to proceed.
+// client can decide how to proceed. Errors other than io.EOF are
+// wrapped in a *ReadError carrying the position of the failure. If the
+// underlying reader violates the io.Reader contract, Err reports
+// ErrBadRead from then on.
 func (lrd *Reader) Err() error {
 	return lrd.err
 }
 
 func (lrd *Reader) fill() {
+	lrd.fillTo(utf8.UTFMax)
+}
+
+// fillTo reads more input unless at least need bytes are buffered past
+// the current position or the input has ended.
+func (lrd *Reader) fillTo(need int) {
 	var (
 		newBuf []byte
+		keep   int
 		n      int
 		err    error
+		i      int
 	)
 
+	lrd.guard.expire()
+
 	if lrd.buf == nil {
 		lrd.buf = make([]byte, initBufSize)
 	}
 
-	switch {
-	case lrd.err == io.EOF || lrd.head-lrd.current >= utf8.UTFMax:
+	// Bytes before keep belong to neither the current token nor the
+	// current line and may be discarded when sliding. At most readSize
+	// bytes of the line preceding the token are kept, so a single huge
+	// line cannot pin the whole input in memory.
+	keep = max(min(lrd.start, lrd.lineStart), lrd.start-readSize)
+
+	if lrd.err == io.EOF ||
+		errors.Is(lrd.err, ErrBadRead) ||
+		lrd.head-lrd.current >= need {
 		return
+	}
+
+	// The buffer of a cloned Reader is shared with its clone until one
+	// of them reads further.
+	if lrd.shared {
+		lrd.buf = bytes.Clone(lrd.buf)
+		lrd.shared = false
+	}
+
+	switch {
 	case len(lrd.buf)-lrd.head >= readSize:
 		// Do nothing
-	case lrd.current-lrd.start >= len(lrd.buf)-readSize:
+	case lrd.head-keep > len(lrd.buf)-readSize:
 		newBuf = make([]byte, len(lrd.buf)*2)
 		copy(newBuf, lrd.buf)
 		lrd.buf = newBuf
 	default:
-		lrd.head -= lrd.start
-		lrd.current -= lrd.start
-		copy(lrd.buf, lrd.buf[lrd.start:])
-		lrd.start = 0
+		if keep > 0 {
+			lrd.dropped, lrd.droppedSize = utf8.DecodeLastRune(lrd.buf[:keep])
+		}
+
+		copy(lrd.buf, lrd.buf[keep:lrd.head])
+		lrd.head -= keep
+		lrd.start -= keep
+		lrd.current -= keep
+		lrd.lineStart = max(lrd.lineStart-keep, 0)
+
+		if lrd.lines != nil {
+			lrd.lines.base += keep
+		}
+
+		for i = range lrd.history {
+			lrd.history[i].current -= keep
+			lrd.history[i].lineStart = max(lrd.history[i].lineStart-keep, 0)
+		}
 	}
 
 	n, err = lrd.rd.Read(lrd.buf[lrd.head : lrd.head+readSize])
 	if n < 0 || n > readSize {
-		panic("langengine/lexer: bogus io.Reader")
+		lrd.err = lrd.readError(ErrBadRead)
+
+		return
 	}
 
 	lrd.head += n
 
-	if lrd.err == nil && err != nil {
+	switch {
+	case lrd.err != nil || err == nil:
+	case err == io.EOF:
 		lrd.err = err
+	default:
+		lrd.err = lrd.readError(err)
+	}
+
+	if lrd.lines != nil {
+		lrd.lines.scan(lrd.buf[:lrd.head], lrd.width, lrd.err != nil)
+	}
+}
+
+// Error formats the error as "source:line:column: message", omitting
+// the source when it is empty, followed by the byte offset.
+func (readErr *ReadError) Error() string {
+	var prefix string
+
+	if readErr.Source != "" {
+		prefix = readErr.Source + ":"
+	}
+
+	return fmt.Sprintf(
+		"%s%d:%d: lexer: read failed at offset %d: %v",
+		prefix,
+		readErr.Pos.Line,
+		readErr.Pos.Column,
+		readErr.Pos.Offset,
+		readErr.Err,
+	)
+}
+
+// Unwrap returns the underlying error.
+func (readErr *ReadError) Unwrap() error {
+	return readErr.Err
+}
+
+// readError wraps err with the position of the end of the buffered
+// input, which is where the underlying io.Reader stopped.
+func (lrd *Reader) readError(err error) *ReadError {
+	var (
+		pos  Position
+		char rune
+		size int
+		i    int
+	)
+
+	pos = lrd.currentPos
+
+	for i = lrd.current; i < lrd.head; i += size {
+		char, size = utf8.DecodeRune(lrd.buf[i:lrd.head])
+		pos = lrd.advance(pos, char, size)
+	}
+
+	return &ReadError{
+		Source: lrd.source,
+		Pos:    pos,
+		Err:    err,
+	}
+}
+
+// last returns the rune preceding the current position and its size in
+// bytes, or EOF and zero at the start of input.
+func (lrd *Reader) last() (rune, int) {
+	switch {
+	case lrd.current > 0:
+		return utf8.DecodeLastRune(lrd.buf[:lrd.current])
+	case lrd.droppedSize > 0:
+		return lrd.dropped, lrd.droppedSize
+	default:
+		return EOF, 0
+	}
+}
+
+// lookahead returns up to the next n bytes of input without consuming
+// them, reading ahead as needed. Fewer than n bytes are returned only at
+// the end of input. The result aliases the buffer and is only valid
+// until the next read.
+func (lrd *Reader) lookahead(n int) []byte {
+	var head int
+
+	for lrd.head-lrd.current < n {
+		head = lrd.head
+
+		lrd.fillTo(n)
+
+		if lrd.head == head {
+			break
+		}
+	}
+
+	return lrd.buf[lrd.current:min(lrd.head, lrd.current+n)]
+}
+
+// step consumes size bytes decoded as char, recording a snapshot for
+// Backup and advancing the current position past them.
+func (lrd *Reader) step(char rune, size int) {
+	lrd.save()
+	lrd.move(char, size)
+	lrd.record("next", char, size)
+}
+
+// discard resets the token boundaries to the current position.
+func (lrd *Reader) discard() {
+	lrd.start = lrd.current
+	lrd.startPos = lrd.currentPos
+	lrd.history = lrd.history[:0]
+}
+
+// save records a snapshot of the current position for Backup.
+func (lrd *Reader) save() {
+	lrd.history = append(lrd.history, snapshot{
+		current:    lrd.current,
+		currentPos: lrd.currentPos,
+		lineStart:  lrd.lineStart,
+	})
+}
+
+// move advances the current position past size bytes decoded as char
+// without recording history.
+func (lrd *Reader) move(char rune, size int) {
+	lrd.current += size
+	lrd.currentPos = lrd.advance(lrd.currentPos, char, size)
+
+	if char == '\n' {
+		lrd.lineStart = lrd.current
+	}
+}
+
+// advance returns pos moved past size bytes decoded as char.
+func (lrd *Reader) advance(pos Position, char rune, size int) Position {
+	pos.Offset += size
+
+	switch {
+	case lrd.noPositions:
+	case char == '\n':
+		pos.Line++
+		pos.Column = lrd.columnBase
+	case char == utf8.RuneError && size == 1:
+		pos.Column++
+	default:
+		pos.Column += lrd.width(char)
+	}
+
+	return pos
+}
+
+// advanceText returns pos moved past text, decoded as lrd decodes its
+// input, so that a malformed byte advances the offset by one.
+func (lrd *Reader) advanceText(pos Position, text string) Position {
+	var (
+		char rune
+		size int
+		i    int
+	)
+
+	for i = 0; i < len(text); i += size {
+		char, size = utf8.DecodeRuneInString(text[i:])
+		pos = lrd.advance(pos, char, size)
+	}
+
+	return pos
+}
+
+// fragment returns a Reader over text, configured like lrd by inherit,
+// then by opts, and starting at pos, for lexing a region of the input
+// of lrd on its own.
+func (lrd *Reader) fragment(text string, pos Position, opts ...Option) *Reader {
+	var (
+		frag   *Reader
+		origin Position
+		ok     bool
+	)
+
+	frag = NewReader(strings.NewReader(text), append([]Option{lrd.inherit}, opts...)...)
+	frag.SetPosition(pos)
+
+	if frag.lines != nil {
+		origin, ok = lrd.PositionAt(pos.Offset)
+		if !ok {
+			origin = pos
+		}
+
+		frag.lines.origin = &origin
+	}
+
+	return frag
+}
+
+// inherit configures sub, a Reader derived from lrd over a region of
+// its input or over another file, to name its source, to count lines
+// and columns as lrd does, to apply its whitespace profile, and to log
+// and report diagnostics where lrd does. It is the first option of
+// every derived Reader, so that the options given for the derived
+// Reader override it.
+func (lrd *Reader) inherit(sub *Reader) {
+	sub.source = lrd.source
+	sub.whitespace = lrd.whitespace
+	sub.logger = lrd.logger
+	sub.diagnostics = lrd.diagnostics
+	sub.width = lrd.width
+	sub.columnBase = lrd.columnBase
+	sub.startPos = Position{Line: lrd.columnBase, Column: lrd.columnBase}
+	sub.noPositions = lrd.noPositions
+	sub.lines = nil
+
+	if lrd.lines != nil {
+		sub.lines = &lineIndex{starts: []int{0}}
 	}
 }
 
@@ -423,12 +1082,14 @@ func (lrd *Reader) untilSeq(match string, inclusive bool) (int, bool) {
 			return char == runes[0]
 		})
 
-		char = lrd.Next()
-		if char == EOF {
+		// UntilFunc also stops at a NUL byte, which is consumed here
+		// like any rune that does not start the sequence.
+		if lrd.AtEOF() {
 			return count, false
 		}
 
-		if !lrd.AcceptSeq(string(runes[1:])) {
+		char = lrd.Next()
+		if char != runes[0] || !lrd.AcceptSeq(string(runes[1:])) {
 			count++
 
 			continue
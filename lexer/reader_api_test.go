@@ -1,10 +1,14 @@
 package lexer_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/andrieee44/langengine/lexer"
 	"github.com/stretchr/testify/assert"
@@ -134,6 +138,16 @@ func TestReaderAcceptFunc(t *testing.T) {
 				return lrd.AcceptFunc(unicode.IsUpper)
 			},
 		},
+		"NUL": {
+			content: "\x00a",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.AcceptFunc(func(rune) bool {
+					return true
+				})
+			},
+		},
 		"EmptyArgument": {
 			content: "abc",
 			afterOp: "",
@@ -382,6 +396,16 @@ func TestReaderAcceptSeq(t *testing.T) {
 				return lrd.AcceptSeq("abcde")
 			},
 		},
+		"PartialMatchEOF": {
+			content: "abcd",
+			afterOp: "a",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				lrd.Next()
+
+				return lrd.AcceptSeq("bcde")
+			},
+		},
 		"EmptyArgument": {
 			content: "abc",
 			afterOp: "",
@@ -723,69 +747,85 @@ func TestReaderUntilFuncInclusive(t *testing.T) {
 func TestReaderUntilSeq(t *testing.T) {
 	t.Parallel()
 
-	assertHelperTestDataTbl(t, map[string]helperTestData[int]{
+	assertHelperTestDataTbl(t, map[string]helperTestData[inclusiveResult]{
 		"Base": {
 			content: "/* abc */",
 			afterOp: "/* abc ",
-			result:  7,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("*/")
+			result:  mkInclusiveResult(7, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("*/"))
 			},
 		},
 		"NoMatch": {
 			content: "/* abc ",
 			afterOp: "/* abc ",
-			result:  7,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("*/")
+			result:  mkInclusiveResult(7, false),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("*/"))
+			},
+		},
+		"NUL": {
+			content: "/* a\x00b */",
+			afterOp: "/* a\x00b ",
+			result:  mkInclusiveResult(7, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("*/"))
 			},
 		},
 		"PartialMatch": {
 			content: "abcd!",
 			afterOp: "abcd!",
-			result:  5,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("abcde")
+			result:  mkInclusiveResult(5, false),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("abcde"))
+			},
+		},
+		"PartialMatchEOF": {
+			content: "abcd",
+			afterOp: "abcd",
+			result:  mkInclusiveResult(4, false),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("cde"))
 			},
 		},
 		"PartialContent": {
 			content: "abc!abc",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("abc")
+			result:  mkInclusiveResult(0, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("abc"))
 			},
 		},
 		"PartialMatchContent": {
 			content: "abcd!abcde",
 			afterOp: "abcd!",
-			result:  5,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("abcde")
+			result:  mkInclusiveResult(5, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("abcde"))
 			},
 		},
 		"EmptyArgument": {
 			content: "abc",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("")
+			result:  mkInclusiveResult(0, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq(""))
 			},
 		},
 		"EmptyContent": {
 			content: "",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("abc")
+			result:  mkInclusiveResult(0, false),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("abc"))
 			},
 		},
 		"EmptyAll": {
 			content: "",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("")
+			result:  mkInclusiveResult(0, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq(""))
 			},
 		},
 		"Unicode": {
@@ -796,9 +836,9 @@ func TestReaderUntilSeq(t *testing.T) {
 			// 요 U+D558 (3 bytes)
 			content: "안녕하세요",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("안녕하세요")
+			result:  mkInclusiveResult(0, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("안녕하세요"))
 			},
 		},
 		"UnicodePartialMatch": {
@@ -809,9 +849,9 @@ func TestReaderUntilSeq(t *testing.T) {
 			// 요 U+D558 (3 bytes)
 			content: "안녕하세!",
 			afterOp: "안녕하세!",
-			result:  5,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("안녕하세요")
+			result:  mkInclusiveResult(5, false),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("안녕하세요"))
 			},
 		},
 		"UnicodePartialContent": {
@@ -822,9 +862,9 @@ func TestReaderUntilSeq(t *testing.T) {
 			// 요 U+D558 (3 bytes)
 			content: "안녕하세요!안녕하세요",
 			afterOp: "",
-			result:  0,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("안녕하세요")
+			result:  mkInclusiveResult(0, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("안녕하세요"))
 			},
 		},
 		"UnicodePartialMatchContent": {
@@ -835,9 +875,9 @@ func TestReaderUntilSeq(t *testing.T) {
 			// 요 U+D558 (3 bytes)
 			content: "안녕하세_!안녕하세요",
 			afterOp: "안녕하세_!",
-			result:  6,
-			op: func(lrd *lexer.Reader) int {
-				return lrd.UntilSeq("안녕하세요")
+			result:  mkInclusiveResult(6, true),
+			op: func(lrd *lexer.Reader) inclusiveResult {
+				return mkInclusiveResult(lrd.UntilSeq("안녕하세요"))
 			},
 		},
 	})
@@ -966,6 +1006,442 @@ func TestReaderUntilSeqInclusive(t *testing.T) {
 	})
 }
 
+func TestReaderNextIf(t *testing.T) {
+	type nextIfResult struct {
+		char rune
+		ok   bool
+	}
+
+	var isQuote func(rune) bool
+
+	t.Parallel()
+
+	isQuote = func(char rune) bool {
+		return char == '"' || char == '\''
+	}
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[nextIfResult]{
+		"Match": {
+			content: "'a'",
+			afterOp: "'",
+			result:  nextIfResult{'\'', true},
+			op: func(lrd *lexer.Reader) nextIfResult {
+				var result nextIfResult
+
+				result.char, result.ok = lrd.NextIf(isQuote)
+
+				return result
+			},
+		},
+		"Mismatch": {
+			content: "a'",
+			afterOp: "",
+			result:  nextIfResult{'a', false},
+			op: func(lrd *lexer.Reader) nextIfResult {
+				var result nextIfResult
+
+				result.char, result.ok = lrd.NextIf(isQuote)
+
+				return result
+			},
+		},
+		"EOF": {
+			content: "",
+			afterOp: "",
+			result:  nextIfResult{lexer.EOF, false},
+			op: func(lrd *lexer.Reader) nextIfResult {
+				var result nextIfResult
+
+				result.char, result.ok = lrd.NextIf(isQuote)
+
+				return result
+			},
+		},
+	})
+}
+
+func TestReaderAcceptRunString(t *testing.T) {
+	var long string
+
+	t.Parallel()
+
+	long = strings.Repeat("0123456789", 2000)
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[string]{
+		"ASCII": {
+			content: "x123y",
+			afterOp: "x123",
+			result:  "123",
+			op: func(lrd *lexer.Reader) string {
+				lrd.Next()
+
+				return lrd.AcceptRunString("0123456789")
+			},
+		},
+		"Unicode": {
+			content: "éèe",
+			afterOp: "éè",
+			result:  "éè",
+			op: func(lrd *lexer.Reader) string {
+				return lrd.AcceptRunString("éè")
+			},
+		},
+		"None": {
+			content: "abc",
+			afterOp: "",
+			result:  "",
+			op: func(lrd *lexer.Reader) string {
+				return lrd.AcceptRunString("xyz")
+			},
+		},
+		"Long": {
+			content: long + ".",
+			afterOp: long,
+			result:  long,
+			op: func(lrd *lexer.Reader) string {
+				return lrd.AcceptRunString("0123456789")
+			},
+		},
+	})
+}
+
+func TestReaderLast(t *testing.T) {
+	var (
+		lrd  *lexer.Reader
+		long string
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("aé\xff"))
+
+	assert.Equal(t, lexer.EOF, lrd.Last())
+
+	lrd.Next()
+	lrd.Next()
+
+	assert.Equal(t, 'é', lrd.Last())
+
+	lrd.Emit()
+
+	assert.Equal(t, 'é', lrd.Last())
+
+	lrd.Next()
+
+	assert.Equal(t, utf8.RuneError, lrd.Last())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, 'é', lrd.Last())
+
+	long = strings.Repeat("0123456789", 2000)
+	lrd = lexer.NewReader(strings.NewReader(long + "日" + long))
+
+	for lrd.Next() != '日' {
+		lrd.Ignore()
+	}
+
+	lrd.Ignore()
+	lrd.AcceptRun("0123456789")
+
+	assert.Equal(t, '9', lrd.Last())
+
+	lrd.Reject()
+
+	assert.Equal(t, '日', lrd.Last())
+}
+
+func TestReaderWidth(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		char  rune
+		width int
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("a日\xff🙂"))
+
+	assert.Equal(t, 0, lrd.Width())
+
+	for _, width = range []int{1, 3, 1, 4} {
+		char = lrd.Next()
+
+		assert.Equal(t, width, lrd.Width(), string(char))
+		assert.Equal(t, char, lrd.Last())
+	}
+
+	lrd.Next()
+
+	assert.Equal(t, 4, lrd.Width())
+
+	lrd.Backup(2)
+
+	assert.Equal(t, 3, lrd.Width())
+	assert.Equal(t, 1, lrd.CurrentPosition().Offset-lrd.Width())
+}
+
+func TestReaderAtLineStart(t *testing.T) {
+	var (
+		lrd  *lexer.Reader
+		want []bool
+		i    int
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("\ta\n\u0301\n"),
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+	want = []bool{true, false, false, true, false, true}
+
+	for i = range want {
+		assert.Equal(t, want[i], lrd.AtLineStart(), i)
+		lrd.Next()
+	}
+
+	lrd.Backup(2)
+
+	assert.True(t, lrd.AtLineStart())
+
+	lrd = lexer.NewReader(strings.NewReader("x"))
+	lrd.SetPosition(lexer.Position{3, 5, 20})
+
+	assert.False(t, lrd.AtLineStart())
+
+	lrd.SetPosition(lexer.Position{3, 1, 20})
+
+	assert.True(t, lrd.AtLineStart())
+}
+
+func TestReaderAnchors(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("a\x00"))
+
+	assert.True(t, lrd.AtStart())
+	assert.False(t, lrd.AtEOF())
+
+	lrd.Next()
+
+	assert.False(t, lrd.AtStart())
+	assert.False(t, lrd.AtEOF())
+	assert.Equal(t, lexer.EOF, lrd.Peek())
+
+	lrd.Next()
+
+	assert.True(t, lrd.AtEOF())
+	assert.Equal(t, 2, lrd.BackupLimit())
+	assert.Equal(t, 2, lrd.Backup(2))
+	assert.True(t, lrd.AtStart())
+
+	lrd = lexer.NewReader(strings.NewReader(""))
+
+	assert.True(t, lrd.AtStart())
+	assert.True(t, lrd.AtEOF())
+}
+
+func TestReaderEmitBytes(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		token []byte
+		pos   lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\ncd"))
+	lrd.Next()
+	lrd.Next()
+	lrd.Ignore()
+	lrd.Next()
+	lrd.AcceptRun("cd")
+
+	token, pos = lrd.EmitBytes()
+
+	assert.Equal(t, []byte("\ncd"), token)
+	assert.Equal(t, lexer.Position{1, 3, 2}, pos)
+	assert.Equal(t, lexer.Position{2, 3, 5}, lrd.StartPosition())
+	assert.Empty(t, lrd.PeekToken())
+	assert.Equal(t, 0, lrd.BackupLimit())
+}
+
+func TestReaderAcceptRune(t *testing.T) {
+	type result struct {
+		char    rune
+		matched bool
+		err     error
+	}
+
+	var (
+		lrd *lexer.Reader
+		res result
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("1a\x00"))
+
+	res.char, res.matched, res.err = lrd.AcceptRune("0123456789")
+	assert.Equal(t, result{'1', true, nil}, res)
+
+	res.char, res.matched, res.err = lrd.AcceptRune("0123456789")
+	assert.Equal(t, result{'a', false, nil}, res)
+	assert.Equal(t, "1", lrd.PeekToken())
+
+	res.char, res.matched, res.err = lrd.AcceptRuneFunc(unicode.IsLetter)
+	assert.Equal(t, result{'a', true, nil}, res)
+
+	res.char, res.matched, res.err = lrd.AcceptRuneFunc(unicode.IsLetter)
+	assert.Equal(t, result{0, false, nil}, res)
+
+	res.char, res.matched, res.err = lrd.AcceptRune("\x00")
+	assert.Equal(t, result{0, true, nil}, res)
+
+	res.char, res.matched, res.err = lrd.AcceptRune("\x00")
+	assert.Equal(t, result{lexer.EOF, false, io.EOF}, res)
+
+	lrd = lexer.NewReader(iotest.ErrReader(io.ErrClosedPipe))
+
+	_, _, res.err = lrd.AcceptRune("a")
+	assert.ErrorIs(t, res.err, io.ErrClosedPipe)
+}
+
+func TestReaderAcceptSeqHistory(t *testing.T) {
+	var (
+		lrd  *lexer.Reader
+		long string
+	)
+
+	t.Parallel()
+
+	long = strings.Repeat("x", 10000)
+	lrd = lexer.NewReader(strings.NewReader("a" + long + "y\xff"))
+	lrd.Next()
+
+	assert.False(t, lrd.AcceptSeq(long+"z"))
+	assert.Equal(t, 1, lrd.BackupLimit())
+	assert.Equal(t, "a", lrd.PeekToken())
+
+	assert.True(t, lrd.AcceptSeq(long+"y\uFFFD"))
+	assert.Equal(t, len(long)+3, lrd.BackupLimit())
+	assert.Equal(t, lexer.EOF, lrd.Peek())
+
+	assert.Equal(t, 2, lrd.Backup(2))
+	assert.Equal(t, 'y', lrd.Peek())
+}
+
+func TestReaderEmptyArguments(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		count int
+		ok    bool
+		err   error
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab"))
+	lrd.Next()
+
+	assert.True(t, lrd.AcceptSeq(""))
+	assert.True(t, lrd.AcceptBytes(nil))
+	assert.True(t, lrd.HasPrefix(""))
+
+	count, ok = lrd.UntilSeq("")
+	assert.Equal(t, 0, count)
+	assert.True(t, ok)
+
+	count, ok = lrd.UntilSeqInclusive("")
+	assert.Equal(t, 0, count)
+	assert.True(t, ok)
+
+	assert.False(t, lrd.Accept(""))
+	assert.Equal(t, 0, lrd.AcceptRun(""))
+	assert.Equal(t, "a", lrd.PeekToken())
+	assert.Equal(t, 1, lrd.BackupLimit())
+
+	assert.Equal(t, 1, lrd.Until(""))
+	assert.Equal(t, "ab", lrd.PeekToken())
+
+	lrd = lexer.NewReader(strings.NewReader("\n"))
+	ok, err = lrd.AcceptFrontMatter("")
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, lexer.ErrEmptyPattern)
+}
+
+func TestReaderBackupBoundary(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("abcdef"))
+
+	lrd.Next()
+	lrd.Next()
+
+	assert.Equal(t, 2, lrd.BackupLimit())
+	assert.Equal(t, 1, lrd.Backup(1))
+
+	lrd.Next()
+	lrd.Emit()
+	lrd.Next()
+
+	assert.Equal(t, 1, lrd.BackupLimit())
+	assert.Equal(t, 1, lrd.Backup(3))
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+	assert.Equal(t, 0, lrd.Backup(1))
+
+	lrd.Next()
+	lrd.Ignore()
+
+	assert.Equal(t, 0, lrd.Backup(1))
+	assert.Equal(t, lexer.Position{1, 4, 3}, lrd.CurrentPosition())
+
+	lrd.Next()
+	lrd.SyncTo("f")
+
+	assert.Equal(t, 0, lrd.BackupLimit())
+	assert.Equal(t, "de", lrd.PeekToken())
+}
+
+func TestReaderReject(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\ncd\nef"))
+	lrd.Next()
+	lrd.Ignore()
+	lrd.Until("d")
+
+	assert.Equal(t, "b\nc", lrd.PeekToken())
+
+	lrd.Reject()
+
+	assert.Equal(t, lexer.Position{1, 2, 1}, lrd.CurrentPosition())
+	assert.Equal(t, "", lrd.PeekToken())
+	assert.Equal(t, "ab", lrd.LineText())
+	assert.Equal(t, 'b', lrd.Next())
+
+	lrd.SkipToLineStart()
+	lrd.SyncTo("f")
+
+	assert.Equal(t, 0, lrd.BackupLimit())
+
+	lrd.Reject()
+
+	assert.Equal(t, lexer.Position{1, 2, 1}, lrd.CurrentPosition())
+	assert.Equal(t, "ab", lrd.LineText())
+
+	lrd.Reject()
+
+	assert.Equal(t, lexer.Position{1, 2, 1}, lrd.CurrentPosition())
+}
+
 func TestReaderBackup(t *testing.T) {
 	type testData struct {
 		content     string
@@ -1137,6 +1613,101 @@ func TestReaderPeek(t *testing.T) {
 	assert.Equal(t, lexer.EOF, lrd.Peek())
 }
 
+func TestReaderPeekHistory(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\x00c"))
+	lrd.Next()
+
+	assert.Equal(t, 'b', lrd.Peek())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, lexer.Position{1, 1, 0}, lrd.CurrentPosition())
+
+	lrd.Next()
+	lrd.Next()
+
+	assert.Equal(t, lexer.EOF, lrd.Peek())
+	assert.Equal(t, lexer.Position{1, 3, 2}, lrd.CurrentPosition())
+}
+
+func TestReaderPeekAllocs(t *testing.T) {
+	var lrd *lexer.Reader
+
+	lrd = lexer.NewReader(strings.NewReader("abc"))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		lrd.Peek()
+		lrd.HasPrefix("ab")
+	}))
+}
+
+func TestReaderHasPrefix(t *testing.T) {
+	var long string
+
+	t.Parallel()
+
+	long = strings.Repeat("0123456789", 2000)
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[bool]{
+		"Match": {
+			content: "abc",
+			afterOp: "",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.HasPrefix("ab")
+			},
+		},
+		"Mismatch": {
+			content: "abc",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.HasPrefix("ac")
+			},
+		},
+		"TooShort": {
+			content: "ab",
+			afterOp: "",
+			result:  false,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.HasPrefix("abc")
+			},
+		},
+		"Empty": {
+			content: "",
+			afterOp: "",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				return lrd.HasPrefix("")
+			},
+		},
+		"AfterNext": {
+			content: "xé!",
+			afterOp: "x",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				lrd.Next()
+
+				return lrd.HasPrefix("é!")
+			},
+		},
+		"Long": {
+			content: "x" + long + "y",
+			afterOp: "x",
+			result:  true,
+			op: func(lrd *lexer.Reader) bool {
+				lrd.Next()
+
+				return lrd.HasPrefix(long + "y")
+			},
+		},
+	})
+}
+
 func TestReaderEmit(t *testing.T) {
 	var (
 		lrd   *lexer.Reader
@@ -1153,7 +1724,7 @@ func TestReaderEmit(t *testing.T) {
 	token, pos = lrd.Emit()
 
 	assert.Equal(t, "ab", token)
-	assert.Equal(t, lexer.Position{1, 1}, pos)
+	assert.Equal(t, lexer.Position{1, 1, 0}, pos)
 	assert.Equal(t, 'c', lrd.Next())
 
 	lrd.Ignore()
@@ -1164,14 +1735,14 @@ func TestReaderEmit(t *testing.T) {
 	token, pos = lrd.Emit()
 
 	assert.Equal(t, "ABC", token)
-	assert.Equal(t, lexer.Position{1, 4}, pos)
+	assert.Equal(t, lexer.Position{1, 4, 3}, pos)
 	assert.Equal(t, lexer.EOF, lrd.Next())
 
 	lrd = lexer.NewReader(strings.NewReader(""))
 	token, pos = lrd.Emit()
 
 	assert.Equal(t, "", token)
-	assert.Equal(t, lexer.Position{1, 1}, pos)
+	assert.Equal(t, lexer.Position{1, 1, 0}, pos)
 	assert.Equal(t, lexer.EOF, lrd.Next())
 }
 
@@ -1186,3 +1757,198 @@ func TestReaderIgnore(t *testing.T) {
 
 	assert.Equal(t, 'b', lrd.Next())
 }
+
+func TestReaderLineText(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[string]{
+		"Base": {
+			content: "abc def\nghi",
+			afterOp: "de",
+			result:  "abc def",
+			op: func(lrd *lexer.Reader) string {
+				lrd.AcceptSeq("abc ")
+				lrd.Ignore()
+				lrd.AcceptSeq("de")
+
+				return lrd.LineText()
+			},
+		},
+		"SecondLine": {
+			content: "abc\r\ndef\r\nghi",
+			afterOp: "",
+			result:  "def",
+			op: func(lrd *lexer.Reader) string {
+				lrd.Until("d")
+				lrd.Ignore()
+
+				return lrd.LineText()
+			},
+		},
+		"LastLine": {
+			content: "abc\ndef",
+			afterOp: "abc\nd",
+			result:  "def",
+			op: func(lrd *lexer.Reader) string {
+				lrd.AcceptSeq("abc\nd")
+
+				return lrd.LineText()
+			},
+		},
+		"AfterNewline": {
+			content: "abc\n",
+			afterOp: "abc\n",
+			result:  "",
+			op: func(lrd *lexer.Reader) string {
+				lrd.AcceptSeq("abc\n")
+
+				return lrd.LineText()
+			},
+		},
+		"Empty": {
+			content: "",
+			afterOp: "",
+			result:  "",
+			op: func(lrd *lexer.Reader) string {
+				return lrd.LineText()
+			},
+		},
+		"Slide": {
+			content: strings.Repeat("a\n", 10000) + "bcd",
+			afterOp: "c",
+			result:  "bcd",
+			op: func(lrd *lexer.Reader) string {
+				for lrd.Accept("a\n") {
+					lrd.Ignore()
+				}
+
+				lrd.Accept("b")
+				lrd.Ignore()
+				lrd.Accept("c")
+
+				return lrd.LineText()
+			},
+		},
+	})
+}
+
+func TestReaderSetPosition(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		token string
+		pos   lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("ab\ncd"))
+	lrd.Next()
+	lrd.SetPosition(lexer.Position{10, 5, 120})
+
+	assert.Equal(t, lexer.Position{10, 5, 120}, lrd.StartPosition())
+	assert.Equal(t, lexer.Position{10, 5, 120}, lrd.CurrentPosition())
+
+	lrd.AcceptSeq("b\nc")
+	token, pos = lrd.Emit()
+
+	assert.Equal(t, "b\nc", token)
+	assert.Equal(t, lexer.Position{10, 5, 120}, pos)
+	assert.Equal(t, lexer.Position{11, 2, 123}, lrd.CurrentPosition())
+}
+
+func TestReaderSource(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader(""))
+
+	assert.Equal(t, "", lrd.Source())
+
+	lrd.SetSource("main.go")
+
+	assert.Equal(t, "main.go", lrd.Source())
+}
+
+func TestReaderWithZeroBased(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		pos lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("a\U0001f600\nb"),
+		lexer.WithZeroBased(),
+		lexer.WithColumnWidth(lexer.UTF16Width),
+	)
+
+	assert.Equal(t, lexer.Position{0, 0, 0}, lrd.CurrentPosition())
+
+	lrd.AcceptSeq("a\U0001f600")
+	_, pos = lrd.Emit()
+
+	assert.Equal(t, lexer.Position{0, 0, 0}, pos)
+	assert.Equal(t, lexer.Position{0, 3, 5}, lrd.CurrentPosition())
+
+	lrd.AcceptSeq("\nb")
+
+	assert.Equal(t, lexer.Position{1, 1, 7}, lrd.CurrentPosition())
+}
+
+func TestReaderErr(t *testing.T) {
+	var (
+		errRead error
+		lrd     *lexer.Reader
+		readErr *lexer.ReadError
+		err     error
+	)
+
+	t.Parallel()
+
+	errRead = errors.New("connection reset")
+	lrd = lexer.NewReader(io.MultiReader(
+		strings.NewReader("ab\nc\u00e9"),
+		iotest.ErrReader(errRead),
+	))
+	lrd.SetSource("stream")
+
+	lrd.AcceptRunFunc(func(rune) bool {
+		return true
+	})
+
+	err = lrd.Err()
+
+	assert.ErrorIs(t, err, errRead)
+	assert.EqualError(
+		t,
+		err,
+		"stream:2:3: lexer: read failed at offset 6: connection reset",
+	)
+
+	if assert.ErrorAs(t, err, &readErr) {
+		assert.Equal(t, lexer.Position{2, 3, 6}, readErr.Pos)
+		assert.Equal(t, lrd.CurrentPosition(), readErr.Pos)
+	}
+}
+
+func TestReaderErrLookahead(t *testing.T) {
+	var (
+		lrd     *lexer.Reader
+		readErr *lexer.ReadError
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(iotest.DataErrReader(iotest.TimeoutReader(
+		strings.NewReader("ab\ncd"),
+	)))
+
+	assert.Equal(t, 'a', lrd.Next())
+
+	if assert.ErrorAs(t, lrd.Err(), &readErr) {
+		assert.ErrorIs(t, readErr, iotest.ErrTimeout)
+		assert.Equal(t, lexer.Position{2, 3, 5}, readErr.Pos)
+	}
+}
@@ -235,6 +235,7 @@ func TestReaderFill(t *testing.T) {
 
 		lrd.start = lrd.head
 		lrd.current = lrd.head
+		lrd.lineStart = lrd.head
 		lrd.fill()
 		slices.Reverse(buf)
 
@@ -257,15 +258,17 @@ func TestReaderFill(t *testing.T) {
 	})
 
 	t.Run("bogusReader", func(t *testing.T) {
+		var lrd *Reader
+
 		t.Parallel()
 
-		assert.PanicsWithValue(
-			t,
-			"langengine/lexer: bogus io.Reader",
-			func() {
-				NewReader(bogusReader{}).fill()
-			},
-		)
+		lrd = NewReader(bogusReader{})
+
+		assert.NotPanics(t, lrd.fill)
+		assert.Equal(t, 0, lrd.head)
+		assert.ErrorIs(t, lrd.Err(), ErrBadRead)
+		assert.Equal(t, EOF, lrd.Next())
+		assert.ErrorIs(t, lrd.Err(), ErrBadRead)
 	})
 }
 
@@ -286,39 +289,39 @@ func TestReaderNext(t *testing.T) {
 		{
 			content: "abc",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 1},
-				{Position{1, 3}, 2},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 1}, 1, 0},
+				{Position{1, 3, 2}, 2, 0},
 			},
 		},
 		{
 			content: "qwertyuiop",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 1},
-				{Position{1, 3}, 2},
-				{Position{1, 4}, 3},
-				{Position{1, 5}, 4},
-				{Position{1, 6}, 5},
-				{Position{1, 7}, 6},
-				{Position{1, 8}, 7},
-				{Position{1, 9}, 8},
-				{Position{1, 10}, 9},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 1}, 1, 0},
+				{Position{1, 3, 2}, 2, 0},
+				{Position{1, 4, 3}, 3, 0},
+				{Position{1, 5, 4}, 4, 0},
+				{Position{1, 6, 5}, 5, 0},
+				{Position{1, 7, 6}, 6, 0},
+				{Position{1, 8, 7}, 7, 0},
+				{Position{1, 9, 8}, 8, 0},
+				{Position{1, 10, 9}, 9, 0},
 			},
 		},
 		{
 			// 😀 U+1F600 GRINNING FACE (4 bytes)
 			content: "😀😀abc😀😀\n😀",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 4},
-				{Position{1, 3}, 8},
-				{Position{1, 4}, 9},
-				{Position{1, 5}, 10},
-				{Position{1, 6}, 11},
-				{Position{1, 7}, 15},
-				{Position{1, 8}, 19},
-				{Position{2, 1}, 20},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 4}, 4, 0},
+				{Position{1, 3, 8}, 8, 0},
+				{Position{1, 4, 9}, 9, 0},
+				{Position{1, 5, 10}, 10, 0},
+				{Position{1, 6, 11}, 11, 0},
+				{Position{1, 7, 15}, 15, 0},
+				{Position{1, 8, 19}, 19, 0},
+				{Position{2, 1, 20}, 20, 20},
 			},
 		},
 		{
@@ -326,23 +329,23 @@ func TestReaderNext(t *testing.T) {
 			// 文 U+6587 (3 bytes)
 			content: "中文a",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 3},
-				{Position{1, 3}, 6},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 3}, 3, 0},
+				{Position{1, 3, 6}, 6, 0},
 			},
 		},
 		{
 			// 🐍 U+1F40D (4 bytes)
 			content: "go🐍lang",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 1},
-				{Position{1, 3}, 2},
-				{Position{1, 4}, 6},
-				{Position{1, 5}, 7},
-				{Position{1, 6}, 8},
-				{Position{1, 7}, 9},
-				{Position{1, 8}, 9},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 1}, 1, 0},
+				{Position{1, 3, 2}, 2, 0},
+				{Position{1, 4, 6}, 6, 0},
+				{Position{1, 5, 7}, 7, 0},
+				{Position{1, 6, 8}, 8, 0},
+				{Position{1, 7, 9}, 9, 0},
+				{Position{1, 8, 9}, 9, 0},
 			},
 		},
 		{
@@ -351,11 +354,11 @@ func TestReaderNext(t *testing.T) {
 			// 😀 U+1F600 (4 bytes)
 			content: "Aé中😀B",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 1},
-				{Position{1, 3}, 3},
-				{Position{1, 4}, 6},
-				{Position{1, 5}, 10},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 1}, 1, 0},
+				{Position{1, 3, 3}, 3, 0},
+				{Position{1, 4, 6}, 6, 0},
+				{Position{1, 5, 10}, 10, 0},
 			},
 		},
 		{
@@ -364,12 +367,12 @@ func TestReaderNext(t *testing.T) {
 			// 🐍 U+1F40D (4 bytes)
 			content: "😀\n文\n🐍a",
 			history: []snapshot{
-				{Position{1, 1}, 0},
-				{Position{1, 2}, 4},
-				{Position{2, 1}, 5},
-				{Position{2, 2}, 8},
-				{Position{3, 1}, 9},
-				{Position{3, 2}, 13},
+				{Position{1, 1, 0}, 0, 0},
+				{Position{1, 2, 4}, 4, 0},
+				{Position{2, 1, 5}, 5, 5},
+				{Position{2, 2, 8}, 8, 5},
+				{Position{3, 1, 9}, 9, 9},
+				{Position{3, 2, 13}, 13, 9},
 			},
 		},
 	}
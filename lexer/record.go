@@ -0,0 +1,201 @@
+package lexer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+)
+
+// Recording captures everything a Reader attached with WithRecording
+// reads from its io.Reader, chunk by chunk, together with a trace of
+// the primitive operations performed on it. A Recording can be saved
+// with WriteTo, loaded back with ReadRecording, and replayed with
+// NewReader(rec.Replay()), which reproduces the input exactly as it
+// was delivered, read boundaries and errors included, even when the
+// original source was a transient stream such as a socket or stdin.
+//
+// A Recording is not safe for concurrent use and should be attached to
+// a single Reader.
+type Recording struct {
+	// Reads lists the results of the calls to Read on the underlying
+	// io.Reader, in order.
+	Reads []RecordedRead
+
+	// Events lists the primitive operations performed on the Reader, in
+	// order.
+	Events []RecordedEvent
+}
+
+// RecordedRead is the result of a single call to Read.
+type RecordedRead struct {
+	// Data holds the bytes returned by the call.
+	Data []byte
+
+	// Err is the message of the error returned by the call, or the
+	// empty string if there was none or it was io.EOF.
+	Err string `json:",omitempty"`
+
+	// EOF reports whether the call returned io.EOF.
+	EOF bool `json:",omitempty"`
+}
+
+// RecordedEvent is a primitive operation performed on a Reader.
+type RecordedEvent struct {
+	// Op names the operation: "next" for a rune or byte delivered by
+	// Next and the methods built on it, "runes" for runes consumed by
+	// NextRunes, "bytes" for bytes consumed by ReadN or AcceptLine,
+	// "backup", "reject", "ignore", "emit", or "position" for
+	// SetPosition.
+	Op string
+
+	// Rune is the rune delivered by a "next" event.
+	Rune rune `json:",omitempty"`
+
+	// N is the number of bytes of a "next" or "bytes" event, the number
+	// of runes of a "runes" event, or the number of runes requested from
+	// a "backup" event.
+	N int `json:",omitempty"`
+
+	// Pos is the current position after the operation.
+	Pos Position
+}
+
+// recordingReader tees the results of Read into a Recording.
+type recordingReader struct {
+	rd  io.Reader
+	rec *Recording
+}
+
+// replayReader replays the reads of a Recording.
+type replayReader struct {
+	reads []RecordedRead
+}
+
+// WithRecording makes the Reader append to rec everything it reads from
+// its io.Reader and every primitive operation performed on it. It must
+// be the first option that concerns the io.Reader, and rec should be
+// empty. Recording copies all input, so it is meant for reproducing
+// bugs rather than for production use.
+func WithRecording(rec *Recording) Option {
+	return func(lrd *Reader) {
+		lrd.rd = &recordingReader{
+			rd:  lrd.rd,
+			rec: rec,
+		}
+
+		lrd.recording = rec
+	}
+}
+
+// ReadRecording decodes a Recording written by Recording.WriteTo.
+//
+// Returns an error if r cannot be read or does not hold a Recording.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	var (
+		rec *Recording
+		err error
+	)
+
+	rec = &Recording{}
+
+	err = json.NewDecoder(r).Decode(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// WriteTo writes the recording to w as JSON, implementing io.WriterTo.
+func (rec *Recording) WriteTo(w io.Writer) (int64, error) {
+	var (
+		data []byte
+		n    int
+		err  error
+	)
+
+	data, err = json.Marshal(rec)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = w.Write(append(data, '\n'))
+
+	return int64(n), err
+}
+
+// Replay returns an io.Reader that delivers the recorded reads again,
+// with the same data in the same chunks. Recorded errors other than
+// io.EOF are returned as new errors with the same message. Once the
+// recorded reads are exhausted, it returns io.EOF.
+func (rec *Recording) Replay() io.Reader {
+	return &replayReader{reads: slices.Clone(rec.Reads)}
+}
+
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	var (
+		read RecordedRead
+		n    int
+		err  error
+	)
+
+	n, err = rr.rd.Read(p)
+
+	read.Data = append([]byte{}, p[:max(min(n, len(p)), 0)]...)
+
+	switch {
+	case err == io.EOF:
+		read.EOF = true
+	case err != nil:
+		read.Err = err.Error()
+	}
+
+	rr.rec.Reads = append(rr.rec.Reads, read)
+
+	return n, err
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	var (
+		read RecordedRead
+		n    int
+	)
+
+	if len(rr.reads) == 0 {
+		return 0, io.EOF
+	}
+
+	read = rr.reads[0]
+
+	n = copy(p, read.Data)
+	if n < len(read.Data) {
+		rr.reads[0].Data = read.Data[n:]
+
+		return n, nil
+	}
+
+	rr.reads = rr.reads[1:]
+
+	switch {
+	case read.EOF:
+		return n, io.EOF
+	case read.Err != "":
+		return n, errors.New(read.Err)
+	default:
+		return n, nil
+	}
+}
+
+func (lrd *Reader) record(op string, char rune, n int) {
+	if lrd.recording == nil {
+		return
+	}
+
+	lrd.recording.Events = append(lrd.recording.Events, RecordedEvent{
+		Op:   op,
+		Rune: char,
+		N:    n,
+		Pos:  lrd.currentPos,
+	})
+}
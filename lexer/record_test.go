@@ -0,0 +1,90 @@
+package lexer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordingEvents(t *testing.T) {
+	var (
+		rec lexer.Recording
+		lrd *lexer.Reader
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("aé"), lexer.WithRecording(&rec))
+	lrd.Next()
+	lrd.Next()
+	lrd.Backup(1)
+	lrd.Emit()
+	lrd.Next()
+	lrd.Ignore()
+	lrd.SetPosition(lexer.Position{5, 1, 0})
+
+	assert.Equal(t, []lexer.RecordedEvent{
+		{Op: "next", Rune: 'a', N: 1, Pos: lexer.Position{1, 2, 1}},
+		{Op: "next", Rune: 'é', N: 2, Pos: lexer.Position{1, 3, 3}},
+		{Op: "backup", N: 1, Pos: lexer.Position{1, 2, 1}},
+		{Op: "emit", Pos: lexer.Position{1, 2, 1}},
+		{Op: "next", Rune: 'é', N: 2, Pos: lexer.Position{1, 3, 3}},
+		{Op: "ignore", Pos: lexer.Position{1, 3, 3}},
+		{Op: "ignore", Pos: lexer.Position{1, 3, 3}},
+		{Op: "position", Pos: lexer.Position{5, 1, 0}},
+	}, rec.Events)
+
+	assert.Equal(t, []lexer.RecordedRead{
+		{Data: []byte("aé")},
+	}, rec.Reads[:1])
+}
+
+func TestRecordingReplay(t *testing.T) {
+	var (
+		rec      lexer.Recording
+		replay   lexer.Recording
+		loaded   *lexer.Recording
+		buf      bytes.Buffer
+		expected []lexer.Token
+		lx       *lexer.Lexer
+		err      error
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		io.MultiReader(
+			iotest.OneByteReader(strings.NewReader("ab cd\n")),
+			iotest.ErrReader(errors.New("connection reset")),
+		),
+		lexWords,
+		lexer.WithRecording(&rec),
+	)
+	expected = slices.Collect(lx.Tokens())
+
+	_, err = rec.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	loaded, err = lexer.ReadRecording(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, rec, *loaded)
+
+	lx = lexer.NewLexer(loaded.Replay(), lexWords, lexer.WithRecording(&replay))
+
+	assert.Equal(t, expected, slices.Collect(lx.Tokens()))
+	assert.Equal(t, rec, replay)
+	assert.EqualError(t, errors.Unwrap(lx.Err()), "connection reset")
+
+	_, err = lexer.ReadRecording(strings.NewReader("{"))
+	assert.Error(t, err)
+}
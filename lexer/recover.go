@@ -0,0 +1,85 @@
+package lexer
+
+// SkipToLineStart consumes runes up to and including the next line
+// feed, leaving the Reader at the start of the following line, or at
+// the end of input if there is no further line. It is meant for error
+// recovery in line-oriented grammars: after a lexical error, the rest
+// of the offending line is skipped and lexing resumes on the next one.
+//
+// The skipped runes remain part of the current token, so they can be
+// reported with Emit or dropped with Ignore, but the history used by
+// Backup is discarded as they are consumed. Skipping an arbitrarily
+// long region therefore takes constant memory, and the skipped runes
+// can no longer be restored with Backup.
+//
+// Returns the number of runes consumed, including the line feed.
+func (lrd *Reader) SkipToLineStart() int {
+	var (
+		from  Position
+		count int
+	)
+
+	from = lrd.currentPos
+
+	count = lrd.syncTo(containsFn("\n"))
+	if lrd.Accept("\n") {
+		count++
+	}
+
+	lrd.history = lrd.history[:0]
+	lrd.logRecovery(from, count)
+
+	return count
+}
+
+// SyncTo consumes runes until EOF or until a rune found in the given
+// string, such as ";" or "}" in a C-like grammar, which is left
+// unconsumed. Like SkipToLineStart, it keeps the skipped runes in the
+// current token but discards the history used by Backup.
+//
+// Returns the number of runes consumed.
+func (lrd *Reader) SyncTo(match string) int {
+	return lrd.SyncToFunc(containsFn(match))
+}
+
+// SyncToFunc consumes runes until EOF or until the provided predicate
+// function returns true for a rune, which is left unconsumed. Like
+// SkipToLineStart, it keeps the skipped runes in the current token but
+// discards the history used by Backup.
+//
+// Returns the number of runes consumed.
+func (lrd *Reader) SyncToFunc(fn func(rune) bool) int {
+	var (
+		from  Position
+		count int
+	)
+
+	from = lrd.currentPos
+	count = lrd.syncTo(fn)
+	lrd.logRecovery(from, count)
+
+	return count
+}
+
+func (lrd *Reader) syncTo(fn func(rune) bool) int {
+	var (
+		char  rune
+		count int
+	)
+
+	for {
+		lrd.history = lrd.history[:0]
+
+		char = lrd.Next()
+		switch {
+		case char == EOF:
+			return count
+		case fn(char):
+			lrd.Backup(1)
+
+			return count
+		}
+
+		count++
+	}
+}
@@ -0,0 +1,128 @@
+package lexer_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+func TestReaderSkipToLineStart(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[int]{
+		"Base": {
+			content: "a!c\ndef",
+			afterOp: "a!c\n",
+			result:  4,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SkipToLineStart()
+			},
+		},
+		"NoNewline": {
+			content: "a!c",
+			afterOp: "a!c",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SkipToLineStart()
+			},
+		},
+		"EmptyLine": {
+			content: "\nabc",
+			afterOp: "\n",
+			result:  1,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SkipToLineStart()
+			},
+		},
+		"Empty": {
+			content: "",
+			afterOp: "",
+			result:  0,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SkipToLineStart()
+			},
+		},
+		"DiscardHistory": {
+			content: "ab\ncd",
+			afterOp: "ab\n",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				var count int
+
+				count = lrd.SkipToLineStart()
+				lrd.Backup(3)
+
+				return count
+			},
+		},
+	})
+}
+
+func TestReaderSyncTo(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[int]{
+		"Base": {
+			content: "a @ b; c",
+			afterOp: "a @ b",
+			result:  5,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SyncTo(";}")
+			},
+		},
+		"NoMatch": {
+			content: "abc",
+			afterOp: "abc",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SyncTo(";}")
+			},
+		},
+		"Immediate": {
+			content: "}abc",
+			afterOp: "",
+			result:  0,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SyncTo(";}")
+			},
+		},
+		"DiscardHistory": {
+			content: "abc;",
+			afterOp: "abc",
+			result:  2,
+			op: func(lrd *lexer.Reader) int {
+				var count int
+
+				lrd.Next()
+				count = lrd.SyncTo(";")
+				lrd.Backup(3)
+
+				return count
+			},
+		},
+	})
+}
+
+func TestReaderSyncToFunc(t *testing.T) {
+	t.Parallel()
+
+	assertHelperTestDataTbl(t, map[string]helperTestData[int]{
+		"Base": {
+			content: "a@b c",
+			afterOp: "a@b",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SyncToFunc(unicode.IsSpace)
+			},
+		},
+		"NoMatch": {
+			content: "abc",
+			afterOp: "abc",
+			result:  3,
+			op: func(lrd *lexer.Reader) int {
+				return lrd.SyncToFunc(unicode.IsSpace)
+			},
+		},
+	})
+}
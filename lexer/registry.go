@@ -0,0 +1,177 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidLanguage is returned by Register for a Language without
+	// a name or without a NewLexer function.
+	ErrInvalidLanguage = errors.New("lexer: language needs a name and a NewLexer function")
+
+	// ErrLanguageExists is returned by Register for a Language whose
+	// name or alias is already registered.
+	ErrLanguageExists = errors.New("lexer: language already registered")
+)
+
+// Language describes a language known to the registry, so that tools
+// built on the package can lex any registered format by name or file
+// extension without depending on every grammar. Grammar packages, or
+// plugins loaded at run time, register their languages with Register,
+// typically from an init function.
+type Language struct {
+	// Name is the canonical name of the language, such as "toml".
+	Name string
+
+	// Aliases lists other names the language is known by, such as
+	// "yml" for "yaml".
+	Aliases []string
+
+	// Extensions lists the file name extensions of the language,
+	// including the leading dot, such as ".toml".
+	Extensions []string
+
+	// NewLexer returns a Lexer over rd for the language, such as the
+	// NewLexer method of a compiled lexgen.Grammar or a closure around
+	// the NewLexer function of a format package.
+	NewLexer func(rd io.Reader, opts ...Option) *Lexer
+
+	// KindNames maps the kinds produced by the language to readable
+	// names, for tools displaying tokens. Kinds missing from it are
+	// displayed with Kind.String.
+	KindNames map[Kind]string
+}
+
+// KindName returns the name of kind in lang, as set in KindNames, or
+// else the result of kind.String.
+func (lang Language) KindName(kind Kind) string {
+	var (
+		name string
+		ok   bool
+	)
+
+	name, ok = lang.KindNames[kind]
+	if !ok {
+		return kind.String()
+	}
+
+	return name
+}
+
+type registry struct {
+	mu     sync.RWMutex
+	langs  []Language
+	byName map[string]int
+	byExt  map[string]int
+}
+
+var languages = registry{
+	byName: make(map[string]int),
+	byExt:  make(map[string]int),
+}
+
+// Register adds lang to the registry. Names, aliases, and extensions
+// are matched without regard to case. An extension may be claimed by
+// several languages, such as ".h" by C and C++, in which case ForFile
+// picks the one registered first.
+//
+// Returns an error wrapping ErrInvalidLanguage if lang has no name or no
+// NewLexer function, or ErrLanguageExists if its name or one of its
+// aliases is already registered, in which case nothing is registered.
+func Register(lang Language) error {
+	var (
+		names []string
+		name  string
+		ext   string
+		ok    bool
+	)
+
+	if lang.Name == "" || lang.NewLexer == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidLanguage, lang.Name)
+	}
+
+	lang.Aliases = slices.Clone(lang.Aliases)
+	lang.Extensions = slices.Clone(lang.Extensions)
+	names = append([]string{lang.Name}, lang.Aliases...)
+
+	languages.mu.Lock()
+	defer languages.mu.Unlock()
+
+	for _, name = range names {
+		_, ok = languages.byName[strings.ToLower(name)]
+		if ok {
+			return fmt.Errorf("%w: %q", ErrLanguageExists, name)
+		}
+	}
+
+	for _, name = range names {
+		languages.byName[strings.ToLower(name)] = len(languages.langs)
+	}
+
+	for _, ext = range lang.Extensions {
+		ext = strings.ToLower(ext)
+
+		_, ok = languages.byExt[ext]
+		if !ok {
+			languages.byExt[ext] = len(languages.langs)
+		}
+	}
+
+	languages.langs = append(languages.langs, lang)
+
+	return nil
+}
+
+// ForLanguage returns the registered Language with the given name or
+// alias. The boolean is false if no such language is registered.
+func ForLanguage(name string) (Language, bool) {
+	languages.mu.RLock()
+	defer languages.mu.RUnlock()
+
+	return languages.lookup(languages.byName, strings.ToLower(name))
+}
+
+// ForFile returns the registered Language claiming the extension of the
+// file name, such as ".toml" for "config/app.toml". The boolean is
+// false if no registered language claims it.
+func ForFile(name string) (Language, bool) {
+	languages.mu.RLock()
+	defer languages.mu.RUnlock()
+
+	return languages.lookup(languages.byExt, strings.ToLower(path.Ext(name)))
+}
+
+// Languages returns the registered languages, sorted by name.
+func Languages() []Language {
+	var langs []Language
+
+	languages.mu.RLock()
+	langs = slices.Clone(languages.langs)
+	languages.mu.RUnlock()
+
+	slices.SortFunc(langs, func(a, b Language) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return langs
+}
+
+func (reg *registry) lookup(index map[string]int, key string) (Language, bool) {
+	var (
+		i  int
+		ok bool
+	)
+
+	i, ok = index[key]
+	if !ok || key == "" {
+		return Language{}, false
+	}
+
+	return reg.langs[i], true
+}
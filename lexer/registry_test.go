@@ -0,0 +1,82 @@
+package lexer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	var (
+		lang   lexer.Language
+		found  lexer.Language
+		tokens []kindText
+		ok     bool
+	)
+
+	t.Parallel()
+
+	lang = lexer.Language{
+		Name:       "registry-words",
+		Aliases:    []string{"Registry-W"},
+		Extensions: []string{".regwords"},
+		NewLexer: func(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+			return lexer.NewLexer(rd, lexWords, opts...)
+		},
+		KindNames: map[lexer.Kind]string{kindWord: "word"},
+	}
+
+	assert.NoError(t, lexer.Register(lang))
+	assert.ErrorIs(t, lexer.Register(lexer.Language{
+		Name:     "registry-other",
+		Aliases:  []string{"registry-w"},
+		NewLexer: lang.NewLexer,
+	}), lexer.ErrLanguageExists)
+	assert.ErrorIs(
+		t,
+		lexer.Register(lexer.Language{Name: "registry-nil"}),
+		lexer.ErrInvalidLanguage,
+	)
+
+	_, ok = lexer.ForLanguage("registry-other")
+	assert.False(t, ok)
+
+	found, ok = lexer.ForLanguage("REGISTRY-W")
+	assert.True(t, ok)
+	assert.Equal(t, "registry-words", found.Name)
+
+	found, ok = lexer.ForFile("dir/input.RegWords")
+	assert.True(t, ok)
+	assert.Equal(t, "registry-words", found.Name)
+
+	_, ok = lexer.ForFile("dir/regwords")
+	assert.False(t, ok)
+
+	assert.Equal(t, "word", found.KindName(kindWord))
+	assert.Equal(t, lexer.KindError.String(), found.KindName(lexer.KindError))
+
+	assert.Contains(t, languageNames(lexer.Languages()), "registry-words")
+
+	tokens = collectKinds(found.NewLexer(strings.NewReader("a b")))
+	assert.Equal(t, []kindText{
+		{kindWord, "a"},
+		{kindSpace, " "},
+		{kindWord, "b"},
+	}, tokens)
+}
+
+func languageNames(langs []lexer.Language) []string {
+	var (
+		result []string
+		lang   lexer.Language
+	)
+
+	for _, lang = range langs {
+		result = append(result, lang.Name)
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package lexer
+
+import "unicode/utf8"
+
+// NextRunes consumes up to len(dst) runes and decodes them into dst, as
+// if by successive calls to Next, but in a single call and with a
+// single snapshot for the whole batch: Backup(1) restores the position
+// preceding all of the runes consumed, and the history used by Backup
+// does not grow with len(dst). It suits consumers that scan long runs
+// of input themselves.
+//
+// Returns the number of runes consumed, which is less than len(dst)
+// only at the end of input. A NUL byte is decoded as a zero rune like
+// any other.
+func (lrd *Reader) NextRunes(dst []rune) int {
+	var (
+		char  rune
+		size  int
+		count int
+	)
+
+	if len(dst) == 0 {
+		return 0
+	}
+
+	lrd.save()
+
+	for count < len(dst) {
+		lrd.fill()
+
+		if lrd.head == lrd.current {
+			break
+		}
+
+		// Decode like Next after each fill, then keep going without
+		// filling while the next rune is certainly complete.
+		for {
+			char, size = rune(lrd.buf[lrd.current]), 1
+			if char >= utf8.RuneSelf {
+				char, size = utf8.DecodeRune(lrd.buf[lrd.current:lrd.head])
+			}
+
+			lrd.move(char, size)
+			dst[count] = char
+			count++
+
+			if count == len(dst) || lrd.head-lrd.current < utf8.UTFMax {
+				break
+			}
+		}
+	}
+
+	if count == 0 {
+		lrd.history = lrd.history[:len(lrd.history)-1]
+
+		return 0
+	}
+
+	lrd.record("runes", 0, count)
+
+	return count
+}
@@ -0,0 +1,112 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderNextRunes(t *testing.T) {
+	type testData struct {
+		content string
+		size    int
+		runes   []rune
+		text    string
+		pos     lexer.Position
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Partial": {
+			content: "aé\n世b",
+			size:    4,
+			runes:   []rune("aé\n世"),
+			text:    "aé\n世",
+			pos:     lexer.Position{2, 2, 7},
+		},
+		"EOF": {
+			content: "ab",
+			size:    4,
+			runes:   []rune("ab"),
+			text:    "ab",
+			pos:     lexer.Position{1, 3, 2},
+		},
+		"Invalid": {
+			content: "a\xffb\x00",
+			size:    4,
+			runes:   []rune("a�b\x00"),
+			text:    "a\xffb\x00",
+			pos:     lexer.Position{1, 5, 4},
+		},
+		"Empty": {
+			content: "",
+			size:    4,
+			runes:   []rune{},
+			pos:     lexer.Position{1, 1, 0},
+		},
+		"ZeroLength": {
+			content: "ab",
+			size:    0,
+			runes:   []rune{},
+			pos:     lexer.Position{1, 1, 0},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				lrd *lexer.Reader
+				dst []rune
+				n   int
+			)
+
+			lrd = lexer.NewReader(strings.NewReader(test.content))
+			dst = make([]rune, test.size)
+
+			n = lrd.NextRunes(dst)
+
+			assert.Equal(t, test.runes, dst[:n])
+			assert.Equal(t, test.pos, lrd.CurrentPosition())
+			assert.Equal(t, test.text, lrd.PeekToken())
+		})
+	}
+}
+
+func TestReaderNextRunesBackup(t *testing.T) {
+	var (
+		lrd     *lexer.Reader
+		dst     []rune
+		content string
+	)
+
+	t.Parallel()
+
+	content = strings.Repeat("日本語", 5000)
+	lrd = lexer.NewReader(iotest.HalfReader(strings.NewReader("x" + content)))
+	dst = make([]rune, 15000)
+
+	assert.Equal(t, 'x', lrd.Next())
+	assert.Equal(t, 15000, lrd.NextRunes(dst))
+	assert.Equal(t, content, string(dst))
+	assert.Equal(t, lexer.Position{1, 15002, 45001}, lrd.CurrentPosition())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, lexer.Position{1, 2, 1}, lrd.CurrentPosition())
+
+	lrd.Backup(1)
+
+	assert.Equal(t, lexer.Position{1, 1, 0}, lrd.CurrentPosition())
+	assert.Equal(t, 'x', lrd.Next())
+	assert.Equal(t, '日', lrd.Next())
+}
@@ -0,0 +1,11 @@
+//go:build !tinygo && !lexertiny
+
+package lexer_test
+
+const (
+	// lexWordsName is the name of lexWords in a Profile.
+	lexWordsName = "github.com/andrieee44/langengine/lexer_test.lexWords"
+
+	// persistentDirCache reports whether a DirCache stores entries.
+	persistentDirCache = true
+)
@@ -0,0 +1,202 @@
+package lexer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SecurityScanner flags tokens that can make source code read
+// differently to a human than to a compiler, as described by the
+// "Trojan Source" family of attacks. It reports:
+//
+//   - Unicode bidirectional control characters in any token, including
+//     comments and string literals where they are most often hidden;
+//   - identifiers mixing letters from the Latin, Greek, and Cyrillic
+//     scripts, such as a Latin word with a Cyrillic 'а';
+//   - identifiers that are visually confusable with a different
+//     identifier seen earlier, such as "scope" and "ѕсоре".
+//
+// The confusable check uses a small table of the most common
+// homoglyphs rather than the full Unicode confusables data, trading
+// completeness for a dependency-free implementation. A new
+// SecurityScanner is constructed with NewSecurityScanner and remembers
+// identifiers across calls to Scan, so one scanner should be used per
+// document.
+type SecurityScanner struct {
+	identifiers []Kind
+	opts        []Option
+	skeletons   map[string]string
+}
+
+var scripts = []*unicode.RangeTable{
+	unicode.Latin,
+	unicode.Greek,
+	unicode.Cyrillic,
+}
+
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x',
+	'ѕ': 's', 'і': 'i', 'ј': 'j', 'ԁ': 'd', 'ɡ': 'g', 'ո': 'n',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H',
+	'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'Ѕ': 'S',
+	'І': 'I', 'Ј': 'J',
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'ρ': 'p',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I',
+	'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T',
+	'Υ': 'Y', 'Χ': 'X',
+}
+
+// NewSecurityScanner returns a SecurityScanner that treats tokens of
+// the given kinds as identifiers for the mixed-script and confusable
+// checks. Bidirectional control characters are flagged in tokens of
+// every kind. The options, which should be those the tokens were lexed
+// with, such as WithColumnWidth or WithZeroBased, configure the Reader
+// that locates the flagged characters within a token.
+func NewSecurityScanner(identifiers []Kind, opts ...Option) *SecurityScanner {
+	return &SecurityScanner{
+		identifiers: identifiers,
+		opts:        opts,
+		skeletons:   make(map[string]string),
+	}
+}
+
+// Scan inspects tok and returns a Diagnostic for every problem found,
+// or nil if the token is clean. Diagnostics for bidirectional control
+// characters span the offending character; identifier diagnostics span
+// the whole token.
+func (sc *SecurityScanner) Scan(tok Token) []Diagnostic {
+	var (
+		diags []Diagnostic
+		lrd   *Reader
+		pos   Position
+		char  rune
+	)
+
+	if tok.Kind == KindError {
+		return nil
+	}
+
+	lrd = NewReader(strings.NewReader(tok.Text), sc.opts...)
+	lrd.SetPosition(tok.Span.Start)
+
+	for !lrd.AtEOF() {
+		pos = lrd.CurrentPosition()
+
+		char = lrd.Next()
+		if !isBidiControl(char) {
+			continue
+		}
+
+		diags = append(diags, Diagnostic{
+			Span: Span{
+				Start: pos,
+				End:   lrd.CurrentPosition(),
+			},
+			Message: fmt.Sprintf(
+				"bidirectional control character %U",
+				char,
+			),
+		})
+	}
+
+	if !slices.Contains(sc.identifiers, tok.Kind) {
+		return diags
+	}
+
+	if mixesScripts(tok.Text) {
+		diags = append(diags, Diagnostic{
+			Span:    tok.Span,
+			Message: fmt.Sprintf("identifier %q mixes scripts", tok.Text),
+		})
+	}
+
+	return sc.checkConfusable(tok, diags)
+}
+
+func (sc *SecurityScanner) checkConfusable(
+	tok Token,
+	diags []Diagnostic,
+) []Diagnostic {
+	var (
+		skel  string
+		other string
+		ok    bool
+	)
+
+	skel = skeleton(tok.Text)
+
+	other, ok = sc.skeletons[skel]
+	if !ok {
+		sc.skeletons[skel] = tok.Text
+
+		return diags
+	}
+
+	if other == tok.Text {
+		return diags
+	}
+
+	return append(diags, Diagnostic{
+		Span: tok.Span,
+		Message: fmt.Sprintf(
+			"identifier %q is confusable with %q",
+			tok.Text,
+			other,
+		),
+		Fixes: []Fix{{
+			Title: fmt.Sprintf("rename to %q", other),
+			Edits: []Edit{{Span: tok.Span, NewText: other}},
+		}},
+	})
+}
+
+func isBidiControl(char rune) bool {
+	return '\u202A' <= char && char <= '\u202E' ||
+		'\u2066' <= char && char <= '\u2069' ||
+		char == '\u200E' ||
+		char == '\u200F' ||
+		char == '\u061C'
+}
+
+func mixesScripts(text string) bool {
+	var (
+		char  rune
+		seen  *unicode.RangeTable
+		table *unicode.RangeTable
+	)
+
+	for _, char = range text {
+		for _, table = range scripts {
+			if !unicode.Is(table, char) {
+				continue
+			}
+
+			if seen != nil && seen != table {
+				return true
+			}
+
+			seen = table
+		}
+	}
+
+	return false
+}
+
+func skeleton(text string) string {
+	return strings.Map(func(char rune) rune {
+		var (
+			mapped rune
+			ok     bool
+		)
+
+		mapped, ok = homoglyphs[char]
+		if ok {
+			return mapped
+		}
+
+		return char
+	}, text)
+}
@@ -0,0 +1,156 @@
+package lexer_test
+
+import (
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityScannerScan(t *testing.T) {
+	var sc *lexer.SecurityScanner
+
+	t.Parallel()
+
+	sc = lexer.NewSecurityScanner([]lexer.Kind{kindWord})
+
+	assert.Nil(t, sc.Scan(lexer.Token{
+		Kind: kindWord,
+		Text: "scope",
+		Span: lexer.Span{
+			Start: lexer.Position{1, 1, 0},
+			End:   lexer.Position{1, 6, 5},
+		},
+	}))
+
+	assert.Equal(t, []lexer.Diagnostic{
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{2, 4, 9},
+				End:   lexer.Position{2, 5, 12},
+			},
+			Message: "bidirectional control character U+202E",
+		},
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{3, 2, 14},
+				End:   lexer.Position{3, 3, 17},
+			},
+			Message: "bidirectional control character U+2066",
+		},
+	}, sc.Scan(lexer.Token{
+		Kind: kindSpace,
+		Text: "// \u202E\n \u2066",
+		Span: lexer.Span{
+			Start: lexer.Position{2, 1, 6},
+			End:   lexer.Position{3, 3, 17},
+		},
+	}))
+
+	assert.Equal(t, []lexer.Diagnostic{
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{4, 1, 18},
+				End:   lexer.Position{4, 6, 24},
+			},
+			Message: `identifier "sсope" mixes scripts`,
+		},
+		{
+			Span: lexer.Span{
+				Start: lexer.Position{4, 1, 18},
+				End:   lexer.Position{4, 6, 24},
+			},
+			Message: `identifier "sсope" is confusable with "scope"`,
+			Fixes: []lexer.Fix{{
+				Title: `rename to "scope"`,
+				Edits: []lexer.Edit{{
+					Span: lexer.Span{
+						Start: lexer.Position{4, 1, 18},
+						End:   lexer.Position{4, 6, 24},
+					},
+					NewText: "scope",
+				}},
+			}},
+		},
+	}, sc.Scan(lexer.Token{
+		Kind: kindWord,
+		// U+0441 CYRILLIC SMALL LETTER ES
+		Text: "s\u0441ope",
+		Span: lexer.Span{
+			Start: lexer.Position{4, 1, 18},
+			End:   lexer.Position{4, 6, 24},
+		},
+	}))
+
+	assert.Equal(t, []lexer.Diagnostic{
+		{
+			Message: `identifier "ѕсоре" is confusable with "scope"`,
+			Fixes: []lexer.Fix{{
+				Title: `rename to "scope"`,
+				Edits: []lexer.Edit{{NewText: "scope"}},
+			}},
+		},
+	}, sc.Scan(lexer.Token{
+		Kind: kindWord,
+		Text: "\u0455\u0441\u043e\u0440\u0435",
+	}))
+
+	assert.Nil(t, sc.Scan(lexer.Token{
+		Kind: kindWord,
+		Text: "scope",
+	}))
+
+	assert.Nil(t, sc.Scan(lexer.Token{
+		Kind: kindPunct,
+		Text: "s\u0441ope",
+	}))
+
+	assert.Nil(t, sc.Scan(lexer.Token{
+		Kind: lexer.KindError,
+		Text: "\u202E",
+	}))
+}
+
+func TestSecurityScannerPositions(t *testing.T) {
+	var sc *lexer.SecurityScanner
+
+	t.Parallel()
+
+	sc = lexer.NewSecurityScanner(nil)
+
+	assert.Equal(t, []lexer.Diagnostic{{
+		Span: lexer.Span{
+			Start: lexer.Position{1, 4, 3},
+			End:   lexer.Position{1, 5, 6},
+		},
+		Message: "bidirectional control character U+202E",
+	}}, sc.Scan(lexer.Token{
+		Kind: kindSpace,
+		Text: "\xff\x00\xfe\u202E",
+		Span: lexer.Span{
+			Start: lexer.Position{1, 1, 0},
+			End:   lexer.Position{1, 5, 6},
+		},
+	}))
+
+	sc = lexer.NewSecurityScanner(
+		nil,
+		lexer.WithZeroBased(),
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+
+	assert.Equal(t, []lexer.Diagnostic{{
+		Span: lexer.Span{
+			Start: lexer.Position{1, 2, 4},
+			End:   lexer.Position{1, 2, 7},
+		},
+		Message: "bidirectional control character U+2066",
+	}}, sc.Scan(lexer.Token{
+		Kind: kindSpace,
+		Text: "\n界\u2066",
+		Span: lexer.Span{
+			Start: lexer.Position{0, 0, 0},
+			End:   lexer.Position{1, 2, 7},
+		},
+	}))
+}
@@ -0,0 +1,97 @@
+package lexer
+
+import (
+	"errors"
+	"slices"
+	"unicode"
+)
+
+// ErrUnterminated is returned by helpers for delimited constructs when
+// the opening delimiter was consumed but the line or the input ended
+// before the closing delimiter was found.
+var ErrUnterminated = errors.New("lexer: unterminated construct")
+
+// SlashContext decides whether a '/' starts a regular expression literal
+// or is a division operator, as required by JavaScript-like grammars.
+// The decision follows the classic rule of looking at the previous
+// significant token reported by Lexer.LastToken: a '/' that follows an
+// operand divides it, while a '/' anywhere else starts a literal.
+type SlashContext struct {
+	// Operands lists the kinds of tokens that can end an operand, such
+	// as identifiers, numbers, strings, and closing brackets.
+	Operands []Kind
+
+	// Keywords lists token texts that cannot end an operand even though
+	// their kind is listed in Operands, such as "return", "typeof", or
+	// "case" in a grammar that lexes keywords as identifiers.
+	Keywords []string
+}
+
+// RegexpAllowed reports whether a '/' at the current position of lx
+// starts a regular expression literal. It is true at the start of
+// input, after tokens whose kind is not listed in Operands, and after
+// tokens whose text is listed in Keywords.
+func (ctx SlashContext) RegexpAllowed(lx *Lexer) bool {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	tok, ok = lx.LastToken()
+	if !ok {
+		return true
+	}
+
+	return !slices.Contains(ctx.Operands, tok.Kind) ||
+		slices.Contains(ctx.Keywords, tok.Text)
+}
+
+// AcceptRegexpLiteral consumes a regular expression literal such as
+// /a[/]b\/c/gi: an opening '/', a body in which '/' only terminates the
+// literal outside of backslash escapes and character classes, a closing
+// '/', and any trailing letters used as flags.
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// next rune is not '/'. Returns true with ErrUnterminated if a line
+// terminator or the end of input was reached before the closing '/'.
+// Callers should consult SlashContext.RegexpAllowed first, as every
+// division operator is also accepted as the start of a literal.
+func AcceptRegexpLiteral(lrd *Reader) (bool, error) {
+	var (
+		char    rune
+		inClass bool
+	)
+
+	if !lrd.Accept("/") {
+		return false, nil
+	}
+
+	for {
+		char = lrd.Next()
+
+		switch {
+		case char == EOF || char == '\n' || char == '\r':
+			if char != EOF {
+				lrd.Backup(1)
+			}
+
+			return true, ErrUnterminated
+		case char == '\\':
+			if !lrd.AcceptFunc(isNotLineTerminator) {
+				return true, ErrUnterminated
+			}
+		case char == '[':
+			inClass = true
+		case char == ']':
+			inClass = false
+		case char == '/' && !inClass:
+			lrd.AcceptRunFunc(unicode.IsLetter)
+
+			return true, nil
+		}
+	}
+}
+
+func isNotLineTerminator(char rune) bool {
+	return char != '\n' && char != '\r'
+}
@@ -0,0 +1,83 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+var slashCtx = lexer.SlashContext{
+	Operands: []lexer.Kind{kindWord, kindNumber, kindPunct},
+	Keywords: []string{"return", "typeof", "(", "="},
+}
+
+func lexSlash(lx *lexer.Lexer) lexer.StateFn {
+	var err error
+
+	switch {
+	case lx.AcceptRunFunc(unicode.IsSpace) != 0:
+		lx.EmitTrivia(kindSpace)
+	case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+		lx.Emit(kindWord)
+	case lx.AcceptRunFunc(unicode.IsDigit) != 0:
+		lx.Emit(kindNumber)
+	case lx.Peek() == '/' && slashCtx.RegexpAllowed(lx):
+		_, err = lexer.AcceptRegexpLiteral(lx.Reader)
+		if err != nil {
+			return lx.Errorf("%v", err)
+		}
+
+		lx.Emit(kindRegexp)
+	case lx.Peek() == lexer.EOF:
+		return nil
+	default:
+		lx.Next()
+		lx.Emit(kindPunct)
+	}
+
+	return lexSlash
+}
+
+func TestSlashContext(t *testing.T) {
+	var (
+		testTbl map[string][]string
+		content string
+		want    []string
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]string{
+		"a / b / c":            {"a", "/", "b", "/", "c"},
+		"/a/g":                 {"/a/g"},
+		"x = /[/]\\//.test(y)": {"x", "=", "/[/]\\//", ".", "test", "(", "y", ")"},
+		"return /a/i":          {"return", "/a/i"},
+		"f(1) / 2":             {"f", "(", "1", ")", "/", "2"},
+		"typeof /a/":           {"typeof", "/a/"},
+		"x = /abc\ny":          {"x", "=", "lexer: unterminated construct"},
+		"x = (/a\\":            {"x", "=", "(", "lexer: unterminated construct"},
+	}
+
+	for content, want = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			var (
+				lx  *lexer.Lexer
+				tok lexer.Token
+				got []string
+			)
+
+			lx = lexer.NewLexer(strings.NewReader(content), lexSlash)
+
+			for tok = range lx.Tokens() {
+				if !tok.Trivia {
+					got = append(got, tok.Text)
+				}
+			}
+
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package lexer
+
+import (
+	"slices"
+	"sync"
+)
+
+// Speculation is the outcome of running a candidate state on its own
+// clone of a Lexer, as reported to the choice function of Speculate.
+type Speculation struct {
+	// Tokens holds the tokens the candidate emitted.
+	Tokens []Token
+
+	// End is the position the candidate stopped at.
+	End Position
+
+	// Diagnostics holds the diagnostics the candidate reported with
+	// Lexer.Report.
+	Diagnostics []Diagnostic
+}
+
+// Speculate returns a state that tries several tokenizations of the
+// input from the current position, for lexical constructs that are
+// genuinely ambiguous until more input is seen, such as ">>" closing two
+// C++ template argument lists or shifting right. Every candidate state
+// runs a single step on its own Clone of the Lexer, concurrently with
+// the others, and choose is called with the outcomes in the order of
+// candidates.
+//
+// The Lexer then commits the candidate at the index returned by choose:
+// its tokens are emitted, its Reader replaces the embedded Reader of the
+// Lexer, and the state it returned runs next. A Recording attached with
+// WithRecording stays attached, and receives the events of the
+// committed candidate but not those of the others. The tokens of the other
+// candidates are discarded. If choose returns an index out of range, no
+// candidate is committed and the Lexer stops with a KindError token.
+func Speculate(
+	choose func([]Speculation) int,
+	candidates ...StateFn,
+) StateFn {
+	return func(lx *Lexer) StateFn {
+		var (
+			clones  []*Lexer
+			next    []StateFn
+			results []Speculation
+			wg      sync.WaitGroup
+			tok     Token
+			diag    Diagnostic
+			i       int
+		)
+
+		clones = make([]*Lexer, len(candidates))
+		next = make([]StateFn, len(candidates))
+		results = make([]Speculation, len(candidates))
+
+		for i = range candidates {
+			clones[i] = lx.clone()
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				next[i] = clones[i].run(candidates[i])
+				results[i] = Speculation{
+					Tokens:      clones[i].queue,
+					End:         clones[i].CurrentPosition(),
+					Diagnostics: clones[i].held,
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		i = choose(results)
+		if i < 0 || i >= len(candidates) {
+			return lx.Errorf("no candidate tokenization was chosen")
+		}
+
+		lx.commit(clones[i])
+
+		for _, tok = range results[i].Tokens {
+			lx.push(tok)
+		}
+
+		for _, diag = range results[i].Diagnostics {
+			lx.report(diag)
+		}
+
+		return next[i]
+	}
+}
+
+// Longest returns a state that runs the candidate states as Speculate
+// does and commits the one that consumed the most input, preferring
+// the earliest candidate on ties.
+func Longest(candidates ...StateFn) StateFn {
+	return Speculate(longest, candidates...)
+}
+
+func longest(results []Speculation) int {
+	var (
+		best int
+		i    int
+	)
+
+	for i = 1; i < len(results); i++ {
+		if results[i].End.Offset > results[best].End.Offset {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// commit makes the Reader and modes of the candidate clone those of lx.
+// The events the candidate recorded on its own are appended to the
+// Recording of lx, which the committed Reader then records into.
+func (lx *Lexer) commit(clone *Lexer) {
+	if lx.recording != nil {
+		lx.recording.Events = append(lx.recording.Events, clone.recording.Events...)
+		clone.recording = lx.recording
+	}
+
+	lx.Reader = clone.Reader
+	lx.modes = clone.modes
+}
+
+// clone returns a Lexer in the same state as lx, reading from a Clone
+// of its Reader, with no tokens queued, that holds back the diagnostics
+// it reports and the events it records.
+func (lx *Lexer) clone() *Lexer {
+	var lrd *Reader
+
+	lrd = lx.Reader.Clone()
+	if lx.recording != nil {
+		lrd.recording = &Recording{}
+	}
+
+	return &Lexer{
+		Reader:      lrd,
+		state:       lx.state,
+		last:        lx.last,
+		hasLast:     lx.hasLast,
+		modes:       slices.Clone(lx.modes),
+		label:       lx.label,
+		includes:    lx.includes,
+		speculative: true,
+	}
+}
@@ -0,0 +1,136 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexDigits(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(unicode.IsDigit)
+	lx.Emit(kindNumber)
+
+	return lexWords
+}
+
+func lexAlnum(lx *lexer.Lexer) lexer.StateFn {
+	lx.AcceptRunFunc(func(char rune) bool {
+		return unicode.IsLetter(char) || unicode.IsDigit(char)
+	})
+	lx.Emit(kindWord)
+
+	return lexWords
+}
+
+func TestLongest(t *testing.T) {
+	var (
+		testTbl map[string][]kindText
+		content string
+		tokens  []kindText
+	)
+
+	t.Parallel()
+
+	testTbl = map[string][]kindText{
+		"12ab cd": {{kindWord, "12ab"}, {kindSpace, " "}, {kindWord, "cd"}},
+		"12 cd":   {{kindNumber, "12"}, {kindSpace, " "}, {kindWord, "cd"}},
+	}
+
+	for content, tokens = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			assert.Equal(t, tokens, collectKinds(lexer.NewLexer(
+				strings.NewReader(content),
+				lexer.Longest(lexDigits, lexAlnum),
+			)))
+		})
+	}
+}
+
+func TestSpeculate(t *testing.T) {
+	var (
+		results []lexer.Speculation
+		lx      *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("12ab"),
+		lexer.Speculate(func(res []lexer.Speculation) int {
+			results = res
+
+			return 0
+		}, lexDigits, lexAlnum),
+	)
+
+	assert.Equal(t, []kindText{
+		{kindNumber, "12"},
+		{kindWord, "ab"},
+	}, collectKinds(lx))
+	assert.Equal(t, []lexer.Speculation{
+		{
+			Tokens: []lexer.Token{mkToken(
+				kindNumber,
+				"12",
+				lexer.Position{1, 1, 0},
+				lexer.Position{1, 3, 2},
+			)},
+			End: lexer.Position{1, 3, 2},
+		},
+		{
+			Tokens: []lexer.Token{mkToken(
+				kindWord,
+				"12ab",
+				lexer.Position{1, 1, 0},
+				lexer.Position{1, 5, 4},
+			)},
+			End: lexer.Position{1, 5, 4},
+		},
+	}, results)
+
+	lx = lexer.NewLexer(
+		strings.NewReader("12ab"),
+		lexer.Speculate(func([]lexer.Speculation) int {
+			return -1
+		}, lexDigits, lexAlnum),
+	)
+
+	assert.Equal(t, []kindText{
+		{lexer.KindError, "no candidate tokenization was chosen"},
+	}, collectKinds(lx))
+}
+
+func TestSpeculateRecording(t *testing.T) {
+	var (
+		rec   lexer.Recording
+		lx    *lexer.Lexer
+		emits []int
+		ev    lexer.RecordedEvent
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("1a b"),
+		lexer.Longest(lexDigits, lexAlnum),
+		lexer.WithRecording(&rec),
+	)
+
+	assert.Equal(t, []kindText{
+		{kindWord, "1a"},
+		{kindSpace, " "},
+		{kindWord, "b"},
+	}, collectKinds(lx))
+
+	for _, ev = range rec.Events {
+		if ev.Op == "emit" {
+			emits = append(emits, ev.Pos.Offset)
+		}
+	}
+
+	assert.Equal(t, []int{2, 3, 4}, emits)
+	assert.Equal(t, lexer.Position{1, 5, 4}, rec.Events[len(rec.Events)-1].Pos)
+}
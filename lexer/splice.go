@@ -0,0 +1,84 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrRange is returned by functions given indexes or spans that do not
+// form a valid range of the tokens or text they apply to.
+var ErrRange = errors.New("lexer: index out of range")
+
+// Splice returns a copy of tokens in which the range tokens[i:j] is
+// replaced by repl, for code-mod tools that rewrite source at the token
+// level: Splice(tokens, i, i, repl) inserts tokens before index i, and
+// Splice(tokens, i, j, nil) deletes a range. The spans of the
+// replacement tokens and of every token following them are recomputed
+// from their text, starting where the token before the range ends, so
+// that the result stays exact and can be rendered with Render.
+//
+// Recomputed positions advance as those of a Reader constructed with
+// the given options, such as WithColumnWidth or WithZeroBased, which
+// should be the options the tokens were lexed with. Malformed UTF-8
+// advances the offset by one byte per invalid byte, as a Reader does.
+//
+// Returns an error wrapping ErrRange if i and j do not form a valid
+// range of tokens.
+func Splice(tokens []Token, i, j int, repl []Token, opts ...Option) ([]Token, error) {
+	var (
+		lrd     *Reader
+		spliced []Token
+		pos     Position
+		k       int
+	)
+
+	if i < 0 || i > j || j > len(tokens) {
+		return nil, fmt.Errorf("%w: [%d:%d] of %d tokens", ErrRange, i, j, len(tokens))
+	}
+
+	lrd = NewReader(nil, opts...)
+	spliced = slices.Concat(tokens[:i], repl, tokens[j:])
+
+	switch {
+	case i > 0:
+		pos = tokens[i-1].Span.End
+	case len(tokens) > 0:
+		pos = tokens[0].Span.Start
+	default:
+		pos = lrd.startPos
+	}
+
+	for k = i; k < len(spliced); k++ {
+		spliced[k].Span.Start = pos
+		pos = lrd.advanceText(pos, spliced[k].Text)
+		spliced[k].Span.End = pos
+	}
+
+	return spliced, nil
+}
+
+// Render returns the source text of tokens, as written by WriteTokens,
+// along with an error wrapping ErrInexact if the tokens are not exact.
+func Render(tokens []Token) (string, error) {
+	var (
+		sb  strings.Builder
+		err error
+	)
+
+	_, err = WriteTokens(&sb, StreamFunc(func() (Token, bool) {
+		var tok Token
+
+		if len(tokens) == 0 {
+			return Token{}, false
+		}
+
+		tok = tokens[0]
+		tokens = tokens[1:]
+
+		return tok, true
+	}))
+
+	return sb.String(), err
+}
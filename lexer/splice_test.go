@@ -0,0 +1,144 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplice(t *testing.T) {
+	var (
+		tokens  []lexer.Token
+		spliced []lexer.Token
+		text    string
+		err     error
+	)
+
+	t.Parallel()
+
+	tokens = slices.Collect(lexer.NewLexer(
+		strings.NewReader("let x\nlet y"),
+		lexWords,
+	).Tokens())
+
+	spliced, err = lexer.Splice(tokens, 2, 3, []lexer.Token{{
+		Kind: kindWord,
+		Text: "long\nname",
+	}})
+	assert.NoError(t, err)
+
+	text, err = lexer.Render(spliced)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "let long\nname\nlet y", text)
+	assert.Equal(t, mkToken(
+		kindWord,
+		"y",
+		lexer.Position{3, 5, 18},
+		lexer.Position{3, 6, 19},
+	), spliced[len(spliced)-1])
+	assert.Equal(t, "x", tokens[2].Text)
+	assert.NoError(t, lexer.VerifyRoundTrip([]byte(text), lexer.NewLexer(
+		strings.NewReader(text),
+		lexWords,
+	)))
+
+	spliced, err = lexer.Splice(spliced, 0, 4, nil)
+	assert.NoError(t, err)
+
+	text, err = lexer.Render(spliced)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "let y", text)
+	assert.Equal(t, lexer.Position{1, 1, 0}, spliced[0].Span.Start)
+
+	spliced, err = lexer.Splice(nil, 0, 0, []lexer.Token{{Kind: kindWord, Text: "z"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, mkToken(
+		kindWord,
+		"z",
+		lexer.Position{1, 1, 0},
+		lexer.Position{1, 2, 1},
+	), spliced[0])
+}
+
+func TestSpliceMalformed(t *testing.T) {
+	var (
+		tokens  []lexer.Token
+		spliced []lexer.Token
+		text    string
+		err     error
+	)
+
+	t.Parallel()
+
+	tokens = []lexer.Token{
+		mkToken(kindWord, "x", lexer.Position{1, 1, 0}, lexer.Position{1, 2, 1}),
+	}
+
+	spliced, err = lexer.Splice(tokens, 1, 1, []lexer.Token{
+		{Kind: kindWord, Text: "a\xffb"},
+		{Kind: kindWord, Text: "界"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []lexer.Token{
+		tokens[0],
+		mkToken(kindWord, "a\xffb", lexer.Position{1, 2, 1}, lexer.Position{1, 5, 4}),
+		mkToken(kindWord, "界", lexer.Position{1, 5, 4}, lexer.Position{1, 6, 7}),
+	}, spliced)
+
+	text, err = lexer.Render(spliced)
+	assert.NoError(t, err)
+	assert.Equal(t, "xa\xffb界", text)
+}
+
+func TestSpliceOptions(t *testing.T) {
+	var (
+		spliced []lexer.Token
+		err     error
+	)
+
+	t.Parallel()
+
+	spliced, err = lexer.Splice(
+		nil,
+		0,
+		0,
+		[]lexer.Token{{Kind: kindWord, Text: "界\n界"}},
+		lexer.WithZeroBased(),
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, mkToken(
+		kindWord,
+		"界\n界",
+		lexer.Position{0, 0, 0},
+		lexer.Position{1, 2, 7},
+	), spliced[0])
+}
+
+func TestSpliceRange(t *testing.T) {
+	var (
+		tokens []lexer.Token
+		ranges [][2]int
+		r      [2]int
+		err    error
+	)
+
+	t.Parallel()
+
+	tokens = make([]lexer.Token, 2)
+	ranges = [][2]int{{-1, 0}, {2, 1}, {0, 3}, {3, 3}}
+
+	for _, r = range ranges {
+		_, err = lexer.Splice(tokens, r[0], r[1], nil)
+		assert.ErrorIs(t, err, lexer.ErrRange, r)
+	}
+
+	_, err = lexer.Splice(tokens, 2, 1, nil)
+	assert.EqualError(t, err, "lexer: index out of range: [2:1] of 2 tokens")
+}
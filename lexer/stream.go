@@ -0,0 +1,115 @@
+package lexer
+
+import (
+	"iter"
+	"sync"
+)
+
+// TokenStream is a source of tokens, such as a Lexer or the output of a
+// Pass. NextToken returns the next token, and false once the stream is
+// exhausted.
+type TokenStream interface {
+	NextToken() (Token, bool)
+}
+
+// StreamFunc adapts an ordinary function to the TokenStream interface.
+type StreamFunc func() (Token, bool)
+
+// Pass is a rewriting stage layered over a raw token stream, such as
+// collapsing significant newlines, contextualizing keywords, or pairing
+// doc comments with declarations. A Pass wraps its input in a new
+// stream and should pull from the input only as tokens are requested,
+// so that a chain of passes stays as lazy as the Lexer it starts from.
+type Pass func(TokenStream) TokenStream
+
+// NextToken calls fn.
+func (fn StreamFunc) NextToken() (Token, bool) {
+	return fn()
+}
+
+// Chain returns the stream produced by applying each pass to src in
+// order, the first pass reading from src and each later pass reading
+// from the one before it.
+func Chain(src TokenStream, passes ...Pass) TokenStream {
+	var pass Pass
+
+	for _, pass = range passes {
+		src = pass(src)
+	}
+
+	return src
+}
+
+// Tokens returns an iterator over the remaining tokens of stream,
+// calling NextToken until it reports that the stream is exhausted.
+func Tokens(stream TokenStream) iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		var (
+			tok Token
+			ok  bool
+		)
+
+		for {
+			tok, ok = stream.NextToken()
+			if !ok || !yield(tok) {
+				return
+			}
+		}
+	}
+}
+
+// Synchronized returns a TokenStream that serializes calls to the
+// NextToken method of stream with a mutex, so that several goroutines
+// can consume one stream, each token being delivered to exactly one of
+// them. The goroutines must not otherwise use stream or the Reader
+// behind it.
+func Synchronized(stream TokenStream) TokenStream {
+	var mu sync.Mutex
+
+	return StreamFunc(func() (Token, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return stream.NextToken()
+	})
+}
+
+// Filter returns a Pass that drops every token for which keep returns
+// false, such as trivia in a grammar that does not need it.
+func Filter(keep func(Token) bool) Pass {
+	return func(src TokenStream) TokenStream {
+		return StreamFunc(func() (Token, bool) {
+			var (
+				tok Token
+				ok  bool
+			)
+
+			for {
+				tok, ok = src.NextToken()
+				if !ok || keep(tok) {
+					return tok, ok
+				}
+			}
+		})
+	}
+}
+
+// Map returns a Pass that replaces every token with the result of
+// calling fn on it, such as KeywordSet.Resolve.
+func Map(fn func(Token) Token) Pass {
+	return func(src TokenStream) TokenStream {
+		return StreamFunc(func() (Token, bool) {
+			var (
+				tok Token
+				ok  bool
+			)
+
+			tok, ok = src.NextToken()
+			if !ok {
+				return tok, false
+			}
+
+			return fn(tok), true
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func sliceStream(tokens []lexer.Token) lexer.TokenStream {
+	return lexer.StreamFunc(func() (lexer.Token, bool) {
+		var tok lexer.Token
+
+		if len(tokens) == 0 {
+			return lexer.Token{}, false
+		}
+
+		tok = tokens[0]
+		tokens = tokens[1:]
+
+		return tok, true
+	})
+}
+
+func TestChain(t *testing.T) {
+	var (
+		set    *lexer.KeywordSet
+		stream lexer.TokenStream
+	)
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSet(map[string]lexer.Kind{
+		"if": kindIf,
+	})
+
+	stream = lexer.Chain(
+		lexer.NewLexer(strings.NewReader("if a\nb"), lexWords),
+		lexer.Filter(func(tok lexer.Token) bool {
+			return tok.Kind != kindSpace
+		}),
+		lexer.Map(set.Resolve),
+	)
+
+	assert.Equal(t, []lexer.Token{
+		mkToken(kindIf, "if", lexer.Position{1, 1, 0}, lexer.Position{1, 3, 2}),
+		mkToken(kindWord, "a", lexer.Position{1, 4, 3}, lexer.Position{1, 5, 4}),
+		mkToken(kindWord, "b", lexer.Position{2, 1, 5}, lexer.Position{2, 2, 6}),
+	}, slices.Collect(lexer.Tokens(stream)))
+}
+
+func TestChainEmpty(t *testing.T) {
+	var tokens []lexer.Token
+
+	t.Parallel()
+
+	tokens = []lexer.Token{
+		{Kind: kindWord, Text: "a"},
+		{Kind: kindWord, Text: "b"},
+	}
+
+	assert.Equal(
+		t,
+		tokens,
+		slices.Collect(lexer.Tokens(lexer.Chain(sliceStream(tokens)))),
+	)
+}
+
+func TestFilter(t *testing.T) {
+	var stream lexer.TokenStream
+
+	t.Parallel()
+
+	stream = lexer.Filter(func(tok lexer.Token) bool {
+		return tok.Text != "b"
+	})(sliceStream([]lexer.Token{
+		{Kind: kindWord, Text: "a"},
+		{Kind: kindWord, Text: "b"},
+		{Kind: kindWord, Text: "b"},
+		{Kind: kindWord, Text: "c"},
+		{Kind: kindWord, Text: "b"},
+	}))
+
+	assert.Equal(t, []lexer.Token{
+		{Kind: kindWord, Text: "a"},
+		{Kind: kindWord, Text: "c"},
+	}, slices.Collect(lexer.Tokens(stream)))
+}
+
+func TestMap(t *testing.T) {
+	var stream lexer.TokenStream
+
+	t.Parallel()
+
+	stream = lexer.Map(func(tok lexer.Token) lexer.Token {
+		tok.Text = strings.ToUpper(tok.Text)
+
+		return tok
+	})(sliceStream([]lexer.Token{
+		{Kind: kindWord, Text: "a"},
+		{Kind: kindWord, Text: "b"},
+	}))
+
+	assert.Equal(t, []lexer.Token{
+		{Kind: kindWord, Text: "A"},
+		{Kind: kindWord, Text: "B"},
+	}, slices.Collect(lexer.Tokens(stream)))
+}
+
+func TestSynchronized(t *testing.T) {
+	var (
+		stream lexer.TokenStream
+		counts []int
+		wg     sync.WaitGroup
+		total  int
+		i      int
+	)
+
+	t.Parallel()
+
+	stream = lexer.Synchronized(lexer.NewLexer(
+		strings.NewReader(strings.Repeat("ab ", 1000)),
+		lexWords,
+	))
+	counts = make([]int, 4)
+
+	for i = range counts {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for range lexer.Tokens(stream) {
+				counts[i]++
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i = range counts {
+		total += counts[i]
+	}
+
+	assert.Equal(t, 2000, total)
+}
@@ -0,0 +1,251 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CommentStripper configures the comment stripping of its Strip method,
+// as needed by build systems feeding sources to tools that do not
+// understand comments. It knows just enough of a language to tell
+// comments from string literals that happen to contain comment
+// delimiters.
+type CommentStripper struct {
+	// LineComments lists the prefixes of comments running to the end
+	// of the line, such as "//" or "#".
+	LineComments []string
+
+	// BlockComments lists the delimiters of comments that may span
+	// lines, such as "/*" and "*/". Block comments do not nest.
+	BlockComments []BracketPair
+
+	// Strings lists the string literals, whose contents are copied
+	// verbatim even where they look like comments.
+	Strings []StringLiteral
+
+	// KeepLines makes Strip replace a block comment spanning lines by
+	// its line terminators rather than by a space, so that every line
+	// of the output keeps the line number it had in the input.
+	KeepLines bool
+}
+
+// StringLiteral describes a kind of string literal recognized by a
+// CommentStripper.
+type StringLiteral struct {
+	// Open and Close are the delimiters of the literal, such as `"` and
+	// `"`.
+	Open, Close string
+
+	// Escape introduces a rune taken literally inside the string, such
+	// as a quote in "a\"b". A zero Escape disables escaping, as in Go
+	// raw strings.
+	Escape rune
+}
+
+// Strip copies rd to w with the comments removed. A line comment is
+// removed up to, but not including, the line terminator ending it. A
+// block comment is replaced by a single space, so that the tokens on
+// either side of it stay apart, or by its line terminators if it spans
+// lines and KeepLines is set. Everything else, string literals
+// included, is copied unchanged. At every position, string literals are
+// tried first, then block comments, then line comments, each in the
+// order listed.
+//
+// Returns an error wrapping ErrUnterminated, with the position of the
+// opening delimiter, if a block comment or string literal is not
+// closed before the end of input, the error of the underlying io.Reader
+// if it fails, or the error of w if writing fails. Part of the output
+// may have been written by then.
+func (strip CommentStripper) Strip(w io.Writer, rd io.Reader) error {
+	var (
+		lrd  *Reader
+		repl string
+		ok   bool
+		err  error
+	)
+
+	lrd = NewReader(rd)
+
+	for {
+		switch {
+		case lrd.AtEOF():
+			err = flushToken(w, lrd)
+			if err == nil && lrd.Err() != io.EOF {
+				err = lrd.Err()
+			}
+
+			return err
+		case strip.atComment(lrd):
+			err = flushToken(w, lrd)
+			if err != nil {
+				return err
+			}
+
+			repl, err = strip.acceptComment(lrd)
+			if err != nil {
+				return err
+			}
+
+			lrd.Ignore()
+
+			_, err = io.WriteString(w, repl)
+			if err != nil {
+				return err
+			}
+		default:
+			ok, err = strip.acceptString(lrd)
+			if err != nil {
+				return err
+			}
+
+			// The output is flushed line by line so that the current
+			// token, and the memory it holds, stays small.
+			if !ok && lrd.Next() == '\n' {
+				err = flushToken(w, lrd)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// atComment reports whether a comment starts at the current position,
+// and not a string literal that takes precedence.
+func (strip CommentStripper) atComment(lrd *Reader) bool {
+	var (
+		lit    StringLiteral
+		pair   BracketPair
+		prefix string
+	)
+
+	for _, lit = range strip.Strings {
+		if lrd.HasPrefix(lit.Open) {
+			return false
+		}
+	}
+
+	for _, pair = range strip.BlockComments {
+		if lrd.HasPrefix(pair.Open) {
+			return true
+		}
+	}
+
+	for _, prefix = range strip.LineComments {
+		if lrd.HasPrefix(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acceptComment consumes the comment starting at the current position
+// and returns the text replacing it.
+func (strip CommentStripper) acceptComment(lrd *Reader) (string, error) {
+	var (
+		pair BracketPair
+		pos  Position
+		ok   bool
+	)
+
+	pos = lrd.CurrentPosition()
+
+	for _, pair = range strip.BlockComments {
+		if !lrd.AcceptSeq(pair.Open) {
+			continue
+		}
+
+		_, ok = lrd.UntilSeqInclusive(pair.Close)
+		if !ok {
+			return "", unterminated(pos, "comment", pair.Open)
+		}
+
+		if !strip.KeepLines || !strings.Contains(lrd.PeekToken(), "\n") {
+			return " ", nil
+		}
+
+		return lineTerminators(lrd.PeekToken()), nil
+	}
+
+	for !lrd.AtEOF() && lrd.Peek() != '\r' && lrd.Peek() != '\n' {
+		lrd.Next()
+	}
+
+	return "", nil
+}
+
+// acceptString consumes the string literal starting at the current
+// position, if any, into the current token. Returns true if a string
+// literal was consumed.
+func (strip CommentStripper) acceptString(lrd *Reader) (bool, error) {
+	var (
+		lit StringLiteral
+		pos Position
+	)
+
+	pos = lrd.CurrentPosition()
+
+	for _, lit = range strip.Strings {
+		if !lrd.AcceptSeq(lit.Open) {
+			continue
+		}
+
+		for !lrd.AcceptSeq(lit.Close) {
+			if lrd.AtEOF() {
+				return true, unterminated(pos, "string", lit.Open)
+			}
+
+			if lrd.Next() == lit.Escape && lit.Escape != 0 {
+				lrd.Next()
+			}
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func flushToken(w io.Writer, lrd *Reader) error {
+	var (
+		text string
+		err  error
+	)
+
+	text, _ = lrd.Emit()
+	_, err = io.WriteString(w, text)
+
+	return err
+}
+
+// lineTerminators returns the line terminators of text, in order.
+func lineTerminators(text string) string {
+	var (
+		sb   strings.Builder
+		line string
+	)
+
+	for line = range strings.Lines(text) {
+		switch {
+		case strings.HasSuffix(line, "\r\n"):
+			sb.WriteString("\r\n")
+		case strings.HasSuffix(line, "\n"):
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func unterminated(pos Position, what, open string) error {
+	return fmt.Errorf(
+		"%w: %d:%d: %s %q",
+		ErrUnterminated,
+		pos.Line,
+		pos.Column,
+		what,
+		open,
+	)
+}
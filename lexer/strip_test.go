@@ -0,0 +1,97 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentStripperStrip(t *testing.T) {
+	type testData struct {
+		content   string
+		keepLines bool
+		result    string
+		err       string
+	}
+
+	var (
+		base    lexer.CommentStripper
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	base = lexer.CommentStripper{
+		LineComments:  []string{"//"},
+		BlockComments: []lexer.BracketPair{{Open: "/*", Close: "*/"}},
+		Strings: []lexer.StringLiteral{
+			{Open: `"`, Close: `"`, Escape: '\\'},
+			{Open: "`", Close: "`"},
+		},
+	}
+
+	testTbl = map[string]testData{
+		"LineComment": {
+			content: "a // b\r\nc//\n",
+			result:  "a \r\nc\n",
+		},
+		"BlockComment": {
+			content: "a/* b */c /*\n\n*/d",
+			result:  "a c  d",
+		},
+		"KeepLines": {
+			content:   "a /* b */c /*\r\n\n*/d",
+			keepLines: true,
+			result:    "a  c \r\n\nd",
+		},
+		"Strings": {
+			content: "s = \"// \\\" /*\"// c\n`/* \\`x",
+			result:  "s = \"// \\\" /*\"\n`/* \\`x",
+		},
+		"NUL": {
+			content: "a\x00b // c\x00\nd/*\x00*/\"\x00\\\x00\"\n",
+			result:  "a\x00b \nd \"\x00\\\x00\"\n",
+		},
+		"UnterminatedComment": {
+			content: "a\n  /* b",
+			result:  "a\n  ",
+			err:     "lexer: unterminated construct: 2:3: comment \"/*\"",
+		},
+		"UnterminatedString": {
+			content: "a \"b\\\"",
+			result:  "",
+			err:     "lexer: unterminated construct: 1:3: string \"\\\"\"",
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				sb    strings.Builder
+				strip lexer.CommentStripper
+				err   error
+			)
+
+			t.Parallel()
+
+			strip = base
+			strip.KeepLines = test.keepLines
+			err = strip.Strip(&sb, strings.NewReader(test.content))
+
+			assert.Equal(t, test.result, sb.String())
+
+			if test.err == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, lexer.ErrUnterminated)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package lexer
+
+// SubReader returns a new Reader over the text of tok, a token captured
+// earlier from lrd, reporting positions from the start of its span so
+// that they match the original document. It serves grammars lexing in
+// two passes, whose first, coarse pass captures a region, such as an
+// attribute value or a fenced code block, that a second pass tokenizes
+// in detail.
+//
+// The new Reader inherits the source name, column width, line and
+// column numbering, whitespace profile, logger, diagnostics handler,
+// and position tracking of lrd, including the index of
+// WithLazyPositions, from which its PositionAt resolves the offsets of
+// the original document; the given options are applied after them. The
+// rest of its state, including any Recording or limit, starts afresh. The input of lrd is left
+// untouched, so the region may be lexed again at any time, even once
+// lrd has moved past it.
+func (lrd *Reader) SubReader(tok Token, opts ...Option) *Reader {
+	return lrd.fragment(tok.Text, tok.Span.Start, opts...)
+}
+
+// SubLexer returns a new Lexer lexing the text of tok from the start
+// state, over a Reader constructed as SubReader describes, so that it
+// applies the whitespace profile of lx. Other options of the Lexer,
+// such as WithEOFToken, are not inherited and must be given again if
+// the sub-grammar needs them.
+func (lx *Lexer) SubLexer(tok Token, start StateFn, opts ...Option) *Lexer {
+	return newLexer(lx.SubReader(tok, opts...), start)
+}
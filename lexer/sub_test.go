@@ -0,0 +1,123 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func lexLines(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.Accept("\n"):
+		lx.Emit(kindSpace)
+	case lx.Until("\n") != 0:
+		lx.Emit(kindTag)
+	default:
+		return nil
+	}
+
+	return lexLines
+}
+
+func TestLexerSubLexer(t *testing.T) {
+	var (
+		lx     *lexer.Lexer
+		tokens []lexer.Token
+		want   []lexer.Token
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("x\n界 ab cd\n"),
+		lexLines,
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+	lx.SetSource("doc")
+
+	tokens = slices.Collect(lx.Tokens())
+	assert.Equal(t, "界 ab cd", tokens[2].Text)
+
+	want = []lexer.Token{
+		mkToken(kindWord, "界", lexer.Position{2, 1, 2}, lexer.Position{2, 3, 5}),
+		mkToken(kindSpace, " ", lexer.Position{2, 3, 5}, lexer.Position{2, 4, 6}),
+		mkToken(kindWord, "ab", lexer.Position{2, 4, 6}, lexer.Position{2, 6, 8}),
+		mkToken(kindSpace, " ", lexer.Position{2, 6, 8}, lexer.Position{2, 7, 9}),
+		mkToken(kindWord, "cd", lexer.Position{2, 7, 9}, lexer.Position{2, 9, 11}),
+		mkToken(lexer.KindEOF, "", lexer.Position{2, 9, 11}, lexer.Position{2, 9, 11}),
+	}
+
+	assert.Equal(
+		t,
+		want,
+		slices.Collect(lx.SubLexer(tokens[2], lexWords, lexer.WithEOFToken()).Tokens()),
+	)
+	assert.Equal(
+		t,
+		want[:len(want)-1],
+		slices.Collect(lx.SubLexer(tokens[2], lexWords).Tokens()),
+	)
+}
+
+func TestReaderSubReader(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		sub *lexer.Reader
+		tok lexer.Token
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader(""), lexer.WithZeroBased())
+	lrd.SetSource("doc")
+
+	tok = mkToken(kindTag, "ab\ncd", lexer.Position{3, 4, 20}, lexer.Position{4, 2, 25})
+	sub = lrd.SubReader(tok, lexer.WithColumnWidth(lexer.ByteWidth))
+
+	assert.Equal(t, "doc", sub.Source())
+	assert.Equal(t, tok.Span.Start, sub.CurrentPosition())
+
+	sub.Until("d")
+	assert.Equal(t, lexer.Position{4, 1, 24}, sub.CurrentPosition())
+
+	sub.Until("")
+	assert.Equal(t, tok.Span.End, sub.CurrentPosition())
+	assert.True(t, sub.AtEOF())
+}
+
+func TestReaderSubReaderLazy(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		sub *lexer.Reader
+		tok lexer.Token
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("x = \"a\nbc\"\n"),
+		lexer.WithLazyPositions(),
+		lexer.WithZeroBased(),
+	)
+	lrd.Until("\"")
+	lrd.Ignore()
+	lrd.Next()
+	lrd.Until("\"")
+	lrd.Next()
+	tok.Kind = kindTag
+	tok.Text, tok.Span.Start = lrd.Emit()
+
+	assert.Equal(t, "\"a\nbc\"", tok.Text)
+
+	sub = lrd.SubReader(tok)
+	sub.Until("c")
+
+	assert.Equal(t, lexer.Position{0, 0, 8}, sub.CurrentPosition())
+	assert.Equal(t, lexer.Position{0, 5, 5}, resolved(sub.PositionAt(5)))
+	assert.Equal(t, lexer.Position{1, 1, 8}, resolved(sub.PositionAt(8)))
+	assert.Equal(t, resolved(lrd.PositionAt(8)), resolved(sub.PositionAt(8)))
+	assert.Equal(t, lexer.Position{-1, -1, -1}, resolved(sub.PositionAt(3)))
+}
@@ -0,0 +1,200 @@
+package lexer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions bounds the number of candidates named by ReportSuggest.
+const maxSuggestions = 3
+
+type suggestion struct {
+	text     string
+	distance int
+}
+
+// Levenshtein returns the edit distance between a and b: the least
+// number of runes inserted, deleted, or substituted to turn one into
+// the other.
+func Levenshtein(a, b string) int {
+	var (
+		ra, rb     []rune
+		prev, curr []int
+		i, j       int
+		cost       int
+	)
+
+	ra, rb = []rune(a), []rune(b)
+	prev = make([]int, len(rb)+1)
+	curr = make([]int, len(rb)+1)
+
+	for j = range prev {
+		prev[j] = j
+	}
+
+	for i = 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j = 1; j <= len(rb); j++ {
+			cost = 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// Suggest returns the candidates close enough to word to be what was
+// meant, such as "else" for "esle", nearest first and alphabetically
+// among candidates at the same distance. A candidate is close enough
+// if its Levenshtein distance from word is at most a third of the
+// length of word, rounded up. Candidates equal to word are left out,
+// so the result is empty for a word that is not misspelled.
+func Suggest(word string, candidates []string) []string {
+	var (
+		found     []suggestion
+		texts     []string
+		candidate string
+		limit     int
+		distance  int
+		sug       suggestion
+	)
+
+	limit = (utf8.RuneCountInString(word) + 2) / 3
+
+	for _, candidate = range candidates {
+		distance = Levenshtein(word, candidate)
+		if distance != 0 && distance <= limit {
+			found = append(found, suggestion{candidate, distance})
+		}
+	}
+
+	slices.SortFunc(found, func(a, b suggestion) int {
+		return cmp.Or(
+			cmp.Compare(a.distance, b.distance),
+			strings.Compare(a.text, b.text),
+		)
+	})
+
+	for _, sug = range found {
+		if len(texts) == 0 || texts[len(texts)-1] != sug.text {
+			texts = append(texts, sug.text)
+		}
+	}
+
+	return texts
+}
+
+// Keywords returns the unconditional and contextual keywords of the
+// set, sorted, in lower case if the set is case-insensitive.
+func (set *KeywordSet) Keywords() []string {
+	var (
+		texts []string
+		text  string
+		ok    bool
+	)
+
+	for text = range set.kinds {
+		texts = append(texts, text)
+	}
+
+	for text = range set.contextual {
+		_, ok = set.kinds[text]
+		if !ok {
+			texts = append(texts, text)
+		}
+	}
+
+	slices.Sort(texts)
+
+	return texts
+}
+
+// Suggest returns the keywords of the set that text may be a
+// misspelling of, as the function Suggest does. A case-insensitive set
+// compares text in lower case.
+func (set *KeywordSet) Suggest(text string) []string {
+	if set.fold {
+		text = strings.ToLower(text)
+	}
+
+	return Suggest(text, set.Keywords())
+}
+
+// DidYouMean returns diag with its message followed by a question
+// naming suggestions, as in `unknown keyword "esle"; did you mean
+// "else"?`, and a Fix replacing the span of diag with each of them.
+// It returns diag unchanged if suggestions is empty.
+func DidYouMean(diag Diagnostic, suggestions ...string) Diagnostic {
+	var (
+		quoted []string
+		text   string
+	)
+
+	if len(suggestions) == 0 {
+		return diag
+	}
+
+	diag.Fixes = slices.Clone(diag.Fixes)
+
+	for _, text = range suggestions {
+		quoted = append(quoted, strconv.Quote(text))
+		diag.Fixes = append(diag.Fixes, Fix{
+			Title: fmt.Sprintf("replace with %q", text),
+			Edits: []Edit{{Span: diag.Span, NewText: text}},
+		})
+	}
+
+	diag.Message += "; did you mean " + OrList(quoted) + "?"
+
+	return diag
+}
+
+// ReportSuggest reports a diagnostic as Report does for the runes
+// accumulated since the last call to Ignore or Emit, such as an
+// unknown directive name, suggesting the closest of candidates they
+// may be a misspelling of, as DidYouMean does. At most three
+// candidates are suggested.
+func (lx *Lexer) ReportSuggest(
+	sev Severity,
+	candidates []string,
+	format string,
+	args ...any,
+) {
+	var suggestions []string
+
+	suggestions = Suggest(lx.PeekToken(), candidates)
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	lx.report(DidYouMean(lx.diagnostic(sev, format, args...), suggestions...))
+}
+
+// OrList joins items as an English list ending in "or", such as
+// `a, b, or c`, or returns "nothing" if there are no items. It formats
+// the alternatives of the "expected ..." messages of this package and
+// of the parsers built on it, so that they read alike.
+func OrList(items []string) string {
+	switch len(items) {
+	case 0:
+		return "nothing"
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " or " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") +
+			", or " + items[len(items)-1]
+	}
+}
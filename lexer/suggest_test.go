@@ -0,0 +1,140 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, lexer.Levenshtein("", ""))
+	assert.Equal(t, 3, lexer.Levenshtein("", "abc"))
+	assert.Equal(t, 3, lexer.Levenshtein("kitten", "sitting"))
+	assert.Equal(t, 2, lexer.Levenshtein("esle", "else"))
+	assert.Equal(t, 1, lexer.Levenshtein("naïve", "naive"))
+}
+
+func TestSuggest(t *testing.T) {
+	type testData struct {
+		word string
+		want []string
+	}
+
+	var (
+		keywords []string
+		testTbl  map[string]testData
+		name     string
+		test     testData
+	)
+
+	t.Parallel()
+
+	keywords = []string{"else", "elif", "for", "func", "if", "while"}
+	testTbl = map[string]testData{
+		"transposed":  {"esle", []string{"else"}},
+		"ranked":      {"elsf", []string{"elif", "else"}},
+		"substituted": {"fur", []string{"for"}},
+		"missing":     {"funcc", []string{"func"}},
+		"exact":       {"while", nil},
+		"distant":     {"return", nil},
+		"short":       {"i", []string{"if"}},
+	}
+
+	for name, test = range testTbl {
+		assert.Equal(t, test.want, lexer.Suggest(test.word, keywords), name)
+	}
+}
+
+func TestKeywordSetSuggest(t *testing.T) {
+	var set *lexer.KeywordSet
+
+	t.Parallel()
+
+	set = lexer.NewKeywordSetFold(map[string]lexer.Kind{
+		"SELECT": kindIf,
+		"FROM":   kindElse,
+	})
+	set.AddContextual("where", kindWord, lexer.InMode("query"))
+
+	assert.Equal(t, []string{"from", "select", "where"}, set.Keywords())
+	assert.Equal(t, []string{"select"}, set.Suggest("SELEC"))
+	assert.Equal(t, []string{"where"}, set.Suggest("Whre"))
+	assert.Nil(t, set.Suggest("From"))
+}
+
+func TestDidYouMean(t *testing.T) {
+	var diag lexer.Diagnostic
+
+	t.Parallel()
+
+	diag = lexer.Diagnostic{Message: "unknown keyword"}
+
+	assert.Equal(t, diag, lexer.DidYouMean(diag))
+	assert.Equal(
+		t,
+		`unknown keyword; did you mean "a", "b", or "c"?`,
+		lexer.DidYouMean(diag, "a", "b", "c").Message,
+	)
+	assert.Len(t, lexer.DidYouMean(diag, "a", "b").Fixes, 2)
+}
+
+func TestOrList(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "nothing", lexer.OrList(nil))
+	assert.Equal(t, "a", lexer.OrList([]string{"a"}))
+	assert.Equal(t, "a or b", lexer.OrList([]string{"a", "b"}))
+	assert.Equal(t, "a, b, or c", lexer.OrList([]string{"a", "b", "c"}))
+}
+
+func TestLexerReportSuggest(t *testing.T) {
+	var (
+		src   string
+		diags []lexer.Diagnostic
+		lx    *lexer.Lexer
+		fixed []byte
+		err   error
+	)
+
+	t.Parallel()
+
+	src = "#inclde x"
+	lx = lexer.NewLexer(
+		strings.NewReader(src),
+		func(lx *lexer.Lexer) lexer.StateFn {
+			lx.Accept("#")
+			lx.Ignore()
+			lx.AcceptRunFunc(unicode.IsLetter)
+			lx.ReportSuggest(
+				lexer.SeverityError,
+				[]string{"define", "if", "include"},
+				"unknown directive %q",
+				lx.PeekToken(),
+			)
+			lx.Emit(kindWord)
+
+			return lexWords
+		},
+		lexer.WithDiagnostics(func(diag lexer.Diagnostic) {
+			diags = append(diags, diag)
+		}),
+	)
+
+	collectKinds(lx)
+
+	assert.Len(t, diags, 1)
+	assert.Equal(
+		t,
+		`1:2: unknown directive "inclde"; did you mean "include"?`,
+		diags[0].String(),
+	)
+
+	fixed, err = diags[0].Fixes[0].Apply([]byte(src))
+	assert.NoError(t, err)
+	assert.Equal(t, "#include x", string(fixed))
+}
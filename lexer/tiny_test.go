@@ -0,0 +1,34 @@
+//go:build tinygo || lexertiny
+
+package lexer_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// lexWordsName is the name of lexWords in a Profile.
+	lexWordsName = "state"
+
+	// persistentDirCache reports whether a DirCache stores entries.
+	persistentDirCache = false
+)
+
+func TestDirCacheTiny(t *testing.T) {
+	var (
+		cache *lexer.DirCache
+		ok    bool
+	)
+
+	t.Parallel()
+
+	cache = lexer.NewDirCache(filepath.Join(t.TempDir(), "cache"))
+	cache.Store("key", []lexer.Token{{Kind: kindWord, Text: "a"}})
+
+	_, ok = cache.Load("key")
+	assert.False(t, ok)
+}
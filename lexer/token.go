@@ -0,0 +1,130 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kind classifies a Token. Grammars define their own kinds as constants
+// starting at KindUser, leaving the lower values for the kinds reserved
+// by this package.
+type Kind int
+
+const (
+	// KindEOF marks the end of the token stream.
+	KindEOF Kind = iota
+
+	// KindError marks a lexical error. The Text of an error token holds
+	// the error message rather than source text.
+	KindError
+
+	// KindUser is the first Kind available to user-defined grammars.
+	KindUser
+)
+
+// Span represents a region of the input stream. Start is the position
+// of the first rune in the region and End is the position immediately
+// following the last rune. The byte offsets of Start and End delimit
+// the region exactly as it appears in the original source.
+type Span struct {
+	// Start is the position where the region begins.
+	Start Position
+
+	// End is the position immediately after the region ends.
+	End Position
+}
+
+// Token is a classified piece of input produced by a Lexer. It carries
+// its Kind, the text it was built from, and the Span it occupies in the
+// input stream.
+type Token struct {
+	// Kind is the classification of the token.
+	Kind Kind
+
+	// Text is the source text of the token, or the error message when
+	// Kind is KindError.
+	Text string
+
+	// Span is the region of the input stream the token occupies.
+	Span Span
+
+	// Trivia reports whether the token was emitted with EmitTrivia,
+	// marking it as insignificant to the grammar, like whitespace or
+	// comments.
+	Trivia bool
+
+	// Doc holds the doc comments preceding the token, in source order,
+	// as attached by the DocComments pass. It is nil for tokens without
+	// doc comments and for streams that do not run the pass.
+	Doc []Token
+
+	// Origin is the span of the token in the input whose expansion by
+	// the Expand pass produced this token, such as a macro invocation.
+	// Span then refers to wherever the replacement tokens were lexed
+	// from. It is nil for tokens read directly from the input.
+	Origin *Span
+
+	// Source is the name of the file an Include state lexed the token
+	// from, which Span then refers to. It is empty for tokens lexed from
+	// the input of the Lexer itself, whose name Reader.Source returns.
+	Source string
+}
+
+// Raw returns the bytes of src covered by the span, where src is the
+// complete input the span was produced from. Unlike Token.Text, which
+// may be rewritten by the grammar, the result is the source exactly as
+// written, byte order marks and malformed UTF-8 included. Returns nil
+// if the span does not lie within src.
+func (span Span) Raw(src []byte) []byte {
+	if span.Start.Offset < 0 ||
+		span.Start.Offset > span.End.Offset ||
+		span.End.Offset > len(src) {
+		return nil
+	}
+
+	return src[span.Start.Offset:span.End.Offset]
+}
+
+// SubSpan returns the span of the bytes of the text of tok from start
+// to end, as for a diagnostic about an escape sequence within a string
+// literal. The positions are computed from the start of the span of
+// tok, advancing as those of a Reader constructed with the given
+// options, so they are exact only if its text is the source as written
+// and the options are those it was lexed with.
+//
+// Returns an error wrapping ErrRange if start and end do not form a
+// valid range of the text of tok.
+func (tok Token) SubSpan(start, end int, opts ...Option) (Span, error) {
+	var (
+		lrd *Reader
+		pos Position
+	)
+
+	if start < 0 || start > end || end > len(tok.Text) {
+		return Span{}, fmt.Errorf(
+			"%w: [%d:%d] of %d bytes",
+			ErrRange,
+			start,
+			end,
+			len(tok.Text),
+		)
+	}
+
+	lrd = NewReader(nil, opts...)
+	pos = lrd.advanceText(tok.Span.Start, tok.Text[:start])
+
+	return Span{Start: pos, End: lrd.advanceText(pos, tok.Text[start:end])}, nil
+}
+
+// String returns a readable name for the reserved kinds and a generic
+// numbered form for user-defined kinds.
+func (kind Kind) String() string {
+	switch kind {
+	case KindEOF:
+		return "EOF"
+	case KindError:
+		return "Error"
+	default:
+		return "Kind(" + strconv.Itoa(int(kind)) + ")"
+	}
+}
@@ -0,0 +1,163 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "EOF", lexer.KindEOF.String())
+	assert.Equal(t, "Error", lexer.KindError.String())
+	assert.Equal(t, "Kind(2)", lexer.KindUser.String())
+}
+
+func TestSpanRaw(t *testing.T) {
+	var (
+		src    []byte
+		tokens []string
+		tok    lexer.Token
+	)
+
+	t.Parallel()
+
+	src = []byte("\u00e9f cd\nab\xff")
+
+	for tok = range lexer.NewLexer(
+		strings.NewReader(string(src)),
+		lexWords,
+	).Tokens() {
+		tokens = append(tokens, string(tok.Span.Raw(src)))
+	}
+
+	assert.Equal(t, []string{
+		"\u00e9f",
+		" ",
+		"cd",
+		"\n",
+		"ab",
+		"\xff",
+	}, tokens)
+}
+
+func TestSpanRawOutOfRange(t *testing.T) {
+	type testData struct {
+		start, end int
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Negative": {-1, 2},
+		"Reversed": {2, 1},
+		"PastEnd":  {1, 4},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			assert.Nil(t, lexer.Span{
+				Start: lexer.Position{Offset: test.start},
+				End:   lexer.Position{Offset: test.end},
+			}.Raw([]byte("abc")))
+		})
+	}
+}
+
+func TestTokenSubSpan(t *testing.T) {
+	var (
+		tok  lexer.Token
+		span lexer.Span
+		err  error
+	)
+
+	t.Parallel()
+
+	tok = lexer.Token{
+		Text: "\"é\nab\xff\"",
+		Span: lexer.Span{
+			Start: lexer.Position{Line: 2, Column: 5, Offset: 10},
+		},
+	}
+
+	span, err = tok.SubSpan(1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{Line: 2, Column: 6, Offset: 11},
+		End:   lexer.Position{Line: 3, Column: 1, Offset: 14},
+	}, span)
+
+	span, err = tok.SubSpan(5, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{Line: 3, Column: 2, Offset: 15},
+		End:   lexer.Position{Line: 3, Column: 4, Offset: 17},
+	}, span)
+
+	span, err = tok.SubSpan(1, 4, lexer.WithZeroBased(), lexer.WithColumnWidth(lexer.ByteWidth))
+	assert.NoError(t, err)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{Line: 2, Column: 6, Offset: 11},
+		End:   lexer.Position{Line: 3, Column: 0, Offset: 14},
+	}, span)
+
+	_, err = tok.SubSpan(4, 9)
+	assert.ErrorIs(t, err, lexer.ErrRange)
+	assert.EqualError(t, err, "lexer: index out of range: [4:9] of 8 bytes")
+
+	_, err = tok.SubSpan(2, 1)
+	assert.ErrorIs(t, err, lexer.ErrRange)
+
+	_, err = tok.SubSpan(-1, 1)
+	assert.ErrorIs(t, err, lexer.ErrRange)
+}
+
+// TestInvalidRanges checks that the functions taking indices report the
+// ranges lying outside of their input instead of panicking.
+func TestInvalidRanges(t *testing.T) {
+	var (
+		tok    lexer.Token
+		tokens []lexer.Token
+		lrd    *lexer.Reader
+		ranges [][2]int
+		r      [2]int
+		err    error
+	)
+
+	t.Parallel()
+
+	tok = mkToken(kindWord, "word", lexer.Position{1, 1, 0}, lexer.Position{1, 5, 4})
+	tokens = []lexer.Token{tok}
+	ranges = [][2]int{{-1, 0}, {0, -1}, {1, 0}, {0, 5}, {5, 5}, {-5, -1}}
+
+	for _, r = range ranges {
+		assert.NotPanics(t, func() {
+			_, err = tok.SubSpan(r[0], r[1])
+		}, r)
+		assert.ErrorIs(t, err, lexer.ErrRange, r)
+
+		assert.NotPanics(t, func() {
+			_, err = lexer.Splice(tokens, r[0], r[1], tokens)
+		}, r)
+		assert.ErrorIs(t, err, lexer.ErrRange, r)
+	}
+
+	lrd = lexer.NewReader(strings.NewReader("word"), lexer.WithLazyPositions())
+
+	assert.NotPanics(t, func() {
+		_, err = lrd.ReadN(-1)
+		lrd.Backup(-1)
+		lrd.PositionAt(-1)
+		lrd.PositionAt(5)
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,62 @@
+package lexer
+
+import "slices"
+
+// TokenReader reads tokens from a TokenStream on behalf of a parser,
+// with one token of lookahead and the ability to push tokens back into
+// the stream. A new TokenReader is constructed with NewTokenReader.
+type TokenReader struct {
+	src     TokenStream
+	pending []Token
+}
+
+// NewTokenReader returns a TokenReader reading from src.
+func NewTokenReader(src TokenStream) *TokenReader {
+	return &TokenReader{src: src}
+}
+
+// NextToken returns the next token, which is the first token inserted
+// with Insert and not yet read, or else the next token of the
+// underlying stream. The boolean is false once both are exhausted.
+// TokenReader therefore satisfies TokenStream, so passes can be layered
+// over it.
+func (trd *TokenReader) NextToken() (Token, bool) {
+	var tok Token
+
+	if len(trd.pending) == 0 {
+		return trd.src.NextToken()
+	}
+
+	tok = trd.pending[0]
+	trd.pending = trd.pending[1:]
+
+	return tok, true
+}
+
+// Peek returns the token NextToken would return, without consuming it.
+func (trd *TokenReader) Peek() (Token, bool) {
+	var (
+		tok Token
+		ok  bool
+	)
+
+	if len(trd.pending) == 0 {
+		tok, ok = trd.src.NextToken()
+		if !ok {
+			return Token{}, false
+		}
+
+		trd.pending = append(trd.pending, tok)
+	}
+
+	return trd.pending[0], true
+}
+
+// Insert pushes tokens back into the stream at the current point, so
+// that the next calls to NextToken return them in order before any
+// token that was to be read next. It lets a parser inject synthetic
+// tokens, such as a virtual closing brace during error recovery or the
+// expansion of a macro, or return tokens it read too far.
+func (trd *TokenReader) Insert(tokens ...Token) {
+	trd.pending = slices.Insert(trd.pending, 0, tokens...)
+}
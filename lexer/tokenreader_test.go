@@ -0,0 +1,50 @@
+package lexer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenReader(t *testing.T) {
+	var (
+		a, b, c, x, y lexer.Token
+		trd           *lexer.TokenReader
+		tok           lexer.Token
+		ok            bool
+	)
+
+	t.Parallel()
+
+	a = lexer.Token{Kind: kindWord, Text: "a"}
+	b = lexer.Token{Kind: kindWord, Text: "b"}
+	c = lexer.Token{Kind: kindWord, Text: "c"}
+	x = lexer.Token{Kind: kindPunct, Text: "x"}
+	y = lexer.Token{Kind: kindPunct, Text: "y"}
+	trd = lexer.NewTokenReader(sliceStream([]lexer.Token{a, b, c}))
+
+	tok, ok = trd.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, a, tok)
+
+	tok, ok = trd.NextToken()
+	assert.True(t, ok)
+	assert.Equal(t, a, tok)
+
+	trd.Peek()
+	trd.Insert(x, y)
+	trd.Insert(a)
+
+	assert.Equal(t, []lexer.Token{a, x, y, b, c}, slices.Collect(lexer.Tokens(trd)))
+
+	_, ok = trd.Peek()
+	assert.False(t, ok)
+
+	trd.Insert(c)
+
+	tok, ok = trd.NextToken()
+	assert.True(t, ok)
+	assert.Equal(t, c, tok)
+}
@@ -0,0 +1,24 @@
+package lexer
+
+import "context"
+
+// WithPprofLabels makes a Lexer run every state function under the pprof
+// labels of ctx extended with "lexer.state", the name of the state
+// function, and "lexer.mode", the current mode, so that CPU profiles
+// attribute lexing time to grammar states rather than to anonymous
+// closures. The labels can be filtered with pprof's -tagfocus option.
+//
+// While an execution trace is being collected with runtime/trace, every
+// state function also runs in a trace region named after it, and every
+// token it emits is logged under the "lexer.token" category with its
+// kind as the message.
+//
+// The labels are set on the goroutine calling NextToken for the
+// duration of each state and restored afterwards. The Reader itself is
+// unaffected. The option has no effect in builds with the tinygo or
+// lexertiny tag.
+func WithPprofLabels(ctx context.Context) Option {
+	return func(lrd *Reader) {
+		lrd.labels = ctx
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !tinygo && !lexertiny
+
+package lexer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+)
+
+// stateNames caches the runtime names of state functions by entry
+// point.
+var stateNames sync.Map
+
+func (lx *Lexer) runLabeled(state StateFn) StateFn {
+	var (
+		next   StateFn
+		name   string
+		labels pprof.LabelSet
+	)
+
+	name = stateName(state)
+	labels = pprof.Labels("lexer.state", name, "lexer.mode", lx.Mode())
+
+	pprof.Do(lx.labels, labels, func(ctx context.Context) {
+		var (
+			region *trace.Region
+			queued int
+			tok    Token
+		)
+
+		if !trace.IsEnabled() {
+			next = lx.runState(state)
+
+			return
+		}
+
+		queued = len(lx.queue)
+		region = trace.StartRegion(ctx, name)
+		next = lx.runState(state)
+		region.End()
+
+		for _, tok = range lx.queue[queued:] {
+			trace.Log(ctx, "lexer.token", tok.Kind.String())
+		}
+	})
+
+	return next
+}
+
+// stateName returns the name of state as reported by the runtime, such
+// as "example.com/pkg.lexNumber" or "example.com/pkg.NewGrammar.func1".
+func stateName(state StateFn) string {
+	var (
+		pc    uintptr
+		name  string
+		fn    *runtime.Func
+		value any
+		ok    bool
+	)
+
+	pc = reflect.ValueOf(state).Pointer()
+
+	value, ok = stateNames.Load(pc)
+	if ok {
+		return value.(string)
+	}
+
+	name = fmt.Sprintf("%#x", pc)
+
+	fn = runtime.FuncForPC(pc)
+	if fn != nil {
+		name = fn.Name()
+	}
+
+	stateNames.Store(pc, name)
+
+	return name
+}
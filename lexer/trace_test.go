@@ -0,0 +1,63 @@
+//go:build !tinygo && !lexertiny
+
+package lexer_test
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	var profile *lexer.Profile
+
+	t.Parallel()
+
+	profile = lexer.NewProfile()
+
+	assert.Equal(
+		t,
+		slices.Collect(lexer.NewLexer(
+			strings.NewReader("ab cd"),
+			lexWords,
+		).Tokens()),
+		slices.Collect(lexer.NewLexer(
+			strings.NewReader("ab cd"),
+			lexWords,
+			lexer.WithPprofLabels(context.Background()),
+			lexer.WithProfile(profile),
+		).Tokens()),
+	)
+
+	assert.Equal(t, 4, profile.Entries()[0].Calls)
+}
+
+func TestWithPprofLabelsTrace(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		tok lexer.Token
+	)
+
+	if !assert.NoError(t, trace.Start(&buf)) {
+		return
+	}
+
+	for tok = range lexer.NewLexer(
+		strings.NewReader("ab cd"),
+		lexWords,
+		lexer.WithPprofLabels(context.Background()),
+	).Tokens() {
+		_ = tok
+	}
+
+	trace.Stop()
+
+	assert.Contains(t, buf.String(), "lexer_test.lexWords")
+	assert.Contains(t, buf.String(), "lexer.token")
+}
@@ -0,0 +1,15 @@
+//go:build tinygo || lexertiny
+
+package lexer
+
+// runLabeled runs state without labels, as profiling is not supported.
+func (lx *Lexer) runLabeled(state StateFn) StateFn {
+	return lx.runState(state)
+}
+
+// stateName returns a placeholder, as the names of functions are not
+// available without reflection. Label states with Lexer.ProfileLabel
+// to tell them apart in a Profile.
+func stateName(StateFn) string {
+	return "state"
+}
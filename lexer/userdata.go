@@ -0,0 +1,59 @@
+package lexer
+
+// Key identifies a value of type T that helpers shared across grammars
+// store on a Reader, such as the stack of open delimiters of a bracket
+// matcher, instead of in globals or in structs wrapping every Lexer.
+// Keys are compared by identity, so two Keys never refer to the same
+// value even if their names are equal, and a package can keep its Keys
+// unexported to keep its state private. A new Key is constructed with
+// NewKey, usually once in a package-level variable.
+//
+// Values are stored per Reader: a Lexer stores them on its embedded
+// Reader. A Clone starts with the values of the Reader it was cloned
+// from, and setting a value on either afterward does not affect the
+// other, so the candidates of Speculate see the values of the Lexer,
+// whose values become those of the committed candidate. The values
+// themselves are not copied, so a helper keeping a slice or map that a
+// clone may change should store a modified copy with Set rather than
+// modify it in place.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a new Key named name, the name serving only to
+// describe the Key in debugging output.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// Get returns the value stored under key on lrd. The boolean is false,
+// and the value is the zero value of T, if none is stored.
+func (key *Key[T]) Get(lrd *Reader) (T, bool) {
+	var (
+		value T
+		ok    bool
+	)
+
+	value, ok = lrd.values[key].(T)
+
+	return value, ok
+}
+
+// Set stores value under key on lrd, replacing any value stored before.
+func (key *Key[T]) Set(lrd *Reader, value T) {
+	if lrd.values == nil {
+		lrd.values = make(map[any]any)
+	}
+
+	lrd.values[key] = value
+}
+
+// Delete removes the value stored under key on lrd, if any.
+func (key *Key[T]) Delete(lrd *Reader) {
+	delete(lrd.values, key)
+}
+
+// String returns the name of key.
+func (key *Key[T]) String() string {
+	return key.name
+}
@@ -0,0 +1,144 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	depthKey = lexer.NewKey[int]("depth")
+	openKey  = lexer.NewKey[[]rune]("open")
+	closers  = map[rune]rune{'(': ')', '[': ']'}
+)
+
+// lexNested emits the brackets of its input as kindPunct tokens,
+// tracking the open ones with openKey and reporting unbalanced ones.
+func lexNested(lx *lexer.Lexer) lexer.StateFn {
+	var (
+		open  []rune
+		char  rune
+		depth int
+	)
+
+	open, _ = openKey.Get(lx.Reader)
+
+	char = lx.Next()
+
+	switch char {
+	case lexer.EOF:
+		if len(open) != 0 {
+			return lx.Errorf("unclosed %q", open[len(open)-1])
+		}
+
+		return nil
+	case '(', '[':
+		openKey.Set(lx.Reader, append(open[:len(open):len(open)], char))
+
+		depth, _ = depthKey.Get(lx.Reader)
+		depthKey.Set(lx.Reader, max(depth, len(open)+1))
+	default:
+		if len(open) == 0 || closers[open[len(open)-1]] != char {
+			return lx.Errorf("unexpected %q", char)
+		}
+
+		openKey.Set(lx.Reader, open[:len(open)-1])
+	}
+
+	lx.Emit(kindPunct)
+
+	return lexNested
+}
+
+func TestKey(t *testing.T) {
+	type testData struct {
+		want  []kindText
+		depth int
+	}
+
+	var (
+		testTbl map[string]testData
+		src     string
+		test    testData
+		lx      *lexer.Lexer
+		depth   int
+		ok      bool
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"([])[]": {
+			want: []kindText{
+				{kindPunct, "("},
+				{kindPunct, "["},
+				{kindPunct, "]"},
+				{kindPunct, ")"},
+				{kindPunct, "["},
+				{kindPunct, "]"},
+			},
+			depth: 2,
+		},
+		"([)": {
+			want: []kindText{
+				{kindPunct, "("},
+				{kindPunct, "["},
+				{lexer.KindError, `unexpected ')'`},
+			},
+			depth: 2,
+		},
+		"(": {
+			want: []kindText{
+				{kindPunct, "("},
+				{lexer.KindError, `unclosed '('`},
+			},
+			depth: 1,
+		},
+	}
+
+	for src, test = range testTbl {
+		lx = lexer.NewLexer(strings.NewReader(src), lexNested)
+
+		assert.Equal(t, test.want, collectKinds(lx), src)
+
+		depth, ok = depthKey.Get(lx.Reader)
+		assert.True(t, ok, src)
+		assert.Equal(t, test.depth, depth, src)
+	}
+
+	lx = lexer.NewLexer(strings.NewReader(""), lexNested)
+	depth, ok = depthKey.Get(lx.Reader)
+	assert.False(t, ok)
+	assert.Zero(t, depth)
+	assert.Equal(t, "depth", depthKey.String())
+}
+
+func TestKeyClone(t *testing.T) {
+	var (
+		lrd   *lexer.Reader
+		clone *lexer.Reader
+		depth int
+		ok    bool
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(strings.NewReader("x"))
+	depthKey.Set(lrd, 1)
+
+	clone = lrd.Clone()
+	depth, _ = depthKey.Get(clone)
+	assert.Equal(t, 1, depth)
+
+	depthKey.Set(clone, 2)
+	depthKey.Delete(lrd)
+
+	_, ok = depthKey.Get(lrd)
+	assert.False(t, ok)
+
+	depth, ok = depthKey.Get(clone)
+	assert.True(t, ok)
+	assert.Equal(t, 2, depth)
+}
@@ -0,0 +1,293 @@
+package lexer
+
+import "unicode"
+
+// tabStop is the multiple of columns a tab advances indentation to for
+// WithSignificantIndentation.
+const tabStop = 8
+
+type whitespacePolicy int
+
+const (
+	skipWhitespace whitespacePolicy = iota
+	whitespaceTrivia
+	significantNewlines
+	significantIndentation
+)
+
+// whitespace is the whitespace profile selected by one of the
+// whitespace options, with the kinds of the tokens it emits.
+type whitespace struct {
+	policy                  whitespacePolicy
+	space                   Kind
+	newline, indent, dedent Kind
+}
+
+// layoutState is the state of the line layout tracked by a Lexer with
+// significant newlines or indentation.
+type layoutState struct {
+	// widths is the stack of the indentation widths of the open blocks,
+	// above the implicit outermost block of width 0.
+	widths []int
+
+	// indent is the indentation of the current line, until the first
+	// token of the line decides the layout tokens it implies.
+	indent Token
+
+	midLine, pending, tokens bool
+}
+
+// WithSkipWhitespace makes a Lexer skip the whitespace between tokens,
+// line breaks included, before it runs a state, so that states never
+// see whitespace at the start of a token. It is the profile of most
+// free-form languages, such as C or JSON.
+//
+// Like the other whitespace profiles, it applies between tokens only:
+// when no runes are accumulated since the last call to Ignore or Emit,
+// and while no mode is pushed, so that a state lexing the inside of a
+// string literal in a mode of its own sees its whitespace. States must
+// leave the whitespace between tokens to the profile. A later
+// whitespace option replaces an earlier one.
+func WithSkipWhitespace() Option {
+	return withWhitespace(whitespace{policy: skipWhitespace})
+}
+
+// WithWhitespaceTrivia makes a Lexer emit every run of whitespace
+// between tokens, line breaks included, as a trivia token of the given
+// kind, for tools that reproduce the input exactly, such as formatters,
+// while parsers skip trivia as usual. It applies as WithSkipWhitespace
+// describes.
+func WithWhitespaceTrivia(space Kind) Option {
+	return withWhitespace(whitespace{policy: whitespaceTrivia, space: space})
+}
+
+// WithSignificantNewlines makes a Lexer skip the whitespace between
+// tokens other than line feeds, and emit a token of kind newline for
+// the line feed ending a line that holds tokens other than trivia, for
+// languages ending statements at line ends, such as shells. The
+// carriage return of a CRLF line end is skipped. Lines that are blank or
+// hold only trivia, such as comments, emit no token. A last line
+// holding tokens but no line feed is closed by an empty newline token
+// at the end of input, so WithFinalNewline is not needed. It applies
+// as WithSkipWhitespace describes.
+func WithSignificantNewlines(newline Kind) Option {
+	return withWhitespace(whitespace{
+		policy:  significantNewlines,
+		newline: newline,
+	})
+}
+
+// WithSignificantIndentation makes a Lexer emit line ends as
+// WithSignificantNewlines does, and the blocks delimited by the
+// indentation of lines as tokens, in the style of Python: a token of
+// kind indent, holding the indentation, before the first token of a
+// line indented deeper than the enclosing block, and an empty token of
+// kind dedent before the first token of a line for every block its
+// indentation closes. The blocks still open at the end of input are
+// closed after the last newline token.
+//
+// Indentation is measured in columns, a tab advancing to the next
+// multiple of 8. Indentation returning to a width no enclosing block
+// has is reported with a KindError token, and lexing continues as if a
+// block of that width were open. Lines that are blank or hold only
+// trivia do not affect the layout. Lines continued inside brackets are
+// not recognized, so grammars allowing them drop the layout tokens
+// between the pairs found by MatchBrackets.
+func WithSignificantIndentation(newline, indent, dedent Kind) Option {
+	return withWhitespace(whitespace{
+		policy:  significantIndentation,
+		newline: newline,
+		indent:  indent,
+		dedent:  dedent,
+	})
+}
+
+func withWhitespace(ws whitespace) Option {
+	return func(lrd *Reader) {
+		lrd.whitespace = &ws
+	}
+}
+
+// skipBetween applies the whitespace profile of the Lexer if it is
+// between tokens.
+func (lx *Lexer) skipBetween() {
+	if lx.whitespace == nil || lx.start != lx.current || len(lx.modes) != 0 {
+		return
+	}
+
+	switch lx.whitespace.policy {
+	case skipWhitespace:
+		lx.AcceptRunFunc(unicode.IsSpace)
+		lx.Ignore()
+	case whitespaceTrivia:
+		if lx.AcceptRunFunc(unicode.IsSpace) != 0 {
+			lx.EmitTrivia(lx.whitespace.space)
+		}
+	default:
+		lx.skipLayout()
+	}
+}
+
+// skipLayout skips the blanks and line breaks up to the next token,
+// emitting a newline token for the first line break after a token and
+// recording the indentation of the line of the next token.
+func (lx *Lexer) skipLayout() {
+	var (
+		lay        *layoutState
+		text       string
+		start, end Position
+	)
+
+	lay = &lx.layout
+
+	for {
+		lx.AcceptRunFunc(isBlank)
+
+		if !lx.HasPrefix("\n") {
+			break
+		}
+
+		lx.Ignore()
+		lx.Accept("\n")
+
+		lay.midLine = false
+		lay.pending = false
+
+		if lay.tokens {
+			lx.Emit(lx.whitespace.newline)
+			lay.tokens = false
+
+			return
+		}
+
+		lx.Ignore()
+	}
+
+	if lay.midLine || lx.AtEOF() {
+		lx.Ignore()
+
+		return
+	}
+
+	lay.midLine = true
+
+	end = lx.CurrentPosition()
+	text, start = lx.Reader.Emit()
+
+	if lx.whitespace.policy == significantIndentation {
+		lay.pending = true
+		lay.indent = Token{
+			Kind: lx.whitespace.indent,
+			Text: text,
+			Span: Span{Start: start, End: end},
+		}
+	}
+}
+
+// layoutBefore updates the layout for tok, a token that is not trivia
+// emitted while the Lexer has significant newlines or indentation,
+// pushing the indent, dedent, or error tokens the indentation of its
+// line implies before it.
+func (lx *Lexer) layoutBefore(tok Token) {
+	var (
+		lay   *layoutState
+		width int
+		pos   Position
+	)
+
+	lay = &lx.layout
+
+	if tok.Kind != lx.whitespace.newline {
+		lay.tokens = true
+	}
+
+	if !lay.pending {
+		return
+	}
+
+	lay.pending = false
+	width = indentWidth(lay.indent.Text)
+	pos = lay.indent.Span.End
+
+	if width > lay.top() {
+		lay.widths = append(lay.widths, width)
+		lx.push(lay.indent)
+
+		return
+	}
+
+	for width < lay.top() {
+		lay.widths = lay.widths[:len(lay.widths)-1]
+		lx.push(Token{Kind: lx.whitespace.dedent, Span: Span{Start: pos, End: pos}})
+	}
+
+	if width > lay.top() {
+		lay.widths = append(lay.widths, width)
+		lx.push(Token{
+			Kind: KindError,
+			Text: "unindent does not match any outer indentation level",
+			Span: lay.indent.Span,
+		})
+	}
+}
+
+// closeLayout closes the last line and the open blocks at the end of
+// input, reporting whether it emitted a newline token.
+func (lx *Lexer) closeLayout() bool {
+	var (
+		lay    *layoutState
+		pos    Position
+		closed bool
+	)
+
+	if lx.whitespace == nil || lx.whitespace.policy < significantNewlines {
+		return false
+	}
+
+	lay = &lx.layout
+	pos = lx.CurrentPosition()
+
+	if lay.tokens {
+		lx.push(Token{Kind: lx.whitespace.newline, Span: Span{Start: pos, End: pos}})
+		lay.tokens = false
+		closed = true
+	}
+
+	for ; len(lay.widths) != 0; lay.widths = lay.widths[:len(lay.widths)-1] {
+		lx.push(Token{Kind: lx.whitespace.dedent, Span: Span{Start: pos, End: pos}})
+	}
+
+	return closed
+}
+
+// top returns the indentation width of the innermost open block.
+func (lay *layoutState) top() int {
+	if len(lay.widths) == 0 {
+		return 0
+	}
+
+	return lay.widths[len(lay.widths)-1]
+}
+
+// indentWidth returns the width of the indentation text in columns.
+func indentWidth(text string) int {
+	var (
+		width int
+		char  rune
+	)
+
+	for _, char = range text {
+		if char == '\t' {
+			width += tabStop - width%tabStop
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
+
+// isBlank reports whether char is whitespace other than a line feed.
+func isBlank(char rune) bool {
+	return char != '\n' && unicode.IsSpace(char)
+}
@@ -0,0 +1,245 @@
+package lexer_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindNewline lexer.Kind = kindSkipped + 1 + iota
+	kindIndent
+	kindDedent
+)
+
+// lexBare lexes words, comments, and quoted strings, leaving the
+// whitespace between them to a whitespace profile. A string is lexed in
+// the "string" mode, so that the profile leaves its spaces alone.
+func lexBare(lx *lexer.Lexer) lexer.StateFn {
+	switch {
+	case lx.Mode() == "string":
+		lx.Until(`"`)
+		lx.Accept(`"`)
+		lx.PopMode()
+		lx.Emit(kindWord)
+	case lx.AtEOF():
+		return nil
+	case lx.AcceptRunFunc(unicode.IsLetter) != 0:
+		lx.Emit(kindWord)
+	case lx.Accept("#"):
+		lx.Until("\n")
+		lx.EmitTrivia(kindComment)
+	case lx.Accept(`"`):
+		lx.PushMode("string")
+	default:
+		lx.Next()
+
+		return lx.Errorf("unexpected %q", lx.PeekToken())
+	}
+
+	return lexBare
+}
+
+func TestLexerWhitespaceProfiles(t *testing.T) {
+	type testData struct {
+		opt  lexer.Option
+		want []kindText
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		src     string
+	)
+
+	t.Parallel()
+
+	src = "a  b # c\n\n  \"d  e\"\r\n"
+
+	testTbl = map[string]testData{
+		"skip": {
+			opt: lexer.WithSkipWhitespace(),
+			want: []kindText{
+				{kindWord, "a"},
+				{kindWord, "b"},
+				{kindComment, "# c"},
+				{kindWord, `"d  e"`},
+			},
+		},
+		"trivia": {
+			opt: lexer.WithWhitespaceTrivia(kindSpace),
+			want: []kindText{
+				{kindWord, "a"},
+				{kindSpace, "  "},
+				{kindWord, "b"},
+				{kindSpace, " "},
+				{kindComment, "# c"},
+				{kindSpace, "\n\n  "},
+				{kindWord, `"d  e"`},
+				{kindSpace, "\r\n"},
+			},
+		},
+		"newlines": {
+			opt: lexer.WithSignificantNewlines(kindNewline),
+			want: []kindText{
+				{kindWord, "a"},
+				{kindWord, "b"},
+				{kindComment, "# c"},
+				{kindNewline, "\n"},
+				{kindWord, `"d  e"`},
+				{kindNewline, "\n"},
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		assert.Equal(
+			t,
+			test.want,
+			collectKinds(lexer.NewLexer(strings.NewReader(src), lexBare, test.opt)),
+			name,
+		)
+	}
+}
+
+func TestLexerWhitespaceInherited(t *testing.T) {
+	type testData struct {
+		lx   *lexer.Lexer
+		want []kindText
+	}
+
+	var (
+		fsys    fstest.MapFS
+		testTbl map[string]testData
+		name    string
+		test    testData
+		lx      *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	fsys = fstest.MapFS{
+		"main.txt": {Data: []byte("a b")},
+		"inc.txt":  {Data: []byte("x y")},
+	}
+
+	lx, _ = lexer.NewLexerFS(
+		fsys,
+		"main.txt",
+		lexer.Include(fsys, "inc.txt", lexBare, lexBare),
+		lexer.WithWhitespaceTrivia(kindSpace),
+	)
+
+	testTbl = map[string]testData{
+		"Include": {
+			lx: lx,
+			want: []kindText{
+				{kindWord, "x"},
+				{kindSpace, " "},
+				{kindWord, "y"},
+				{kindWord, "a"},
+				{kindSpace, " "},
+				{kindWord, "b"},
+			},
+		},
+		"Embed": {
+			lx: lexer.NewLexer(
+				strings.NewReader("x y;a b"),
+				lexer.Embed(lexBare, ";", func(lx *lexer.Lexer) lexer.StateFn {
+					lx.Next()
+					lx.Ignore()
+
+					return lexBare
+				}),
+				lexer.WithWhitespaceTrivia(kindSpace),
+			),
+			want: []kindText{
+				{kindWord, "x"},
+				{kindSpace, " "},
+				{kindWord, "y"},
+				{kindWord, "a"},
+				{kindSpace, " "},
+				{kindWord, "b"},
+			},
+		},
+		"SubLexer": {
+			lx: lexer.NewLexer(
+				strings.NewReader("x y"),
+				lexBare,
+				lexer.WithWhitespaceTrivia(kindSpace),
+			).SubLexer(
+				mkToken(kindWord, "x y", lexer.Position{1, 1, 0}, lexer.Position{1, 4, 3}),
+				lexBare,
+			),
+			want: []kindText{
+				{kindWord, "x"},
+				{kindSpace, " "},
+				{kindWord, "y"},
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		assert.Equal(t, test.want, collectKinds(test.lx), name)
+	}
+}
+
+func TestLexerSignificantIndentation(t *testing.T) {
+	var (
+		lx   *lexer.Lexer
+		want []lexer.Token
+	)
+
+	t.Parallel()
+
+	lx = lexer.NewLexer(
+		strings.NewReader("a\n  b\n\n    # c\n    c\n\td\n e\n  f"),
+		lexBare,
+		lexer.WithSignificantIndentation(kindNewline, kindIndent, kindDedent),
+		lexer.WithFinalNewline(kindNewline),
+	)
+
+	want = []lexer.Token{
+		mkToken(kindWord, "a", lexer.Position{1, 1, 0}, lexer.Position{1, 2, 1}),
+		mkToken(kindNewline, "\n", lexer.Position{1, 2, 1}, lexer.Position{2, 1, 2}),
+		mkToken(kindIndent, "  ", lexer.Position{2, 1, 2}, lexer.Position{2, 3, 4}),
+		mkToken(kindWord, "b", lexer.Position{2, 3, 4}, lexer.Position{2, 4, 5}),
+		mkToken(kindNewline, "\n", lexer.Position{2, 4, 5}, lexer.Position{3, 1, 6}),
+		{
+			Kind:   kindComment,
+			Text:   "# c",
+			Span:   lexer.Span{Start: lexer.Position{4, 5, 11}, End: lexer.Position{4, 8, 14}},
+			Trivia: true,
+		},
+		mkToken(kindIndent, "    ", lexer.Position{5, 1, 15}, lexer.Position{5, 5, 19}),
+		mkToken(kindWord, "c", lexer.Position{5, 5, 19}, lexer.Position{5, 6, 20}),
+		mkToken(kindNewline, "\n", lexer.Position{5, 6, 20}, lexer.Position{6, 1, 21}),
+		mkToken(kindIndent, "\t", lexer.Position{6, 1, 21}, lexer.Position{6, 2, 22}),
+		mkToken(kindWord, "d", lexer.Position{6, 2, 22}, lexer.Position{6, 3, 23}),
+		mkToken(kindNewline, "\n", lexer.Position{6, 3, 23}, lexer.Position{7, 1, 24}),
+		mkToken(kindDedent, "", lexer.Position{7, 2, 25}, lexer.Position{7, 2, 25}),
+		mkToken(kindDedent, "", lexer.Position{7, 2, 25}, lexer.Position{7, 2, 25}),
+		mkToken(kindDedent, "", lexer.Position{7, 2, 25}, lexer.Position{7, 2, 25}),
+		mkToken(
+			lexer.KindError,
+			"unindent does not match any outer indentation level",
+			lexer.Position{7, 1, 24},
+			lexer.Position{7, 2, 25},
+		),
+		mkToken(kindWord, "e", lexer.Position{7, 2, 25}, lexer.Position{7, 3, 26}),
+		mkToken(kindNewline, "\n", lexer.Position{7, 3, 26}, lexer.Position{8, 1, 27}),
+		mkToken(kindIndent, "  ", lexer.Position{8, 1, 27}, lexer.Position{8, 3, 29}),
+		mkToken(kindWord, "f", lexer.Position{8, 3, 29}, lexer.Position{8, 4, 30}),
+		mkToken(kindNewline, "", lexer.Position{8, 4, 30}, lexer.Position{8, 4, 30}),
+		mkToken(kindDedent, "", lexer.Position{8, 4, 30}, lexer.Position{8, 4, 30}),
+		mkToken(kindDedent, "", lexer.Position{8, 4, 30}, lexer.Position{8, 4, 30}),
+	}
+
+	assert.Equal(t, want, slices.Collect(lx.Tokens()))
+}
@@ -0,0 +1,101 @@
+package lexer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	var (
+		testTbl map[rune]int
+		char    rune
+		width   int
+	)
+
+	t.Parallel()
+
+	testTbl = map[rune]int{
+		'a':      1,
+		'\t':     1,
+		'\u00e9': 1,
+		// U+0301 COMBINING ACUTE ACCENT
+		'\u0301': 0,
+		// U+200B ZERO WIDTH SPACE
+		'\u200b': 0,
+		// U+1161 HANGUL JUNGSEONG A
+		'\u1161': 0,
+		'\u4e2d': 2,
+		'\uac00': 2,
+		// U+FF21 FULLWIDTH LATIN CAPITAL LETTER A
+		'\uff21': 2,
+		// U+FF61 HALFWIDTH IDEOGRAPHIC FULL STOP
+		'\uff61':     1,
+		'\U0001f600': 2,
+		'\U00020000': 2,
+	}
+
+	for char, width = range testTbl {
+		assert.Equal(t, width, lexer.DisplayWidth(char), "%U", char)
+	}
+}
+
+func TestWithColumnWidth(t *testing.T) {
+	var (
+		lrd *lexer.Reader
+		pos lexer.Position
+	)
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("\u4e2d\u6587e\u0301x\n\u4e2d"),
+		lexer.WithColumnWidth(lexer.DisplayWidth),
+	)
+
+	lrd.AcceptSeq("\u4e2d\u6587e\u0301")
+	_, pos = lrd.Emit()
+
+	assert.Equal(t, lexer.Position{1, 1, 0}, pos)
+	assert.Equal(t, lexer.Position{1, 6, 9}, lrd.CurrentPosition())
+
+	lrd.AcceptSeq("x\n\u4e2d")
+
+	assert.Equal(t, lexer.Position{2, 3, 14}, lrd.CurrentPosition())
+}
+
+func TestUTF16Width(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, lexer.UTF16Width('a'))
+	assert.Equal(t, 1, lexer.UTF16Width('\u4e2d'))
+	assert.Equal(t, 2, lexer.UTF16Width('\U0001f600'))
+}
+
+func TestByteWidth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, lexer.ByteWidth('a'))
+	assert.Equal(t, 2, lexer.ByteWidth('\u00e9'))
+	assert.Equal(t, 3, lexer.ByteWidth('\u4e2d'))
+	assert.Equal(t, 4, lexer.ByteWidth('\U0001f600'))
+}
+
+func TestWithColumnWidthMalformed(t *testing.T) {
+	var lrd *lexer.Reader
+
+	t.Parallel()
+
+	lrd = lexer.NewReader(
+		strings.NewReader("\xff\U0001f600a"),
+		lexer.WithColumnWidth(lexer.ByteWidth),
+	)
+
+	lrd.AcceptRunFunc(func(rune) bool {
+		return true
+	})
+
+	assert.Equal(t, lexer.Position{1, 7, 6}, lrd.CurrentPosition())
+}
@@ -0,0 +1,123 @@
+package lexgen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Conflict reports a rule of a Grammar that can never produce a token.
+type Conflict struct {
+	// Rule is the rule that can never match.
+	Rule Rule
+
+	// By lists the rules that win instead of Rule on every text Rule
+	// matches, in the order they were given to Compile. It is empty when
+	// Rule is unreachable for another reason.
+	By []Rule
+
+	// Message explains why Rule can never match.
+	Message string
+}
+
+// Conflicts returns the rules of the grammar that can never produce a
+// token, in the order they were given to Compile. A rule can never
+// match if every text it matches is also matched by an earlier rule of
+// the same mode, as with a keyword listed after the identifier rule
+// that covers it, if its pattern matches no text at all, or if it
+// belongs to a mode that no rule pushes.
+//
+// The analysis is exact for the automata built by Compile: a rule that
+// is not reported wins on at least one input.
+func (grammar *Grammar) Conflicts() []Conflict {
+	var (
+		conflicts []Conflict
+		pushed    map[string]bool
+		rule      Rule
+		i         int
+	)
+
+	pushed = map[string]bool{"": true}
+
+	for _, rule = range grammar.rules {
+		if rule.Push != "" {
+			pushed[rule.Push] = true
+		}
+	}
+
+	for i, rule = range grammar.rules {
+		switch {
+		case !pushed[rule.Mode]:
+			conflicts = append(conflicts, Conflict{
+				Rule: rule,
+				Message: fmt.Sprintf(
+					"rule %q can never match: no rule pushes mode %q",
+					ruleName(rule),
+					rule.Mode,
+				),
+			})
+		default:
+			conflicts = grammar.appendShadowed(conflicts, i)
+		}
+	}
+
+	return conflicts
+}
+
+// String returns the message of the conflict.
+func (conflict Conflict) String() string {
+	return conflict.Message
+}
+
+func (grammar *Grammar) appendShadowed(conflicts []Conflict, index int) []Conflict {
+	var (
+		rule  Rule
+		state dfaState
+		by    []int
+		names []string
+		i     int
+	)
+
+	rule = grammar.rules[index]
+
+	for _, state = range grammar.dfas[rule.Mode].states {
+		if state.rule == index {
+			return conflicts
+		}
+
+		if slices.Contains(state.matches, index) && !slices.Contains(by, state.rule) {
+			by = append(by, state.rule)
+		}
+	}
+
+	if len(by) == 0 {
+		return append(conflicts, Conflict{
+			Rule: rule,
+			Message: fmt.Sprintf(
+				"rule %q can never match: its pattern matches no text",
+				ruleName(rule),
+			),
+		})
+	}
+
+	slices.Sort(by)
+
+	conflicts = append(conflicts, Conflict{Rule: rule})
+
+	for _, i = range by {
+		conflicts[len(conflicts)-1].By = append(
+			conflicts[len(conflicts)-1].By,
+			grammar.rules[i],
+		)
+
+		names = append(names, fmt.Sprintf("%q", ruleName(grammar.rules[i])))
+	}
+
+	conflicts[len(conflicts)-1].Message = fmt.Sprintf(
+		"rule %q can never match: every text it matches is matched by earlier rule %s",
+		ruleName(rule),
+		strings.Join(names, ", "),
+	)
+
+	return conflicts
+}
@@ -0,0 +1,107 @@
+package lexgen_test
+
+import (
+	"testing"
+
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrammarConflicts(t *testing.T) {
+	type testData struct {
+		rules []lexgen.Rule
+		msgs  []string
+		by    [][]string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"None": {
+			rules: rules,
+		},
+		"Shadowed": {
+			rules: []lexgen.Rule{
+				{Name: "ident", Pattern: `[a-z]+`},
+				{Name: "if", Pattern: `if`},
+			},
+			msgs: []string{
+				`rule "if" can never match: every text it matches is matched by earlier rule "ident"`,
+			},
+			by: [][]string{{"ident"}},
+		},
+		"Subsumed": {
+			rules: []lexgen.Rule{
+				{Name: "ab", Pattern: `ab`},
+				{Name: "cd", Pattern: `cd+`},
+				{Name: "either", Pattern: `ab|cd`},
+				{Name: "longer", Pattern: `cdd`},
+			},
+			msgs: []string{
+				`rule "either" can never match: every text it matches is matched by earlier rule "ab", "cd"`,
+				`rule "longer" can never match: every text it matches is matched by earlier rule "cd"`,
+			},
+			by: [][]string{{"ab", "cd"}, {"cd"}},
+		},
+		"Longest": {
+			rules: []lexgen.Rule{
+				{Name: "a", Pattern: `a`},
+				{Name: "as", Pattern: `a+`},
+			},
+		},
+		"NoText": {
+			rules: []lexgen.Rule{
+				{Name: "never", Pattern: `[^\x00-\x{10FFFF}]`},
+			},
+			msgs: []string{
+				`rule "never" can never match: its pattern matches no text`,
+			},
+			by: [][]string{nil},
+		},
+		"UnreachableMode": {
+			rules: []lexgen.Rule{
+				{Name: "word", Pattern: `\w+`},
+				{Name: "orphan", Pattern: `x`, Mode: "lost"},
+			},
+			msgs: []string{
+				`rule "orphan" can never match: no rule pushes mode "lost"`,
+			},
+			by: [][]string{nil},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				conflicts []lexgen.Conflict
+				msgs      []string
+				by        [][]string
+				names     []string
+				conflict  lexgen.Conflict
+				rule      lexgen.Rule
+			)
+
+			conflicts = lexgen.MustCompile(test.rules).Conflicts()
+
+			for _, conflict = range conflicts {
+				names = nil
+
+				for _, rule = range conflict.By {
+					names = append(names, rule.Name)
+				}
+
+				msgs = append(msgs, conflict.String())
+				by = append(by, names)
+			}
+
+			assert.Equal(t, test.msgs, msgs)
+			assert.Equal(t, test.by, by)
+		})
+	}
+}
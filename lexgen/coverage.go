@@ -0,0 +1,295 @@
+package lexgen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Coverage records which rules of a Grammar, and which branches of
+// their patterns, match while lexing a corpus, so that grammar authors
+// can find dead rules and constructs the corpus never exercises, much
+// as code coverage does for tests. A Coverage may be shared by any
+// number of concurrently running lexers.
+type Coverage struct {
+	grammar  *Grammar
+	branches [][]branch
+	mu       sync.Mutex
+	hits     []int
+}
+
+// RuleCoverage reports how often a rule matched.
+type RuleCoverage struct {
+	// Rule is the rule of the grammar.
+	Rule Rule
+
+	// Hits is the number of tokens the rule matched, including the
+	// text it skipped.
+	Hits int
+
+	// Branches reports the top-level alternatives of the pattern of
+	// Rule, in the order they are written. It is empty when the pattern
+	// has a single alternative.
+	Branches []BranchCoverage
+}
+
+// BranchCoverage reports how often a top-level alternative of the
+// pattern of a rule matched.
+type BranchCoverage struct {
+	// Pattern is the text of the alternative, as written in the rule.
+	Pattern string
+
+	// Hits is the number of texts matched by the rule that the
+	// alternative matches in full. A text matched by several
+	// alternatives counts for all of them.
+	Hits int
+}
+
+// leadingFlags matches the flags set at the start of a pattern.
+var leadingFlags = regexp.MustCompile(`^\(\?[a-zA-Z]+\)`)
+
+type branch struct {
+	pattern string
+	re      *regexp.Regexp
+	hits    int
+}
+
+// NewCoverage returns a Coverage for the grammar with no matches
+// recorded.
+func (grammar *Grammar) NewCoverage() *Coverage {
+	var (
+		cov  *Coverage
+		rule Rule
+		i    int
+	)
+
+	cov = &Coverage{
+		grammar:  grammar,
+		branches: make([][]branch, len(grammar.rules)),
+		hits:     make([]int, len(grammar.rules)),
+	}
+
+	for i, rule = range grammar.rules {
+		cov.branches[i] = splitBranches(rule.Pattern)
+	}
+
+	return cov
+}
+
+// NewLexer returns a lexer.Lexer that tokenizes rd with the grammar,
+// like Grammar.NewLexer, recording every match.
+func (cov *Coverage) NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, cov.Start(), opts...)
+}
+
+// Start returns the state function that lexes with the grammar, like
+// Grammar.Start, recording every match.
+func (cov *Coverage) Start() lexer.StateFn {
+	return cov.grammar.start(cov.record)
+}
+
+// Report returns the coverage of every rule of the grammar, in the
+// order the rules were given to Compile.
+func (cov *Coverage) Report() []RuleCoverage {
+	var (
+		report []RuleCoverage
+		rule   Rule
+		br     branch
+		i      int
+	)
+
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+
+	report = make([]RuleCoverage, len(cov.grammar.rules))
+
+	for i, rule = range cov.grammar.rules {
+		report[i] = RuleCoverage{Rule: rule, Hits: cov.hits[i]}
+
+		for _, br = range cov.branches[i] {
+			report[i].Branches = append(report[i].Branches, BranchCoverage{
+				Pattern: br.pattern,
+				Hits:    br.hits,
+			})
+		}
+	}
+
+	return report
+}
+
+// Unmatched returns a message for every rule that never matched and
+// every branch of a matching rule that never did, in the order the
+// rules were given to Compile.
+func (cov *Coverage) Unmatched() []string {
+	var (
+		msgs []string
+		rc   RuleCoverage
+		bc   BranchCoverage
+	)
+
+	for _, rc = range cov.Report() {
+		if rc.Hits == 0 {
+			msgs = append(msgs, fmt.Sprintf(
+				"rule %q never matched",
+				ruleName(rc.Rule),
+			))
+
+			continue
+		}
+
+		for _, bc = range rc.Branches {
+			if bc.Hits == 0 {
+				msgs = append(msgs, fmt.Sprintf(
+					"rule %q: branch %q never matched",
+					ruleName(rc.Rule),
+					bc.Pattern,
+				))
+			}
+		}
+	}
+
+	return msgs
+}
+
+// WriteReport writes a summary of the coverage to w, followed by the
+// number of matches of every rule and branch, one per line.
+func (cov *Coverage) WriteReport(w io.Writer) error {
+	var (
+		sb       strings.Builder
+		report   []RuleCoverage
+		rc       RuleCoverage
+		bc       BranchCoverage
+		rules    int
+		branches int
+		total    int
+	)
+
+	report = cov.Report()
+
+	for _, rc = range report {
+		if rc.Hits != 0 {
+			rules++
+		}
+
+		for _, bc = range rc.Branches {
+			total++
+
+			if bc.Hits != 0 {
+				branches++
+			}
+		}
+	}
+
+	fmt.Fprintf(
+		&sb,
+		"rules matched: %d/%d, branches matched: %d/%d\n",
+		rules,
+		len(report),
+		branches,
+		total,
+	)
+
+	for _, rc = range report {
+		fmt.Fprintf(&sb, "%8d  %s\n", rc.Hits, ruleName(rc.Rule))
+
+		for _, bc = range rc.Branches {
+			fmt.Fprintf(&sb, "%8d    | %s\n", bc.Hits, bc.Pattern)
+		}
+	}
+
+	return writeString(w, sb.String())
+}
+
+func (cov *Coverage) record(rule int, text string) {
+	var i int
+
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+
+	cov.hits[rule]++
+
+	for i = range cov.branches[rule] {
+		if cov.branches[rule][i].re.MatchString(text) {
+			cov.branches[rule][i].hits++
+		}
+	}
+}
+
+// splitBranches returns the top-level alternatives of pattern, each
+// compiled to match a whole text, or nil if the pattern has a single
+// alternative. Flags set at the start of the pattern, such as (?i),
+// apply to every alternative.
+func splitBranches(pattern string) []branch {
+	var (
+		flags    string
+		branches []branch
+		re       *regexp.Regexp
+		alt      string
+		err      error
+	)
+
+	flags = leadingFlags.FindString(pattern)
+
+	for _, alt = range splitAlternatives(pattern[len(flags):]) {
+		re, err = regexp.Compile(`^(?:` + flags + alt + `)$`)
+		if err != nil {
+			return nil
+		}
+
+		branches = append(branches, branch{pattern: alt, re: re})
+	}
+
+	if len(branches) < 2 {
+		return nil
+	}
+
+	return branches
+}
+
+// splitAlternatives splits pattern at the | operators outside of groups
+// and character classes.
+func splitAlternatives(pattern string) []string {
+	var (
+		alts  []string
+		depth int
+		class bool
+		start int
+		i     int
+	)
+
+	for i = 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++
+		case class && strings.HasPrefix(pattern[i:], "[:"):
+			i += strings.Index(pattern[i:], ":]") + 1
+		case class:
+			class = pattern[i] != ']'
+		case pattern[i] == '[':
+			class = true
+
+			// A ] right after the opening bracket, possibly negated,
+			// is a literal member of the class.
+			if strings.HasPrefix(pattern[i+1:], "^") {
+				i++
+			}
+
+			if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case pattern[i] == '(':
+			depth++
+		case pattern[i] == ')':
+			depth--
+		case pattern[i] == '|' && depth == 0:
+			alts = append(alts, pattern[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(alts, pattern[start:])
+}
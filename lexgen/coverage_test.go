@@ -0,0 +1,76 @@
+package lexgen_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverage(t *testing.T) {
+	var (
+		cov    *lexgen.Coverage
+		report []lexgen.RuleCoverage
+		sb     strings.Builder
+		wg     sync.WaitGroup
+		corpus []string
+		src    string
+	)
+
+	t.Parallel()
+
+	cov = lexgen.MustCompile(rules).NewCoverage()
+	corpus = []string{`x = 1 + 2.5`, `IF y <= 3`, `z == "a"`}
+
+	for _, src = range corpus {
+		wg.Add(1)
+
+		go func(src string) {
+			var tok lexer.Token
+
+			defer wg.Done()
+
+			for tok = range cov.NewLexer(strings.NewReader(src)).Tokens() {
+				assert.NotEqual(t, lexer.KindError, tok.Kind)
+			}
+		}(src)
+	}
+
+	wg.Wait()
+
+	report = cov.Report()
+	assert.Len(t, report, len(rules))
+	assert.Equal(t, 9, report[0].Hits)
+	assert.Equal(t, 4, report[5].Hits)
+	assert.Equal(t, []lexgen.BranchCoverage{
+		{Pattern: `[-+*/=<>]=?`, Hits: 4},
+		{Pattern: `==`, Hits: 1},
+	}, report[5].Branches)
+
+	assert.Equal(t, []string{
+		`rule "comment" never matched`,
+		`rule "text": branch "\\\\." never matched`,
+	}, cov.Unmatched())
+
+	assert.NoError(t, cov.WriteReport(&sb))
+	assert.Equal(t, strings.Join([]string{
+		"rules matched: 8/9, branches matched: 3/4",
+		"       9  space",
+		"       0  comment",
+		"       1  if",
+		"       3  ident",
+		"       3  number",
+		"       4  op",
+		"       4    | [-+*/=<>]=?",
+		"       1    | ==",
+		"       1  open",
+		"       1  close",
+		"       1  text",
+		"       1    | [^\"\\\\]+",
+		"       0    | \\\\.",
+		"",
+	}, "\n"), sb.String())
+}
@@ -0,0 +1,386 @@
+package lexgen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// dfa is the deterministic automaton of a single mode. State 0 is the
+// start state.
+type dfa struct {
+	states []dfaState
+}
+
+type dfaState struct {
+	// rule is the index of the rule accepted in this state, or -1.
+	rule int
+
+	// matches lists the rules whose pattern matches in this state,
+	// including those that lose to rule.
+	matches []int
+
+	// edges are the transitions out of the state, sorted and disjoint.
+	edges []edge
+}
+
+type edge struct {
+	lo, hi rune
+	next   int
+}
+
+// thread is a position in the compiled program of a rule.
+type thread struct {
+	rule, pc int
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+type dfaBuilder struct {
+	progs  []*syntax.Prog
+	auto   *dfa
+	index  map[string]int
+	queued [][]thread
+}
+
+// buildDFA runs the subset construction over the programs of the rules
+// for which active returns true.
+func buildDFA(progs []*syntax.Prog, active func(int) bool) *dfa {
+	var (
+		builder *dfaBuilder
+		start   []thread
+		seen    map[thread]bool
+		i       int
+	)
+
+	builder = &dfaBuilder{
+		progs: progs,
+		auto:  &dfa{},
+		index: make(map[string]int),
+	}
+
+	seen = make(map[thread]bool)
+
+	for i = range progs {
+		if active(i) {
+			start = builder.add(start, seen, thread{i, progs[i].Start})
+		}
+	}
+
+	builder.state(start)
+
+	for i = 0; i < len(builder.queued); i++ {
+		builder.auto.states[i].edges = builder.edges(builder.queued[i])
+	}
+
+	return builder.auto
+}
+
+// match consumes the longest prefix of the input accepted by the
+// automaton and returns the index of the accepted rule, or -1 with
+// nothing consumed if no prefix is accepted.
+func (auto *dfa) match(lrd *lexer.Reader) int {
+	var (
+		state    int
+		rule     int
+		count    int
+		accepted int
+		char     rune
+	)
+
+	rule = -1
+
+	for !lrd.AtEOF() {
+		char = lrd.Next()
+		count++
+
+		state = auto.states[state].step(char)
+		if state < 0 {
+			break
+		}
+
+		if auto.states[state].rule >= 0 {
+			rule = auto.states[state].rule
+			accepted = count
+		}
+	}
+
+	lrd.Backup(count - accepted)
+
+	return rule
+}
+
+func (state dfaState) step(char rune) int {
+	var (
+		i  int
+		ok bool
+	)
+
+	i, ok = slices.BinarySearchFunc(state.edges, char, compareEdge)
+	if !ok {
+		return -1
+	}
+
+	return state.edges[i].next
+}
+
+func compareEdge(e edge, char rune) int {
+	switch {
+	case e.hi < char:
+		return -1
+	case e.lo > char:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// state returns the index of the automaton state for threads, adding it
+// to the construction queue if it is new.
+func (builder *dfaBuilder) state(threads []thread) int {
+	var (
+		key     string
+		index   int
+		ok      bool
+		rule    int
+		matches []int
+		t       thread
+	)
+
+	slices.SortFunc(threads, func(a, b thread) int {
+		if a.rule != b.rule {
+			return a.rule - b.rule
+		}
+
+		return a.pc - b.pc
+	})
+
+	key = threadsKey(threads)
+
+	index, ok = builder.index[key]
+	if ok {
+		return index
+	}
+
+	rule = -1
+
+	for _, t = range threads {
+		if builder.inst(t).Op != syntax.InstMatch {
+			continue
+		}
+
+		if rule < 0 {
+			rule = t.rule
+		}
+
+		matches = append(matches, t.rule)
+	}
+
+	index = len(builder.auto.states)
+	builder.index[key] = index
+	builder.auto.states = append(builder.auto.states, dfaState{
+		rule:    rule,
+		matches: matches,
+	})
+	builder.queued = append(builder.queued, threads)
+
+	return index
+}
+
+// edges computes the transitions out of the state made of threads by
+// splitting the runes they consume into disjoint intervals.
+func (builder *dfaBuilder) edges(threads []thread) []edge {
+	var (
+		points []rune
+		edges  []edge
+		next   []thread
+		seen   map[thread]bool
+		target int
+		r      runeRange
+		t      thread
+		i      int
+	)
+
+	for _, t = range threads {
+		for _, r = range instRanges(builder.inst(t)) {
+			points = append(points, r.lo, r.hi+1)
+		}
+	}
+
+	slices.Sort(points)
+	points = slices.Compact(points)
+
+	for i = 0; i+1 < len(points); i++ {
+		next = nil
+		seen = make(map[thread]bool)
+
+		for _, t = range threads {
+			if rangesContain(instRanges(builder.inst(t)), points[i]) {
+				next = builder.add(next, seen, thread{
+					rule: t.rule,
+					pc:   int(builder.inst(t).Out),
+				})
+			}
+		}
+
+		if len(next) == 0 {
+			continue
+		}
+
+		target = builder.state(next)
+
+		if len(edges) > 0 &&
+			edges[len(edges)-1].next == target &&
+			edges[len(edges)-1].hi+1 == points[i] {
+			edges[len(edges)-1].hi = points[i+1] - 1
+
+			continue
+		}
+
+		edges = append(edges, edge{
+			lo:   points[i],
+			hi:   points[i+1] - 1,
+			next: target,
+		})
+	}
+
+	return edges
+}
+
+// add appends t and every thread reachable from it without consuming
+// input to threads, keeping only the threads that consume a rune or
+// match.
+func (builder *dfaBuilder) add(
+	threads []thread,
+	seen map[thread]bool,
+	t thread,
+) []thread {
+	var inst *syntax.Inst
+
+	if seen[t] {
+		return threads
+	}
+
+	seen[t] = true
+	inst = builder.inst(t)
+
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		threads = builder.add(threads, seen, thread{t.rule, int(inst.Out)})
+		threads = builder.add(threads, seen, thread{t.rule, int(inst.Arg)})
+	case syntax.InstCapture, syntax.InstNop:
+		threads = builder.add(threads, seen, thread{t.rule, int(inst.Out)})
+	case syntax.InstFail, syntax.InstEmptyWidth:
+	default:
+		threads = append(threads, t)
+	}
+
+	return threads
+}
+
+func (builder *dfaBuilder) inst(t thread) *syntax.Inst {
+	return &builder.progs[t.rule].Inst[t.pc]
+}
+
+// matchesEmpty reports whether prog matches the empty string.
+func matchesEmpty(prog *syntax.Prog) bool {
+	var (
+		builder *dfaBuilder
+		t       thread
+	)
+
+	builder = &dfaBuilder{progs: []*syntax.Prog{prog}}
+
+	for _, t = range builder.add(nil, make(map[thread]bool), thread{0, prog.Start}) {
+		if builder.inst(t).Op == syntax.InstMatch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// instRanges returns the runes consumed by inst as inclusive ranges.
+func instRanges(inst *syntax.Inst) []runeRange {
+	var (
+		ranges []runeRange
+		i      int
+	)
+
+	switch inst.Op {
+	case syntax.InstRune1:
+		return foldRanges(inst.Rune[0], foldsCase(inst))
+	case syntax.InstRuneAny:
+		return []runeRange{{0, unicode.MaxRune}}
+	case syntax.InstRuneAnyNotNL:
+		return []runeRange{{0, '\n' - 1}, {'\n' + 1, unicode.MaxRune}}
+	case syntax.InstRune:
+		if len(inst.Rune) == 1 {
+			return foldRanges(inst.Rune[0], foldsCase(inst))
+		}
+
+		for i = 0; i+1 < len(inst.Rune); i += 2 {
+			ranges = append(ranges, runeRange{inst.Rune[i], inst.Rune[i+1]})
+		}
+
+		return ranges
+	default:
+		return nil
+	}
+}
+
+func foldsCase(inst *syntax.Inst) bool {
+	return syntax.Flags(inst.Arg)&syntax.FoldCase != 0
+}
+
+// foldRanges returns char, along with its case-folding equivalents if
+// fold is true.
+func foldRanges(char rune, fold bool) []runeRange {
+	var (
+		ranges []runeRange
+		other  rune
+	)
+
+	ranges = []runeRange{{char, char}}
+
+	if !fold {
+		return ranges
+	}
+
+	for other = unicode.SimpleFold(char); other != char; other = unicode.SimpleFold(other) {
+		ranges = append(ranges, runeRange{other, other})
+	}
+
+	return ranges
+}
+
+func rangesContain(ranges []runeRange, char rune) bool {
+	var r runeRange
+
+	for _, r = range ranges {
+		if r.lo <= char && char <= r.hi {
+			return true
+		}
+	}
+
+	return false
+}
+
+func threadsKey(threads []thread) string {
+	var (
+		sb strings.Builder
+		t  thread
+	)
+
+	for _, t = range threads {
+		fmt.Fprintf(&sb, "%d.%d,", t.rule, t.pc)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,271 @@
+package lexgen
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxEdgeRanges is the number of rune ranges spelled out on an edge
+// before the label is abbreviated.
+const maxEdgeRanges = 6
+
+// WriteDOT writes the compiled automata of the grammar to w in the
+// Graphviz DOT language, one cluster per mode. Accepting states are
+// drawn as double circles labeled with the name of the accepted rule,
+// and mode changes are drawn as dashed edges to the start state of the
+// entered mode.
+func (grammar *Grammar) WriteDOT(w io.Writer) error {
+	var (
+		sb    strings.Builder
+		mode  string
+		auto  *dfa
+		state dfaState
+		group edgeGroup
+		i, j  int
+	)
+
+	sb.WriteString("digraph lexgen {\n\trankdir=LR;\n")
+
+	for i, mode = range grammar.modes {
+		auto = grammar.dfas[mode]
+
+		fmt.Fprintf(&sb, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "\t\tlabel=%s;\n", dotQuote(modeName(mode)))
+
+		for j, state = range auto.states {
+			if state.rule < 0 {
+				fmt.Fprintf(&sb, "\t\tm%d_s%d [label=\"s%d\", shape=circle];\n", i, j, j)
+
+				continue
+			}
+
+			fmt.Fprintf(
+				&sb,
+				"\t\tm%d_s%d [label=%s, shape=doublecircle];\n",
+				i,
+				j,
+				dotQuote(fmt.Sprintf("s%d\n%s", j, grammar.stateLabel(state))),
+			)
+		}
+
+		sb.WriteString("\t}\n")
+
+		for j, state = range auto.states {
+			for _, group = range groupEdges(state.edges) {
+				fmt.Fprintf(
+					&sb,
+					"\tm%d_s%d -> m%d_s%d [label=%s];\n",
+					i,
+					j,
+					i,
+					group.next,
+					dotQuote(group.label()),
+				)
+			}
+
+			if state.rule >= 0 && grammar.rules[state.rule].Push != "" {
+				fmt.Fprintf(
+					&sb,
+					"\tm%d_s%d -> m%d_s0 [style=dashed, label=%s];\n",
+					i,
+					j,
+					grammar.modeIndex(grammar.rules[state.rule].Push),
+					dotQuote("push "+grammar.rules[state.rule].Push),
+				)
+			}
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return writeString(w, sb.String())
+}
+
+// WriteMermaid writes the compiled automata of the grammar to w as a
+// Mermaid flowchart, with the same layout as WriteDOT: one subgraph per
+// mode, accepting states drawn as double circles, and mode changes
+// drawn as dotted edges.
+func (grammar *Grammar) WriteMermaid(w io.Writer) error {
+	var (
+		sb    strings.Builder
+		mode  string
+		auto  *dfa
+		state dfaState
+		group edgeGroup
+		i, j  int
+	)
+
+	sb.WriteString("flowchart LR\n")
+
+	for i, mode = range grammar.modes {
+		auto = grammar.dfas[mode]
+
+		fmt.Fprintf(&sb, "\tsubgraph m%d [%s]\n", i, mermaidQuote(modeName(mode)))
+
+		for j, state = range auto.states {
+			if state.rule < 0 {
+				fmt.Fprintf(&sb, "\t\tm%d_s%d((\"s%d\"))\n", i, j, j)
+
+				continue
+			}
+
+			fmt.Fprintf(
+				&sb,
+				"\t\tm%d_s%d(((%s)))\n",
+				i,
+				j,
+				mermaidQuote(fmt.Sprintf("s%d %s", j, grammar.stateLabel(state))),
+			)
+		}
+
+		sb.WriteString("\tend\n")
+
+		for j, state = range auto.states {
+			for _, group = range groupEdges(state.edges) {
+				fmt.Fprintf(
+					&sb,
+					"\tm%d_s%d -- %s --> m%d_s%d\n",
+					i,
+					j,
+					mermaidQuote(group.label()),
+					i,
+					group.next,
+				)
+			}
+
+			if state.rule >= 0 && grammar.rules[state.rule].Push != "" {
+				fmt.Fprintf(
+					&sb,
+					"\tm%d_s%d -. %s .-> m%d_s0\n",
+					i,
+					j,
+					mermaidQuote("push "+grammar.rules[state.rule].Push),
+					grammar.modeIndex(grammar.rules[state.rule].Push),
+				)
+			}
+		}
+	}
+
+	return writeString(w, sb.String())
+}
+
+// edgeGroup gathers the edges of a state leading to the same target.
+type edgeGroup struct {
+	next   int
+	ranges []runeRange
+}
+
+func (grammar *Grammar) stateLabel(state dfaState) string {
+	var rule Rule
+
+	rule = grammar.rules[state.rule]
+
+	if rule.Pop {
+		return ruleName(rule) + " (pop)"
+	}
+
+	return ruleName(rule)
+}
+
+func (grammar *Grammar) modeIndex(mode string) int {
+	var (
+		name string
+		i    int
+	)
+
+	for i, name = range grammar.modes {
+		if name == mode {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (group edgeGroup) label() string {
+	var (
+		parts []string
+		r     runeRange
+	)
+
+	for _, r = range group.ranges {
+		if len(parts) == maxEdgeRanges {
+			parts = append(parts, "...")
+
+			break
+		}
+
+		if r.lo == r.hi {
+			parts = append(parts, runeLabel(r.lo))
+
+			continue
+		}
+
+		parts = append(parts, runeLabel(r.lo)+"-"+runeLabel(r.hi))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func groupEdges(edges []edge) []edgeGroup {
+	var (
+		groups []edgeGroup
+		index  map[int]int
+		e      edge
+		i      int
+		ok     bool
+	)
+
+	index = make(map[int]int)
+
+	for _, e = range edges {
+		i, ok = index[e.next]
+		if !ok {
+			i = len(groups)
+			index[e.next] = i
+			groups = append(groups, edgeGroup{next: e.next})
+		}
+
+		groups[i].ranges = append(groups[i].ranges, runeRange{e.lo, e.hi})
+	}
+
+	return groups
+}
+
+func runeLabel(char rune) string {
+	var quoted string
+
+	quoted = strconv.QuoteRuneToGraphic(char)
+
+	return quoted[1 : len(quoted)-1]
+}
+
+func modeName(mode string) string {
+	if mode == "" {
+		return "(initial)"
+	}
+
+	return mode
+}
+
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+	).Replace(text) + `"`
+}
+
+func mermaidQuote(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, "#quot;") + `"`
+}
+
+func writeString(w io.Writer, text string) error {
+	var err error
+
+	_, err = io.WriteString(w, text)
+
+	return err
+}
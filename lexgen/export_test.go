@@ -0,0 +1,102 @@
+package lexgen_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/stretchr/testify/assert"
+)
+
+type failWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+var exportRules = []lexgen.Rule{
+	{Name: "a", Pattern: `a`},
+	{Name: "ab", Pattern: `ab|b`},
+	{Name: "open", Pattern: `"`, Push: "str"},
+	{Name: "close", Pattern: `"`, Mode: "str", Pop: true},
+}
+
+func TestGrammarWriteDOT(t *testing.T) {
+	var sb strings.Builder
+
+	t.Parallel()
+
+	assert.NoError(t, lexgen.MustCompile(exportRules).WriteDOT(&sb))
+	assert.Equal(t, `digraph lexgen {
+	rankdir=LR;
+	subgraph cluster_0 {
+		label="(initial)";
+		m0_s0 [label="s0", shape=circle];
+		m0_s1 [label="s1\nopen", shape=doublecircle];
+		m0_s2 [label="s2\na", shape=doublecircle];
+		m0_s3 [label="s3\nab", shape=doublecircle];
+	}
+	m0_s0 -> m0_s1 [label="\""];
+	m0_s0 -> m0_s2 [label="a"];
+	m0_s0 -> m0_s3 [label="b"];
+	m0_s1 -> m1_s0 [style=dashed, label="push str"];
+	m0_s2 -> m0_s3 [label="b"];
+	subgraph cluster_1 {
+		label="str";
+		m1_s0 [label="s0", shape=circle];
+		m1_s1 [label="s1\nclose (pop)", shape=doublecircle];
+	}
+	m1_s0 -> m1_s1 [label="\""];
+}
+`, sb.String())
+
+	assert.ErrorIs(
+		t,
+		lexgen.MustCompile(exportRules).WriteDOT(failWriter{}),
+		errWrite,
+	)
+}
+
+func TestGrammarWriteMermaid(t *testing.T) {
+	var sb strings.Builder
+
+	t.Parallel()
+
+	assert.NoError(t, lexgen.MustCompile(exportRules).WriteMermaid(&sb))
+	assert.Equal(t, `flowchart LR
+	subgraph m0 ["(initial)"]
+		m0_s0(("s0"))
+		m0_s1((("s1 open")))
+		m0_s2((("s2 a")))
+		m0_s3((("s3 ab")))
+	end
+	m0_s0 -- "#quot;" --> m0_s1
+	m0_s0 -- "a" --> m0_s2
+	m0_s0 -- "b" --> m0_s3
+	m0_s1 -. "push str" .-> m1_s0
+	m0_s2 -- "b" --> m0_s3
+	subgraph m1 ["str"]
+		m1_s0(("s0"))
+		m1_s1((("s1 close (pop)")))
+	end
+	m1_s0 -- "#quot;" --> m1_s1
+`, sb.String())
+}
+
+func TestGrammarWriteDOTLabels(t *testing.T) {
+	var sb strings.Builder
+
+	t.Parallel()
+
+	assert.NoError(t, lexgen.MustCompile([]lexgen.Rule{
+		{Name: "odd", Pattern: `[a-cegikmo\n]`},
+	}).WriteDOT(&sb))
+	assert.Contains(
+		t,
+		sb.String(),
+		`m0_s0 -> m0_s1 [label="\\n a-c e g i k ..."];`,
+	)
+}
@@ -0,0 +1,277 @@
+// Package lexgen builds lexers from declarative rule sets. Each Rule
+// pairs a regular expression with the token kind it produces, and
+// Compile turns a list of rules into a Grammar: one deterministic
+// automaton per lexer mode, run with longest-match semantics, where
+// the earliest rule wins among matches of equal length.
+//
+// A Grammar plugs into the lexer package as an ordinary state function,
+// so rule-based lexing can be mixed with hand-written states, embedded
+// with lexer.Embed, and post-processed with lexer passes.
+package lexgen // import "github.com/andrieee44/langengine/lexgen"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"slices"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Rule is a single declarative lexer rule.
+type Rule struct {
+	// Name identifies the rule in diagnostics and exported graphs. The
+	// pattern is used when Name is empty.
+	Name string
+
+	// Pattern is the regular expression matched by the rule, in the
+	// syntax of the regexp package. Empty-width assertions such as ^, $,
+	// and \b are not supported, and the pattern must not match the
+	// empty string.
+	Pattern string
+
+	// Kind is the kind of the tokens produced by the rule.
+	Kind lexer.Kind
+
+	// Trivia emits the tokens with lexer.Lexer.EmitTrivia instead of
+	// lexer.Lexer.Emit.
+	Trivia bool
+
+	// Skip discards the matched text instead of emitting a token, as for
+	// whitespace in most grammars.
+	Skip bool
+
+	// Mode is the lexer mode in which the rule is active. The empty
+	// string is the initial mode.
+	Mode string
+
+	// Push is the mode entered after the rule matches, if not empty.
+	Push string
+
+	// Pop leaves the current mode after the rule matches. It is applied
+	// before Push, so a rule can switch between sibling modes.
+	Pop bool
+}
+
+// Grammar is a compiled rule set. A Grammar is immutable and may be
+// shared by any number of concurrently running lexers.
+type Grammar struct {
+	rules []Rule
+	modes []string
+	dfas  map[string]*dfa
+}
+
+var (
+	// ErrEmptyMatch is returned by Compile for a rule whose pattern
+	// matches the empty string, which would never consume input.
+	ErrEmptyMatch = errors.New("lexgen: pattern matches the empty string")
+
+	// ErrAssertion is returned by Compile for a rule whose pattern uses
+	// an empty-width assertion, such as ^, $, or \b.
+	ErrAssertion = errors.New("lexgen: empty-width assertions are not supported")
+
+	// ErrUnknownMode is returned by Compile for a rule that pushes a mode
+	// in which no rule is active.
+	ErrUnknownMode = errors.New("lexgen: no rules for pushed mode")
+)
+
+// Compile compiles rules into a Grammar. Rules are tried together: at
+// every position, the rule matching the longest text wins, and among
+// rules matching the same text, the one listed first.
+//
+// Returns an error naming the offending rule if a pattern fails to
+// parse, matches the empty string, or uses an empty-width assertion, or
+// if a rule pushes a mode in which no rule is active. Rules that can
+// never match are accepted and reported by Grammar.Conflicts.
+func Compile(rules []Rule) (*Grammar, error) {
+	var (
+		grammar *Grammar
+		progs   []*syntax.Prog
+		prog    *syntax.Prog
+		rule    Rule
+		mode    string
+		i       int
+		err     error
+	)
+
+	grammar = &Grammar{
+		rules: slices.Clone(rules),
+		dfas:  make(map[string]*dfa),
+	}
+
+	progs = make([]*syntax.Prog, len(rules))
+
+	for i, rule = range rules {
+		prog, err = compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("lexgen: rule %q: %w", ruleName(rule), err)
+		}
+
+		progs[i] = prog
+
+		if !slices.Contains(grammar.modes, rule.Mode) {
+			grammar.modes = append(grammar.modes, rule.Mode)
+		}
+	}
+
+	for _, rule = range rules {
+		if rule.Push != "" && !slices.Contains(grammar.modes, rule.Push) {
+			return nil, fmt.Errorf(
+				"lexgen: rule %q: %w: %q",
+				ruleName(rule),
+				ErrUnknownMode,
+				rule.Push,
+			)
+		}
+	}
+
+	slices.Sort(grammar.modes)
+
+	for _, mode = range grammar.modes {
+		grammar.dfas[mode] = buildDFA(progs, func(i int) bool {
+			return rules[i].Mode == mode
+		})
+	}
+
+	return grammar, nil
+}
+
+// MustCompile is like Compile but panics if the rules cannot be
+// compiled. It simplifies the initialization of global grammars.
+func MustCompile(rules []Rule) *Grammar {
+	var (
+		grammar *Grammar
+		err     error
+	)
+
+	grammar, err = Compile(rules)
+	if err != nil {
+		panic(err)
+	}
+
+	return grammar
+}
+
+// NewLexer returns a lexer.Lexer that tokenizes rd with the grammar.
+// The options configure the underlying lexer.Reader.
+func (grammar *Grammar) NewLexer(
+	rd io.Reader,
+	opts ...lexer.Option,
+) *lexer.Lexer {
+	return lexer.NewLexer(rd, grammar.Start(), opts...)
+}
+
+// Start returns the state function that lexes with the grammar. At each
+// step it selects the automaton of the current lexer mode, consumes the
+// longest match, and applies the action of the matching rule. It stops
+// at the end of input, and stops with a lexer.KindError token if no
+// rule matches or no rule is active in the current mode. Under
+// lexer.WithProfile, every match is attributed to the matching rule.
+func (grammar *Grammar) Start() lexer.StateFn {
+	return grammar.start(nil)
+}
+
+// start returns the state function of Start, calling matched, if not
+// nil, with the index of every matching rule and the text it matched.
+func (grammar *Grammar) start(matched func(rule int, text string)) lexer.StateFn {
+	var state lexer.StateFn
+
+	state = func(lx *lexer.Lexer) lexer.StateFn {
+		var (
+			auto *dfa
+			rule int
+			ok   bool
+		)
+
+		auto, ok = grammar.dfas[lx.Mode()]
+		if !ok {
+			return lx.Errorf("no rules for mode %q", lx.Mode())
+		}
+
+		if lx.AtEOF() {
+			return nil
+		}
+
+		rule = auto.match(lx.Reader)
+		if rule < 0 {
+			lx.Next()
+
+			return lx.Errorf("no rule matches %q", lx.PeekToken())
+		}
+
+		if matched != nil {
+			matched(rule, lx.PeekToken())
+		}
+
+		lx.ProfileLabel(ruleName(grammar.rules[rule]))
+		grammar.apply(lx, grammar.rules[rule])
+
+		return state
+	}
+
+	return state
+}
+
+// Rules returns a copy of the rules the grammar was compiled from.
+func (grammar *Grammar) Rules() []Rule {
+	return slices.Clone(grammar.rules)
+}
+
+func (grammar *Grammar) apply(lx *lexer.Lexer, rule Rule) {
+	switch {
+	case rule.Skip:
+		lx.Ignore()
+	case rule.Trivia:
+		lx.EmitTrivia(rule.Kind)
+	default:
+		lx.Emit(rule.Kind)
+	}
+
+	if rule.Pop {
+		lx.PopMode()
+	}
+
+	if rule.Push != "" {
+		lx.PushMode(rule.Push)
+	}
+}
+
+func compileRule(rule Rule) (*syntax.Prog, error) {
+	var (
+		re   *syntax.Regexp
+		prog *syntax.Prog
+		inst syntax.Inst
+		err  error
+	)
+
+	re, err = syntax.Parse(rule.Pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err = syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inst = range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth {
+			return nil, ErrAssertion
+		}
+	}
+
+	if matchesEmpty(prog) {
+		return nil, ErrEmptyMatch
+	}
+
+	return prog, nil
+}
+
+func ruleName(rule Rule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+
+	return rule.Pattern
+}
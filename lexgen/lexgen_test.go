@@ -0,0 +1,304 @@
+package lexgen_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindIdent lexer.Kind = lexer.KindUser + iota
+	kindKeyword
+	kindNumber
+	kindOp
+	kindComment
+	kindQuote
+	kindChars
+)
+
+type kindText struct {
+	kind lexer.Kind
+	text string
+}
+
+var rules = []lexgen.Rule{
+	{Name: "space", Pattern: `\s+`, Skip: true},
+	{Name: "comment", Pattern: `#.*`, Kind: kindComment, Trivia: true},
+	{Name: "if", Pattern: `(?i)if`, Kind: kindKeyword},
+	{Name: "ident", Pattern: `\pL[\pL\d_]*`, Kind: kindIdent},
+	{Name: "number", Pattern: `\d+(\.\d+)?`, Kind: kindNumber},
+	{Name: "op", Pattern: `[-+*/=<>]=?|==`, Kind: kindOp},
+	{Name: "open", Pattern: `"`, Kind: kindQuote, Push: "string"},
+	{Name: "close", Pattern: `"`, Kind: kindQuote, Mode: "string", Pop: true},
+	{Name: "text", Pattern: `[^"\\]+|\\.`, Kind: kindChars, Mode: "string"},
+}
+
+func collect(lx *lexer.Lexer) []kindText {
+	var (
+		tokens []kindText
+		tok    lexer.Token
+	)
+
+	for tok = range lx.Tokens() {
+		tokens = append(tokens, kindText{tok.Kind, tok.Text})
+	}
+
+	return tokens
+}
+
+func TestGrammar(t *testing.T) {
+	var (
+		grammar *lexgen.Grammar
+		testTbl map[string][]kindText
+		content string
+		tokens  []kindText
+	)
+
+	t.Parallel()
+
+	grammar = lexgen.MustCompile(rules)
+	testTbl = map[string][]kindText{
+		"IF iffy 3.14 <= 2.": {
+			{kindKeyword, "IF"},
+			{kindIdent, "iffy"},
+			{kindNumber, "3.14"},
+			{kindOp, "<="},
+			{kindNumber, "2"},
+			{lexer.KindError, `no rule matches "."`},
+		},
+		"héllo # note\nx": {
+			{kindIdent, "héllo"},
+			{kindComment, "# note"},
+			{kindIdent, "x"},
+		},
+		`s = "a\"b" + c`: {
+			{kindIdent, "s"},
+			{kindOp, "="},
+			{kindQuote, `"`},
+			{kindChars, "a"},
+			{kindChars, `\"`},
+			{kindChars, "b"},
+			{kindQuote, `"`},
+			{kindOp, "+"},
+			{kindIdent, "c"},
+		},
+		"": nil,
+	}
+
+	for content, tokens = range testTbl {
+		t.Run(content, func(t *testing.T) {
+			assert.Equal(
+				t,
+				tokens,
+				collect(grammar.NewLexer(strings.NewReader(content))),
+			)
+		})
+	}
+}
+
+func TestGrammarTrivia(t *testing.T) {
+	var tokens []lexer.Token
+
+	t.Parallel()
+
+	tokens = slices.Collect(lexgen.MustCompile(rules).NewLexer(
+		strings.NewReader("#x"),
+	).Tokens())
+
+	assert.Len(t, tokens, 1)
+	assert.True(t, tokens[0].Trivia)
+}
+
+func TestGrammarNUL(t *testing.T) {
+	var (
+		grammar *lexgen.Grammar
+		testTbl map[string][]kindText
+		content string
+		tokens  []kindText
+	)
+
+	t.Parallel()
+
+	grammar = lexgen.MustCompile([]lexgen.Rule{
+		{Name: "word", Pattern: `[a-z]+`, Kind: kindIdent},
+		{Name: "nul", Pattern: `\x00`, Kind: kindOp},
+	})
+	testTbl = map[string][]kindText{
+		"ab\x00cd": {
+			{kindIdent, "ab"},
+			{kindOp, "\x00"},
+			{kindIdent, "cd"},
+		},
+		"\x00ab": {
+			{kindOp, "\x00"},
+			{kindIdent, "ab"},
+		},
+	}
+
+	for content, tokens = range testTbl {
+		assert.Equal(
+			t,
+			tokens,
+			collect(grammar.NewLexer(strings.NewReader(content))),
+			content,
+		)
+	}
+
+	assert.Equal(
+		t,
+		[]kindText{{kindIdent, "ab"}, {lexer.KindError, `no rule matches "\x00"`}},
+		collect(lexgen.MustCompile(rules).NewLexer(strings.NewReader("ab\x00cd"))),
+	)
+}
+
+func TestGrammarEmbed(t *testing.T) {
+	var (
+		grammar *lexgen.Grammar
+		lx      *lexer.Lexer
+	)
+
+	t.Parallel()
+
+	grammar = lexgen.MustCompile(rules)
+	lx = lexer.NewLexer(
+		strings.NewReader("a+1?b"),
+		lexer.Embed(grammar.Start(), "?", func(lx *lexer.Lexer) lexer.StateFn {
+			lx.Next()
+			lx.Emit(kindOp)
+
+			return grammar.Start()
+		}),
+	)
+
+	assert.Equal(t, []kindText{
+		{kindIdent, "a"},
+		{kindOp, "+"},
+		{kindNumber, "1"},
+		{kindOp, "?"},
+		{kindIdent, "b"},
+	}, collect(lx))
+}
+
+func TestCompileError(t *testing.T) {
+	type testData struct {
+		rules []lexgen.Rule
+		err   error
+		msg   string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"EmptyMatch": {
+			rules: []lexgen.Rule{{Name: "ws", Pattern: `\s*`}},
+			err:   lexgen.ErrEmptyMatch,
+			msg:   `lexgen: rule "ws": lexgen: pattern matches the empty string`,
+		},
+		"Assertion": {
+			rules: []lexgen.Rule{{Pattern: `^a`}},
+			err:   lexgen.ErrAssertion,
+			msg:   `lexgen: rule "^a": lexgen: empty-width assertions are not supported`,
+		},
+		"UnknownMode": {
+			rules: []lexgen.Rule{{Name: "open", Pattern: `"`, Push: "str"}},
+			err:   lexgen.ErrUnknownMode,
+			msg:   `lexgen: rule "open": lexgen: no rules for pushed mode: "str"`,
+		},
+		"Syntax": {
+			rules: []lexgen.Rule{{Name: "bad", Pattern: `a(`}},
+			msg:   "lexgen: rule \"bad\": error parsing regexp: missing closing ): `a(`",
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var err error
+
+			_, err = lexgen.Compile(test.rules)
+
+			assert.EqualError(t, err, test.msg)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		lexgen.MustCompile([]lexgen.Rule{{Pattern: `a*`}})
+	})
+}
+
+func TestGrammarRules(t *testing.T) {
+	var (
+		grammar *lexgen.Grammar
+		got     []lexgen.Rule
+	)
+
+	t.Parallel()
+
+	grammar = lexgen.MustCompile(rules)
+	got = grammar.Rules()
+	got[0].Name = "changed"
+
+	assert.Equal(t, rules, grammar.Rules())
+}
+
+func TestGrammarProfile(t *testing.T) {
+	var (
+		profile *lexer.Profile
+		calls   map[string]int
+		entry   lexer.ProfileEntry
+	)
+
+	t.Parallel()
+
+	profile = lexer.NewProfile()
+	collect(lexgen.MustCompile(rules).NewLexer(
+		strings.NewReader("if x = 1 + y"),
+		lexer.WithProfile(profile),
+	))
+
+	calls = make(map[string]int)
+
+	for _, entry = range profile.Entries() {
+		calls[entry.Name] = entry.Calls
+	}
+
+	assert.Equal(t, 2, calls["ident"])
+	assert.Equal(t, 1, calls["if"])
+	assert.Equal(t, 2, calls["op"])
+	assert.Equal(t, 1, calls["number"])
+	assert.Equal(t, 5, calls["space"])
+}
+
+func BenchmarkGrammar(b *testing.B) {
+	var (
+		grammar *lexgen.Grammar
+		content string
+		tok     lexer.Token
+	)
+
+	grammar = lexgen.MustCompile(rules)
+	content = strings.Repeat(`if x1 <= 42.5 # check`+"\n"+`s = "a\"b"`+"\n", 100)
+
+	for b.Loop() {
+		for tok = range grammar.NewLexer(strings.NewReader(content)).Tokens() {
+			_ = tok
+		}
+	}
+}
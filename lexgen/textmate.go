@@ -0,0 +1,279 @@
+package lexgen
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// ErrUnsupportedInclude is reported by ImportTextMate for an include of
+// another grammar, such as "source.js", or of a repository entry that
+// does not exist.
+var ErrUnsupportedInclude = errors.New("lexgen: unsupported include")
+
+// Import is the result of converting a grammar of another ecosystem
+// into rules.
+type Import struct {
+	// Rules are the converted rules, ready for Compile.
+	Rules []Rule
+
+	// Skipped lists, for every part of the grammar that could not be
+	// converted, an error naming it and explaining why.
+	Skipped []error
+}
+
+type tmGrammar struct {
+	ScopeName  string               `json:"scopeName"`
+	Patterns   []tmPattern          `json:"patterns"`
+	Repository map[string]tmPattern `json:"repository"`
+}
+
+type tmPattern struct {
+	Include     string      `json:"include"`
+	Name        string      `json:"name"`
+	ContentName string      `json:"contentName"`
+	Match       string      `json:"match"`
+	Begin       string      `json:"begin"`
+	End         string      `json:"end"`
+	Patterns    []tmPattern `json:"patterns"`
+}
+
+type tmImporter struct {
+	grammar  tmGrammar
+	kind     func(scope string) lexer.Kind
+	imp      Import
+	modes    map[string]bool
+	included map[string]bool
+}
+
+// ImportTextMate converts the lexical portion of a TextMate grammar, in
+// the JSON form used by editors such as Visual Studio Code, into rules,
+// so that existing highlighting grammars can drive a lexer. The kind
+// function maps the scope name of every token, such as
+// "string.quoted.double.go", to its kind.
+//
+// A match pattern becomes a rule, and a begin and end pattern becomes
+// a pair of rules pushing and popping a mode holding the nested
+// patterns. Patterns without a name take the scope of their enclosing
+// region, and every mode ends with a rule consuming any single rune
+// with that scope, so that text no pattern covers is still tokenized,
+// as it is in an editor. Includes of repository entries and of the
+// grammar itself are expanded in place.
+//
+// The conversion is good enough for highlighting but not exact. Rules
+// are tried with longest-match semantics rather than in order, so an
+// end pattern only takes precedence over the nested patterns matching
+// text of the same length, and captures are ignored, so every match is
+// a single token. Patterns using
+// features the regexp package lacks, such as lookaround, back
+// references, or anchors, are skipped along with the region they open,
+// and so are includes of other grammars; each is reported in Skipped.
+// Oniguruma's \h and \H are translated.
+//
+// Returns an error only if data is not a valid grammar.
+func ImportTextMate(data []byte, kind func(scope string) lexer.Kind) (Import, error) {
+	var (
+		im  tmImporter
+		err error
+	)
+
+	err = json.Unmarshal(data, &im.grammar)
+	if err != nil {
+		return Import{}, fmt.Errorf("lexgen: textmate grammar: %w", err)
+	}
+
+	im.kind = kind
+	im.modes = make(map[string]bool)
+	im.included = make(map[string]bool)
+
+	im.patterns("", im.grammar.ScopeName, "patterns", im.grammar.Patterns)
+	im.fallback("", im.grammar.ScopeName)
+
+	return im.imp, nil
+}
+
+func (im *tmImporter) patterns(mode, scope, path string, pats []tmPattern) {
+	var (
+		pat tmPattern
+		i   int
+	)
+
+	for i, pat = range pats {
+		im.pattern(mode, scope, fmt.Sprintf("%s/%d", path, i), pat)
+	}
+}
+
+func (im *tmImporter) pattern(mode, scope, path string, pat tmPattern) {
+	switch {
+	case pat.Include != "":
+		im.include(mode, scope, path, pat.Include)
+	case pat.Match != "":
+		im.add(path, cmp.Or(pat.Name, scope), Rule{
+			Name:    path,
+			Pattern: translateOniguruma(pat.Match),
+			Mode:    mode,
+		})
+	case pat.Begin != "":
+		im.region(mode, scope, path, pat)
+	default:
+		im.patterns(mode, scope, path+"/patterns", pat.Patterns)
+	}
+}
+
+// include expands the include ref into mode, once per mode, which also
+// ends recursive includes.
+func (im *tmImporter) include(mode, scope, path, ref string) {
+	var (
+		pat tmPattern
+		key string
+		ok  bool
+	)
+
+	key = mode + "\x00" + ref
+	if im.included[key] {
+		return
+	}
+
+	im.included[key] = true
+
+	switch {
+	case ref == "$self" || ref == "$base":
+		im.patterns(mode, scope, "patterns", im.grammar.Patterns)
+	case strings.HasPrefix(ref, "#"):
+		pat, ok = im.grammar.Repository[ref[1:]]
+		if !ok {
+			im.skip(path, fmt.Errorf("%w: %q", ErrUnsupportedInclude, ref))
+
+			return
+		}
+
+		im.pattern(mode, scope, ref, pat)
+	default:
+		im.skip(path, fmt.Errorf("%w: %q", ErrUnsupportedInclude, ref))
+	}
+}
+
+// region converts a begin and end pattern. The mode of the region is
+// named after path, so a region included from several places is built
+// once.
+func (im *tmImporter) region(mode, scope, path string, pat tmPattern) {
+	var (
+		begin, end Rule
+		inner      string
+		err        error
+	)
+
+	begin = Rule{
+		Name:    path + "/begin",
+		Pattern: translateOniguruma(pat.Begin),
+		Mode:    mode,
+		Push:    path,
+	}
+
+	end = Rule{
+		Name:    path + "/end",
+		Pattern: translateOniguruma(pat.End),
+		Mode:    path,
+		Pop:     true,
+	}
+
+	_, err = compileRule(begin)
+	if err == nil {
+		_, err = compileRule(end)
+	}
+
+	if err != nil {
+		im.skip(path, err)
+
+		return
+	}
+
+	begin.Kind = im.kind(cmp.Or(pat.Name, scope))
+	end.Kind = begin.Kind
+	im.imp.Rules = append(im.imp.Rules, begin)
+
+	if im.modes[path] {
+		return
+	}
+
+	im.modes[path] = true
+	inner = cmp.Or(pat.ContentName, pat.Name, scope)
+
+	im.imp.Rules = append(im.imp.Rules, end)
+	im.patterns(path, inner, path+"/patterns", pat.Patterns)
+	im.fallback(path, inner)
+}
+
+func (im *tmImporter) fallback(mode, scope string) {
+	im.imp.Rules = append(im.imp.Rules, Rule{
+		Name:    modeName(mode) + "/text",
+		Pattern: `(?s).`,
+		Kind:    im.kind(scope),
+		Mode:    mode,
+	})
+}
+
+// add adds rule, producing tokens of scope, unless its pattern cannot
+// be compiled.
+func (im *tmImporter) add(path, scope string, rule Rule) {
+	var err error
+
+	_, err = compileRule(rule)
+	if err != nil {
+		im.skip(path, err)
+
+		return
+	}
+
+	rule.Kind = im.kind(scope)
+	im.imp.Rules = append(im.imp.Rules, rule)
+}
+
+func (im *tmImporter) skip(path string, err error) {
+	im.imp.Skipped = append(
+		im.imp.Skipped,
+		fmt.Errorf("lexgen: textmate pattern %q: %w", path, err),
+	)
+}
+
+// translateOniguruma rewrites the escapes of Oniguruma that the regexp
+// package lacks.
+func translateOniguruma(pattern string) string {
+	var (
+		sb      strings.Builder
+		inClass bool
+		i       int
+	)
+
+	for i = 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+
+			switch {
+			case pattern[i] == 'h' && inClass:
+				sb.WriteString("0-9A-Fa-f")
+			case pattern[i] == 'h':
+				sb.WriteString("[0-9A-Fa-f]")
+			case pattern[i] == 'H' && !inClass:
+				sb.WriteString("[^0-9A-Fa-f]")
+			default:
+				sb.WriteString(pattern[i-1 : i+1])
+			}
+
+			continue
+		case pattern[i] == '[':
+			inClass = true
+		case pattern[i] == ']':
+			inClass = false
+		}
+
+		sb.WriteByte(pattern[i])
+	}
+
+	return sb.String()
+}
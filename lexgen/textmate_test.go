@@ -0,0 +1,104 @@
+package lexgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/stretchr/testify/assert"
+)
+
+const textMateGrammar = `{
+	"scopeName": "source.demo",
+	"patterns": [
+		{"include": "#keywords"},
+		{"include": "#strings"},
+		{"include": "source.other"},
+		{"match": "(?<=x)y", "name": "invalid.lookbehind"},
+		{"match": "\\h+h", "name": "constant.numeric.hex"},
+		{"match": "\\s+"}
+	],
+	"repository": {
+		"keywords": {
+			"patterns": [
+				{"match": "if|else", "name": "keyword.control"}
+			]
+		},
+		"strings": {
+			"begin": "\"",
+			"end": "\"",
+			"name": "string.quoted",
+			"patterns": [
+				{"match": "\\\\.", "name": "constant.character.escape"},
+				{"include": "#strings"}
+			]
+		}
+	}
+}`
+
+func TestImportTextMate(t *testing.T) {
+	var (
+		scopes  []string
+		imp     lexgen.Import
+		grammar *lexgen.Grammar
+		tokens  []kindText
+		i       int
+		err     error
+	)
+
+	t.Parallel()
+
+	imp, err = lexgen.ImportTextMate(
+		[]byte(textMateGrammar),
+		func(scope string) lexer.Kind {
+			var i int
+
+			for i = range scopes {
+				if scopes[i] == scope {
+					return lexer.KindUser + lexer.Kind(i)
+				}
+			}
+
+			scopes = append(scopes, scope)
+
+			return lexer.KindUser + lexer.Kind(len(scopes)-1)
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Len(t, imp.Skipped, 2)
+	assert.ErrorIs(t, imp.Skipped[0], lexgen.ErrUnsupportedInclude)
+	assert.ErrorContains(t, imp.Skipped[1], `"patterns/3"`)
+
+	grammar, err = lexgen.Compile(imp.Rules)
+	assert.NoError(t, err)
+	assert.Len(t, grammar.Conflicts(), 1)
+
+	tokens = collect(grammar.NewLexer(strings.NewReader(`if "a\"b" ff0h ?`)))
+
+	for i = range tokens {
+		tokens[i].kind -= lexer.KindUser
+	}
+
+	assert.Equal(t, []string{
+		"keyword.control",
+		"string.quoted",
+		"constant.character.escape",
+		"constant.numeric.hex",
+		"source.demo",
+	}, scopes)
+	assert.Equal(t, []kindText{
+		{0, "if"},
+		{4, " "},
+		{1, `"`},
+		{1, "a"},
+		{2, `\"`},
+		{1, "b"},
+		{1, `"`},
+		{4, " "},
+		{3, "ff0h"},
+		{4, " "},
+		{4, "?"},
+	}, tokens)
+}
@@ -0,0 +1,77 @@
+// Package literal evaluates the text of literal tokens into Go values:
+// integers with overflow detection, floating-point numbers, strings
+// with escape sequences, and dates and times. It is shared by parsers
+// folding constants and evaluators turning literals into runtime
+// values, so that a language checks its literals in one place.
+//
+// Errors are *Error values carrying a lexer.Diagnostic whose span is
+// as precise as the failure allows: the invalid digit of a number or
+// the malformed escape of a string, rather than the whole token, so an
+// editor can underline exactly what is wrong.
+package literal // import "github.com/andrieee44/langengine/literal"
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+var (
+	// ErrSyntax is wrapped by the errors of literals that are malformed.
+	ErrSyntax = errors.New("literal: invalid syntax")
+
+	// ErrRange is wrapped by the errors of literals that are well-formed
+	// but denote a value out of the range of the requested type.
+	ErrRange = errors.New("literal: value out of range")
+)
+
+// Error is the error returned for a literal that cannot be evaluated.
+type Error struct {
+	// Diagnostic describes the error, spanning the offending part of the
+	// literal.
+	Diagnostic lexer.Diagnostic
+
+	// Err is ErrSyntax or ErrRange.
+	Err error
+}
+
+// Error returns the diagnostic of the error, formatted as
+// "line:column: message".
+func (err *Error) Error() string {
+	return err.Diagnostic.String()
+}
+
+// Unwrap returns ErrSyntax or ErrRange.
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// errorf returns an *Error wrapping cause and spanning the bytes of the
+// text of tok from start to end, or the whole token if they are out of
+// range, with the formatted message.
+func errorf(
+	tok lexer.Token,
+	start, end int,
+	cause error,
+	format string,
+	args ...any,
+) *Error {
+	var (
+		span lexer.Span
+		err  error
+	)
+
+	span, err = tok.SubSpan(start, end)
+	if err != nil {
+		span = tok.Span
+	}
+
+	return &Error{
+		Diagnostic: lexer.Diagnostic{
+			Span:    span,
+			Message: fmt.Sprintf(format, args...),
+		},
+		Err: cause,
+	}
+}
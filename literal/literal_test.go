@@ -0,0 +1,354 @@
+package literal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/literal"
+	"github.com/stretchr/testify/assert"
+)
+
+// token returns a token of text starting at line 1, column 1.
+func token(text string) lexer.Token {
+	return lexer.Token{
+		Text: text,
+		Span: lexer.Span{Start: lexer.Position{Line: 1, Column: 1}},
+	}
+}
+
+// assertError asserts that err is an *Error wrapping cause, formatted
+// as msg, and spanning the bytes of the literal from start to end.
+func assertError(
+	t *testing.T,
+	err error,
+	cause error,
+	msg string,
+	start, end int,
+	name string,
+) {
+	var litErr *literal.Error
+
+	t.Helper()
+
+	if !assert.ErrorAs(t, err, &litErr, name) {
+		return
+	}
+
+	assert.ErrorIs(t, err, cause, name)
+	assert.EqualError(t, err, msg, name)
+	assert.Equal(t, start, litErr.Diagnostic.Span.Start.Offset, name)
+	assert.Equal(t, end, litErr.Diagnostic.Span.End.Offset, name)
+}
+
+func TestInt(t *testing.T) {
+	type testData struct {
+		text       string
+		bitSize    int
+		want       int64
+		err        error
+		msg        string
+		start, end int
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		n       int64
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"decimal":     {text: "1_000", want: 1000},
+		"leading 0":   {text: "010", want: 10},
+		"hexadecimal": {text: "-0x_fF", want: -255},
+		"octal":       {text: "0O17", want: 15},
+		"binary":      {text: "+0b101", want: 5},
+		"min":         {text: "-128", bitSize: 8, want: -128},
+		"overflow": {
+			text:    "128",
+			bitSize: 8,
+			err:     literal.ErrRange,
+			msg:     "1:1: integer literal 128 overflows int8",
+			end:     3,
+		},
+		"digit": {
+			text:  "0b102",
+			err:   literal.ErrSyntax,
+			msg:   "1:5: invalid digit '2' in binary literal",
+			start: 4,
+			end:   5,
+		},
+		"underscores": {
+			text:  "1__0",
+			err:   literal.ErrSyntax,
+			msg:   "1:3: '_' must separate successive digits",
+			start: 2,
+			end:   3,
+		},
+		"trailing underscore": {
+			text:  "10_",
+			err:   literal.ErrSyntax,
+			msg:   "1:3: '_' must separate successive digits",
+			start: 2,
+			end:   3,
+		},
+		"no digits": {
+			text: "0x",
+			err:  literal.ErrSyntax,
+			msg:  "1:1: hexadecimal literal has no digits",
+			end:  2,
+		},
+	}
+
+	for name, test = range testTbl {
+		n, err = literal.Int(token(test.text), test.bitSize)
+		if test.err != nil {
+			assertError(t, err, test.err, test.msg, test.start, test.end, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, n, name)
+	}
+}
+
+func TestUint(t *testing.T) {
+	var (
+		n   uint64
+		err error
+	)
+
+	t.Parallel()
+
+	n, err = literal.Uint(token("0xFFFF_FFFF_FFFF_FFFF"), 64)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<64-1), n)
+
+	_, err = literal.Uint(token("256"), 8)
+	assertError(
+		t,
+		err,
+		literal.ErrRange,
+		"1:1: integer literal 256 overflows uint8",
+		0,
+		3,
+		"",
+	)
+
+	_, err = literal.Uint(token("-1"), 0)
+	assertError(
+		t,
+		err,
+		literal.ErrSyntax,
+		"1:1: invalid digit '-' in decimal literal",
+		0,
+		1,
+		"",
+	)
+}
+
+func TestFloat(t *testing.T) {
+	type testData struct {
+		text       string
+		bitSize    int
+		want       float64
+		err        error
+		msg        string
+		start, end int
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		f       float64
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"decimal":     {text: "1_000.5e-3", bitSize: 64, want: 1.0005},
+		"hexadecimal": {text: "0x1p-2", bitSize: 64, want: 0.25},
+		"overflow": {
+			text:    "1e39",
+			bitSize: 32,
+			err:     literal.ErrRange,
+			msg:     "1:1: floating-point literal 1e39 overflows float32",
+			end:     4,
+		},
+		"character": {
+			text:    "1.5g",
+			bitSize: 64,
+			err:     literal.ErrSyntax,
+			msg:     "1:4: invalid character 'g' in floating-point literal",
+			start:   3,
+			end:     4,
+		},
+		"infinity": {
+			text:    "inf",
+			bitSize: 64,
+			err:     literal.ErrSyntax,
+			msg:     "1:1: invalid character 'i' in floating-point literal",
+			end:     1,
+		},
+		"malformed": {
+			text:    "1..5",
+			bitSize: 64,
+			err:     literal.ErrSyntax,
+			msg:     "1:1: invalid floating-point literal 1..5",
+			end:     4,
+		},
+	}
+
+	for name, test = range testTbl {
+		f, err = literal.Float(token(test.text), test.bitSize)
+		if test.err != nil {
+			assertError(t, err, test.err, test.msg, test.start, test.end, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.InDelta(t, test.want, f, 1e-12, name)
+	}
+}
+
+func TestString(t *testing.T) {
+	type testData struct {
+		text       string
+		want       string
+		msg        string
+		start, end int
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		s       string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"escapes": {text: `"a\tb\x41é\n\""`, want: "a\tbAé\n\""},
+		"single":  {text: `'it\'s'`, want: "it's"},
+		"bytes":   {text: `"\xff\377\x80"`, want: "\xff\xff\x80"},
+		"runes":   {text: `"\u00ff\U0001F600ÿ"`, want: "ÿ😀ÿ"},
+		"raw":     {text: "`a\\n\r\nb`", want: "a\\n\nb"},
+		"invalid escape": {
+			text:  `"ab\qc"`,
+			msg:   `1:4: invalid escape sequence \q in string literal`,
+			start: 3,
+			end:   5,
+		},
+		"short escape": {
+			text:  `"é\x4"`,
+			msg:   `1:3: invalid escape sequence \x4 in string literal`,
+			start: 3,
+			end:   6,
+		},
+		"unescaped quote": {
+			text:  `"a"b"`,
+			msg:   `1:3: unescaped " in string literal`,
+			start: 2,
+			end:   3,
+		},
+		"unquoted": {
+			text: `"abc`,
+			msg:  `1:1: string literal "abc is not quoted`,
+			end:  4,
+		},
+	}
+
+	for name, test = range testTbl {
+		s, err = literal.String(token(test.text))
+		if test.msg != "" {
+			assertError(t, err, literal.ErrSyntax, test.msg, test.start, test.end, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, s, name)
+	}
+}
+
+func TestTime(t *testing.T) {
+	type testData struct {
+		text       string
+		layouts    []string
+		want       time.Time
+		err        error
+		msg        string
+		start, end int
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		value   time.Time
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"offset": {
+			text: "1979-05-27T07:32:00-07:00",
+			want: time.Date(1979, 5, 27, 14, 32, 0, 0, time.UTC),
+		},
+		"local": {
+			text: "1979-05-27 07:32:00.5",
+			want: time.Date(1979, 5, 27, 7, 32, 0, 5e8, time.UTC),
+		},
+		"date": {text: "1979-05-27", want: time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC)},
+		"time": {text: "07:32:00", want: time.Date(0, 1, 1, 7, 32, 0, 0, time.UTC)},
+		"layout": {
+			text:    "27/05/1979",
+			layouts: []string{"02/01/2006"},
+			want:    time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC),
+		},
+		"syntax": {
+			text:  "1979-05-27X07:32",
+			err:   literal.ErrSyntax,
+			msg:   `1:11: invalid date and time literal 1979-05-27X07:32: unexpected "X07:32"`,
+			start: 10,
+			end:   16,
+		},
+		"short": {
+			text:    "1979-05",
+			layouts: []string{time.DateOnly},
+			err:     literal.ErrSyntax,
+			msg:     `1:8: invalid date and time literal 1979-05: missing "-"`,
+			start:   7,
+			end:     7,
+		},
+		"range": {
+			text: "1979-04-31",
+			err:  literal.ErrRange,
+			msg:  "1:1: date and time literal 1979-04-31: day out of range",
+			end:  10,
+		},
+	}
+
+	for name, test = range testTbl {
+		value, err = literal.Time(token(test.text), test.layouts...)
+		if test.err != nil {
+			assertError(t, err, test.err, test.msg, test.start, test.end, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.True(t, test.want.Equal(value), name)
+	}
+}
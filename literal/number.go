@@ -0,0 +1,255 @@
+package literal
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Int evaluates an integer literal into a signed integer of bitSize
+// bits, where 0 selects the size of int. The literal is decimal, or
+// hexadecimal, octal, or binary with a prefix of 0x, 0o, or 0b in
+// either case, and may have a sign and underscores between its digits,
+// as in -0x_FF or 1_000. Unlike in Go, a leading zero does not make a
+// literal octal.
+//
+// Returns an *Error wrapping ErrSyntax at the first invalid character
+// of a malformed literal, or wrapping ErrRange and spanning the whole
+// literal if its value does not fit.
+func Int(tok lexer.Token, bitSize int) (int64, error) {
+	var (
+		clean string
+		base  int
+		n     int64
+		err   error
+	)
+
+	clean, base, err = digits(tok, true)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = strconv.ParseInt(clean, base, bitSize)
+	if err != nil {
+		return n, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrRange,
+			"integer literal %s overflows %s",
+			tok.Text,
+			typeName("int", bitSize),
+		)
+	}
+
+	return n, nil
+}
+
+// Uint evaluates an integer literal into an unsigned integer of
+// bitSize bits, as Int does, except that the literal cannot have a
+// sign.
+func Uint(tok lexer.Token, bitSize int) (uint64, error) {
+	var (
+		clean string
+		base  int
+		n     uint64
+		err   error
+	)
+
+	clean, base, err = digits(tok, false)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = strconv.ParseUint(clean, base, bitSize)
+	if err != nil {
+		return n, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrRange,
+			"integer literal %s overflows %s",
+			tok.Text,
+			typeName("uint", bitSize),
+		)
+	}
+
+	return n, nil
+}
+
+// Float evaluates a floating-point literal into a float of bitSize
+// bits, 32 or 64, with the syntax of Go: decimal or hexadecimal
+// mantissa, optional exponent, and underscores between digits, as in
+// 1_000.5e-3 or 0x1p-2.
+//
+// Returns an *Error wrapping ErrSyntax, at the first character that
+// cannot appear in a number or else spanning the whole literal, if the
+// literal is malformed, or wrapping ErrRange if its magnitude is too
+// large for the type.
+func Float(tok lexer.Token, bitSize int) (float64, error) {
+	var (
+		f      float64
+		i      int
+		char   rune
+		numErr *strconv.NumError
+		err    error
+	)
+
+	for i, char = range tok.Text {
+		if !strings.ContainsRune("0123456789abcdefABCDEFxXpP_.+-", char) {
+			return 0, errorf(
+				tok,
+				i,
+				i+utf8.RuneLen(char),
+				ErrSyntax,
+				"invalid character %q in floating-point literal",
+				char,
+			)
+		}
+	}
+
+	f, err = strconv.ParseFloat(tok.Text, bitSize)
+	if errors.As(err, &numErr) && numErr.Err == strconv.ErrRange {
+		return f, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrRange,
+			"floating-point literal %s overflows %s",
+			tok.Text,
+			typeName("float", bitSize),
+		)
+	}
+
+	if err != nil {
+		return 0, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrSyntax,
+			"invalid floating-point literal %s",
+			tok.Text,
+		)
+	}
+
+	return f, nil
+}
+
+// digits checks the integer literal of tok and returns it without its
+// base prefix and underscores, as accepted by strconv with its base.
+func digits(tok lexer.Token, signed bool) (string, int, error) {
+	var (
+		text  string
+		sb    strings.Builder
+		base  int
+		name  string
+		i     int
+		char  rune
+		size  int
+		under int
+		last  bool
+	)
+
+	text = tok.Text
+	base, name = 10, "decimal"
+
+	if signed && len(text) != 0 && (text[0] == '-' || text[0] == '+') {
+		sb.WriteByte(text[0])
+		i++
+	}
+
+	if len(text) >= i+2 && text[i] == '0' {
+		switch text[i+1] | 0x20 {
+		case 'x':
+			base, name = 16, "hexadecimal"
+		case 'o':
+			base, name = 8, "octal"
+		case 'b':
+			base, name = 2, "binary"
+		}
+
+		if base != 10 {
+			i += 2
+			last = true
+		}
+	}
+
+	for under = -1; i < len(text); i += size {
+		char, size = utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case char == '_' && last:
+			under = i
+			last = false
+		case char == '_':
+			return "", 0, errorf(
+				tok,
+				i,
+				i+size,
+				ErrSyntax,
+				"'_' must separate successive digits",
+			)
+		case digitValue(char) < base:
+			sb.WriteRune(char)
+			last = true
+		default:
+			return "", 0, errorf(
+				tok,
+				i,
+				i+size,
+				ErrSyntax,
+				"invalid digit %q in %s literal",
+				char,
+				name,
+			)
+		}
+	}
+
+	switch {
+	case !last && under >= 0:
+		return "", 0, errorf(
+			tok,
+			under,
+			under+1,
+			ErrSyntax,
+			"'_' must separate successive digits",
+		)
+	case strings.TrimLeft(sb.String(), "+-") == "":
+		return "", 0, errorf(
+			tok,
+			0,
+			len(text),
+			ErrSyntax,
+			"%s literal has no digits",
+			name,
+		)
+	}
+
+	return sb.String(), base, nil
+}
+
+// digitValue returns the value of char as a digit, or 36 if it is not
+// one.
+func digitValue(char rune) int {
+	switch {
+	case '0' <= char && char <= '9':
+		return int(char - '0')
+	case 'a' <= char|0x20 && char|0x20 <= 'z':
+		return int(char|0x20-'a') + 10
+	default:
+		return 36
+	}
+}
+
+// typeName returns the name of the Go type of kind and bitSize, as in
+// int64, or int for the size 0.
+func typeName(kind string, bitSize int) string {
+	if bitSize == 0 {
+		return kind
+	}
+
+	return kind + strconv.Itoa(bitSize)
+}
@@ -0,0 +1,128 @@
+package literal
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// String evaluates a string literal, quoted with double quotes, single
+// quotes, or backquotes. Within double and single quotes, escape
+// sequences are those of Go, with \' only valid within single quotes
+// and \" only within double quotes, and the quote itself must be
+// escaped. Hexadecimal and octal escapes, such as \xff, stand for a
+// single byte, which may leave the result invalid UTF-8. Backquotes
+// delimit a raw string, in which backslashes have no meaning and
+// carriage returns are discarded.
+//
+// Returns an *Error wrapping ErrSyntax, spanning the invalid escape
+// sequence or unescaped quote, or the whole literal if it is not
+// quoted.
+func String(tok lexer.Token) (string, error) {
+	var (
+		text      string
+		quote     byte
+		sb        strings.Builder
+		char      rune
+		multibyte bool
+		size      int
+		tail      string
+		i         int
+		err       error
+	)
+
+	text = tok.Text
+
+	if len(text) < 2 || !strings.ContainsRune("\"'`", rune(text[0])) ||
+		text[len(text)-1] != text[0] {
+		return "", errorf(
+			tok,
+			0,
+			len(text),
+			ErrSyntax,
+			"string literal %s is not quoted",
+			text,
+		)
+	}
+
+	quote = text[0]
+	text = text[:len(text)-1]
+
+	if quote == '`' {
+		if strings.Contains(text[1:], "`") {
+			i = strings.Index(text[1:], "`") + 1
+
+			return "", errorf(tok, i, i+1, ErrSyntax, "unexpected ` in raw string literal")
+		}
+
+		return strings.ReplaceAll(text[1:], "\r", ""), nil
+	}
+
+	for i = 1; i < len(text); i = len(text) - len(tail) {
+		char, multibyte, tail, err = strconv.UnquoteChar(text[i:], quote)
+		switch {
+		case err == nil && multibyte:
+			sb.WriteRune(char)
+
+			continue
+		case err == nil:
+			sb.WriteByte(byte(char))
+
+			continue
+		}
+
+		_, size = utf8.DecodeRuneInString(text[i:])
+
+		if text[i] != '\\' {
+			return "", errorf(
+				tok,
+				i,
+				i+size,
+				ErrSyntax,
+				"unescaped %c in string literal",
+				quote,
+			)
+		}
+
+		return "", errorf(
+			tok,
+			i,
+			escapeEnd(text, i),
+			ErrSyntax,
+			"invalid escape sequence %s in string literal",
+			text[i:escapeEnd(text, i)],
+		)
+	}
+
+	return sb.String(), nil
+}
+
+// escapeEnd returns the end of the invalid escape sequence starting at
+// i in text: the backslash and the rune after it, extended over the
+// hexadecimal digits following a numeric escape.
+func escapeEnd(text string, i int) int {
+	var (
+		char rune
+		size int
+	)
+
+	i++
+	if i == len(text) {
+		return i
+	}
+
+	char, size = utf8.DecodeRuneInString(text[i:])
+	i += size
+
+	if !strings.ContainsRune("xuU01234567", char) {
+		return i
+	}
+
+	for i < len(text) && digitValue(rune(text[i])) < 16 {
+		i++
+	}
+
+	return i
+}
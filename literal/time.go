@@ -0,0 +1,105 @@
+package literal
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// TimeLayouts are the layouts tried by Time when given none: dates and
+// times with an offset as in RFC 3339, local dates and times separated
+// by T or a space, dates, and times of day, as in TOML. Fractional
+// seconds are accepted after the seconds of any of them.
+var TimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	time.DateTime,
+	time.DateOnly,
+	time.TimeOnly,
+}
+
+// Time evaluates a date and time literal with the first of layouts, as
+// in time.Parse, that accepts it, or with TimeLayouts if layouts is
+// empty. Values without an offset are in UTC.
+//
+// Returns an *Error wrapping ErrSyntax, spanning the text from where
+// the layout that went furthest failed to the end of the literal, or
+// wrapping ErrRange for an element out of range, such as the 31st of
+// April.
+func Time(tok lexer.Token, layouts ...string) (time.Time, error) {
+	var (
+		layout   string
+		value    time.Time
+		parseErr *time.ParseError
+		furthest *time.ParseError
+		start    int
+		err      error
+	)
+
+	if len(layouts) == 0 {
+		layouts = TimeLayouts
+	}
+
+	start = -1
+
+	for _, layout = range layouts {
+		value, err = time.Parse(layout, tok.Text)
+		if err == nil {
+			return value, nil
+		}
+
+		// Of layouts failing at the same position, the first is kept,
+		// unless a later one got as far as checking the ranges.
+		if errors.As(err, &parseErr) &&
+			(len(tok.Text)-len(parseErr.ValueElem) > start ||
+				len(tok.Text)-len(parseErr.ValueElem) == start &&
+					strings.HasSuffix(parseErr.Message, "out of range")) {
+			furthest = parseErr
+			start = len(tok.Text) - len(parseErr.ValueElem)
+		}
+	}
+
+	switch {
+	case furthest == nil:
+		return time.Time{}, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrSyntax,
+			"invalid date and time literal %s",
+			tok.Text,
+		)
+	case strings.HasSuffix(furthest.Message, "out of range"):
+		return time.Time{}, errorf(
+			tok,
+			0,
+			len(tok.Text),
+			ErrRange,
+			"date and time literal %s: %s",
+			tok.Text,
+			strings.TrimPrefix(furthest.Message, ": "),
+		)
+	case start == len(tok.Text):
+		return time.Time{}, errorf(
+			tok,
+			start,
+			start,
+			ErrSyntax,
+			"invalid date and time literal %s: missing %q",
+			tok.Text,
+			furthest.LayoutElem,
+		)
+	default:
+		return time.Time{}, errorf(
+			tok,
+			start,
+			len(tok.Text),
+			ErrSyntax,
+			"invalid date and time literal %s: unexpected %q",
+			tok.Text,
+			tok.Text[start:],
+		)
+	}
+}
@@ -0,0 +1,236 @@
+// Package markuplex provides helpers for lexing HTML and XML markup on
+// top of a lexer.Reader. Each helper recognises one recurring markup
+// construct, such as a tag name, a quoted attribute value, an entity
+// reference, a CDATA section, or a processing instruction, and consumes
+// it into the current token of the Reader.
+package markuplex // import "github.com/andrieee44/langengine/markuplex"
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// ErrUnterminated is returned by the helpers for delimited constructs
+// when the opening delimiter was consumed but the input ended before
+// the closing delimiter was found.
+var ErrUnterminated = errors.New("markuplex: unterminated construct")
+
+// AcceptName consumes an XML name: a name start character (a letter,
+// '_' or ':') followed by any number of name characters (name start
+// characters, digits, '-', '.', and combining marks). It is suitable
+// for tag names, attribute names, and processing instruction targets.
+//
+// Returns true if a name was consumed. Returns false, leaving the
+// reader untouched, if the next rune cannot start a name.
+func AcceptName(lrd *lexer.Reader) bool {
+	if !lrd.AcceptFunc(isNameStart) {
+		return false
+	}
+
+	lrd.AcceptRunFunc(isNameChar)
+
+	return true
+}
+
+// AcceptAttrValue consumes an attribute value. Values enclosed in
+// either double or single quotes are consumed along with both quotes.
+// Unquoted values, as permitted by HTML, are consumed up to the next
+// whitespace, quote, '=', '<', '>', or '`'.
+//
+// Returns true if a value was consumed. Returns false with a nil error,
+// leaving the reader untouched, if no value is present. Returns true
+// with ErrUnterminated if a quoted value reached EOF before its closing
+// quote.
+func AcceptAttrValue(lrd *lexer.Reader) (bool, error) {
+	var (
+		quote   rune
+		matched bool
+	)
+
+	quote = lrd.Peek()
+	if quote != '"' && quote != '\'' {
+		return lrd.AcceptRunFunc(isUnquotedValueChar) != 0, nil
+	}
+
+	lrd.Next()
+
+	_, matched = lrd.UntilInclusive(string(quote))
+	if !matched {
+		return true, ErrUnterminated
+	}
+
+	return true, nil
+}
+
+// AcceptEntity consumes a character or entity reference: a named
+// reference such as "&amp;", a decimal reference such as "&#38;", or a
+// hexadecimal reference such as "&#x26;". The reference must end with a
+// semicolon.
+//
+// Returns true if a complete reference was consumed. Returns false,
+// leaving the reader untouched, otherwise. Use DecodeEntity to obtain
+// the text a consumed reference stands for.
+func AcceptEntity(lrd *lexer.Reader) bool {
+	var (
+		count int
+		body  int
+	)
+
+	if !lrd.Accept("&") {
+		return false
+	}
+
+	count = 1
+
+	switch {
+	case lrd.AcceptSeq("#x") || lrd.AcceptSeq("#X"):
+		body = lrd.AcceptRunFunc(isHexDigit)
+		count += 2 + body
+	case lrd.Accept("#"):
+		body = lrd.AcceptRunFunc(isDigit)
+		count += 1 + body
+	case lrd.AcceptFunc(isNameStart):
+		body = 1 + lrd.AcceptRunFunc(isNameChar)
+		count += body
+	}
+
+	if body == 0 || !lrd.Accept(";") {
+		lrd.Backup(count)
+
+		return false
+	}
+
+	return true
+}
+
+// AcceptCDATA consumes a CDATA section, from "<![CDATA[" through the
+// closing "]]>".
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// input does not start with a CDATA section. Returns true with
+// ErrUnterminated if the input ended before "]]>".
+func AcceptCDATA(lrd *lexer.Reader) (bool, error) {
+	return acceptDelimited(lrd, "<![CDATA[", "]]>")
+}
+
+// AcceptComment consumes a markup comment, from "<!--" through the
+// closing "-->".
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// input does not start with a comment. Returns true with
+// ErrUnterminated if the input ended before "-->".
+func AcceptComment(lrd *lexer.Reader) (bool, error) {
+	return acceptDelimited(lrd, "<!--", "-->")
+}
+
+// AcceptPI consumes a processing instruction, from "<?" through the
+// closing "?>", such as an XML declaration or a stylesheet directive.
+//
+// Returns false with a nil error, leaving the reader untouched, if the
+// input does not start with a processing instruction. Returns true with
+// ErrUnterminated if the input ended before "?>".
+func AcceptPI(lrd *lexer.Reader) (bool, error) {
+	return acceptDelimited(lrd, "<?", "?>")
+}
+
+// DecodeEntity returns the text that a character or entity reference,
+// as consumed by AcceptEntity, stands for. Numeric references decode to
+// the referenced code point; named references are limited to the five
+// entities predefined by XML (amp, lt, gt, quot, and apos).
+//
+// The boolean is false if ref is not a well-formed reference, names an
+// unknown entity, or refers to an invalid code point.
+func DecodeEntity(ref string) (string, bool) {
+	var (
+		body  string
+		value uint64
+		err   error
+	)
+
+	if !strings.HasPrefix(ref, "&") || !strings.HasSuffix(ref, ";") {
+		return "", false
+	}
+
+	body = ref[1 : len(ref)-1]
+
+	switch {
+	case strings.HasPrefix(body, "#x"), strings.HasPrefix(body, "#X"):
+		value, err = strconv.ParseUint(body[2:], 16, 32)
+	case strings.HasPrefix(body, "#"):
+		value, err = strconv.ParseUint(body[1:], 10, 32)
+	default:
+		return decodeNamedEntity(body)
+	}
+
+	if err != nil || value == 0 || !utf8.ValidRune(rune(value)) {
+		return "", false
+	}
+
+	return string(rune(value)), true
+}
+
+func acceptDelimited(
+	lrd *lexer.Reader,
+	opener, closer string,
+) (bool, error) {
+	var matched bool
+
+	if !lrd.AcceptSeq(opener) {
+		return false, nil
+	}
+
+	_, matched = lrd.UntilSeqInclusive(closer)
+	if !matched {
+		return true, ErrUnterminated
+	}
+
+	return true, nil
+}
+
+func decodeNamedEntity(name string) (string, bool) {
+	switch name {
+	case "amp":
+		return "&", true
+	case "lt":
+		return "<", true
+	case "gt":
+		return ">", true
+	case "quot":
+		return `"`, true
+	case "apos":
+		return "'", true
+	default:
+		return "", false
+	}
+}
+
+func isNameStart(char rune) bool {
+	return char == '_' || char == ':' || unicode.IsLetter(char)
+}
+
+func isNameChar(char rune) bool {
+	return isNameStart(char) ||
+		char == '-' ||
+		char == '.' ||
+		unicode.IsDigit(char) ||
+		unicode.Is(unicode.Mn, char)
+}
+
+func isUnquotedValueChar(char rune) bool {
+	return !unicode.IsSpace(char) && !strings.ContainsRune("\"'=<>`", char)
+}
+
+func isDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+func isHexDigit(char rune) bool {
+	return isDigit(char) ||
+		'a' <= char && char <= 'f' ||
+		'A' <= char && char <= 'F'
+}
@@ -0,0 +1,272 @@
+package markuplex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/markuplex"
+	"github.com/stretchr/testify/assert"
+)
+
+type result struct {
+	matched bool
+	err     error
+}
+
+type testData struct {
+	content string
+	afterOp string
+	result  result
+}
+
+func assertTestDataTbl(
+	t *testing.T,
+	op func(*lexer.Reader) (bool, error),
+	testTbl map[string]testData,
+) {
+	var (
+		name string
+		test testData
+	)
+
+	t.Helper()
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				lrd *lexer.Reader
+				got result
+			)
+
+			lrd = lexer.NewReader(strings.NewReader(test.content))
+			got.matched, got.err = op(lrd)
+
+			assert.Equal(t, test.afterOp, lrd.PeekToken())
+			assert.Equal(t, test.result, got)
+		})
+	}
+}
+
+func noErr(fn func(*lexer.Reader) bool) func(*lexer.Reader) (bool, error) {
+	return func(lrd *lexer.Reader) (bool, error) {
+		return fn(lrd), nil
+	}
+}
+
+func TestAcceptName(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, noErr(markuplex.AcceptName), map[string]testData{
+		"Base": {
+			content: "div class",
+			afterOp: "div",
+			result:  result{matched: true},
+		},
+		"Namespaced": {
+			content: "xsl:value-of.x1>",
+			afterOp: "xsl:value-of.x1",
+			result:  result{matched: true},
+		},
+		"Unicode": {
+			content: "données=",
+			afterOp: "données",
+			result:  result{matched: true},
+		},
+		"LeadingDigit": {
+			content: "1div",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+		"Empty": {
+			content: "",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptAttrValue(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, markuplex.AcceptAttrValue, map[string]testData{
+		"DoubleQuoted": {
+			content: `"a 'b' c" d`,
+			afterOp: `"a 'b' c"`,
+			result:  result{matched: true},
+		},
+		"SingleQuoted": {
+			content: `'a "b" c' d`,
+			afterOp: `'a "b" c'`,
+			result:  result{matched: true},
+		},
+		"Unquoted": {
+			content: "main-nav>",
+			afterOp: "main-nav",
+			result:  result{matched: true},
+		},
+		"Unterminated": {
+			content: `"abc`,
+			afterOp: `"abc`,
+			result:  result{true, markuplex.ErrUnterminated},
+		},
+		"Missing": {
+			content: ">",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptEntity(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, noErr(markuplex.AcceptEntity), map[string]testData{
+		"Named": {
+			content: "&amp;b",
+			afterOp: "&amp;",
+			result:  result{matched: true},
+		},
+		"Decimal": {
+			content: "&#38;b",
+			afterOp: "&#38;",
+			result:  result{matched: true},
+		},
+		"Hex": {
+			content: "&#x1F600;b",
+			afterOp: "&#x1F600;",
+			result:  result{matched: true},
+		},
+		"MissingSemicolon": {
+			content: "&amp b",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+		"EmptyNumeric": {
+			content: "&#;",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+		"TruncatedHex": {
+			content: "&#",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+		"Bare": {
+			content: "& b",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptCDATA(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, markuplex.AcceptCDATA, map[string]testData{
+		"Base": {
+			content: "<![CDATA[a < b]]>c",
+			afterOp: "<![CDATA[a < b]]>",
+			result:  result{matched: true},
+		},
+		"Unterminated": {
+			content: "<![CDATA[a]]",
+			afterOp: "<![CDATA[a]]",
+			result:  result{true, markuplex.ErrUnterminated},
+		},
+		"NoMatch": {
+			content: "<![CDAT",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptComment(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, markuplex.AcceptComment, map[string]testData{
+		"Base": {
+			content: "<!-- a -- b -->c",
+			afterOp: "<!-- a -- b -->",
+			result:  result{matched: true},
+		},
+		"Unterminated": {
+			content: "<!-- a -",
+			afterOp: "<!-- a -",
+			result:  result{true, markuplex.ErrUnterminated},
+		},
+		"NoMatch": {
+			content: "<a>",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestAcceptPI(t *testing.T) {
+	t.Parallel()
+
+	assertTestDataTbl(t, markuplex.AcceptPI, map[string]testData{
+		"Base": {
+			content: `<?xml version="1.0"?><a>`,
+			afterOp: `<?xml version="1.0"?>`,
+			result:  result{matched: true},
+		},
+		"Unterminated": {
+			content: "<?xml",
+			afterOp: "<?xml",
+			result:  result{true, markuplex.ErrUnterminated},
+		},
+		"NoMatch": {
+			content: "<!x>",
+			afterOp: "",
+			result:  result{matched: false},
+		},
+	})
+}
+
+func TestDecodeEntity(t *testing.T) {
+	type testData struct {
+		text string
+		ok   bool
+	}
+
+	var (
+		testTbl map[string]testData
+		ref     string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"&amp;":      {"&", true},
+		"&lt;":       {"<", true},
+		"&apos;":     {"'", true},
+		"&#38;":      {"&", true},
+		"&#x1F600;":  {"😀", true},
+		"&#X41;":     {"A", true},
+		"&nbsp;":     {"", false},
+		"&#0;":       {"", false},
+		"&#x110000;": {"", false},
+		"&#xD800;":   {"", false},
+		"&#57343;":   {"", false},
+		"&#xZZ;":     {"", false},
+		"amp;":       {"", false},
+	}
+
+	for ref, test = range testTbl {
+		t.Run(ref, func(t *testing.T) {
+			var (
+				text string
+				ok   bool
+			)
+
+			text, ok = markuplex.DecodeEntity(ref)
+
+			assert.Equal(t, test.text, text)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
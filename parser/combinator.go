@@ -0,0 +1,348 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Parser is a parser combinator: a function parsing a T at in, which
+// returns the value and the input following it, and true, or false if
+// in does not start with a T. Parsers are built from the primitives
+// Kind, Text, and End, and combined with Seq, Choice, Many, Optional,
+// SepBy, and Map, for prototyping grammars before writing a hand-tuned
+// recursive-descent parser.
+//
+// Input values are immutable, so a failing alternative backtracks by
+// simply retrying from the input it was given. The failures of the
+// primitives are merged into the error of Parse, which reports the
+// furthest position any alternative reached and every token expected
+// there.
+type Parser[T any] func(in Input) (T, Input, bool)
+
+// Input is a position in the tokens read by Parse.
+type Input struct {
+	state *inputState
+	pos   int
+}
+
+type inputState struct {
+	tokens    []lexer.Token
+	furthest  int
+	expected  []string
+	memo      map[memoKey]*memoEntry
+	memoLimit int
+}
+
+// ParseOption configures Parse.
+type ParseOption func(*inputState)
+
+// Peek returns the token at in. The boolean is false at the end of the
+// input.
+func (in Input) Peek() (lexer.Token, bool) {
+	if in.pos >= len(in.state.tokens) {
+		return lexer.Token{}, false
+	}
+
+	return in.state.tokens[in.pos], true
+}
+
+// Advance returns the input following the token at in.
+func (in Input) Advance() Input {
+	in.pos = min(in.pos+1, len(in.state.tokens))
+
+	return in
+}
+
+// Offset returns the number of tokens preceding in.
+func (in Input) Offset() int {
+	return in.pos
+}
+
+// Expected records that a token described by what was expected at in,
+// such as "identifier" or `"("`, for the error of Parse. Custom
+// primitives call it when they fail.
+func (in Input) Expected(what string) {
+	switch {
+	case in.pos > in.state.furthest:
+		in.state.furthest = in.pos
+		in.state.expected = []string{what}
+	case in.pos == in.state.furthest && !slices.Contains(in.state.expected, what):
+		in.state.expected = append(in.state.expected, what)
+	}
+}
+
+// Parse parses the tokens of stream with p, leaving out trivia, and
+// requires p to consume them all.
+//
+// Returns a *SyntaxError at the furthest token reached by any
+// alternative, listing the tokens expected there, if p fails or leaves
+// tokens unread.
+func Parse[T any](
+	p Parser[T],
+	stream lexer.TokenStream,
+	opts ...ParseOption,
+) (T, error) {
+	var (
+		state *inputState
+		opt   ParseOption
+		tok   lexer.Token
+		value T
+		in    Input
+		ok    bool
+	)
+
+	state = &inputState{memo: make(map[memoKey]*memoEntry)}
+
+	for _, opt = range opts {
+		opt(state)
+	}
+
+	for tok = range lexer.Tokens(stream) {
+		if !tok.Trivia {
+			state.tokens = append(state.tokens, tok)
+		}
+	}
+
+	value, in, ok = p(Input{state: state})
+	if ok {
+		_, _, ok = End(in)
+	}
+
+	if !ok {
+		return value, state.err()
+	}
+
+	return value, nil
+}
+
+// err returns the error at the furthest position reached.
+func (state *inputState) err() *SyntaxError {
+	var (
+		tok   lexer.Token
+		found string
+		end   lexer.Position
+	)
+
+	if state.furthest < len(state.tokens) {
+		tok = state.tokens[state.furthest]
+		found = fmt.Sprintf("%q", tok.Text)
+	} else {
+		if len(state.tokens) != 0 {
+			end = state.tokens[len(state.tokens)-1].Span.End
+		}
+
+		tok = lexer.Token{Span: lexer.Span{Start: end, End: end}}
+		found = "end of input"
+	}
+
+	return errorf(tok, "expected %s, found %s", lexer.OrList(state.expected), found)
+}
+
+// Kind returns a Parser of a token of kind, described as name in
+// errors.
+func Kind(kind lexer.Kind, name string) Parser[lexer.Token] {
+	return token(name, func(tok lexer.Token) bool {
+		return tok.Kind == kind
+	})
+}
+
+// Text returns a Parser of a token with text, such as a keyword or a
+// punctuator.
+func Text(text string) Parser[lexer.Token] {
+	return token(fmt.Sprintf("%q", text), func(tok lexer.Token) bool {
+		return tok.Text == text
+	})
+}
+
+// End parses the end of the input.
+func End(in Input) (struct{}, Input, bool) {
+	var ok bool
+
+	_, ok = in.Peek()
+	if ok {
+		in.Expected("end of input")
+
+		return struct{}{}, in, false
+	}
+
+	return struct{}{}, in, true
+}
+
+// Seq returns a Parser of ps in sequence, returning their values in
+// order. It fails if any of ps fails.
+func Seq[T any](ps ...Parser[T]) Parser[[]T] {
+	return func(in Input) ([]T, Input, bool) {
+		var (
+			values []T
+			value  T
+			start  Input
+			p      Parser[T]
+			ok     bool
+		)
+
+		start = in
+
+		for _, p = range ps {
+			value, in, ok = p(in)
+			if !ok {
+				return nil, start, false
+			}
+
+			values = append(values, value)
+		}
+
+		return values, in, true
+	}
+}
+
+// Choice returns a Parser of the first of ps that succeeds, trying
+// each from the same input.
+func Choice[T any](ps ...Parser[T]) Parser[T] {
+	return func(in Input) (T, Input, bool) {
+		var (
+			value, zero T
+			next        Input
+			p           Parser[T]
+			ok          bool
+		)
+
+		for _, p = range ps {
+			value, next, ok = p(in)
+			if ok {
+				return value, next, true
+			}
+		}
+
+		return zero, in, false
+	}
+}
+
+// Many returns a Parser of zero or more repetitions of p, as many as
+// p succeeds. A repetition consuming no token ends the loop, so Many
+// of a parser that always succeeds terminates.
+func Many[T any](p Parser[T]) Parser[[]T] {
+	return func(in Input) ([]T, Input, bool) {
+		var (
+			values []T
+			value  T
+			next   Input
+			ok     bool
+		)
+
+		for {
+			value, next, ok = p(in)
+			if !ok || next.pos == in.pos {
+				return values, in, true
+			}
+
+			values = append(values, value)
+			in = next
+		}
+	}
+}
+
+// Optional returns a Parser of p or nothing, returning the zero value
+// of T if p fails.
+func Optional[T any](p Parser[T]) Parser[T] {
+	return func(in Input) (T, Input, bool) {
+		var (
+			value, zero T
+			next        Input
+			ok          bool
+		)
+
+		value, next, ok = p(in)
+		if !ok {
+			return zero, in, true
+		}
+
+		return value, next, true
+	}
+}
+
+// SepBy returns a Parser of zero or more repetitions of p separated by
+// sep, such as the arguments of a call, returning the values of p. A
+// trailing separator is left unread.
+func SepBy[T, S any](p Parser[T], sep Parser[S]) Parser[[]T] {
+	return func(in Input) ([]T, Input, bool) {
+		var (
+			values []T
+			value  T
+			next   Input
+			ok     bool
+		)
+
+		value, next, ok = p(in)
+		if !ok {
+			return nil, in, true
+		}
+
+		for ok {
+			values = append(values, value)
+			in = next
+
+			_, next, ok = sep(in)
+			if ok {
+				value, next, ok = p(next)
+			}
+		}
+
+		return values, in, true
+	}
+}
+
+// Map returns a Parser of p whose value is converted by fn, such as
+// into a node of a syntax tree.
+func Map[T, U any](p Parser[T], fn func(T) U) Parser[U] {
+	return func(in Input) (U, Input, bool) {
+		var (
+			value T
+			zero  U
+			next  Input
+			ok    bool
+		)
+
+		value, next, ok = p(in)
+		if !ok {
+			return zero, in, false
+		}
+
+		return fn(value), next, true
+	}
+}
+
+// Lazy returns a Parser that calls the parser returned by fn, which is
+// called once, on first use, for grammars whose rules refer to each
+// other.
+func Lazy[T any](fn func() Parser[T]) Parser[T] {
+	var p func() Parser[T]
+
+	p = sync.OnceValue(fn)
+
+	return func(in Input) (T, Input, bool) {
+		return p()(in)
+	}
+}
+
+// token returns a Parser of a token for which match returns true,
+// described as what in errors.
+func token(what string, match func(lexer.Token) bool) Parser[lexer.Token] {
+	return func(in Input) (lexer.Token, Input, bool) {
+		var (
+			tok lexer.Token
+			ok  bool
+		)
+
+		tok, ok = in.Peek()
+		if !ok || !match(tok) {
+			in.Expected(what)
+
+			return lexer.Token{}, in, false
+		}
+
+		return tok, in.Advance(), true
+	}
+}
@@ -0,0 +1,122 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/expr"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// call parses calls such as f(a, g(b)) into S-expressions.
+var call parser.Parser[string]
+
+func init() {
+	var (
+		ident parser.Parser[string]
+		args  parser.Parser[string]
+	)
+
+	ident = parser.Map(
+		parser.Choice(
+			parser.Kind(expr.KindIdent, "identifier"),
+			parser.Kind(expr.KindNumber, "number"),
+		),
+		func(tok lexer.Token) string {
+			return tok.Text
+		},
+	)
+	args = parser.Map(
+		parser.Seq(
+			parser.Map(parser.Text("("), tokenText),
+			parser.Map(
+				parser.SepBy(
+					parser.Lazy(func() parser.Parser[string] { return call }),
+					parser.Text(","),
+				),
+				func(args []string) string {
+					return strings.Join(args, " ")
+				},
+			),
+			parser.Map(parser.Text(")"), tokenText),
+		),
+		func(parts []string) string {
+			return parts[1]
+		},
+	)
+	call = parser.Map(
+		parser.Seq(ident, parser.Optional(args)),
+		func(parts []string) string {
+			if parts[1] == "" {
+				return parts[0]
+			}
+
+			return "(" + parts[0] + " " + parts[1] + ")"
+		},
+	)
+}
+
+func tokenText(tok lexer.Token) string {
+	return tok.Text
+}
+
+func TestParse(t *testing.T) {
+	type testData struct {
+		src  string
+		want string
+		err  string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		got     string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"operand":  {src: "a", want: "a"},
+		"call":     {src: "f(a, 1)", want: "(f a 1)"},
+		"nested":   {src: "f(g(a), b) # comment", want: "(f (g a) b)"},
+		"trailing": {src: "f(a,)", err: `1:5: expected identifier or number, found ")"`},
+		"merged":   {src: "f(a b)", err: `1:5: expected "(", ",", or ")", found "b"`},
+		"leftover": {src: "a b", err: `1:3: expected "(" or end of input, found "b"`},
+		"empty":    {src: "", err: `0:0: expected identifier or number, found end of input`},
+		"unclosed": {src: "f(a", err: `1:4: expected "(", ",", or ")", found end of input`},
+	}
+
+	for name, test = range testTbl {
+		got, err = parser.Parse(call, expr.NewLexer(strings.NewReader(test.src)))
+
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, got, name)
+	}
+}
+
+func TestMany(t *testing.T) {
+	var (
+		words []lexer.Token
+		err   error
+	)
+
+	t.Parallel()
+
+	words, err = parser.Parse(
+		parser.Many(parser.Optional(parser.Kind(expr.KindIdent, "identifier"))),
+		expr.NewLexer(strings.NewReader("a b c")),
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, words, 3)
+}
@@ -0,0 +1,103 @@
+package parser
+
+// memoRule identifies the parser of a Memo in the memo table. It is not
+// empty, so that every rule has a distinct address.
+type memoRule struct {
+	_ byte
+}
+
+type memoKey struct {
+	rule *memoRule
+	pos  int
+}
+
+type memoEntry struct {
+	value    any
+	next     Input
+	ok       bool
+	growing  bool
+	recursed bool
+}
+
+// WithMemoLimit bounds the number of results of Memo parsers retained
+// by a Parse, as the memo table of a long input can grow to several
+// entries for every token. Once the table holds limit entries, further
+// results are computed again whenever they are needed, trading time for
+// memory, except for the results of left-recursive rules being grown,
+// which are always retained while in progress. A limit of zero or less
+// retains every result, which is the default.
+func WithMemoLimit(limit int) ParseOption {
+	return func(state *inputState) {
+		state.memoLimit = limit
+	}
+}
+
+// Memo returns a Parser of p that remembers its result at every
+// position of the input, so that backtracking alternatives parsing the
+// same rule at the same position run p once, as in a packrat parser,
+// which parses in time linear in the input.
+//
+// Memo also makes p left-recursive, so rules ported from PEG grammars,
+// such as
+//
+//	expr = expr "-" term / term
+//
+// parse as written, grouping to the left. A recursive call of p at the
+// position it started from fails at first; p is then run again with the
+// result of the previous run in place of the recursive call, for as
+// long as that consumes more of the input. A left-recursive cycle
+// through several rules must pass through exactly one Memo, as the
+// results of the others depend on the run in progress and must not be
+// remembered.
+func Memo[T any](p Parser[T]) Parser[T] {
+	var rule *memoRule
+
+	rule = &memoRule{}
+
+	return func(in Input) (T, Input, bool) {
+		var (
+			key   memoKey
+			entry *memoEntry
+			value T
+			next  Input
+			ok    bool
+		)
+
+		key = memoKey{rule, in.pos}
+
+		entry, ok = in.state.memo[key]
+		if ok {
+			entry.recursed = entry.recursed || entry.growing
+			value, _ = entry.value.(T)
+
+			return value, entry.next, entry.ok
+		}
+
+		entry = &memoEntry{next: in, growing: true}
+		in.state.memo[key] = entry
+
+		for {
+			value, next, ok = p(in)
+
+			if !entry.recursed || !ok || entry.ok && next.pos <= entry.next.pos {
+				break
+			}
+
+			entry.value, entry.next, entry.ok = value, next, true
+		}
+
+		if !entry.recursed || !entry.ok {
+			entry.value, entry.next, entry.ok = value, next, ok
+		}
+
+		entry.growing = false
+
+		if in.state.memoLimit > 0 && len(in.state.memo) > in.state.memoLimit {
+			delete(in.state.memo, key)
+		}
+
+		value, _ = entry.value.(T)
+
+		return value, entry.next, entry.ok
+	}
+}
@@ -0,0 +1,114 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/expr"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// subtraction returns the left-recursive rule
+//
+//	expr = expr "-" number / number
+//
+// grouping into S-expressions.
+func subtraction() parser.Parser[string] {
+	var (
+		rule   parser.Parser[string]
+		number parser.Parser[string]
+	)
+
+	number = parser.Map(parser.Kind(expr.KindNumber, "number"), tokenText)
+	rule = parser.Memo(parser.Lazy(func() parser.Parser[string] {
+		return parser.Choice(
+			parser.Map(
+				parser.Seq(rule, parser.Map(parser.Text("-"), tokenText), number),
+				func(parts []string) string {
+					return "(- " + parts[0] + " " + parts[2] + ")"
+				},
+			),
+			number,
+		)
+	}))
+
+	return rule
+}
+
+func TestMemo(t *testing.T) {
+	type testData struct {
+		src  string
+		opts []parser.ParseOption
+		want string
+		err  string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		got     string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"single": {src: "1", want: "1"},
+		"left":   {src: "1 - 2 - 3", want: "(- (- 1 2) 3)"},
+		"limit": {
+			src:  "1 - 2 - 3 - 4",
+			opts: []parser.ParseOption{parser.WithMemoLimit(1)},
+			want: "(- (- (- 1 2) 3) 4)",
+		},
+		"dangling": {src: "1 - 2 -", err: `1:8: expected number, found end of input`},
+		"empty":    {src: "", err: `0:0: expected number, found end of input`},
+	}
+
+	for name, test = range testTbl {
+		got, err = parser.Parse(
+			subtraction(),
+			expr.NewLexer(strings.NewReader(test.src)),
+			test.opts...,
+		)
+
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, got, name)
+	}
+}
+
+func TestMemoOnce(t *testing.T) {
+	var (
+		calls int
+		ident parser.Parser[lexer.Token]
+		err   error
+	)
+
+	t.Parallel()
+
+	ident = parser.Memo(func(in parser.Input) (lexer.Token, parser.Input, bool) {
+		calls++
+
+		return parser.Kind(expr.KindIdent, "identifier")(in)
+	})
+
+	_, err = parser.Parse(
+		parser.Choice(
+			parser.Seq(ident, parser.Text("+")),
+			parser.Seq(ident, parser.Text("-")),
+			parser.Seq(ident),
+		),
+		expr.NewLexer(strings.NewReader("a")),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
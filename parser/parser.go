@@ -0,0 +1,49 @@
+// Package parser provides building blocks for parsers reading tokens
+// through a lexer.TokenReader, for grammars whose syntax is more than a
+// flat token stream. A PrecedenceTable declares the operators of an
+// expression grammar once, with their precedence and associativity, and
+// a Pratt parser turns it into an expression parser, so the precedence
+// of operators is not encoded in the structure of the code.
+//
+// For prototyping grammars, the Parser combinators build parsers from
+// smaller ones with Seq, Choice, Many, Optional, and SepBy, backtracking
+// automatically between alternatives. Rules wrapped in Memo are
+// memoized, as in a packrat parser, and may be left-recursive.
+//
+// Grammar engines that build parse trees, such as the peg and earley
+// packages, return them as Node values, which WriteJSON and WriteSExpr
+// dump with their spans for debugging and golden tests.
+//
+// Syntax errors are reported as *SyntaxError values, which carry a
+// lexer.Diagnostic spanning the offending token, so they are handled
+// like the diagnostics of the lexer.
+package parser // import "github.com/andrieee44/langengine/parser"
+
+import (
+	"fmt"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// SyntaxError is the error returned for input that does not conform to
+// a grammar.
+type SyntaxError struct {
+	// Diagnostic describes the error, spanning the offending token, or
+	// the empty span at the end of the input.
+	Diagnostic lexer.Diagnostic
+}
+
+// Error returns the diagnostic of the error, formatted as
+// "line:column: message".
+func (err *SyntaxError) Error() string {
+	return err.Diagnostic.String()
+}
+
+// errorf returns a *SyntaxError spanning tok with the formatted
+// message.
+func errorf(tok lexer.Token, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Diagnostic: lexer.Diagnostic{
+		Span:    tok.Span,
+		Message: fmt.Sprintf(format, args...),
+	}}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import "github.com/andrieee44/langengine/lexer"
+
+// Pratt is a top-down operator precedence parser of expressions whose
+// operators are declared in a PrecedenceTable. Pratt parses the
+// operators and leaves everything else to Operand, which parses
+// literals, identifiers, and parenthesized expressions, calling Parse
+// again for the expression within parentheses. Nodes of type N are
+// built by the callbacks.
+type Pratt[N any] struct {
+	// Table declares the operators.
+	Table *PrecedenceTable
+
+	// Kinds is the set of the kinds of operator tokens. A token whose
+	// kind is not in Kinds is never taken for an operator, so that a
+	// string literal "+" is not read as a plus. The empty set admits
+	// every kind.
+	Kinds lexer.KindSet
+
+	// Operand parses an operand at the next token of trd.
+	Operand func(trd *lexer.TokenReader) (N, error)
+
+	// Prefix builds the node of a prefix operator applied to operand.
+	// It may be nil if the table declares no prefix operator.
+	Prefix func(op lexer.Token, operand N) N
+
+	// Infix builds the node of an infix operator applied to left and
+	// right.
+	Infix func(op lexer.Token, left, right N) N
+
+	// Postfix builds the node of a postfix operator applied to operand.
+	// It may be nil if the table declares no postfix operator.
+	Postfix func(op lexer.Token, operand N) N
+}
+
+// Parse parses an expression at the next token of trd, stopping before
+// the first token that cannot continue it, such as a closing
+// parenthesis.
+//
+// Returns the error of Operand, or a *SyntaxError for a chain of
+// non-associative operators, such as a < b < c.
+func (pratt *Pratt[N]) Parse(trd *lexer.TokenReader) (N, error) {
+	return pratt.parse(trd, 0)
+}
+
+// parse parses an expression whose operators bind at least as tightly
+// as minPower. An operator of level L binds its left operand with power
+// 2L, and its right operand with power 2L+1 if it is left-associative
+// or a prefix operator, and 2L if it is right-associative, so that a
+// chain of operators of one level groups as declared.
+func (pratt *Pratt[N]) parse(trd *lexer.TokenReader, minPower int) (N, error) {
+	var (
+		left, right N
+		tok         lexer.Token
+		op          Operator
+		last        Operator
+		chained     bool
+		present     bool
+		ok          bool
+		err         error
+	)
+
+	tok, present = trd.Peek()
+
+	op, ok = pratt.operator(tok, present, Prefix)
+	if ok {
+		trd.NextToken()
+
+		right, err = pratt.parse(trd, 2*op.Level+1)
+		if err != nil {
+			return left, err
+		}
+
+		left = pratt.Prefix(tok, right)
+	} else {
+		left, err = pratt.Operand(trd)
+		if err != nil {
+			return left, err
+		}
+	}
+
+	for {
+		tok, present = trd.Peek()
+
+		op, ok = pratt.operator(tok, present, Postfix)
+		if ok {
+			if 2*op.Level < minPower {
+				break
+			}
+
+			trd.NextToken()
+			left = pratt.Postfix(tok, left)
+
+			continue
+		}
+
+		op, ok = pratt.operator(tok, present, Infix)
+		if !ok || 2*op.Level < minPower {
+			break
+		}
+
+		if chained && last.Level == op.Level && op.Assoc == AssocNone {
+			return left, errorf(
+				tok,
+				"operator %q is not associative and cannot follow %q",
+				op.Text,
+				last.Text,
+			)
+		}
+
+		trd.NextToken()
+
+		if op.Assoc == AssocRight {
+			right, err = pratt.parse(trd, 2*op.Level)
+		} else {
+			right, err = pratt.parse(trd, 2*op.Level+1)
+		}
+
+		if err != nil {
+			return left, err
+		}
+
+		left = pratt.Infix(tok, left, right)
+		last, chained = op, true
+	}
+
+	return left, nil
+}
+
+// operator returns the operator of the table with the text of tok and
+// fixity, if tok is present and of an operator kind.
+func (pratt *Pratt[N]) operator(
+	tok lexer.Token,
+	present bool,
+	fixity Fixity,
+) (Operator, bool) {
+	if !present || pratt.Kinds.Len() != 0 && !pratt.Kinds.Has(tok.Kind) {
+		return Operator{}, false
+	}
+
+	return pratt.Table.Lookup(tok.Text, fixity)
+}
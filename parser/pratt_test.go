@@ -0,0 +1,103 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/examples/expr"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseExpr parses src with the operators of arithmetic into an
+// S-expression.
+func parseExpr(src string) (string, error) {
+	var (
+		pratt *parser.Pratt[string]
+		trd   *lexer.TokenReader
+	)
+
+	pratt = &parser.Pratt[string]{
+		Table: arithmetic,
+		Kinds: lexer.NewKindSet(expr.KindOperator),
+		Prefix: func(op lexer.Token, operand string) string {
+			return "(" + op.Text + " " + operand + ")"
+		},
+		Infix: func(op lexer.Token, left, right string) string {
+			return "(" + op.Text + " " + left + " " + right + ")"
+		},
+		Postfix: func(op lexer.Token, operand string) string {
+			return "(" + operand + " " + op.Text + ")"
+		},
+	}
+	pratt.Operand = func(trd *lexer.TokenReader) (string, error) {
+		var (
+			tok   lexer.Token
+			inner string
+			err   error
+		)
+
+		tok, _ = trd.NextToken()
+		if tok.Kind != expr.KindLeftParen {
+			return tok.Text, nil
+		}
+
+		inner, err = pratt.Parse(trd)
+		trd.NextToken()
+
+		return inner, err
+	}
+
+	trd = lexer.NewTokenReader(expr.NewLexer(strings.NewReader(src)))
+
+	return pratt.Parse(trd)
+}
+
+func TestPratt(t *testing.T) {
+	type testData struct {
+		src  string
+		want string
+		err  string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		got     string
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"operand":     {src: "a", want: "a"},
+		"precedence":  {src: "a + b * c", want: "(+ a (* b c))"},
+		"left":        {src: "a - b - c", want: "(- (- a b) c)"},
+		"right":       {src: "a ?? b ?? c", want: "(?? a (?? b c))"},
+		"prefix":      {src: "-a * b", want: "(* (- a) b)"},
+		"postfix":     {src: "-a! + b", want: "(+ (- (a !)) b)"},
+		"nested":      {src: "-(a + b) * c", want: "(* (- (+ a b)) c)"},
+		"parentheses": {src: "a < (b < c)", want: "(< a (< b c))"},
+		"stops":       {src: "a + b )", want: "(+ a b)"},
+		"string":      {src: `a "+" b`, want: "a"},
+		"non-associative": {
+			src: "a < b == c",
+			err: `1:7: operator "==" is not associative and cannot follow "<"`,
+		},
+	}
+
+	for name, test = range testTbl {
+		got, err = parseExpr(test.src)
+
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, got, name)
+	}
+}
@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Fixity is the position of an operator relative to its operands.
+type Fixity int
+
+const (
+	// Infix operators stand between two operands, as in a + b.
+	Infix Fixity = iota
+
+	// Prefix operators precede their operand, as in -a.
+	Prefix
+
+	// Postfix operators follow their operand, as in a!.
+	Postfix
+)
+
+// Assoc is the associativity of an infix operator, which decides how a
+// chain of operators of the same precedence groups.
+type Assoc int
+
+const (
+	// AssocLeft groups a - b - c as (a - b) - c.
+	AssocLeft Assoc = iota
+
+	// AssocRight groups a ^ b ^ c as a ^ (b ^ c).
+	AssocRight
+
+	// AssocNone rejects chains such as a < b < c.
+	AssocNone
+)
+
+var (
+	// ErrPrecedenceGap is returned by NewPrecedenceTable for operators
+	// whose levels do not run from 1 without a gap.
+	ErrPrecedenceGap = errors.New("parser: gap in precedence levels")
+
+	// ErrPrecedenceConflict is returned by NewPrecedenceTable for an
+	// operator declared twice with the same fixity, or for infix
+	// operators of the same level with different associativities.
+	ErrPrecedenceConflict = errors.New("parser: conflicting operators")
+)
+
+// Operator declares an operator of an expression grammar.
+type Operator struct {
+	// Text is the text of the operator token, such as "+" or "and".
+	Text string
+
+	// Fixity is the position of the operator. An operator may be
+	// declared once for every fixity, as "-" commonly is for Infix and
+	// Prefix.
+	Fixity Fixity
+
+	// Level is the precedence of the operator, from 1 for the loosest
+	// binding. Operators of higher levels group first.
+	Level int
+
+	// Assoc is the associativity of an Infix operator. It is ignored for
+	// other fixities.
+	Assoc Assoc
+}
+
+// PrecedenceTable holds the operators of an expression grammar, for
+// looking up their precedence and associativity while parsing and for
+// printing them in documentation. A new PrecedenceTable is constructed
+// with NewPrecedenceTable and is safe for concurrent use.
+type PrecedenceTable struct {
+	ops    []Operator
+	index  map[operatorKey]Operator
+	levels int
+}
+
+type operatorKey struct {
+	text   string
+	fixity Fixity
+}
+
+// NewPrecedenceTable returns a PrecedenceTable of ops.
+//
+// Returns ErrPrecedenceGap if a level below the highest has no
+// operator or a level is below 1, and ErrPrecedenceConflict if an
+// operator is declared twice with the same fixity or infix operators of
+// the same level differ in associativity, which would leave the
+// grouping of a chain mixing them undecided.
+func NewPrecedenceTable(ops ...Operator) (*PrecedenceTable, error) {
+	var (
+		tbl   *PrecedenceTable
+		assoc map[int]Assoc
+		used  []bool
+		op    Operator
+		key   operatorKey
+		prev  Assoc
+		ok    bool
+		level int
+	)
+
+	tbl = &PrecedenceTable{
+		ops:   slices.Clone(ops),
+		index: make(map[operatorKey]Operator, len(ops)),
+	}
+	assoc = make(map[int]Assoc)
+
+	for _, op = range ops {
+		if op.Level < 1 {
+			return nil, fmt.Errorf(
+				"%w: operator %q at level %d",
+				ErrPrecedenceGap,
+				op.Text,
+				op.Level,
+			)
+		}
+
+		key = operatorKey{op.Text, op.Fixity}
+
+		_, ok = tbl.index[key]
+		if ok {
+			return nil, fmt.Errorf(
+				"%w: %s operator %q declared twice",
+				ErrPrecedenceConflict,
+				op.Fixity,
+				op.Text,
+			)
+		}
+
+		if op.Fixity == Infix {
+			prev, ok = assoc[op.Level]
+			if ok && prev != op.Assoc {
+				return nil, fmt.Errorf(
+					"%w: infix operator %q is %s-associative at level %d, where others are %s",
+					ErrPrecedenceConflict,
+					op.Text,
+					op.Assoc,
+					op.Level,
+					prev,
+				)
+			}
+
+			assoc[op.Level] = op.Assoc
+		}
+
+		tbl.index[key] = op
+		tbl.levels = max(tbl.levels, op.Level)
+	}
+
+	used = make([]bool, tbl.levels+1)
+
+	for _, op = range ops {
+		used[op.Level] = true
+	}
+
+	for level = 1; level <= tbl.levels; level++ {
+		if !used[level] {
+			return nil, fmt.Errorf("%w: no operator at level %d", ErrPrecedenceGap, level)
+		}
+	}
+
+	return tbl, nil
+}
+
+// MustPrecedenceTable is like NewPrecedenceTable but panics if the
+// operators are invalid. It simplifies the initialization of global
+// tables.
+func MustPrecedenceTable(ops ...Operator) *PrecedenceTable {
+	var (
+		tbl *PrecedenceTable
+		err error
+	)
+
+	tbl, err = NewPrecedenceTable(ops...)
+	if err != nil {
+		panic(err)
+	}
+
+	return tbl
+}
+
+// Lookup returns the operator declared with text and fixity. The
+// boolean is false if there is none.
+func (tbl *PrecedenceTable) Lookup(text string, fixity Fixity) (Operator, bool) {
+	var (
+		op Operator
+		ok bool
+	)
+
+	op, ok = tbl.index[operatorKey{text, fixity}]
+
+	return op, ok
+}
+
+// Levels returns the highest precedence level of the table, which is
+// also the number of levels.
+func (tbl *PrecedenceTable) Levels() int {
+	return tbl.levels
+}
+
+// Operators returns the operators of the table, from the highest level
+// down, in the order they were declared within a level.
+func (tbl *PrecedenceTable) Operators() []Operator {
+	var ops []Operator
+
+	ops = slices.Clone(tbl.ops)
+	slices.SortStableFunc(ops, func(a, b Operator) int {
+		return b.Level - a.Level
+	})
+
+	return ops
+}
+
+// String formats the table for documentation, one line for every level
+// and fixity, from the highest level down:
+//
+//	level  fixity  assoc  operators
+//	3      prefix         - !
+//	2      infix   left   * /
+//	1      infix   left   + -
+func (tbl *PrecedenceTable) String() string {
+	var (
+		sb    strings.Builder
+		tw    *tabwriter.Writer
+		ops   []Operator
+		texts []string
+		assoc string
+		i, j  int
+	)
+
+	tw = tabwriter.NewWriter(&sb, 0, 8, 2, ' ', 0)
+	ops = tbl.Operators()
+	slices.SortStableFunc(ops, func(a, b Operator) int {
+		return cmp.Or(b.Level-a.Level, int(a.Fixity-b.Fixity))
+	})
+
+	fmt.Fprintln(tw, "level\tfixity\tassoc\toperators")
+
+	for i = 0; i < len(ops); i = j {
+		texts = texts[:0]
+
+		for j = i; j < len(ops) &&
+			ops[j].Level == ops[i].Level &&
+			ops[j].Fixity == ops[i].Fixity; j++ {
+			texts = append(texts, ops[j].Text)
+		}
+
+		assoc = ""
+		if ops[i].Fixity == Infix {
+			assoc = ops[i].Assoc.String()
+		}
+
+		fmt.Fprintf(
+			tw,
+			"%d\t%s\t%s\t%s\n",
+			ops[i].Level,
+			ops[i].Fixity,
+			assoc,
+			strings.Join(texts, " "),
+		)
+	}
+
+	tw.Flush()
+
+	return sb.String()
+}
+
+// String returns the lower-case name of the fixity, such as "infix".
+func (fixity Fixity) String() string {
+	switch fixity {
+	case Infix:
+		return "infix"
+	case Prefix:
+		return "prefix"
+	case Postfix:
+		return "postfix"
+	default:
+		return "Fixity(" + strconv.Itoa(int(fixity)) + ")"
+	}
+}
+
+// String returns the lower-case name of the associativity, such as
+// "left".
+func (assoc Assoc) String() string {
+	switch assoc {
+	case AssocLeft:
+		return "left"
+	case AssocRight:
+		return "right"
+	case AssocNone:
+		return "none"
+	default:
+		return "Assoc(" + strconv.Itoa(int(assoc)) + ")"
+	}
+}
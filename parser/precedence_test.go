@@ -0,0 +1,105 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var arithmetic = parser.MustPrecedenceTable(
+	parser.Operator{Text: "??", Level: 1, Assoc: parser.AssocRight},
+	parser.Operator{Text: "==", Level: 2, Assoc: parser.AssocNone},
+	parser.Operator{Text: "<", Level: 2, Assoc: parser.AssocNone},
+	parser.Operator{Text: "+", Level: 3},
+	parser.Operator{Text: "-", Level: 3},
+	parser.Operator{Text: "*", Level: 4},
+	parser.Operator{Text: "/", Level: 4},
+	parser.Operator{Text: "-", Fixity: parser.Prefix, Level: 5},
+	parser.Operator{Text: "!", Fixity: parser.Postfix, Level: 6},
+)
+
+func TestNewPrecedenceTable(t *testing.T) {
+	type testData struct {
+		ops []parser.Operator
+		err error
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"empty": {},
+		"valid": {
+			ops: []parser.Operator{
+				{Text: "-", Level: 1},
+				{Text: "-", Fixity: parser.Prefix, Level: 2},
+			},
+		},
+		"gap": {
+			ops: []parser.Operator{{Text: "+", Level: 1}, {Text: "*", Level: 3}},
+			err: parser.ErrPrecedenceGap,
+		},
+		"zero level": {
+			ops: []parser.Operator{{Text: "+"}},
+			err: parser.ErrPrecedenceGap,
+		},
+		"duplicate": {
+			ops: []parser.Operator{{Text: "+", Level: 1}, {Text: "+", Level: 2}},
+			err: parser.ErrPrecedenceConflict,
+		},
+		"mixed associativity": {
+			ops: []parser.Operator{
+				{Text: "+", Level: 1},
+				{Text: "^", Level: 1, Assoc: parser.AssocRight},
+			},
+			err: parser.ErrPrecedenceConflict,
+		},
+	}
+
+	for name, test = range testTbl {
+		_, err = parser.NewPrecedenceTable(test.ops...)
+		assert.ErrorIs(t, err, test.err, name)
+	}
+
+	assert.Panics(t, func() {
+		parser.MustPrecedenceTable(parser.Operator{Text: "+", Level: 2})
+	})
+}
+
+func TestPrecedenceTable(t *testing.T) {
+	var (
+		op parser.Operator
+		ok bool
+	)
+
+	t.Parallel()
+
+	op, ok = arithmetic.Lookup("-", parser.Prefix)
+	assert.True(t, ok)
+	assert.Equal(t, 5, op.Level)
+
+	_, ok = arithmetic.Lookup("*", parser.Prefix)
+	assert.False(t, ok)
+
+	assert.Equal(t, 6, arithmetic.Levels())
+	assert.Equal(t, "!", arithmetic.Operators()[0].Text)
+	assert.Equal(t, "Fixity(3)", parser.Fixity(3).String())
+	assert.Equal(t, "Assoc(3)", parser.Assoc(3).String())
+	assert.Equal(t, ""+
+		"level  fixity   assoc  operators\n"+
+		"6      postfix         !\n"+
+		"5      prefix          -\n"+
+		"4      infix    left   * /\n"+
+		"3      infix    left   + -\n"+
+		"2      infix    none   == <\n"+
+		"1      infix    right  ??\n",
+		arithmetic.String(),
+	)
+}
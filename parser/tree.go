@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Node is a node of a parse tree, as produced by grammar engines such
+// as the peg package, for consumers that walk a tree instead of building
+// their own syntax tree as they parse.
+type Node struct {
+	// Rule is the name of the grammar rule the node matched.
+	Rule string `json:",omitempty"`
+
+	// Text is the source text the node spans, or, for engines reading
+	// tokens without the text between them, its tokens joined by spaces.
+	Text string `json:",omitempty"`
+
+	// Span is the region of the input the node matched.
+	Span lexer.Span
+
+	// Children are the nodes of the rules matched within the node, in
+	// source order.
+	Children []*Node `json:",omitempty"`
+}
+
+// WriteJSON writes the tree rooted at node to w as indented JSON, with
+// the fields of Node and the spans in full, followed by a newline. The
+// output is stable, so it suits golden files of grammar tests.
+//
+// Returns the error of w, if any.
+func WriteJSON(w io.Writer, node *Node) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	data, err = json.MarshalIndent(node, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+
+	return err
+}
+
+// WriteSExpr writes the tree rooted at node to w as an S-expression,
+// one node per line indented by depth, followed by a newline. A node is
+// written as its rule, its span as "line:column-line:column", and, for
+// a leaf, its quoted text:
+//
+//	(Sum 1:1-1:6
+//		(Number 1:1-1:2 "1")
+//		(Number 1:5-1:6 "2"))
+//
+// Returns the error of w, if any.
+func WriteSExpr(w io.Writer, node *Node) error {
+	var (
+		sb  strings.Builder
+		err error
+	)
+
+	node.sexpr(&sb, 0)
+	sb.WriteByte('\n')
+
+	_, err = io.WriteString(w, sb.String())
+
+	return err
+}
+
+// String returns the tree rooted at node as an S-expression on a
+// single line, as in (Sum 1:1-1:6 (Number 1:1-1:2 "1")).
+func (node *Node) String() string {
+	var sb strings.Builder
+
+	node.sexpr(&sb, -1)
+
+	return sb.String()
+}
+
+// sexpr writes the S-expression of node to sb at depth, or on a single
+// line if depth is negative.
+func (node *Node) sexpr(sb *strings.Builder, depth int) {
+	var child *Node
+
+	sb.WriteByte('(')
+
+	if node.Rule != "" {
+		sb.WriteString(node.Rule)
+		sb.WriteByte(' ')
+	}
+
+	fmt.Fprintf(
+		sb,
+		"%d:%d-%d:%d",
+		node.Span.Start.Line,
+		node.Span.Start.Column,
+		node.Span.End.Line,
+		node.Span.End.Column,
+	)
+
+	if len(node.Children) == 0 {
+		fmt.Fprintf(sb, " %q", node.Text)
+	}
+
+	for _, child = range node.Children {
+		if depth < 0 {
+			sb.WriteByte(' ')
+			child.sexpr(sb, depth)
+
+			continue
+		}
+
+		sb.WriteByte('\n')
+		sb.WriteString(strings.Repeat("\t", depth+1))
+		child.sexpr(sb, depth+1)
+	}
+
+	sb.WriteByte(')')
+}
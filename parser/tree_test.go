@@ -0,0 +1,98 @@
+package parser_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// span returns the span on line 1 from column start to column end.
+func span(start, end int) lexer.Span {
+	return lexer.Span{
+		Start: lexer.Position{Line: 1, Column: start, Offset: start - 1},
+		End:   lexer.Position{Line: 1, Column: end, Offset: end - 1},
+	}
+}
+
+// sumTree is the tree of "1 + (2)".
+var sumTree = &parser.Node{
+	Rule: "Sum",
+	Text: "1 + (2)",
+	Span: span(1, 8),
+	Children: []*parser.Node{
+		{Rule: "Number", Text: "1", Span: span(1, 2)},
+		{Rule: "Group", Text: "(2)", Span: span(5, 8), Children: []*parser.Node{
+			{Rule: "Number", Text: "2", Span: span(6, 7)},
+		}},
+	},
+}
+
+func TestWriteSExpr(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Parallel()
+
+	assert.NoError(t, parser.WriteSExpr(&buf, sumTree))
+	assert.Equal(t, `(Sum 1:1-1:8
+	(Number 1:1-1:2 "1")
+	(Group 1:5-1:8
+		(Number 1:6-1:7 "2")))
+`, buf.String())
+}
+
+func TestNodeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		`(Sum 1:1-1:8 (Number 1:1-1:2 "1") (Group 1:5-1:8 (Number 1:6-1:7 "2")))`,
+		sumTree.String(),
+	)
+	assert.Equal(t, `(1:1-1:2 "x")`, (&parser.Node{Text: "x", Span: span(1, 2)}).String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Parallel()
+
+	assert.NoError(t, parser.WriteJSON(&buf, sumTree.Children[1]))
+	assert.Equal(t, `{
+	"Rule": "Group",
+	"Text": "(2)",
+	"Span": {
+		"Start": {
+			"Line": 1,
+			"Column": 5,
+			"Offset": 4
+		},
+		"End": {
+			"Line": 1,
+			"Column": 8,
+			"Offset": 7
+		}
+	},
+	"Children": [
+		{
+			"Rule": "Number",
+			"Text": "2",
+			"Span": {
+				"Start": {
+					"Line": 1,
+					"Column": 6,
+					"Offset": 5
+				},
+				"End": {
+					"Line": 1,
+					"Column": 7,
+					"Offset": 6
+				}
+			}
+		}
+	]
+}
+`, buf.String())
+}
@@ -0,0 +1,489 @@
+package peg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type op int
+
+const (
+	opLiteral op = iota
+	opClass
+	opAny
+	opRule
+	opSeq
+	opChoice
+	opOptional
+	opStar
+	opPlus
+	opAnd
+	opNot
+)
+
+// expr is a parsing expression.
+type expr struct {
+	op op
+
+	// text is the text of a literal, or the name of a rule.
+	text string
+
+	// desc describes a terminal in errors, as written in the grammar.
+	desc string
+
+	// ranges holds the sorted pairs of inclusive bounds of a class.
+	ranges  []rune
+	negated bool
+
+	// rule is the index of the rule referred to.
+	rule int
+
+	subs []*expr
+}
+
+// compiler parses the text of a grammar.
+type compiler struct {
+	src string
+	pos int
+}
+
+// matches reports whether char matches a class or the any expression.
+func (e *expr) matches(char rune) bool {
+	var i int
+
+	if e.op == opAny {
+		return true
+	}
+
+	for i = 0; i < len(e.ranges); i += 2 {
+		if e.ranges[i] <= char && char <= e.ranges[i+1] {
+			return !e.negated
+		}
+	}
+
+	return e.negated
+}
+
+// resolve sets the index of every rule referred to within e.
+func (e *expr) resolve(names map[string]int) error {
+	var (
+		sub *expr
+		ok  bool
+		err error
+	)
+
+	if e.op == opRule {
+		e.rule, ok = names[e.text]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUndefinedRule, e.text)
+		}
+	}
+
+	for _, sub = range e.subs {
+		err = sub.resolve(names)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// definition parses Name <- Expression.
+func (cp *compiler) definition() (rule, error) {
+	var (
+		name string
+		body *expr
+		err  error
+	)
+
+	name = cp.identifier()
+	if name == "" {
+		return rule{}, cp.errorf("expected rule name")
+	}
+
+	if !cp.accept("<-") {
+		return rule{}, cp.errorf("expected <- after rule name %q", name)
+	}
+
+	body, err = cp.choice()
+	if err != nil {
+		return rule{}, err
+	}
+
+	return rule{name: name, body: body}, nil
+}
+
+// choice parses Sequence ("/" Sequence)*.
+func (cp *compiler) choice() (*expr, error) {
+	var (
+		e, alt *expr
+		err    error
+	)
+
+	e, err = cp.sequence()
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(cp.src[cp.pos:], "/") {
+		return e, nil
+	}
+
+	e = &expr{op: opChoice, subs: []*expr{e}}
+
+	for cp.accept("/") {
+		alt, err = cp.sequence()
+		if err != nil {
+			return nil, err
+		}
+
+		e.subs = append(e.subs, alt)
+	}
+
+	return e, nil
+}
+
+// sequence parses Prefix*, ending before a "/", a ")", the end of the
+// grammar, or the start of the next definition.
+func (cp *compiler) sequence() (*expr, error) {
+	var (
+		e, item *expr
+		err     error
+	)
+
+	e = &expr{op: opSeq}
+
+	for cp.pos < len(cp.src) &&
+		!strings.ContainsRune("/)", rune(cp.src[cp.pos])) &&
+		!cp.atDefinition() {
+		item, err = cp.prefix()
+		if err != nil {
+			return nil, err
+		}
+
+		e.subs = append(e.subs, item)
+	}
+
+	if len(e.subs) == 1 {
+		return e.subs[0], nil
+	}
+
+	return e, nil
+}
+
+// prefix parses ("&" / "!")? Suffix.
+func (cp *compiler) prefix() (*expr, error) {
+	var (
+		e   *expr
+		op  op
+		ok  bool
+		err error
+	)
+
+	switch {
+	case cp.accept("&"):
+		op, ok = opAnd, true
+	case cp.accept("!"):
+		op, ok = opNot, true
+	}
+
+	e, err = cp.suffix()
+	if err != nil || !ok {
+		return e, err
+	}
+
+	return &expr{op: op, subs: []*expr{e}}, nil
+}
+
+// suffix parses Primary ("?" / "*" / "+")?.
+func (cp *compiler) suffix() (*expr, error) {
+	var (
+		e   *expr
+		err error
+	)
+
+	e, err = cp.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cp.accept("?"):
+		return &expr{op: opOptional, subs: []*expr{e}}, nil
+	case cp.accept("*"):
+		return &expr{op: opStar, subs: []*expr{e}}, nil
+	case cp.accept("+"):
+		return &expr{op: opPlus, subs: []*expr{e}}, nil
+	default:
+		return e, nil
+	}
+}
+
+// primary parses a rule name, a group, a literal, a class, or ".".
+func (cp *compiler) primary() (*expr, error) {
+	var (
+		e    *expr
+		name string
+		err  error
+	)
+
+	switch {
+	case cp.accept("("):
+		e, err = cp.choice()
+		if err != nil {
+			return nil, err
+		}
+
+		if !cp.accept(")") {
+			return nil, cp.errorf("expected )")
+		}
+
+		return e, nil
+	case cp.accept("."):
+		return &expr{op: opAny, desc: "any character"}, nil
+	case strings.HasPrefix(cp.src[cp.pos:], `"`),
+		strings.HasPrefix(cp.src[cp.pos:], "'"):
+		return cp.literal()
+	case strings.HasPrefix(cp.src[cp.pos:], "["):
+		return cp.class()
+	}
+
+	name = cp.identifier()
+	if name == "" {
+		return nil, cp.errorf("unexpected %q", cp.src[cp.pos:cp.pos+1])
+	}
+
+	return &expr{op: opRule, text: name}, nil
+}
+
+// literal parses a quoted literal.
+func (cp *compiler) literal() (*expr, error) {
+	var (
+		quote byte
+		start int
+		sb    strings.Builder
+		char  rune
+		tail  string
+		desc  string
+		err   error
+	)
+
+	quote = cp.src[cp.pos]
+	start = cp.pos
+	cp.pos++
+
+	for {
+		if cp.pos >= len(cp.src) || cp.src[cp.pos] == '\n' {
+			cp.pos = start
+
+			return nil, cp.errorf("unterminated literal")
+		}
+
+		if cp.src[cp.pos] == quote {
+			cp.pos++
+
+			break
+		}
+
+		char, _, tail, err = strconv.UnquoteChar(cp.src[cp.pos:], quote)
+		if err != nil {
+			return nil, cp.errorf("invalid escape in literal")
+		}
+
+		sb.WriteRune(char)
+		cp.pos = len(cp.src) - len(tail)
+	}
+
+	desc = cp.src[start:cp.pos]
+	cp.space()
+
+	return &expr{op: opLiteral, text: sb.String(), desc: desc}, nil
+}
+
+// class parses a character class.
+func (cp *compiler) class() (*expr, error) {
+	var (
+		e      *expr
+		start  int
+		lo, hi rune
+		err    error
+	)
+
+	e = &expr{op: opClass}
+	start = cp.pos
+	cp.pos++
+
+	if strings.HasPrefix(cp.src[cp.pos:], "^") {
+		e.negated = true
+		cp.pos++
+	}
+
+	for !strings.HasPrefix(cp.src[cp.pos:], "]") {
+		lo, err = cp.classChar(start)
+		if err != nil {
+			return nil, err
+		}
+
+		hi = lo
+
+		if strings.HasPrefix(cp.src[cp.pos:], "-") &&
+			!strings.HasPrefix(cp.src[cp.pos:], "-]") {
+			cp.pos++
+
+			hi, err = cp.classChar(start)
+			if err != nil {
+				return nil, err
+			}
+
+			if hi < lo {
+				return nil, cp.errorf("invalid range in class")
+			}
+		}
+
+		e.ranges = append(e.ranges, lo, hi)
+	}
+
+	cp.pos++
+	e.desc = cp.src[start:cp.pos]
+	cp.space()
+
+	return e, nil
+}
+
+// classChar parses a character of a class starting at start, which may
+// be escaped with a backslash.
+func (cp *compiler) classChar(start int) (rune, error) {
+	var (
+		char rune
+		size int
+		tail string
+		err  error
+	)
+
+	if cp.pos >= len(cp.src) || cp.src[cp.pos] == '\n' {
+		cp.pos = start
+
+		return 0, cp.errorf("unterminated class")
+	}
+
+	if strings.HasPrefix(cp.src[cp.pos:], `\`) && cp.pos+1 < len(cp.src) &&
+		strings.ContainsRune(`[]^-`, rune(cp.src[cp.pos+1])) {
+		cp.pos += 2
+
+		return rune(cp.src[cp.pos-1]), nil
+	}
+
+	if cp.src[cp.pos] != '\\' {
+		char, size = utf8.DecodeRuneInString(cp.src[cp.pos:])
+		cp.pos += size
+
+		return char, nil
+	}
+
+	char, _, tail, err = strconv.UnquoteChar(cp.src[cp.pos:], 0)
+	if err != nil {
+		return 0, cp.errorf("invalid escape in class")
+	}
+
+	cp.pos = len(cp.src) - len(tail)
+
+	return char, nil
+}
+
+// identifier parses a rule name, returning the empty string if there
+// is none.
+func (cp *compiler) identifier() string {
+	var (
+		start int
+		char  rune
+		size  int
+		name  string
+	)
+
+	start = cp.pos
+
+	for cp.pos < len(cp.src) {
+		char, size = utf8.DecodeRuneInString(cp.src[cp.pos:])
+		if char != '_' && !unicode.IsLetter(char) &&
+			(cp.pos == start || !unicode.IsDigit(char)) {
+			break
+		}
+
+		cp.pos += size
+	}
+
+	name = cp.src[start:cp.pos]
+	cp.space()
+
+	return name
+}
+
+// atDefinition reports whether the grammar continues with the start of
+// a definition, a rule name followed by "<-".
+func (cp *compiler) atDefinition() bool {
+	var (
+		pos   int
+		name  string
+		found bool
+	)
+
+	pos = cp.pos
+	name = cp.identifier()
+	found = name != "" && strings.HasPrefix(cp.src[cp.pos:], "<-")
+	cp.pos = pos
+
+	return found
+}
+
+// accept consumes tok and the spacing following it, if the grammar
+// continues with tok.
+func (cp *compiler) accept(tok string) bool {
+	if !strings.HasPrefix(cp.src[cp.pos:], tok) {
+		return false
+	}
+
+	cp.pos += len(tok)
+	cp.space()
+
+	return true
+}
+
+// space skips white space and comments.
+func (cp *compiler) space() {
+	for cp.pos < len(cp.src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(cp.src[cp.pos])):
+			cp.pos++
+		case cp.src[cp.pos] == '#':
+			for cp.pos < len(cp.src) && cp.src[cp.pos] != '\n' {
+				cp.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// errorf returns an error wrapping ErrGrammar at the current position.
+func (cp *compiler) errorf(format string, args ...any) error {
+	var (
+		line, col int
+		lineStart int
+	)
+
+	lineStart = strings.LastIndex(cp.src[:cp.pos], "\n") + 1
+	line = strings.Count(cp.src[:cp.pos], "\n") + 1
+	col = utf8.RuneCountInString(cp.src[lineStart:cp.pos]) + 1
+
+	return fmt.Errorf(
+		"%w: %d:%d: %s",
+		ErrGrammar,
+		line,
+		col,
+		fmt.Sprintf(format, args...),
+	)
+}
@@ -0,0 +1,407 @@
+// Package peg interprets parsing expression grammars, written as text,
+// directly over the runes of a lexer.Reader, without a separate lexer.
+// Scannerless parsing suits small languages whose tokens depend on
+// context, such as configuration and query DSLs, and the backtracking
+// of a PEG maps onto lexer.Reader.Backup.
+//
+// A grammar is a list of rules in the notation of Ford's paper, with #
+// starting a comment that runs to the end of the line:
+//
+//	Sum    <- Number (_Plus Number)*
+//	Number <- [0-9]+
+//	_Plus  <- " "* "+" " "*
+//
+// The first rule is the start rule. Expressions are written as follows,
+// from the loosest binding:
+//
+//	e1 / e2      ordered choice: e2 is tried only if e1 fails
+//	e1 e2        sequence
+//	&e !e        positive and negative lookahead, consuming nothing
+//	e? e* e+     optional, zero or more, and one or more, all greedy
+//	(e)          grouping
+//	Name         the rule Name
+//	"s" 's'      a literal, with the escapes of Go strings
+//	[a-z_] [^"]  a character class, negated by ^
+//	.            any character
+//
+// Parse returns a tree of parser.Node values, one for every match of a
+// rule, spanning the text it matched. Rules whose names start with an
+// underscore, typically spacing, produce no node of their own; the
+// nodes of the rules they match belong to the enclosing node instead.
+package peg // import "github.com/andrieee44/langengine/peg"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+)
+
+var (
+	// ErrGrammar is returned by Compile for grammar text that does not
+	// conform to the notation of the package.
+	ErrGrammar = errors.New("peg: invalid grammar")
+
+	// ErrUndefinedRule is returned by Compile for a reference to a rule
+	// the grammar does not define.
+	ErrUndefinedRule = errors.New("peg: undefined rule")
+
+	// ErrLeftRecursion is returned by Parse when a rule calls itself
+	// without consuming input, which a PEG cannot parse.
+	ErrLeftRecursion = errors.New("peg: left recursion")
+)
+
+// Grammar is a compiled parsing expression grammar. A new Grammar is
+// constructed with Compile and is safe for concurrent use.
+type Grammar struct {
+	rules []rule
+}
+
+type rule struct {
+	name string
+	body *expr
+}
+
+// Compile compiles the text of a grammar.
+//
+// Returns an error wrapping ErrGrammar, with the line and column of the
+// offending text, if src does not conform to the notation, or wrapping
+// ErrUndefinedRule if an expression refers to an undefined rule. A rule
+// defined twice is an error of the first kind.
+func Compile(src string) (*Grammar, error) {
+	var (
+		cp      *compiler
+		grammar *Grammar
+		names   map[string]int
+		r       rule
+		ok      bool
+		i       int
+		err     error
+	)
+
+	cp = &compiler{src: src}
+	grammar = &Grammar{}
+	names = make(map[string]int)
+
+	cp.space()
+
+	for cp.pos < len(src) {
+		r, err = cp.definition()
+		if err != nil {
+			return nil, err
+		}
+
+		_, ok = names[r.name]
+		if ok {
+			return nil, cp.errorf("rule %q defined twice", r.name)
+		}
+
+		names[r.name] = len(grammar.rules)
+		grammar.rules = append(grammar.rules, r)
+	}
+
+	if len(grammar.rules) == 0 {
+		return nil, cp.errorf("no rules")
+	}
+
+	for i = range grammar.rules {
+		err = grammar.rules[i].body.resolve(names)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return grammar, nil
+}
+
+// MustCompile is like Compile but panics if the grammar cannot be
+// compiled. It simplifies the initialization of global grammars.
+func MustCompile(src string) *Grammar {
+	var (
+		grammar *Grammar
+		err     error
+	)
+
+	grammar, err = Compile(src)
+	if err != nil {
+		panic(err)
+	}
+
+	return grammar
+}
+
+// Rules returns the names of the rules of the grammar, in the order
+// they are defined.
+func (grammar *Grammar) Rules() []string {
+	var (
+		names []string
+		r     rule
+	)
+
+	for _, r = range grammar.rules {
+		names = append(names, r.name)
+	}
+
+	return names
+}
+
+// Parse parses the whole of rd with the start rule of the grammar and
+// returns the node of the start rule. The options configure the
+// underlying lexer.Reader, as for the positions of the spans.
+//
+// Returns a *parser.SyntaxError at the furthest position reached by
+// any alternative, listing the text expected there, if the input does
+// not match; an error wrapping ErrLeftRecursion for a left-recursive
+// rule; or the error of rd if it cannot be read.
+func (grammar *Grammar) Parse(rd io.Reader, opts ...lexer.Option) (*parser.Node, error) {
+	var (
+		ps       *parseState
+		children []*parser.Node
+		ok       bool
+	)
+
+	ps = &parseState{
+		grammar: grammar,
+		lrd:     lexer.NewReader(rd, opts...),
+		active:  make(map[activeKey]bool),
+	}
+	ps.base = ps.lrd.StartPosition().Offset
+	ps.furthest = ps.lrd.StartPosition()
+
+	ok = ps.rule(0, &children, true)
+
+	switch {
+	case ps.err != nil:
+		return nil, ps.err
+	case ps.lrd.Err() != nil && ps.lrd.Err() != io.EOF:
+		return nil, ps.lrd.Err()
+	case ok && !ps.lrd.AtEOF():
+		ps.expect("end of input")
+
+		ok = false
+	}
+
+	if !ok {
+		return nil, ps.syntaxError()
+	}
+
+	ps.fill(children[0], ps.lrd.PeekToken())
+
+	return children[0], nil
+}
+
+type activeKey struct {
+	rule   int
+	offset int
+}
+
+// parseState holds the state of a Parse.
+type parseState struct {
+	grammar  *Grammar
+	lrd      *lexer.Reader
+	base     int
+	active   map[activeKey]bool
+	quiet    int
+	furthest lexer.Position
+	found    rune
+	expected []string
+	err      error
+}
+
+// rule matches the rule with index i, appending its node to children,
+// or the nodes of its children if it is inlined, unless keep forces a
+// node.
+func (ps *parseState) rule(i int, children *[]*parser.Node, keep bool) bool {
+	var (
+		r     rule
+		key   activeKey
+		start lexer.Position
+		subs  []*parser.Node
+		ok    bool
+	)
+
+	r = ps.grammar.rules[i]
+	start = ps.lrd.CurrentPosition()
+	key = activeKey{i, start.Offset}
+
+	if ps.active[key] {
+		ps.err = fmt.Errorf(
+			"%w: rule %q at %d:%d",
+			ErrLeftRecursion,
+			r.name,
+			start.Line,
+			start.Column,
+		)
+
+		return false
+	}
+
+	ps.active[key] = true
+	ok = ps.match(r.body, &subs)
+	delete(ps.active, key)
+
+	if !ok {
+		return false
+	}
+
+	if !keep && strings.HasPrefix(r.name, "_") {
+		*children = append(*children, subs...)
+
+		return true
+	}
+
+	*children = append(*children, &parser.Node{
+		Rule:     r.name,
+		Span:     lexer.Span{Start: start, End: ps.lrd.CurrentPosition()},
+		Children: subs,
+	})
+
+	return true
+}
+
+// match matches e at the current position, appending the nodes of the
+// rules it matches to children. On failure, it consumes nothing and
+// appends nothing.
+func (ps *parseState) match(e *expr, children *[]*parser.Node) bool {
+	var (
+		mark  int
+		count int
+		n     int
+		sub   *expr
+		ok    bool
+	)
+
+	if ps.err != nil {
+		return false
+	}
+
+	mark = ps.lrd.BackupLimit()
+	count = len(*children)
+
+	switch e.op {
+	case opLiteral:
+		ok = ps.lrd.AcceptSeq(e.text)
+		if !ok {
+			ps.expect(e.desc)
+		}
+
+		return ok
+	case opClass, opAny:
+		ok = ps.lrd.AcceptFunc(e.matches)
+		if !ok {
+			ps.expect(e.desc)
+		}
+
+		return ok
+	case opRule:
+		return ps.rule(e.rule, children, false)
+	case opSeq:
+		for _, sub = range e.subs {
+			if !ps.match(sub, children) {
+				ps.reset(mark)
+				*children = (*children)[:count]
+
+				return false
+			}
+		}
+
+		return true
+	case opChoice:
+		for _, sub = range e.subs {
+			if ps.match(sub, children) {
+				return true
+			}
+		}
+
+		return false
+	case opOptional:
+		ps.match(e.subs[0], children)
+
+		return true
+	case opStar, opPlus:
+		// A repetition consuming nothing would repeat forever.
+		for ps.match(e.subs[0], children) {
+			n++
+
+			if ps.lrd.BackupLimit() == mark {
+				break
+			}
+
+			mark = ps.lrd.BackupLimit()
+		}
+
+		return e.op == opStar || n != 0
+	case opAnd, opNot:
+		// Lookahead is not part of what the input was expected to hold
+		// next, so its failures are left out of the error.
+		ps.quiet++
+		ok = ps.match(e.subs[0], children)
+		ps.quiet--
+		ps.reset(mark)
+		*children = (*children)[:count]
+
+		return ok == (e.op == opAnd)
+	default:
+		return false
+	}
+}
+
+// reset rewinds the reader to mark, a value of BackupLimit.
+func (ps *parseState) reset(mark int) {
+	ps.lrd.Backup(ps.lrd.BackupLimit() - mark)
+}
+
+// expect records that desc was expected at the current position.
+func (ps *parseState) expect(desc string) {
+	var pos lexer.Position
+
+	if ps.quiet != 0 {
+		return
+	}
+
+	pos = ps.lrd.CurrentPosition()
+
+	switch {
+	case pos.Offset > ps.furthest.Offset || ps.expected == nil:
+		ps.furthest = pos
+		ps.found = ps.lrd.Peek()
+		ps.expected = []string{desc}
+	case pos.Offset == ps.furthest.Offset && !slices.Contains(ps.expected, desc):
+		ps.expected = append(ps.expected, desc)
+	}
+}
+
+// syntaxError returns the error at the furthest position reached.
+func (ps *parseState) syntaxError() *parser.SyntaxError {
+	var found string
+
+	found = "end of input"
+	if ps.found != lexer.EOF {
+		found = fmt.Sprintf("%q", ps.found)
+	}
+
+	return &parser.SyntaxError{Diagnostic: lexer.Diagnostic{
+		Span: lexer.Span{Start: ps.furthest, End: ps.furthest},
+		Message: fmt.Sprintf(
+			"expected %s, found %s",
+			lexer.OrList(ps.expected),
+			found,
+		),
+	}}
+}
+
+// fill sets the text of node and its descendants from src, the text
+// consumed by the parse.
+func (ps *parseState) fill(node *parser.Node, src string) {
+	var child *parser.Node
+
+	node.Text = src[node.Span.Start.Offset-ps.base : node.Span.End.Offset-ps.base]
+
+	for _, child = range node.Children {
+		ps.fill(child, src)
+	}
+}
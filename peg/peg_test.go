@@ -0,0 +1,202 @@
+package peg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/parser"
+	"github.com/andrieee44/langengine/peg"
+	"github.com/stretchr/testify/assert"
+)
+
+var sum = peg.MustCompile(`
+# Sums of numbers, with optional spacing.
+Sum    <- _Space Term (_Plus Term)* _Space
+Term   <- Number / "(" Sum ")"
+Number <- [0-9]+ !'x'
+_Plus  <- _Space "+" _Space
+_Space <- [ \t\n]*
+`)
+
+// shape returns the rules and texts of node and its descendants, as in
+// Sum(Term(Number 1)).
+func shape(node *parser.Node) string {
+	var (
+		parts []string
+		child *parser.Node
+	)
+
+	if len(node.Children) == 0 {
+		return node.Rule + " " + node.Text
+	}
+
+	for _, child = range node.Children {
+		parts = append(parts, shape(child))
+	}
+
+	return node.Rule + "(" + strings.Join(parts, ", ") + ")"
+}
+
+func TestGrammarParse(t *testing.T) {
+	type testData struct {
+		src  string
+		want string
+		err  string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		node    *parser.Node
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"number": {src: "12", want: "Sum(Term(Number 12))"},
+		"sum": {
+			src:  " 1 +\n(2+3) ",
+			want: "Sum(Term(Number 1), Term(Sum(Term(Number 2), Term(Number 3))))",
+		},
+		"furthest": {
+			src: "1 + (2 + )",
+			err: `1:10: expected [ \t\n], [0-9], or "(", found ')'`,
+		},
+		"lookahead": {
+			src: "1x",
+			err: `1:2: expected [0-9], found 'x'`,
+		},
+		"end": {
+			src: "1 +",
+			err: `1:4: expected [ \t\n], [0-9], or "(", found end of input`,
+		},
+	}
+
+	for name, test = range testTbl {
+		node, err = sum.Parse(strings.NewReader(test.src))
+
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, name)
+
+			continue
+		}
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.want, shape(node), name)
+		assert.Equal(t, test.src, node.Text, name)
+	}
+}
+
+func TestGrammarParseSpans(t *testing.T) {
+	var (
+		node *parser.Node
+		err  error
+	)
+
+	t.Parallel()
+
+	node, err = peg.MustCompile(`
+List <- Item ("," Item)*
+Item <- [a-zé]+
+`).Parse(strings.NewReader("ab,é"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "é", node.Children[1].Text)
+	assert.Equal(t, lexer.Span{
+		Start: lexer.Position{Line: 1, Column: 4, Offset: 3},
+		End:   lexer.Position{Line: 1, Column: 5, Offset: 5},
+	}, node.Children[1].Span)
+}
+
+func TestCompile(t *testing.T) {
+	type testData struct {
+		src string
+		err error
+		msg string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+		grammar *peg.Grammar
+		err     error
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"escapes": {src: `A <- "\n\"" [\]\-a-c\x41] . 'x'? !B &B B* B+` + "\nB <- 'b'"},
+		"empty": {
+			err: peg.ErrGrammar,
+			msg: "peg: invalid grammar: 1:1: no rules",
+		},
+		"arrow": {
+			src: "A = 'a'",
+			err: peg.ErrGrammar,
+			msg: `peg: invalid grammar: 1:3: expected <- after rule name "A"`,
+		},
+		"unclosed group": {
+			src: "A <- ('a'",
+			err: peg.ErrGrammar,
+			msg: "peg: invalid grammar: 1:10: expected )",
+		},
+		"unterminated": {
+			src: "A <- 'a\nB <- 'b'",
+			err: peg.ErrGrammar,
+			msg: "peg: invalid grammar: 1:6: unterminated literal",
+		},
+		"class": {
+			src: "A <- [a-",
+			err: peg.ErrGrammar,
+			msg: "peg: invalid grammar: 1:6: unterminated class",
+		},
+		"range": {
+			src: "A <- [z-a]",
+			err: peg.ErrGrammar,
+			msg: "peg: invalid grammar: 1:10: invalid range in class",
+		},
+		"twice": {
+			src: "A <- 'a'\nA <- 'b'",
+			err: peg.ErrGrammar,
+			msg: `peg: invalid grammar: 2:9: rule "A" defined twice`,
+		},
+		"undefined": {
+			src: "A <- B",
+			err: peg.ErrUndefinedRule,
+			msg: `peg: undefined rule: "B"`,
+		},
+	}
+
+	for name, test = range testTbl {
+		grammar, err = peg.Compile(test.src)
+
+		assert.ErrorIs(t, err, test.err, name)
+
+		if test.err != nil {
+			assert.EqualError(t, err, test.msg, name)
+		} else {
+			assert.Equal(t, []string{"A", "B"}, grammar.Rules(), name)
+		}
+	}
+
+	assert.Panics(t, func() {
+		peg.MustCompile("A <- B")
+	})
+}
+
+func TestGrammarParseLeftRecursion(t *testing.T) {
+	var err error
+
+	t.Parallel()
+
+	_, err = peg.MustCompile(`
+Expr <- Expr "-" [0-9] / [0-9]
+`).Parse(strings.NewReader("1-2"))
+
+	assert.ErrorIs(t, err, peg.ErrLeftRecursion)
+	assert.EqualError(t, err, `peg: left recursion: rule "Expr" at 1:1`)
+}
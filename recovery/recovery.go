@@ -0,0 +1,352 @@
+// Package recovery provides strategies for recovering from syntax
+// errors in parsers reading tokens through a lexer.TokenReader, so that
+// a parser can report every error of its input in one pass instead of
+// stopping at the first. When the parser expects a token of one of a
+// set of kinds and finds another, it hands the TokenReader to its
+// Strategy, which repairs the stream and describes the repair:
+//
+//   - PanicMode skips tokens until one that can resume parsing, such as
+//     a statement terminator or a closing brace.
+//   - Phrase makes the cheapest local repair, deleting the unexpected
+//     tokens or inserting the missing one, within a cost limit.
+//   - First tries strategies in order, as in the usual combination of
+//     phrase-level repair falling back to panic mode.
+//
+// Strategies are values, so every grammar selects its own, and a parser
+// may switch strategies by context, such as panic mode within
+// expressions and statement-level synchronization elsewhere. Recovery
+// from errors within tokens is the job of the lexer, as with
+// lexer.Reader.SyncTo.
+package recovery // import "github.com/andrieee44/langengine/recovery"
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Strategy repairs a token stream after a syntax error. Recover is
+// called with the TokenReader positioned at the unexpected token, or at
+// the end of the stream, and with the kinds the parser expected there.
+// It returns the repair it made, and true if parsing can resume at the
+// next token of trd. A Strategy that fails must leave trd as it found
+// it.
+type Strategy interface {
+	Recover(trd *lexer.TokenReader, expected lexer.KindSet) (Repair, bool)
+}
+
+// StrategyFunc adapts an ordinary function to the Strategy interface.
+type StrategyFunc func(trd *lexer.TokenReader, expected lexer.KindSet) (Repair, bool)
+
+// Repair describes how a Strategy repaired a token stream.
+type Repair struct {
+	// Deleted lists the tokens removed from the stream, in order.
+	Deleted []lexer.Token
+
+	// Inserted lists the synthetic tokens added to the stream, in
+	// order. A synthetic token has empty text and an empty span at the
+	// start of the token that follows it.
+	Inserted []lexer.Token
+
+	// Diagnostic reports the syntax error and the repair, spanning the
+	// unexpected token.
+	Diagnostic lexer.Diagnostic
+}
+
+// PanicMode is the Strategy that deletes tokens until the next token
+// is of an expected kind or of one of the synchronizing kinds, which
+// typically end or start a construct, such as ';' and '}' or keywords
+// starting a statement. A synchronizing token is left in the stream for
+// the parser, which must be prepared to find it where it expected
+// something else. PanicMode fails, deleting nothing, if the stream ends
+// first.
+type PanicMode struct {
+	// Sync is the set of synchronizing kinds.
+	Sync lexer.KindSet
+
+	// KindName names kinds in diagnostics, such as the KindName method
+	// of a lexer.Language. Nil selects lexer.Kind.String.
+	KindName func(lexer.Kind) string
+}
+
+// Phrase is the Strategy that makes the cheapest of two local repairs:
+// deleting the tokens preceding the next token of an expected kind, or
+// inserting a token of an expected kind before the unexpected one. It
+// fails, changing nothing, if both cost more than MaxCost.
+type Phrase struct {
+	// MaxCost is the highest cost of an acceptable repair.
+	MaxCost int
+
+	// DeleteCost returns the cost of deleting tok. Nil makes every
+	// deletion cost 1.
+	DeleteCost func(tok lexer.Token) int
+
+	// InsertCost returns the cost of inserting a token of kind. Nil
+	// makes every insertion cost 1. On a tie, insertion is preferred,
+	// as a missing token is the more common mistake.
+	InsertCost func(kind lexer.Kind) int
+
+	// KindName names kinds in diagnostics, such as the KindName method
+	// of a lexer.Language. Nil selects lexer.Kind.String.
+	KindName func(lexer.Kind) string
+}
+
+// Recover calls fn.
+func (fn StrategyFunc) Recover(
+	trd *lexer.TokenReader,
+	expected lexer.KindSet,
+) (Repair, bool) {
+	return fn(trd, expected)
+}
+
+// First returns a Strategy that tries strategies in order and returns
+// the repair of the first that succeeds.
+func First(strategies ...Strategy) Strategy {
+	return StrategyFunc(func(
+		trd *lexer.TokenReader,
+		expected lexer.KindSet,
+	) (Repair, bool) {
+		var (
+			strategy Strategy
+			repair   Repair
+			ok       bool
+		)
+
+		for _, strategy = range strategies {
+			repair, ok = strategy.Recover(trd, expected)
+			if ok {
+				return repair, true
+			}
+		}
+
+		return Repair{}, false
+	})
+}
+
+// Recover implements Strategy.
+func (pm PanicMode) Recover(
+	trd *lexer.TokenReader,
+	expected lexer.KindSet,
+) (Repair, bool) {
+	var (
+		found   lexer.Token
+		tok     lexer.Token
+		deleted []lexer.Token
+		ok      bool
+	)
+
+	found, ok = trd.Peek()
+	tok = found
+
+	for ok && !expected.Has(tok.Kind) && !pm.Sync.Has(tok.Kind) {
+		trd.NextToken()
+		deleted = append(deleted, tok)
+		tok, ok = trd.Peek()
+	}
+
+	if !ok {
+		trd.Insert(deleted...)
+
+		return Repair{}, false
+	}
+
+	return Repair{
+		Deleted: deleted,
+		Diagnostic: diagnose(
+			found,
+			true,
+			expected,
+			pm.KindName,
+			"skipped "+plural(len(deleted), "token"),
+		),
+	}, true
+}
+
+// Recover implements Strategy.
+func (phrase Phrase) Recover(
+	trd *lexer.TokenReader,
+	expected lexer.KindSet,
+) (Repair, bool) {
+	var (
+		found      lexer.Token
+		foundOK    bool
+		deleted    []lexer.Token
+		deleteCost int
+		deleteOK   bool
+		kind       lexer.Kind
+		insertCost int
+		insertOK   bool
+		tok        lexer.Token
+		i          int
+	)
+
+	found, foundOK = trd.Peek()
+	deleted, deleteCost, deleteOK = phrase.deletion(trd, expected)
+	kind, insertCost, insertOK = phrase.insertion(expected)
+
+	switch {
+	case insertOK && (!deleteOK || insertCost <= deleteCost):
+		tok = lexer.Token{
+			Kind: kind,
+			Span: lexer.Span{Start: found.Span.Start, End: found.Span.Start},
+		}
+
+		trd.Insert(tok)
+
+		return Repair{
+			Inserted: []lexer.Token{tok},
+			Diagnostic: diagnose(
+				found,
+				foundOK,
+				expected,
+				phrase.KindName,
+				"inserted "+kindName(phrase.KindName, kind),
+			),
+		}, true
+	case deleteOK:
+		for i = 0; i < len(deleted); i++ {
+			trd.NextToken()
+		}
+
+		return Repair{
+			Deleted: deleted,
+			Diagnostic: diagnose(
+				found,
+				foundOK,
+				expected,
+				phrase.KindName,
+				"deleted "+plural(len(deleted), "token"),
+			),
+		}, true
+	default:
+		return Repair{}, false
+	}
+}
+
+// deletion returns the tokens preceding the next token of an expected
+// kind and the cost of deleting them, and false if there is no such
+// token or the cost exceeds MaxCost. It leaves trd as it found it.
+func (phrase Phrase) deletion(
+	trd *lexer.TokenReader,
+	expected lexer.KindSet,
+) ([]lexer.Token, int, bool) {
+	var (
+		deleted []lexer.Token
+		cost    int
+		tok     lexer.Token
+		ok      bool
+	)
+
+	tok, ok = trd.Peek()
+
+	for ok && cost <= phrase.MaxCost && !expected.Has(tok.Kind) {
+		if phrase.DeleteCost == nil {
+			cost++
+		} else {
+			cost += phrase.DeleteCost(tok)
+		}
+
+		trd.NextToken()
+		deleted = append(deleted, tok)
+		tok, ok = trd.Peek()
+	}
+
+	trd.Insert(deleted...)
+
+	return deleted, cost, ok && cost <= phrase.MaxCost && len(deleted) != 0
+}
+
+// insertion returns the expected kind cheapest to insert and its cost,
+// and false if every insertion costs more than MaxCost.
+func (phrase Phrase) insertion(expected lexer.KindSet) (lexer.Kind, int, bool) {
+	var (
+		best     lexer.Kind
+		bestCost int
+		found    bool
+		kind     lexer.Kind
+		cost     int
+	)
+
+	for kind = range expected.All() {
+		cost = 1
+		if phrase.InsertCost != nil {
+			cost = phrase.InsertCost(kind)
+		}
+
+		if cost <= phrase.MaxCost && (!found || cost < bestCost) {
+			best, bestCost, found = kind, cost, true
+		}
+	}
+
+	return best, bestCost, found
+}
+
+// diagnose returns the diagnostic of a syntax error at found, which is
+// the end of the stream if ok is false, followed by the repair made.
+func diagnose(
+	found lexer.Token,
+	ok bool,
+	expected lexer.KindSet,
+	name func(lexer.Kind) string,
+	repair string,
+) lexer.Diagnostic {
+	var (
+		names []string
+		kind  lexer.Kind
+		desc  string
+	)
+
+	for kind = range expected.All() {
+		names = append(names, kindName(name, kind))
+	}
+
+	desc = "end of input"
+	if ok {
+		desc = fmt.Sprintf("%s %q", kindName(name, found.Kind), found.Text)
+	}
+
+	return lexer.Diagnostic{
+		Span: found.Span,
+		Message: fmt.Sprintf(
+			"expected %s, found %s; %s",
+			list(names),
+			desc,
+			repair,
+		),
+	}
+}
+
+func kindName(name func(lexer.Kind) string, kind lexer.Kind) string {
+	if name == nil {
+		return kind.String()
+	}
+
+	return name(kind)
+}
+
+// list joins names as in "a", "a or b", and "a, b, or c".
+func list(names []string) string {
+	switch len(names) {
+	case 0:
+		return "nothing"
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " or " + names[1]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") +
+			", or " + names[len(names)-1]
+	}
+}
+
+func plural(n int, noun string) string {
+	switch n {
+	case 0:
+		return "no " + noun + "s"
+	case 1:
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,214 @@
+package recovery_test
+
+import (
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/recovery"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindIdent lexer.Kind = lexer.KindUser + iota
+	kindSemi
+	kindRBrace
+	kindAssign
+)
+
+var kindNames = map[lexer.Kind]string{
+	kindIdent:  "identifier",
+	kindSemi:   "';'",
+	kindRBrace: "'}'",
+	kindAssign: "'='",
+}
+
+func kindName(kind lexer.Kind) string {
+	return kindNames[kind]
+}
+
+// tokens returns tokens of the given kinds, with texts a, b, c, and so
+// on, laid out on one line.
+func tokens(kinds ...lexer.Kind) []lexer.Token {
+	var (
+		toks []lexer.Token
+		kind lexer.Kind
+		i    int
+	)
+
+	for i, kind = range kinds {
+		toks = append(toks, lexer.Token{
+			Kind: kind,
+			Text: string(rune('a' + i)),
+			Span: lexer.Span{
+				Start: lexer.Position{Line: 1, Column: 2*i + 1, Offset: 2 * i},
+				End:   lexer.Position{Line: 1, Column: 2*i + 2, Offset: 2*i + 1},
+			},
+		})
+	}
+
+	return toks
+}
+
+func reader(toks []lexer.Token) *lexer.TokenReader {
+	return lexer.NewTokenReader(lexer.StreamFunc(func() (lexer.Token, bool) {
+		var tok lexer.Token
+
+		if len(toks) == 0 {
+			return lexer.Token{}, false
+		}
+
+		tok, toks = toks[0], toks[1:]
+
+		return tok, true
+	}))
+}
+
+func rest(trd *lexer.TokenReader) []lexer.Kind {
+	var (
+		kinds []lexer.Kind
+		tok   lexer.Token
+	)
+
+	for tok = range lexer.Tokens(trd) {
+		kinds = append(kinds, tok.Kind)
+	}
+
+	return kinds
+}
+
+func TestStrategies(t *testing.T) {
+	type testData struct {
+		strategy recovery.Strategy
+		input    []lexer.Kind
+		expected []lexer.Kind
+		ok       bool
+		deleted  int
+		inserted []lexer.Kind
+		message  string
+		rest     []lexer.Kind
+	}
+
+	var (
+		phrase    recovery.Phrase
+		panicMode recovery.PanicMode
+		testTbl   map[string]testData
+		name      string
+		test      testData
+	)
+
+	t.Parallel()
+
+	phrase = recovery.Phrase{
+		MaxCost: 2,
+		InsertCost: func(kind lexer.Kind) int {
+			if kind == kindIdent {
+				return 3
+			}
+
+			return 1
+		},
+		KindName: kindName,
+	}
+	panicMode = recovery.PanicMode{
+		Sync:     lexer.NewKindSet(kindSemi, kindRBrace),
+		KindName: kindName,
+	}
+
+	testTbl = map[string]testData{
+		"panic skips to sync": {
+			strategy: panicMode,
+			input:    []lexer.Kind{kindIdent, kindAssign, kindIdent, kindSemi},
+			expected: []lexer.Kind{kindAssign},
+			ok:       true,
+			deleted:  1,
+			message:  `1:1: expected '=', found identifier "a"; skipped 1 token`,
+			rest:     []lexer.Kind{kindAssign, kindIdent, kindSemi},
+		},
+		"panic at sync": {
+			strategy: panicMode,
+			input:    []lexer.Kind{kindRBrace},
+			expected: []lexer.Kind{kindIdent, kindAssign},
+			ok:       true,
+			message:  `1:1: expected identifier or '=', found '}' "a"; skipped no tokens`,
+			rest:     []lexer.Kind{kindRBrace},
+		},
+		"panic fails at end": {
+			strategy: panicMode,
+			input:    []lexer.Kind{kindIdent, kindIdent},
+			expected: []lexer.Kind{kindAssign},
+			rest:     []lexer.Kind{kindIdent, kindIdent},
+		},
+		"phrase inserts": {
+			strategy: phrase,
+			input:    []lexer.Kind{kindRBrace},
+			expected: []lexer.Kind{kindIdent, kindSemi},
+			ok:       true,
+			inserted: []lexer.Kind{kindSemi},
+			message:  `1:1: expected identifier or ';', found '}' "a"; inserted ';'`,
+			rest:     []lexer.Kind{kindSemi, kindRBrace},
+		},
+		"phrase inserts at end": {
+			strategy: phrase,
+			expected: []lexer.Kind{kindSemi},
+			ok:       true,
+			inserted: []lexer.Kind{kindSemi},
+			message:  `0:0: expected ';', found end of input; inserted ';'`,
+			rest:     []lexer.Kind{kindSemi},
+		},
+		"phrase deletes": {
+			strategy: phrase,
+			input:    []lexer.Kind{kindAssign, kindIdent},
+			expected: []lexer.Kind{kindIdent},
+			ok:       true,
+			deleted:  1,
+			message:  `1:1: expected identifier, found '=' "a"; deleted 1 token`,
+			rest:     []lexer.Kind{kindIdent},
+		},
+		"phrase over cost": {
+			strategy: phrase,
+			input:    []lexer.Kind{kindAssign, kindAssign, kindAssign, kindIdent},
+			expected: []lexer.Kind{kindIdent},
+			rest:     []lexer.Kind{kindAssign, kindAssign, kindAssign, kindIdent},
+		},
+		"first falls back": {
+			strategy: recovery.First(phrase, panicMode),
+			input:    []lexer.Kind{kindAssign, kindAssign, kindAssign, kindSemi},
+			expected: []lexer.Kind{kindIdent},
+			ok:       true,
+			deleted:  3,
+			message:  `1:1: expected identifier, found '=' "a"; skipped 3 tokens`,
+			rest:     []lexer.Kind{kindSemi},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				trd      *lexer.TokenReader
+				repair   recovery.Repair
+				ok       bool
+				inserted []lexer.Kind
+				tok      lexer.Token
+			)
+
+			trd = reader(tokens(test.input...))
+			repair, ok = test.strategy.Recover(
+				trd,
+				lexer.NewKindSet(test.expected...),
+			)
+
+			for _, tok = range repair.Inserted {
+				inserted = append(inserted, tok.Kind)
+			}
+
+			assert.Equal(t, test.ok, ok)
+			assert.Len(t, repair.Deleted, test.deleted)
+			assert.Equal(t, test.inserted, inserted)
+			assert.Equal(t, test.rest, rest(trd))
+
+			if ok {
+				assert.Equal(t, test.message, repair.Diagnostic.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package scope tracks the names a program defines and uses, for the
+// consumers of a syntax tree that resolve identifiers: checkers,
+// evaluators, and editor features such as go-to-definition and rename.
+//
+// A Scope is a symbol table nested in the scope enclosing it, as a
+// block within a function within a file. Names are resolved from the
+// innermost scope outwards, so a definition shadows those of the same
+// name in enclosing scopes. Every Symbol records the span of its
+// definition and of each use resolved to it.
+package scope // import "github.com/andrieee44/langengine/scope"
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// ErrRedefined is returned by Scope.Define for a name already defined
+// in the same scope.
+var ErrRedefined = errors.New("scope: name redefined")
+
+// Symbol is a name defined in a scope.
+type Symbol struct {
+	// Name is the name of the symbol.
+	Name string
+
+	// Def is the span of the definition, typically of the identifier.
+	Def lexer.Span
+
+	// Uses holds the spans of the uses resolved to the symbol, in the
+	// order they were recorded.
+	Uses []lexer.Span
+
+	// Scope is the scope the symbol is defined in.
+	Scope *Scope
+
+	// Shadows is the symbol of the same name in an enclosing scope that
+	// the symbol hides, or nil if there is none.
+	Shadows *Symbol
+
+	// Data holds what the application knows of the symbol, such as its
+	// type or value.
+	Data any
+}
+
+// Scope is a symbol table nested in an enclosing scope. A new root
+// Scope is constructed with New and nested ones with Open. A Scope is
+// not safe for concurrent use.
+type Scope struct {
+	// Span is the region of the input the scope covers, or the zero
+	// Span if it is unknown.
+	Span lexer.Span
+
+	parent   *Scope
+	children []*Scope
+	symbols  map[string]*Symbol
+	order    []*Symbol
+}
+
+// New returns a new root scope, such as the universe or file scope,
+// covering span.
+func New(span lexer.Span) *Scope {
+	return &Scope{Span: span, symbols: make(map[string]*Symbol)}
+}
+
+// Open returns a new scope nested in sc and covering span.
+func (sc *Scope) Open(span lexer.Span) *Scope {
+	var child *Scope
+
+	child = New(span)
+	child.parent = sc
+	sc.children = append(sc.children, child)
+
+	return child
+}
+
+// Parent returns the scope enclosing sc, or nil for a root scope.
+func (sc *Scope) Parent() *Scope {
+	return sc.parent
+}
+
+// Children returns the scopes nested directly in sc, in the order they
+// were opened.
+func (sc *Scope) Children() []*Scope {
+	return sc.children
+}
+
+// Define defines name in sc with the definition at span, and returns
+// its symbol.
+//
+// Returns the symbol already defined and an error wrapping ErrRedefined
+// if name is already defined in sc itself; definitions in enclosing
+// scopes are shadowed instead.
+func (sc *Scope) Define(name string, span lexer.Span) (*Symbol, error) {
+	var (
+		sym *Symbol
+		ok  bool
+	)
+
+	sym, ok = sc.symbols[name]
+	if ok {
+		return sym, fmt.Errorf(
+			"%w: %q at %d:%d, previously defined at %d:%d",
+			ErrRedefined,
+			name,
+			span.Start.Line,
+			span.Start.Column,
+			sym.Def.Start.Line,
+			sym.Def.Start.Column,
+		)
+	}
+
+	sym = &Symbol{Name: name, Def: span, Scope: sc}
+
+	if sc.parent != nil {
+		sym.Shadows, _ = sc.parent.Lookup(name)
+	}
+
+	sc.symbols[name] = sym
+	sc.order = append(sc.order, sym)
+
+	return sym, nil
+}
+
+// Lookup returns the symbol name resolves to in sc: its definition in
+// the innermost scope, from sc outwards, defining it.
+func (sc *Scope) Lookup(name string) (*Symbol, bool) {
+	var (
+		sym *Symbol
+		ok  bool
+	)
+
+	for ; sc != nil; sc = sc.parent {
+		sym, ok = sc.symbols[name]
+		if ok {
+			return sym, true
+		}
+	}
+
+	return nil, false
+}
+
+// LookupLocal returns the symbol of name defined in sc itself, ignoring
+// enclosing scopes.
+func (sc *Scope) LookupLocal(name string) (*Symbol, bool) {
+	var (
+		sym *Symbol
+		ok  bool
+	)
+
+	sym, ok = sc.symbols[name]
+
+	return sym, ok
+}
+
+// Use resolves name as in Lookup and records the use at span in the
+// symbol found. It reports false, recording nothing, for an undefined
+// name, which the caller typically reports with a did-you-mean
+// suggestion from Names.
+func (sc *Scope) Use(name string, span lexer.Span) (*Symbol, bool) {
+	var (
+		sym *Symbol
+		ok  bool
+	)
+
+	sym, ok = sc.Lookup(name)
+	if ok {
+		sym.Uses = append(sym.Uses, span)
+	}
+
+	return sym, ok
+}
+
+// Symbols returns the symbols defined in sc itself, in the order they
+// were defined.
+func (sc *Scope) Symbols() []*Symbol {
+	return sc.order
+}
+
+// Names returns the names visible in sc, from sc outwards, leaving out
+// those shadowed, as candidates for lexer.Suggest.
+func (sc *Scope) Names() []string {
+	var (
+		names []string
+		seen  map[string]bool
+		sym   *Symbol
+	)
+
+	seen = make(map[string]bool)
+
+	for ; sc != nil; sc = sc.parent {
+		for _, sym = range sc.order {
+			if !seen[sym.Name] {
+				seen[sym.Name] = true
+				names = append(names, sym.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Unused returns the symbols defined in sc and its nested scopes that
+// have no uses, in the order of their scopes and definitions.
+func (sc *Scope) Unused() []*Symbol {
+	var (
+		unused []*Symbol
+		sym    *Symbol
+		child  *Scope
+	)
+
+	for _, sym = range sc.order {
+		if len(sym.Uses) == 0 {
+			unused = append(unused, sym)
+		}
+	}
+
+	for _, child = range sc.children {
+		unused = append(unused, child.Unused()...)
+	}
+
+	return unused
+}
+
+// SymbolAt returns the symbol defined or used at pos within sc and its
+// nested scopes, as for go-to-definition or rename under a cursor.
+func (sc *Scope) SymbolAt(pos lexer.Position) (*Symbol, bool) {
+	var (
+		sym   *Symbol
+		use   lexer.Span
+		child *Scope
+		ok    bool
+	)
+
+	for _, sym = range sc.order {
+		if contains(sym.Def, pos) {
+			return sym, true
+		}
+
+		for _, use = range sym.Uses {
+			if contains(use, pos) {
+				return sym, true
+			}
+		}
+	}
+
+	for _, child = range sc.children {
+		sym, ok = child.SymbolAt(pos)
+		if ok {
+			return sym, true
+		}
+	}
+
+	return nil, false
+}
+
+// contains reports whether pos lies within span, its end excluded.
+func contains(span lexer.Span, pos lexer.Position) bool {
+	return span.Start.Offset <= pos.Offset && pos.Offset < span.End.Offset
+}
@@ -0,0 +1,156 @@
+package scope_test
+
+import (
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/scope"
+	"github.com/stretchr/testify/assert"
+)
+
+// at returns the span on line 1 from offset start to offset end.
+func at(start, end int) lexer.Span {
+	return lexer.Span{
+		Start: lexer.Position{Line: 1, Column: start + 1, Offset: start},
+		End:   lexer.Position{Line: 1, Column: end + 1, Offset: end},
+	}
+}
+
+func TestScopeShadowing(t *testing.T) {
+	var (
+		file, block *scope.Scope
+		outer, x    *scope.Symbol
+		sym         *scope.Symbol
+		ok          bool
+		err         error
+	)
+
+	t.Parallel()
+
+	file = scope.New(at(0, 40))
+	outer, err = file.Define("x", at(0, 1))
+	assert.NoError(t, err)
+
+	block = file.Open(at(10, 30))
+	assert.Same(t, file, block.Parent())
+	assert.Equal(t, []*scope.Scope{block}, file.Children())
+
+	sym, ok = block.Use("x", at(12, 13))
+	assert.True(t, ok)
+	assert.Same(t, outer, sym)
+
+	x, err = block.Define("x", at(15, 16))
+	assert.NoError(t, err)
+	assert.Same(t, outer, x.Shadows)
+	assert.Same(t, block, x.Scope)
+
+	sym, ok = block.Use("x", at(20, 21))
+	assert.True(t, ok)
+	assert.Same(t, x, sym)
+
+	sym, ok = file.Use("x", at(35, 36))
+	assert.True(t, ok)
+	assert.Same(t, outer, sym)
+
+	assert.Equal(t, []lexer.Span{at(12, 13), at(35, 36)}, outer.Uses)
+	assert.Equal(t, []lexer.Span{at(20, 21)}, x.Uses)
+
+	_, ok = block.LookupLocal("y")
+	assert.False(t, ok)
+
+	_, ok = block.Use("y", at(25, 26))
+	assert.False(t, ok)
+}
+
+func TestScopeDefine(t *testing.T) {
+	var (
+		sc    *scope.Scope
+		first *scope.Symbol
+		sym   *scope.Symbol
+		err   error
+	)
+
+	t.Parallel()
+
+	sc = scope.New(lexer.Span{})
+	first, _ = sc.Define("f", at(4, 5))
+
+	sym, err = sc.Define("f", at(20, 21))
+
+	assert.ErrorIs(t, err, scope.ErrRedefined)
+	assert.EqualError(
+		t,
+		err,
+		`scope: name redefined: "f" at 1:21, previously defined at 1:5`,
+	)
+	assert.Same(t, first, sym)
+	assert.Equal(t, []*scope.Symbol{first}, sc.Symbols())
+	assert.Nil(t, first.Shadows)
+}
+
+func TestScopeNames(t *testing.T) {
+	var file, fn *scope.Scope
+
+	t.Parallel()
+
+	file = scope.New(lexer.Span{})
+	file.Define("print", at(0, 5))
+	file.Define("count", at(6, 11))
+
+	fn = file.Open(lexer.Span{})
+	fn.Define("count", at(12, 17))
+	fn.Define("total", at(18, 23))
+
+	assert.Equal(t, []string{"count", "total", "print"}, fn.Names())
+	assert.Equal(t, []string{"count"}, lexer.Suggest("cont", fn.Names()))
+}
+
+func TestScopeUnused(t *testing.T) {
+	var (
+		file, fn *scope.Scope
+		a, b, c  *scope.Symbol
+	)
+
+	t.Parallel()
+
+	file = scope.New(lexer.Span{})
+	a, _ = file.Define("a", at(0, 1))
+	b, _ = file.Define("b", at(2, 3))
+
+	fn = file.Open(lexer.Span{})
+	c, _ = fn.Define("c", at(4, 5))
+	fn.Use("b", at(6, 7))
+
+	assert.Equal(t, []*scope.Symbol{a, c}, file.Unused())
+	assert.Equal(t, []lexer.Span{at(6, 7)}, b.Uses)
+}
+
+func TestScopeSymbolAt(t *testing.T) {
+	var (
+		file, fn *scope.Scope
+		a, b     *scope.Symbol
+		sym      *scope.Symbol
+		ok       bool
+	)
+
+	t.Parallel()
+
+	file = scope.New(at(0, 20))
+	a, _ = file.Define("a", at(0, 1))
+
+	fn = file.Open(at(5, 20))
+	b, _ = fn.Define("bb", at(6, 8))
+	fn.Use("a", at(10, 11))
+	fn.Use("bb", at(12, 14))
+
+	sym, ok = file.SymbolAt(lexer.Position{Offset: 10})
+	assert.True(t, ok)
+	assert.Same(t, a, sym)
+
+	sym, ok = file.SymbolAt(lexer.Position{Offset: 13})
+	assert.True(t, ok)
+	assert.Same(t, b, sym)
+
+	_, ok = file.SymbolAt(lexer.Position{Offset: 14})
+	assert.False(t, ok)
+}
@@ -0,0 +1,399 @@
+// Package shlex splits command lines into words following the quoting
+// rules of the POSIX shell. It recognises single quotes, double quotes,
+// backslash escapes, line continuations, comments, and the boundaries
+// of parameter references such as $HOME and ${PATH}, reporting every
+// word and reference with its exact span.
+//
+// The package performs no expansion of its own: parameter references
+// are located and, on request, substituted through a caller-supplied
+// function, but the result is never re-split or globbed.
+package shlex // import "github.com/andrieee44/langengine/shlex"
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+const (
+	// KindWord is the kind of a word. Its text is the word exactly as
+	// written, including quotes and backslashes.
+	KindWord lexer.Kind = lexer.KindUser + iota
+
+	// KindOperator is the kind of a control or redirection operator,
+	// such as "|", "&&", ";", or ">>".
+	KindOperator
+)
+
+// Word is a single word or operator of a command line.
+type Word struct {
+	// Value is the word with quotes removed, escapes resolved, and
+	// parameter references expanded as requested by Split.
+	Value string
+
+	// Operator reports whether the word is a control or redirection
+	// operator rather than an ordinary word.
+	Operator bool
+
+	// Span is the region of the command line the word occupies.
+	Span lexer.Span
+
+	// Vars lists the parameter references found in the word, outside
+	// of single quotes, in order of appearance.
+	Vars []Var
+}
+
+// Var is a parameter reference inside a word, such as $HOME or ${PATH}.
+type Var struct {
+	// Name is the name of the referenced parameter, without the dollar
+	// sign or braces.
+	Name string
+
+	// Span is the region of the command line the reference occupies,
+	// including the dollar sign and braces.
+	Span lexer.Span
+}
+
+// SyntaxError reports a malformed command line, such as an unterminated
+// quote.
+type SyntaxError struct {
+	// Span is the region of the command line that is malformed.
+	Span lexer.Span
+
+	// Msg describes the problem.
+	Msg string
+}
+
+var operators = []string{
+	"&&", "||", ";;", ">>", "<<", ">&", "<&", "<>", ">|",
+	"|", "&", ";", "<", ">", "(", ")",
+}
+
+// NewLexer returns a lexer.Lexer that splits rd into KindWord and
+// KindOperator tokens. Blanks, newlines, and comments are skipped.
+// An unterminated quote or parameter reference stops the Lexer with a
+// lexer.KindError token spanning the offending word. The options
+// configure the underlying lexer.Reader.
+func NewLexer(rd io.Reader, opts ...lexer.Option) *lexer.Lexer {
+	return lexer.NewLexer(rd, lexSpace, opts...)
+}
+
+// Split splits line into words. When expand is not nil, every parameter
+// reference outside of single quotes is replaced in Word.Value by the
+// result of calling expand with the parameter name; otherwise the
+// reference is kept verbatim.
+//
+// If line is malformed, Split returns the words preceding the error
+// along with a *SyntaxError.
+func Split(line string, expand func(string) string) ([]Word, error) {
+	var (
+		words []Word
+		tok   lexer.Token
+	)
+
+	for tok = range NewLexer(strings.NewReader(line)).Tokens() {
+		switch tok.Kind {
+		case lexer.KindError:
+			return words, &SyntaxError{
+				Span: tok.Span,
+				Msg:  tok.Text,
+			}
+		case KindOperator:
+			words = append(words, Word{
+				Value:    tok.Text,
+				Operator: true,
+				Span:     tok.Span,
+			})
+		default:
+			words = append(words, decodeWord(tok, expand))
+		}
+	}
+
+	return words, nil
+}
+
+// Error formats the error with the position where the malformed region
+// begins.
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf(
+		"shlex: %d:%d: %s",
+		err.Span.Start.Line,
+		err.Span.Start.Column,
+		err.Msg,
+	)
+}
+
+func lexSpace(lx *lexer.Lexer) lexer.StateFn {
+	var op string
+
+	lx.AcceptRun(" \t\n")
+
+	for lx.AcceptSeq("\\\n") {
+		lx.AcceptRun(" \t\n")
+	}
+
+	if lx.Accept("#") {
+		lx.UntilSeq("\n")
+		lx.Ignore()
+
+		return lexSpace
+	}
+
+	lx.Ignore()
+
+	if lx.AtEOF() {
+		return nil
+	}
+
+	for _, op = range operators {
+		if lx.AcceptSeq(op) {
+			lx.Emit(KindOperator)
+
+			return lexSpace
+		}
+	}
+
+	return lexWord
+}
+
+func lexWord(lx *lexer.Lexer) lexer.StateFn {
+	var matched bool
+
+	for {
+		if lx.AtEOF() {
+			lx.Emit(KindWord)
+
+			return nil
+		}
+
+		switch lx.Next() {
+		case ' ', '\t', '\n', '|', '&', ';', '<', '>', '(', ')':
+			lx.Backup(1)
+			lx.Emit(KindWord)
+
+			return lexSpace
+		case '\\':
+			lx.Next()
+		case '\'':
+			_, matched = lx.UntilSeqInclusive("'")
+			if !matched {
+				return lx.Errorf("unterminated single quote")
+			}
+		case '"':
+			if !acceptDoubleQuoted(lx.Reader) {
+				return lx.Errorf("unterminated double quote")
+			}
+		case '$':
+			if !acceptVar(lx.Reader) {
+				return lx.Errorf("unterminated parameter reference")
+			}
+		}
+	}
+}
+
+func acceptDoubleQuoted(lrd *lexer.Reader) bool {
+	for {
+		if lrd.AtEOF() {
+			return false
+		}
+
+		switch lrd.Next() {
+		case '"':
+			return true
+		case '\\':
+			lrd.Next()
+		case '$':
+			if !acceptVar(lrd) {
+				return false
+			}
+		}
+	}
+}
+
+func acceptVar(lrd *lexer.Reader) bool {
+	var matched bool
+
+	switch {
+	case lrd.Accept("{"):
+		_, matched = lrd.UntilSeqInclusive("}")
+
+		return matched
+	case lrd.AcceptFunc(isNameStart):
+		lrd.AcceptRunFunc(isNameChar)
+	default:
+		lrd.Accept("0123456789@*#?$!-")
+	}
+
+	return true
+}
+
+func decodeWord(tok lexer.Token, expand func(string) string) Word {
+	var (
+		word   Word
+		dec    decoder
+		char   rune
+		quoted bool
+	)
+
+	word.Span = tok.Span
+	dec = decoder{
+		text:   tok.Text,
+		pos:    tok.Span.Start,
+		expand: expand,
+		word:   &word,
+	}
+
+	for !dec.done() {
+		char = dec.next()
+
+		switch {
+		case char == '\\' && quoted:
+			if !strings.ContainsRune("$`\"\\\n", dec.peek()) {
+				dec.sb.WriteRune(char)
+
+				continue
+			}
+
+			fallthrough
+		case char == '\\':
+			char = dec.next()
+			if char != '\n' {
+				dec.sb.WriteRune(char)
+			}
+		case char == '\'' && !quoted:
+			for !dec.done() && dec.peek() != '\'' {
+				dec.sb.WriteRune(dec.next())
+			}
+
+			dec.next()
+		case char == '"':
+			quoted = !quoted
+		case char == '$':
+			dec.variable()
+		default:
+			dec.sb.WriteRune(char)
+		}
+	}
+
+	word.Value = dec.sb.String()
+
+	return word
+}
+
+type decoder struct {
+	text   string
+	offset int
+	pos    lexer.Position
+	expand func(string) string
+	word   *Word
+	sb     strings.Builder
+}
+
+func (dec *decoder) done() bool {
+	return dec.offset >= len(dec.text)
+}
+
+func (dec *decoder) peek() rune {
+	var char rune
+
+	if dec.done() {
+		return lexer.EOF
+	}
+
+	char, _ = utf8.DecodeRuneInString(dec.text[dec.offset:])
+
+	return char
+}
+
+func (dec *decoder) next() rune {
+	var (
+		char rune
+		size int
+	)
+
+	if dec.done() {
+		return lexer.EOF
+	}
+
+	char, size = utf8.DecodeRuneInString(dec.text[dec.offset:])
+	dec.offset += size
+
+	dec.pos.Offset += size
+	dec.pos.Column++
+	if char == '\n' {
+		dec.pos.Line++
+		dec.pos.Column = 1
+	}
+
+	return char
+}
+
+func (dec *decoder) variable() {
+	var (
+		start  int
+		end    int
+		name   string
+		varPos lexer.Position
+	)
+
+	start = dec.offset - 1
+	varPos = dec.pos
+	varPos.Column--
+	varPos.Offset--
+
+	switch {
+	case dec.peek() == '{':
+		dec.next()
+
+		for !dec.done() && dec.peek() != '}' {
+			dec.next()
+		}
+
+		dec.next()
+		name = dec.text[start+2 : dec.offset-1]
+	case isNameStart(dec.peek()):
+		for isNameChar(dec.peek()) {
+			dec.next()
+		}
+
+		name = dec.text[start+1 : dec.offset]
+	case strings.ContainsRune("0123456789@*#?$!-", dec.peek()):
+		dec.next()
+		name = dec.text[start+1 : dec.offset]
+	default:
+		dec.sb.WriteByte('$')
+
+		return
+	}
+
+	end = dec.offset
+
+	dec.word.Vars = append(dec.word.Vars, Var{
+		Name: name,
+		Span: lexer.Span{
+			Start: varPos,
+			End:   dec.pos,
+		},
+	})
+
+	if dec.expand == nil {
+		dec.sb.WriteString(dec.text[start:end])
+
+		return
+	}
+
+	dec.sb.WriteString(dec.expand(name))
+}
+
+func isNameStart(char rune) bool {
+	return char == '_' ||
+		'a' <= char && char <= 'z' ||
+		'A' <= char && char <= 'Z'
+}
+
+func isNameChar(char rune) bool {
+	return isNameStart(char) || '0' <= char && char <= '9'
+}
@@ -0,0 +1,195 @@
+package shlex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/shlex"
+	"github.com/stretchr/testify/assert"
+)
+
+func pos(line, column, offset int) lexer.Position {
+	return lexer.Position{
+		Line:   line,
+		Column: column,
+		Offset: offset,
+	}
+}
+
+func span(start, end lexer.Position) lexer.Span {
+	return lexer.Span{
+		Start: start,
+		End:   end,
+	}
+}
+
+func values(words []shlex.Word) []string {
+	var (
+		vals []string
+		word shlex.Word
+	)
+
+	for _, word = range words {
+		vals = append(vals, word.Value)
+	}
+
+	return vals
+}
+
+func TestSplit(t *testing.T) {
+	type testData struct {
+		values []string
+		err    string
+	}
+
+	var (
+		testTbl map[string]testData
+		line    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"echo  hello\tworld": {
+			values: []string{"echo", "hello", "world"},
+		},
+		`a\ b 'c d' "e f"`: {
+			values: []string{"a b", "c d", "e f"},
+		},
+		`"a\"b\$c\d" 'x\y'`: {
+			values: []string{`a"b$c\d`, `x\y`},
+		},
+		"a\\\nb c # comment\nd": {
+			values: []string{"ab", "c", "d"},
+		},
+		"a|b&&c >>log;": {
+			values: []string{"a", "|", "b", "&&", "c", ">>", "log", ";"},
+		},
+		"pre'mid'\"post\"": {
+			values: []string{"premidpost"},
+		},
+		`$ "$" x$`: {
+			values: []string{"$", "$", "x$"},
+		},
+		"a\x00b 'c\x00d' \"e\x00f\" # g\x00h\ni": {
+			values: []string{"a\x00b", "c\x00d", "e\x00f", "i"},
+		},
+		"a 'b\x00": {
+			values: []string{"a"},
+			err:    "shlex: 1:3: unterminated single quote",
+		},
+		"": {},
+		"a 'b": {
+			values: []string{"a"},
+			err:    "shlex: 1:3: unterminated single quote",
+		},
+		`a "b`: {
+			values: []string{"a"},
+			err:    "shlex: 1:3: unterminated double quote",
+		},
+		"${HOME": {
+			err: "shlex: 1:1: unterminated parameter reference",
+		},
+	}
+
+	for line, test = range testTbl {
+		t.Run(line, func(t *testing.T) {
+			var (
+				words []shlex.Word
+				err   error
+			)
+
+			words, err = shlex.Split(line, nil)
+
+			assert.Equal(t, test.values, values(words))
+
+			if test.err == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestSplitVars(t *testing.T) {
+	var (
+		words []shlex.Word
+		err   error
+	)
+
+	t.Parallel()
+
+	words, err = shlex.Split(
+		"cd $HOME/${DIR}x '$NOPE'\n\"$1\"",
+		strings.ToLower,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []shlex.Word{
+		{
+			Value: "cd",
+			Span:  span(pos(1, 1, 0), pos(1, 3, 2)),
+		},
+		{
+			Value: "home/dirx",
+			Span:  span(pos(1, 4, 3), pos(1, 17, 16)),
+			Vars: []shlex.Var{
+				{
+					Name: "HOME",
+					Span: span(pos(1, 4, 3), pos(1, 9, 8)),
+				},
+				{
+					Name: "DIR",
+					Span: span(pos(1, 10, 9), pos(1, 16, 15)),
+				},
+			},
+		},
+		{
+			Value: "$NOPE",
+			Span:  span(pos(1, 18, 17), pos(1, 25, 24)),
+		},
+		{
+			Value: "1",
+			Span:  span(pos(2, 1, 25), pos(2, 5, 29)),
+			Vars: []shlex.Var{
+				{
+					Name: "1",
+					Span: span(pos(2, 2, 26), pos(2, 4, 28)),
+				},
+			},
+		},
+	}, words)
+}
+
+func TestSplitOperator(t *testing.T) {
+	var (
+		words []shlex.Word
+		err   error
+	)
+
+	t.Parallel()
+
+	words, err = shlex.Split("a>b", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []shlex.Word{
+		{
+			Value: "a",
+			Span:  span(pos(1, 1, 0), pos(1, 2, 1)),
+		},
+		{
+			Value:    ">",
+			Operator: true,
+			Span:     span(pos(1, 2, 1), pos(1, 3, 2)),
+		},
+		{
+			Value: "b",
+			Span:  span(pos(1, 3, 2), pos(1, 4, 3)),
+		},
+	}, words)
+}
@@ -0,0 +1,414 @@
+// Package tokengen generates random token sequences from lexgen
+// grammars, along with source text that lexes back to them, for
+// property testing the consumers of a lexer: a parser and printer pair
+// can be checked to satisfy parse(print(tokens)) == tokens over
+// thousands of inputs no one wrote by hand.
+//
+// Generation is deterministic: a Generator draws every choice from the
+// random source it is given, so a failing input is reproduced by
+// running again with the same seed. Seed picks the seed of a test and
+// logs it, and lets it be fixed through the TOKENGEN_SEED environment
+// variable.
+package tokengen // import "github.com/andrieee44/langengine/tokengen"
+
+import (
+	"math/rand/v2"
+	"os"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+)
+
+// SeedEnv is the environment variable from which Seed reads a fixed
+// seed.
+const SeedEnv = "TOKENGEN_SEED"
+
+// Generator produces random token sequences valid under a grammar. A
+// Generator is not safe for concurrent use, as it advances its random
+// source.
+type Generator struct {
+	// MaxRepeat bounds the number of repetitions generated beyond the
+	// minimum for the unbounded operators *, +, and {n,} of a pattern.
+	MaxRepeat int
+
+	// Attempts is the number of candidate texts tried for every token
+	// before giving up on extending the sequence.
+	Attempts int
+
+	grammar *lexgen.Grammar
+	rules   []lexgen.Rule
+	regexps []*syntax.Regexp
+	rnd     *rand.Rand
+}
+
+// New returns a Generator for grammar that draws its choices from rnd,
+// such as rand.New(rand.NewPCG(seed, 0)). MaxRepeat is set to 3 and
+// Attempts to 64.
+func New(grammar *lexgen.Grammar, rnd *rand.Rand) *Generator {
+	var (
+		gen  *Generator
+		rule lexgen.Rule
+		re   *syntax.Regexp
+		err  error
+	)
+
+	gen = &Generator{
+		MaxRepeat: 3,
+		Attempts:  64,
+		grammar:   grammar,
+		rules:     grammar.Rules(),
+		rnd:       rnd,
+	}
+
+	for _, rule = range gen.rules {
+		// The grammar compiled, so every pattern parses.
+		re, err = syntax.Parse(rule.Pattern, syntax.Perl)
+		if err == nil {
+			re = re.Simplify()
+		}
+
+		gen.regexps = append(gen.regexps, re)
+	}
+
+	return gen
+}
+
+// Seed returns the seed for a property test: the value of the
+// TOKENGEN_SEED environment variable if it is set, or a random seed
+// otherwise. The seed is logged through tb so that a failure can be
+// reproduced by setting TOKENGEN_SEED to it.
+func Seed(tb testing.TB) uint64 {
+	var (
+		seed uint64
+		err  error
+	)
+
+	tb.Helper()
+
+	seed, err = strconv.ParseUint(os.Getenv(SeedEnv), 10, 64)
+	if err != nil {
+		seed = rand.Uint64()
+	}
+
+	tb.Logf("tokengen: %s=%d", SeedEnv, seed)
+
+	return seed
+}
+
+// Generate returns the source text of a random sequence of up to n
+// tokens, and the tokens the grammar produces for it, positions
+// included. Tokens of skipped rules, such as whitespace, are inserted
+// between tokens that would otherwise lex differently, and are left out
+// of the result like the grammar leaves them out.
+//
+// The sequence is shorter than n if no rule active in the current mode
+// yields a token that lexes back unchanged within Attempts tries, as
+// for grammars whose rules all absorb the text following them.
+func (gen *Generator) Generate(n int) (string, []lexer.Token) {
+	var (
+		src    strings.Builder
+		modes  []string
+		tokens []lexer.Token
+		next   string
+		lexed  []lexer.Token
+		ok     bool
+	)
+
+	for len(tokens) < n {
+		next, lexed, modes, ok = gen.extend(src.String(), modes, tokens)
+		if !ok {
+			break
+		}
+
+		src.WriteString(next)
+		tokens = lexed
+	}
+
+	return src.String(), tokens
+}
+
+// extend tries to append one token to src, which lexes to tokens and
+// leaves modes pushed, and returns the text appended, the tokens of the
+// extended source, and the modes pushed after the token.
+func (gen *Generator) extend(
+	src string,
+	modes []string,
+	tokens []lexer.Token,
+) (string, []lexer.Token, []string, bool) {
+	var (
+		active []int
+		rule   int
+		want   lexer.Token
+		sep    string
+		next   string
+		lexed  []lexer.Token
+		size   int
+		i      int
+	)
+
+	active = gen.active(modes, func(rule lexgen.Rule) bool {
+		return !rule.Skip
+	})
+	if len(active) == 0 {
+		return "", nil, modes, false
+	}
+
+	for i = 0; i < gen.Attempts; i++ {
+		rule = active[gen.rnd.IntN(len(active))]
+		want = lexer.Token{
+			Kind:   gen.rules[rule].Kind,
+			Text:   gen.text(gen.regexps[rule]),
+			Trivia: gen.rules[rule].Trivia,
+		}
+
+		// Every other attempt separates the token from the one before
+		// it with the text of a skipped rule. The previous token may
+		// absorb the start of the separator, as a line comment does
+		// with all but a line feed, so its suffixes are tried as well.
+		if i%2 == 1 {
+			sep = gen.separator(modes)
+		} else {
+			sep = ""
+		}
+
+		for {
+			next = sep + want.Text
+			lexed = gen.lex(src + next)
+
+			if gen.matches(lexed, tokens, want) {
+				return next, lexed, gen.apply(modes, gen.rules[rule]), true
+			}
+
+			if sep == "" {
+				break
+			}
+
+			_, size = utf8.DecodeRuneInString(sep)
+			sep = sep[size:]
+		}
+	}
+
+	return "", nil, modes, false
+}
+
+// separator returns the text of a random skipped rule active in the
+// current mode, or the empty string if there is none.
+func (gen *Generator) separator(modes []string) string {
+	var active []int
+
+	active = gen.active(modes, func(rule lexgen.Rule) bool {
+		return rule.Skip && !rule.Pop && rule.Push == ""
+	})
+	if len(active) == 0 {
+		return ""
+	}
+
+	return gen.text(gen.regexps[active[gen.rnd.IntN(len(active))]])
+}
+
+// active returns the indexes of the rules active in the current mode
+// for which keep returns true.
+func (gen *Generator) active(
+	modes []string,
+	keep func(lexgen.Rule) bool,
+) []int {
+	var (
+		active []int
+		mode   string
+		rule   lexgen.Rule
+		i      int
+	)
+
+	if len(modes) != 0 {
+		mode = modes[len(modes)-1]
+	}
+
+	for i, rule = range gen.rules {
+		if rule.Mode == mode && keep(rule) {
+			active = append(active, i)
+		}
+	}
+
+	return active
+}
+
+// apply returns modes after the mode changes of rule, mirroring
+// lexgen.Grammar.
+func (gen *Generator) apply(modes []string, rule lexgen.Rule) []string {
+	modes = slices.Clone(modes)
+
+	if rule.Pop && len(modes) != 0 {
+		modes = modes[:len(modes)-1]
+	}
+
+	if rule.Push != "" {
+		modes = append(modes, rule.Push)
+	}
+
+	return modes
+}
+
+// lex returns the tokens of src, or nil if lexing it fails.
+func (gen *Generator) lex(src string) []lexer.Token {
+	var (
+		tokens []lexer.Token
+		tok    lexer.Token
+	)
+
+	for tok = range gen.grammar.NewLexer(strings.NewReader(src)).Tokens() {
+		if tok.Kind == lexer.KindError {
+			return nil
+		}
+
+		tokens = append(tokens, tok)
+	}
+
+	return tokens
+}
+
+// matches reports whether lexed holds tokens followed by want, ignoring
+// the span of want.
+func (gen *Generator) matches(
+	lexed []lexer.Token,
+	tokens []lexer.Token,
+	want lexer.Token,
+) bool {
+	if len(lexed) != len(tokens)+1 ||
+		!slices.EqualFunc(lexed[:len(tokens)], tokens, sameToken) {
+		return false
+	}
+
+	want.Span = lexed[len(tokens)].Span
+
+	return sameToken(lexed[len(tokens)], want)
+}
+
+// sameToken reports whether two tokens produced by a grammar are equal.
+func sameToken(a, b lexer.Token) bool {
+	return a.Kind == b.Kind &&
+		a.Text == b.Text &&
+		a.Span == b.Span &&
+		a.Trivia == b.Trivia
+}
+
+// text returns a random string matched by re.
+func (gen *Generator) text(re *syntax.Regexp) string {
+	var sb strings.Builder
+
+	if re != nil {
+		gen.write(&sb, re)
+	}
+
+	return sb.String()
+}
+
+// write appends a random string matched by re to sb.
+func (gen *Generator) write(sb *strings.Builder, re *syntax.Regexp) {
+	var (
+		char  rune
+		sub   *syntax.Regexp
+		count int
+		i     int
+	)
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, char = range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && gen.rnd.IntN(2) == 0 {
+				char = unicode.SimpleFold(char)
+			}
+
+			sb.WriteRune(char)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(gen.class(re.Rune))
+	case syntax.OpAnyCharNotNL:
+		sb.WriteRune(gen.class([]rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}))
+	case syntax.OpAnyChar:
+		sb.WriteRune(gen.class([]rune{0, unicode.MaxRune}))
+	case syntax.OpCapture:
+		gen.write(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub = range re.Sub {
+			gen.write(sb, sub)
+		}
+	case syntax.OpAlternate:
+		gen.write(sb, re.Sub[gen.rnd.IntN(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		count = gen.repeat(re)
+
+		for i = 0; i < count; i++ {
+			gen.write(sb, re.Sub[0])
+		}
+	}
+}
+
+// repeat returns a random number of repetitions for a repetition
+// operator.
+func (gen *Generator) repeat(re *syntax.Regexp) int {
+	var lo, hi int
+
+	switch re.Op {
+	case syntax.OpStar:
+		lo, hi = 0, -1
+	case syntax.OpPlus:
+		lo, hi = 1, -1
+	case syntax.OpQuest:
+		lo, hi = 0, 1
+	default:
+		lo, hi = re.Min, re.Max
+	}
+
+	if hi < 0 {
+		hi = lo + max(gen.MaxRepeat, 0)
+	}
+
+	return lo + gen.rnd.IntN(hi-lo+1)
+}
+
+// class returns a random rune of the sorted pairs of inclusive ranges.
+// Printable ASCII runes and ASCII line breaks and tabs are preferred
+// when the class holds any, so that generated text stays readable in
+// test failures.
+func (gen *Generator) class(ranges []rune) rune {
+	var (
+		ascii []rune
+		char  rune
+		lo    rune
+		hi    rune
+		i     int
+	)
+
+	for i = 0; i < len(ranges); i += 2 {
+		for char = max(ranges[i], '\t'); char <= min(ranges[i+1], '~'); char++ {
+			if char >= ' ' || char == '\t' || char == '\n' || char == '\r' {
+				ascii = append(ascii, char)
+			}
+		}
+	}
+
+	if len(ascii) != 0 && gen.rnd.IntN(8) != 0 {
+		return ascii[gen.rnd.IntN(len(ascii))]
+	}
+
+	// Surrogate halves cannot be encoded, so they are drawn again, up
+	// to a bound for classes holding nothing else.
+	for range 16 {
+		i = 2 * gen.rnd.IntN(len(ranges)/2)
+		lo, hi = ranges[i], ranges[i+1]
+		char = lo + gen.rnd.Int32N(hi-lo+1)
+
+		if utf8.ValidRune(char) {
+			return char
+		}
+	}
+
+	return utf8.RuneError
+}
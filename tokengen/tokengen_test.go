@@ -0,0 +1,94 @@
+package tokengen_test
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/lexgen"
+	"github.com/andrieee44/langengine/tokengen"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kindIdent lexer.Kind = lexer.KindUser + iota
+	kindKeyword
+	kindNumber
+	kindOp
+	kindComment
+	kindQuote
+	kindChars
+)
+
+var grammar = lexgen.MustCompile([]lexgen.Rule{
+	{Name: "space", Pattern: `\s+`, Skip: true},
+	{Name: "comment", Pattern: `#.*`, Kind: kindComment, Trivia: true},
+	{Name: "if", Pattern: `(?i)if`, Kind: kindKeyword},
+	{Name: "ident", Pattern: `\pL[\pL\d_]*`, Kind: kindIdent},
+	{Name: "number", Pattern: `\d+(\.\d+)?`, Kind: kindNumber},
+	{Name: "op", Pattern: `[-+*/=<>]=?|==`, Kind: kindOp},
+	{Name: "open", Pattern: `"`, Kind: kindQuote, Push: "string"},
+	{Name: "close", Pattern: `"`, Kind: kindQuote, Mode: "string", Pop: true},
+	{Name: "text", Pattern: `[^"\\]+|\\.`, Kind: kindChars, Mode: "string"},
+})
+
+func generate(seed uint64, n int) (string, []lexer.Token) {
+	return tokengen.New(grammar, rand.New(rand.NewPCG(seed, 0))).Generate(n)
+}
+
+func TestGenerate(t *testing.T) {
+	var (
+		seed   uint64
+		src    string
+		again  string
+		tokens []lexer.Token
+		lexed  []lexer.Token
+		tok    lexer.Token
+		i      int
+	)
+
+	t.Parallel()
+
+	seed = tokengen.Seed(t)
+
+	for i = 0; i < 200; i++ {
+		src, tokens = generate(seed+uint64(i), 20)
+		again, _ = generate(seed+uint64(i), 20)
+
+		assert.Equal(t, src, again, "seed %d", seed+uint64(i))
+		assert.Len(t, tokens, 20, "source %q", src)
+
+		lexed = nil
+
+		for tok = range grammar.NewLexer(strings.NewReader(src)).Tokens() {
+			lexed = append(lexed, tok)
+		}
+
+		assert.Equal(t, lexed, tokens, "source %q", src)
+	}
+}
+
+func TestGenerateStuck(t *testing.T) {
+	var (
+		stuck  *lexgen.Grammar
+		src    string
+		tokens []lexer.Token
+	)
+
+	t.Parallel()
+
+	// Every token absorbs the one after it, so none can follow another.
+	stuck = lexgen.MustCompile([]lexgen.Rule{
+		{Pattern: `a+`, Kind: kindIdent},
+	})
+
+	src, tokens = tokengen.New(stuck, rand.New(rand.NewPCG(1, 0))).Generate(5)
+	assert.Len(t, tokens, 1)
+	assert.Equal(t, tokens[0].Text, src)
+}
+
+func TestSeed(t *testing.T) {
+	t.Setenv(tokengen.SeedEnv, "42")
+	assert.Equal(t, uint64(42), tokengen.Seed(t))
+}
@@ -0,0 +1,242 @@
+// Package tokenservice exposes the languages of the lexer registry as an
+// HTTP service, so that toolchains written in other languages can use
+// the lexers of this module. A client posts source text along with the
+// name of a registered language, and receives its tokens, lexical
+// errors included, as a stream.
+//
+// A request is a POST whose body is the source, with the language given
+// by the "lang" query parameter, or else inferred from the extension of
+// the "file" query parameter:
+//
+//	POST /?lang=json
+//
+// The tokens are streamed back as length-delimited Protocol Buffers
+// messages, in the format of the tokenwire package, if the request
+// accepts ProtoContentType, and otherwise as JSON lines of the form:
+//
+//	{"kind":2,"kindName":"String","text":"\"a\"","start":{...},"end":{...}}
+//
+// Lexical errors are tokens of kind lexer.KindError, whose text is the
+// message, and do not change the status of the response.
+package tokenservice // import "github.com/andrieee44/langengine/tokenservice"
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/tokenwire"
+)
+
+const (
+	// ProtoContentType is the media type of responses encoded with
+	// tokenwire.ProtoEncoder.
+	ProtoContentType = "application/vnd.langengine.tokens+protobuf"
+
+	// JSONContentType is the media type of responses encoded as JSON
+	// lines.
+	JSONContentType = "application/x-ndjson"
+
+	// DefaultMaxSource is the size limit of a source when
+	// Handler.MaxSource is zero.
+	DefaultMaxSource = 16 << 20
+)
+
+// Handler is the http.Handler serving tokenization requests. The zero
+// value is ready to use.
+type Handler struct {
+	// MaxSource is the size limit of a source in bytes. Larger sources
+	// are rejected with status 413. Zero selects DefaultMaxSource.
+	MaxSource int64
+}
+
+// Client sends tokenization requests to a service. The zero value is
+// not usable; URL must be set.
+type Client struct {
+	// URL is the address of the service, such as
+	// "http://localhost:8080/".
+	URL string
+
+	// HTTPClient sends the requests. Nil selects http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+type jsonToken struct {
+	Kind     lexer.Kind   `json:"kind"`
+	KindName string       `json:"kindName"`
+	Text     string       `json:"text"`
+	Start    jsonPosition `json:"start"`
+	End      jsonPosition `json:"end"`
+	Trivia   bool         `json:"trivia,omitempty"`
+}
+
+// ServeHTTP implements http.Handler. It responds with status 405 to
+// methods other than POST, 404 if no registered language matches the
+// request, and 413 if the source exceeds MaxSource.
+func (handler Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		lang   lexer.Language
+		src    []byte
+		enc    func(lexer.Token) error
+		lx     *lexer.Lexer
+		tok    lexer.Token
+		maxErr *http.MaxBytesError
+		ok     bool
+		err    error
+	)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	lang, ok = language(r.URL.Query())
+	if !ok {
+		http.Error(w, "unknown language", http.StatusNotFound)
+
+		return
+	}
+
+	src, err = io.ReadAll(http.MaxBytesReader(
+		w,
+		r.Body,
+		cmp.Or(handler.MaxSource, DefaultMaxSource),
+	))
+
+	switch {
+	case errors.As(err, &maxErr):
+		http.Error(w, "source too large", http.StatusRequestEntityTooLarge)
+
+		return
+	case err != nil:
+		http.Error(w, "cannot read source", http.StatusBadRequest)
+
+		return
+	}
+
+	enc = encoder(w, r, lang)
+	lx = lang.NewLexer(bytes.NewReader(src))
+
+	for tok = range lx.Tokens() {
+		if enc(tok) != nil {
+			return
+		}
+	}
+}
+
+// Tokenize sends src to the service to be lexed with the registered
+// language lang, and returns the tokens, lexical errors included.
+//
+// Returns an error if the request fails, if the service responds with
+// a status other than 200, or if the response is malformed.
+func (client *Client) Tokenize(
+	ctx context.Context,
+	lang string,
+	src []byte,
+) ([]lexer.Token, error) {
+	var (
+		req    *http.Request
+		resp   *http.Response
+		msg    []byte
+		dec    *tokenwire.ProtoDecoder
+		tokens []lexer.Token
+		tok    lexer.Token
+		err    error
+	)
+
+	req, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		client.URL+"?lang="+url.QueryEscape(lang),
+		bytes.NewReader(src),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", ProtoContentType)
+
+	resp, err = cmp.Or(client.HTTPClient, http.DefaultClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ = io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return nil, fmt.Errorf(
+			"tokenservice: %s: %s",
+			resp.Status,
+			strings.TrimSpace(string(msg)),
+		)
+	}
+
+	dec = tokenwire.NewProtoDecoder(resp.Body)
+
+	for tok = range lexer.Tokens(dec) {
+		tokens = append(tokens, tok)
+	}
+
+	return tokens, dec.Err()
+}
+
+// language returns the language selected by the query of a request.
+func language(query url.Values) (lexer.Language, bool) {
+	if query.Has("lang") {
+		return lexer.ForLanguage(query.Get("lang"))
+	}
+
+	return lexer.ForFile(query.Get("file"))
+}
+
+// encoder returns the function writing tokens to w in the format
+// accepted by r, having set the content type accordingly.
+func encoder(
+	w http.ResponseWriter,
+	r *http.Request,
+	lang lexer.Language,
+) func(lexer.Token) error {
+	var (
+		proto *tokenwire.ProtoEncoder
+		enc   *json.Encoder
+	)
+
+	if strings.Contains(r.Header.Get("Accept"), ProtoContentType) {
+		w.Header().Set("Content-Type", ProtoContentType)
+		proto = tokenwire.NewProtoEncoder(w)
+
+		return proto.Encode
+	}
+
+	w.Header().Set("Content-Type", JSONContentType)
+	enc = json.NewEncoder(w)
+
+	return func(tok lexer.Token) error {
+		return enc.Encode(jsonToken{
+			Kind:     tok.Kind,
+			KindName: lang.KindName(tok.Kind),
+			Text:     tok.Text,
+			Start:    jsonPosition(tok.Span.Start),
+			End:      jsonPosition(tok.Span.End),
+			Trivia:   tok.Trivia,
+		})
+	}
+}
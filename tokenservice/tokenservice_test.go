@@ -0,0 +1,155 @@
+package tokenservice_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrieee44/langengine/jsonlex"
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/tokenservice"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	var err error
+
+	err = lexer.Register(lexer.Language{
+		Name:       "tokenservice-json",
+		Extensions: []string{".tsjson"},
+		NewLexer:   jsonlex.NewLexer,
+		KindNames:  map[lexer.Kind]string{jsonlex.KindNumber: "Number"},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestClientTokenize(t *testing.T) {
+	var (
+		server *httptest.Server
+		client tokenservice.Client
+		want   []lexer.Token
+		tokens []lexer.Token
+		tok    lexer.Token
+		err    error
+	)
+
+	t.Parallel()
+
+	server = httptest.NewServer(tokenservice.Handler{})
+	defer server.Close()
+
+	client = tokenservice.Client{URL: server.URL}
+
+	for tok = range lexer.Tokens(jsonlex.NewLexer(strings.NewReader(`[1, @]`))) {
+		want = append(want, tok)
+	}
+
+	tokens, err = client.Tokenize(
+		context.Background(),
+		"tokenservice-json",
+		[]byte(`[1, @]`),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, want, tokens)
+
+	_, err = client.Tokenize(context.Background(), "tokenservice-none", nil)
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestHandler(t *testing.T) {
+	type testData struct {
+		method   string
+		target   string
+		body     string
+		status   int
+		kindName string
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"by name": {
+			method:   http.MethodPost,
+			target:   "/?lang=tokenservice-json",
+			body:     "1",
+			status:   http.StatusOK,
+			kindName: "Number",
+		},
+		"by file": {
+			method:   http.MethodPost,
+			target:   "/?file=dir/a.tsjson",
+			body:     "1",
+			status:   http.StatusOK,
+			kindName: "Number",
+		},
+		"unknown language": {
+			method: http.MethodPost,
+			target: "/?lang=tokenservice-none",
+			status: http.StatusNotFound,
+		},
+		"method": {
+			method: http.MethodGet,
+			target: "/?lang=tokenservice-json",
+			status: http.StatusMethodNotAllowed,
+		},
+		"too large": {
+			method: http.MethodPost,
+			target: "/?lang=tokenservice-json",
+			body:   "12345",
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				rec *httptest.ResponseRecorder
+				tok struct {
+					KindName string `json:"kindName"`
+					Text     string `json:"text"`
+				}
+			)
+
+			t.Parallel()
+
+			rec = httptest.NewRecorder()
+			tokenservice.Handler{MaxSource: 4}.ServeHTTP(
+				rec,
+				httptest.NewRequest(
+					test.method,
+					test.target,
+					strings.NewReader(test.body),
+				),
+			)
+
+			assert.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				return
+			}
+
+			assert.Equal(
+				t,
+				tokenservice.JSONContentType,
+				rec.Header().Get("Content-Type"),
+			)
+			assert.NoError(t, json.NewDecoder(
+				bufio.NewReader(rec.Body),
+			).Decode(&tok))
+			assert.Equal(t, test.kindName, tok.KindName)
+			assert.Equal(t, test.body, tok.Text)
+		})
+	}
+}
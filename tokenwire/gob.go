@@ -0,0 +1,179 @@
+package tokenwire
+
+import (
+	"encoding/gob"
+	"io"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// GobEncoder is an Encoder writing tokens with encoding/gob. A new
+// GobEncoder is constructed with NewGobEncoder.
+type GobEncoder struct {
+	enc *gob.Encoder
+}
+
+// GobDecoder reads tokens written by a GobEncoder. It is a
+// lexer.TokenStream, so that passes can run directly on the decoded
+// tokens. A new GobDecoder is constructed with NewGobDecoder.
+type GobDecoder struct {
+	dec *gob.Decoder
+	err error
+}
+
+// gobToken is the schema of the gob encoding, kept apart from
+// lexer.Token so that the encoding does not change with it.
+type gobToken struct {
+	Kind   int64
+	Text   string
+	Span   gobSpan
+	Trivia bool
+	Doc    []gobToken
+	Origin *gobSpan
+}
+
+type gobSpan struct {
+	Start, End gobPosition
+}
+
+type gobPosition struct {
+	Line, Column, Offset int64
+}
+
+// NewGobEncoder returns a GobEncoder writing to w. Type information is
+// sent once, ahead of the first token.
+func NewGobEncoder(w io.Writer) *GobEncoder {
+	return &GobEncoder{enc: gob.NewEncoder(w)}
+}
+
+// Encode implements Encoder.
+func (enc *GobEncoder) Encode(tok lexer.Token) error {
+	return enc.enc.Encode(toGob(tok))
+}
+
+// NewGobDecoder returns a GobDecoder reading from r.
+func NewGobDecoder(r io.Reader) *GobDecoder {
+	return &GobDecoder{dec: gob.NewDecoder(r)}
+}
+
+// Decode reads the next token. Returns io.EOF at the end of the stream,
+// or the error encountered while decoding.
+func (dec *GobDecoder) Decode() (lexer.Token, error) {
+	var (
+		gt  gobToken
+		err error
+	)
+
+	err = dec.dec.Decode(&gt)
+	if err != nil {
+		return lexer.Token{}, err
+	}
+
+	return fromGob(gt), nil
+}
+
+// NextToken implements lexer.TokenStream. It returns false at the end
+// of the stream or at the first error, which Err reports.
+func (dec *GobDecoder) NextToken() (lexer.Token, bool) {
+	var tok lexer.Token
+
+	if dec.err != nil {
+		return lexer.Token{}, false
+	}
+
+	tok, dec.err = dec.Decode()
+
+	return tok, dec.err == nil
+}
+
+// Err returns the first error encountered by NextToken other than
+// io.EOF, or nil.
+func (dec *GobDecoder) Err() error {
+	if dec.err == io.EOF {
+		return nil
+	}
+
+	return dec.err
+}
+
+func toGob(tok lexer.Token) gobToken {
+	var (
+		gt     gobToken
+		doc    lexer.Token
+		origin gobSpan
+	)
+
+	gt = gobToken{
+		Kind:   int64(tok.Kind),
+		Text:   tok.Text,
+		Span:   toGobSpan(tok.Span),
+		Trivia: tok.Trivia,
+	}
+
+	for _, doc = range tok.Doc {
+		gt.Doc = append(gt.Doc, toGob(doc))
+	}
+
+	if tok.Origin != nil {
+		origin = toGobSpan(*tok.Origin)
+		gt.Origin = &origin
+	}
+
+	return gt
+}
+
+func fromGob(gt gobToken) lexer.Token {
+	var (
+		tok    lexer.Token
+		doc    gobToken
+		origin lexer.Span
+	)
+
+	tok = lexer.Token{
+		Kind:   lexer.Kind(gt.Kind),
+		Text:   gt.Text,
+		Span:   fromGobSpan(gt.Span),
+		Trivia: gt.Trivia,
+	}
+
+	for _, doc = range gt.Doc {
+		tok.Doc = append(tok.Doc, fromGob(doc))
+	}
+
+	if gt.Origin != nil {
+		origin = fromGobSpan(*gt.Origin)
+		tok.Origin = &origin
+	}
+
+	return tok
+}
+
+func toGobSpan(span lexer.Span) gobSpan {
+	return gobSpan{
+		Start: toGobPosition(span.Start),
+		End:   toGobPosition(span.End),
+	}
+}
+
+func fromGobSpan(span gobSpan) lexer.Span {
+	return lexer.Span{
+		Start: fromGobPosition(span.Start),
+		End:   fromGobPosition(span.End),
+	}
+}
+
+func toGobPosition(pos lexer.Position) gobPosition {
+	return gobPosition{
+		Line:   int64(pos.Line),
+		Column: int64(pos.Column),
+		Offset: int64(pos.Offset),
+	}
+}
+
+func fromGobPosition(pos gobPosition) lexer.Position {
+	return lexer.Position{
+		Line:   int(pos.Line),
+		Column: int(pos.Column),
+		Offset: int(pos.Offset),
+	}
+}
@@ -0,0 +1,347 @@
+package tokenwire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// Wire types of the Protocol Buffers encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// ProtoEncoder is an Encoder writing tokens as length-delimited
+// Protocol Buffers messages. A new ProtoEncoder is constructed with
+// NewProtoEncoder.
+type ProtoEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// ProtoDecoder reads tokens written by a ProtoEncoder, or by any
+// Protocol Buffers library following the schema of the package. It is a
+// lexer.TokenStream, so that passes can run directly on the decoded
+// tokens. A new ProtoDecoder is constructed with NewProtoDecoder.
+type ProtoDecoder struct {
+	r   io.ByteReader
+	src io.Reader
+	msg bytes.Buffer
+	err error
+}
+
+// protoField is a single field of a message being decoded.
+type protoField struct {
+	num    int
+	varint uint64
+	data   []byte
+}
+
+// NewProtoEncoder returns a ProtoEncoder writing to w. Every token is
+// written with a single call to the Write method of w.
+func NewProtoEncoder(w io.Writer) *ProtoEncoder {
+	return &ProtoEncoder{w: w}
+}
+
+// Encode implements Encoder.
+func (enc *ProtoEncoder) Encode(tok lexer.Token) error {
+	var (
+		msg []byte
+		err error
+	)
+
+	msg = appendToken(nil, tok)
+	enc.buf = binary.AppendUvarint(enc.buf[:0], uint64(len(msg)))
+	enc.buf = append(enc.buf, msg...)
+
+	_, err = enc.w.Write(enc.buf)
+
+	return err
+}
+
+// NewProtoDecoder returns a ProtoDecoder reading from r. If r does not
+// implement io.ByteReader, it is buffered, so the decoder may read past
+// the last token it returns.
+func NewProtoDecoder(r io.Reader) *ProtoDecoder {
+	var (
+		br io.ByteReader
+		ok bool
+	)
+
+	br, ok = r.(io.ByteReader)
+	if !ok {
+		r = bufio.NewReader(r)
+		br = r.(io.ByteReader)
+	}
+
+	return &ProtoDecoder{
+		r:   br,
+		src: r,
+	}
+}
+
+// Decode reads the next token. Returns io.EOF at the end of the stream,
+// io.ErrUnexpectedEOF if the stream ends inside a token, an error
+// wrapping ErrMalformed if the token is not valid, or the error of the
+// underlying io.Reader.
+func (dec *ProtoDecoder) Decode() (lexer.Token, error) {
+	var (
+		size uint64
+		n    int64
+		err  error
+	)
+
+	size, err = binary.ReadUvarint(dec.r)
+	if err != nil {
+		return lexer.Token{}, err
+	}
+
+	dec.msg.Reset()
+
+	// The message is read as it arrives rather than allocated upfront,
+	// so that a corrupt length cannot exhaust memory.
+	n, err = io.CopyN(&dec.msg, dec.src, int64(min(size, 1<<62)))
+	if err == io.EOF || err == nil && uint64(n) != size {
+		return lexer.Token{}, io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return lexer.Token{}, err
+	}
+
+	return parseToken(dec.msg.Bytes())
+}
+
+// NextToken implements lexer.TokenStream. It returns false at the end
+// of the stream or at the first error, which Err reports.
+func (dec *ProtoDecoder) NextToken() (lexer.Token, bool) {
+	var tok lexer.Token
+
+	if dec.err != nil {
+		return lexer.Token{}, false
+	}
+
+	tok, dec.err = dec.Decode()
+
+	return tok, dec.err == nil
+}
+
+// Err returns the first error encountered by NextToken other than
+// io.EOF, or nil.
+func (dec *ProtoDecoder) Err() error {
+	if dec.err == io.EOF {
+		return nil
+	}
+
+	return dec.err
+}
+
+func appendToken(b []byte, tok lexer.Token) []byte {
+	var doc lexer.Token
+
+	b = appendVarint(b, 1, uint64(tok.Kind))
+	b = appendBytes(b, 2, []byte(tok.Text))
+	b = appendBytes(b, 3, appendSpan(nil, tok.Span))
+
+	if tok.Trivia {
+		b = appendVarint(b, 4, 1)
+	}
+
+	for _, doc = range tok.Doc {
+		b = appendTag(b, 5, wireBytes)
+		b = appendLengthPrefixed(b, appendToken(nil, doc))
+	}
+
+	if tok.Origin != nil {
+		b = appendTag(b, 6, wireBytes)
+		b = appendLengthPrefixed(b, appendSpan(nil, *tok.Origin))
+	}
+
+	return b
+}
+
+func appendSpan(b []byte, span lexer.Span) []byte {
+	b = appendBytes(b, 1, appendPosition(nil, span.Start))
+
+	return appendBytes(b, 2, appendPosition(nil, span.End))
+}
+
+func appendPosition(b []byte, pos lexer.Position) []byte {
+	b = appendVarint(b, 1, uint64(pos.Line))
+	b = appendVarint(b, 2, uint64(pos.Column))
+
+	return appendVarint(b, 3, uint64(pos.Offset))
+}
+
+// appendVarint appends a varint field, omitting the default zero value
+// as proto3 does.
+func appendVarint(b []byte, num int, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+
+	b = appendTag(b, num, wireVarint)
+
+	return binary.AppendUvarint(b, value)
+}
+
+// appendBytes appends a length-delimited field, omitting it when empty
+// as proto3 does.
+func appendBytes(b []byte, num int, data []byte) []byte {
+	if len(data) == 0 {
+		return b
+	}
+
+	b = appendTag(b, num, wireBytes)
+
+	return appendLengthPrefixed(b, data)
+}
+
+func appendLengthPrefixed(b, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+
+	return append(b, data...)
+}
+
+func appendTag(b []byte, num, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(wire))
+}
+
+func parseToken(b []byte) (lexer.Token, error) {
+	var (
+		tok    lexer.Token
+		doc    lexer.Token
+		origin lexer.Span
+		err    error
+	)
+
+	err = parseFields(b, func(field protoField) error {
+		var err error
+
+		switch field.num {
+		case 1:
+			tok.Kind = lexer.Kind(int64(field.varint))
+		case 2:
+			tok.Text = string(field.data)
+		case 3:
+			tok.Span, err = parseSpan(field.data)
+		case 4:
+			tok.Trivia = field.varint != 0
+		case 5:
+			doc, err = parseToken(field.data)
+			tok.Doc = append(tok.Doc, doc)
+		case 6:
+			origin, err = parseSpan(field.data)
+			tok.Origin = &origin
+		}
+
+		return err
+	})
+
+	return tok, err
+}
+
+func parseSpan(b []byte) (lexer.Span, error) {
+	var (
+		span lexer.Span
+		err  error
+	)
+
+	err = parseFields(b, func(field protoField) error {
+		var err error
+
+		switch field.num {
+		case 1:
+			span.Start, err = parsePosition(field.data)
+		case 2:
+			span.End, err = parsePosition(field.data)
+		}
+
+		return err
+	})
+
+	return span, err
+}
+
+func parsePosition(b []byte) (lexer.Position, error) {
+	var (
+		pos lexer.Position
+		err error
+	)
+
+	err = parseFields(b, func(field protoField) error {
+		switch field.num {
+		case 1:
+			pos.Line = int(int64(field.varint))
+		case 2:
+			pos.Column = int(int64(field.varint))
+		case 3:
+			pos.Offset = int(int64(field.varint))
+		}
+
+		return nil
+	})
+
+	return pos, err
+}
+
+// parseFields calls fn for every field of the message b. A field whose
+// wire type does not fit its number reaches fn with zero values, so it
+// is ignored like an unknown field.
+func parseFields(b []byte, fn func(protoField) error) error {
+	var (
+		field protoField
+		tag   uint64
+		size  uint64
+		n     int
+		err   error
+	)
+
+	for len(b) != 0 {
+		tag, n = binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > 1<<29-1 {
+			return ErrMalformed
+		}
+
+		b = b[n:]
+		field = protoField{num: int(tag >> 3)}
+
+		switch tag & 7 {
+		case wireVarint:
+			field.varint, n = binary.Uvarint(b)
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			size, n = binary.Uvarint(b)
+			if n > 0 && size <= uint64(len(b)-n) {
+				field.data = b[n : n+int(size)]
+				n += int(size)
+			} else {
+				n = 0
+			}
+		default:
+			n = 0
+		}
+
+		if n <= 0 || n > len(b) {
+			return ErrMalformed
+		}
+
+		b = b[n:]
+
+		err = fn(field)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package tokenwire streams lexer.Tokens across process boundaries, so
+// that a lexing service can feed a parsing service without a bespoke
+// wire format. Tokens are encoded one at a time as they are produced,
+// either with encoding/gob or as Protocol Buffers messages, and decoded
+// back into a lexer.TokenStream.
+//
+// Both encodings follow a stable schema that does not depend on the
+// layout of lexer.Token, and decoders ignore fields they do not know,
+// so that peers built from different versions of the package can talk
+// to each other. The Protocol Buffers schema is:
+//
+//	syntax = "proto3";
+//
+//	message Position {
+//		int64 line = 1;
+//		int64 column = 2;
+//		int64 offset = 3;
+//	}
+//
+//	message Span {
+//		Position start = 1;
+//		Position end = 2;
+//	}
+//
+//	message Token {
+//		int64 kind = 1;
+//		string text = 2;
+//		Span span = 3;
+//		bool trivia = 4;
+//		repeated Token doc = 5;
+//		Span origin = 6;
+//	}
+//
+// A stream is a sequence of Token messages, each preceded by its length
+// in bytes as a varint, as written by writeDelimitedTo in the Protocol
+// Buffers libraries for Java and C++. The gob encoding sends values of
+// a Go type with the same fields as Token.
+package tokenwire // import "github.com/andrieee44/langengine/tokenwire"
+
+import (
+	"errors"
+
+	"github.com/andrieee44/langengine/lexer"
+)
+
+// ErrMalformed is returned by the decoders for input that is not a
+// valid encoding of a token.
+var ErrMalformed = errors.New("tokenwire: malformed token")
+
+// Encoder writes tokens to a stream.
+type Encoder interface {
+	// Encode writes tok to the stream.
+	Encode(tok lexer.Token) error
+}
+
+// Copy encodes every token of stream with enc, in order, until stream
+// is exhausted or encoding fails. Returns the number of tokens encoded
+// and the first error encountered.
+func Copy(enc Encoder, stream lexer.TokenStream) (int, error) {
+	var (
+		tok   lexer.Token
+		count int
+		err   error
+	)
+
+	for tok = range lexer.Tokens(stream) {
+		err = enc.Encode(tok)
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	return count, nil
+}
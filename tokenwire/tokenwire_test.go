@@ -0,0 +1,217 @@
+package tokenwire_test
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/andrieee44/langengine/lexer"
+	"github.com/andrieee44/langengine/tokenwire"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoder interface {
+	lexer.TokenStream
+
+	Err() error
+}
+
+func pos(line, column, offset int) lexer.Position {
+	return lexer.Position{
+		Line:   line,
+		Column: column,
+		Offset: offset,
+	}
+}
+
+var tokens = []lexer.Token{
+	{
+		Kind: lexer.KindUser,
+		Text: "fn",
+		Span: lexer.Span{Start: pos(2, 1, 10), End: pos(2, 3, 12)},
+		Doc: []lexer.Token{{
+			Kind:   lexer.KindUser + 1,
+			Text:   "// doc",
+			Span:   lexer.Span{Start: pos(1, 1, 0), End: pos(1, 7, 6)},
+			Trivia: true,
+		}},
+	},
+	{
+		Kind:   lexer.KindUser + 2,
+		Text:   "",
+		Span:   lexer.Span{Start: pos(0, 0, 0), End: pos(0, 0, 0)},
+		Origin: &lexer.Span{Start: pos(3, 4, 20), End: pos(3, 9, 25)},
+	},
+	{
+		Kind: lexer.KindError,
+		Text: "unexpected \"é\"",
+		Span: lexer.Span{Start: pos(4, 1, 30), End: pos(4, 2, 32)},
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	type testData struct {
+		encoder func(io.Writer) tokenwire.Encoder
+		decoder func(io.Reader) decoder
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Gob": {
+			encoder: func(w io.Writer) tokenwire.Encoder {
+				return tokenwire.NewGobEncoder(w)
+			},
+			decoder: func(r io.Reader) decoder {
+				return tokenwire.NewGobDecoder(r)
+			},
+		},
+		"Proto": {
+			encoder: func(w io.Writer) tokenwire.Encoder {
+				return tokenwire.NewProtoEncoder(w)
+			},
+			decoder: func(r io.Reader) decoder {
+				return tokenwire.NewProtoDecoder(r)
+			},
+		},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf   bytes.Buffer
+				count int
+				dec   decoder
+				err   error
+			)
+
+			t.Parallel()
+
+			count, err = tokenwire.Copy(
+				test.encoder(&buf),
+				lexer.StreamFunc(sliceTokens(tokens)),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tokens), count)
+
+			dec = test.decoder(&buf)
+
+			assert.Equal(t, tokens, slices.Collect(lexer.Tokens(dec)))
+			assert.NoError(t, dec.Err())
+		})
+	}
+}
+
+func TestProtoSchema(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		dec *tokenwire.ProtoDecoder
+		tok lexer.Token
+		err error
+	)
+
+	t.Parallel()
+
+	assert.NoError(t, tokenwire.NewProtoEncoder(&buf).Encode(lexer.Token{
+		Kind:   lexer.KindUser,
+		Text:   "a",
+		Span:   lexer.Span{Start: pos(1, 1, 0), End: pos(1, 2, 1)},
+		Trivia: true,
+	}))
+
+	assert.Equal(t, []byte{
+		0x17,
+		0x08, 0x02,
+		0x12, 0x01, 'a',
+		0x1a, 0x0e,
+		0x0a, 0x04, 0x08, 0x01, 0x10, 0x01,
+		0x12, 0x06, 0x08, 0x01, 0x10, 0x02, 0x18, 0x01,
+		0x20, 0x01,
+	}, buf.Bytes())
+
+	// Unknown fields of every wire type are skipped.
+	dec = tokenwire.NewProtoDecoder(bytes.NewReader([]byte{
+		0x16,
+		0x38, 0x05,
+		0x41, 1, 2, 3, 4, 5, 6, 7, 8,
+		0x4a, 0x01, 'x',
+		0x55, 1, 2, 3, 4,
+		0x12, 0x01, 'b',
+	}))
+
+	tok, err = dec.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, lexer.Token{Text: "b"}, tok)
+
+	_, err = dec.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestProtoDecoderErrors(t *testing.T) {
+	type testData struct {
+		data []byte
+		err  error
+	}
+
+	var (
+		testTbl map[string]testData
+		name    string
+		test    testData
+	)
+
+	t.Parallel()
+
+	testTbl = map[string]testData{
+		"Empty":     {nil, io.EOF},
+		"Length":    {[]byte{0x80}, io.ErrUnexpectedEOF},
+		"Truncated": {[]byte{0x03, 0x12, 0x01}, io.ErrUnexpectedEOF},
+		"Overrun":   {[]byte{0x02, 0x12, 0x05}, tokenwire.ErrMalformed},
+		"WireType":  {[]byte{0x01, 0x0f}, tokenwire.ErrMalformed},
+		"FieldZero": {[]byte{0x02, 0x00, 0x00}, tokenwire.ErrMalformed},
+	}
+
+	for name, test = range testTbl {
+		t.Run(name, func(t *testing.T) {
+			var (
+				dec *tokenwire.ProtoDecoder
+				ok  bool
+			)
+
+			t.Parallel()
+
+			dec = tokenwire.NewProtoDecoder(bytes.NewReader(test.data))
+
+			_, ok = dec.NextToken()
+			assert.False(t, ok)
+
+			if test.err == io.EOF {
+				assert.NoError(t, dec.Err())
+
+				return
+			}
+
+			assert.ErrorIs(t, dec.Err(), test.err)
+		})
+	}
+}
+
+func sliceTokens(tokens []lexer.Token) func() (lexer.Token, bool) {
+	return func() (lexer.Token, bool) {
+		var tok lexer.Token
+
+		if len(tokens) == 0 {
+			return lexer.Token{}, false
+		}
+
+		tok, tokens = tokens[0], tokens[1:]
+
+		return tok, true
+	}
+}